@@ -11,8 +11,18 @@ import (
 	Disk "backend/Comandos/Disk"
 	Forge "backend/Comandos/Forge"
 	User "backend/Comandos/User"
+	Estructuras "backend/Estructuras"
+	Global "backend/Global"
 )
 
+// Comandos que leen o escriben el disco sin pasar por los inodos y bloques decodificados;
+// se ejecutan con las caches de las particiones montadas vaciadas y suspendidas
+var comandosSinCache = map[string]bool{
+	"mkdisk": true, "rmdisk": true, "fdisk": true, "mkfs": true, "defragdisk": true,
+	"ptdump": true, "ptrestore": true, "verify": true, "hexdump": true, "resizefs": true,
+	"loss": true, "recovery": true,
+}
+
 // Funcion principal que procesa las entradas del usuario
 func Analizador(entrada string) (string, error) {
 	if strings.HasPrefix(strings.TrimSpace(entrada), "#") {
@@ -33,6 +43,7 @@ func Analizador(entrada string) (string, error) {
 		case "clear":
 			return limpiarTerminal()
 		case "exit":
+			Global.CerrarCaches()
 			os.Exit(0)
 		case "help":
 			return mostrarAyuda(nil)
@@ -41,8 +52,23 @@ func Analizador(entrada string) (string, error) {
 		return "", fmt.Errorf("comando no reconocido: %s", tokens[0])
 	}
 
+	if comandosSinCache[tokens[0]] {
+		err := Global.SuspenderCaches()
+		defer Global.ReanudarCaches()
+		if err != nil {
+			return "", err
+		}
+	}
+
+	// Las cuotas se calculan de nuevo para cada comando
+	Estructuras.ReiniciarCuotas()
+
 	// Invocar la funcion asociada al comando
-	return funcionComando(tokens[1:])
+	resultado, err := funcionComando(tokens[1:])
+	for _, advertencia := range Estructuras.AdvertenciasCuotas() {
+		resultado += "Advertencia: " + advertencia + "\n"
+	}
+	return resultado, err
 }
 
 // Diccionario que asocia comandos con sus funciones correspondientes
@@ -67,10 +93,42 @@ var mapaComandos = map[string]func([]string) (string, error){
 		result, err := Disk.ParserMounted(args)
 		return fmt.Sprintf("%v", result), err
 	},
+	"lsblk": func(argumentos []string) (string, error) {
+		resultado, err := Disk.ParserLsblk(argumentos)
+		return fmt.Sprintf("%v", resultado), err
+	},
+	"df": func(argumentos []string) (string, error) {
+		resultado, err := Disk.ParserDf(argumentos)
+		return fmt.Sprintf("%v", resultado), err
+	},
 	"mkfs": func(argumentos []string) (string, error) {
 		resultado, err := Disk.ParserMkfs(argumentos)
 		return fmt.Sprintf("%v", resultado), err
 	},
+	"defragdisk": func(argumentos []string) (string, error) {
+		resultado, err := Disk.ParserDefragdisk(argumentos)
+		return fmt.Sprintf("%v", resultado), err
+	},
+	"ptdump": func(argumentos []string) (string, error) {
+		resultado, err := Disk.ParserPtdump(argumentos)
+		return fmt.Sprintf("%v", resultado), err
+	},
+	"ptrestore": func(argumentos []string) (string, error) {
+		resultado, err := Disk.ParserPtrestore(argumentos)
+		return fmt.Sprintf("%v", resultado), err
+	},
+	"verify": func(argumentos []string) (string, error) {
+		resultado, err := Disk.ParserVerify(argumentos)
+		return fmt.Sprintf("%v", resultado), err
+	},
+	"hexdump": func(argumentos []string) (string, error) {
+		resultado, err := Disk.ParserHexdump(argumentos)
+		return fmt.Sprintf("%v", resultado), err
+	},
+	"resizefs": func(argumentos []string) (string, error) {
+		resultado, err := Disk.ParserResizefs(argumentos)
+		return fmt.Sprintf("%v", resultado), err
+	},
 	"login": func(argumentos []string) (string, error) {
 		resultado, err := User.ParserLogin(argumentos)
 		return fmt.Sprintf("%v", resultado), err
@@ -99,6 +157,14 @@ var mapaComandos = map[string]func([]string) (string, error){
 		resultado, err := User.ParserChgrp(argumentos)
 		return fmt.Sprintf("%v", resultado), err
 	},
+	"setquota": func(argumentos []string) (string, error) {
+		resultado, err := User.ParserSetquota(argumentos)
+		return fmt.Sprintf("%v", resultado), err
+	},
+	"repquota": func(argumentos []string) (string, error) {
+		resultado, err := User.ParserRepquota(argumentos)
+		return fmt.Sprintf("%v", resultado), err
+	},
 	"mkdir": func(argumentos []string) (string, error) {
 		resultado, err := Forge.ParserMkdir(argumentos)
 		return fmt.Sprintf("%v", resultado), err
@@ -119,14 +185,50 @@ var mapaComandos = map[string]func([]string) (string, error){
 		resultado, err := Disk.ParserUnmount(argumentos)
 		return fmt.Sprintf("%v", resultado), err
 	},
+	"sync": func(argumentos []string) (string, error) {
+		resultado, err := Disk.ParserSync(argumentos)
+		return fmt.Sprintf("%v", resultado), err
+	},
 	"edit": func(argumentos []string) (string, error) {
 		resultado, err := Forge.ParserEdit(argumentos)
 		return fmt.Sprintf("%v", resultado), err
 	},
+	"truncate": func(argumentos []string) (string, error) {
+		resultado, err := Forge.ParserTruncate(argumentos)
+		return fmt.Sprintf("%v", resultado), err
+	},
 	"rename": func(argumentos []string) (string, error) {
 		resultado, err := Forge.ParserRename(argumentos)
 		return fmt.Sprintf("%v", resultado), err
 	},
+	"ln": func(argumentos []string) (string, error) {
+		resultado, err := Forge.ParserLn(argumentos)
+		return fmt.Sprintf("%v", resultado), err
+	},
+	"stat": func(argumentos []string) (string, error) {
+		resultado, err := Forge.ParserStat(argumentos)
+		return fmt.Sprintf("%v", resultado), err
+	},
+	"touch": func(argumentos []string) (string, error) {
+		resultado, err := Forge.ParserTouch(argumentos)
+		return fmt.Sprintf("%v", resultado), err
+	},
+	"setxattr": func(argumentos []string) (string, error) {
+		resultado, err := Forge.ParserSetxattr(argumentos)
+		return fmt.Sprintf("%v", resultado), err
+	},
+	"getxattr": func(argumentos []string) (string, error) {
+		resultado, err := Forge.ParserGetxattr(argumentos)
+		return fmt.Sprintf("%v", resultado), err
+	},
+	"listxattr": func(argumentos []string) (string, error) {
+		resultado, err := Forge.ParserListxattr(argumentos)
+		return fmt.Sprintf("%v", resultado), err
+	},
+	"rmxattr": func(argumentos []string) (string, error) {
+		resultado, err := Forge.ParserRmxattr(argumentos)
+		return fmt.Sprintf("%v", resultado), err
+	},
 	"copy": func(argumentos []string) (string, error) {
 		resultado, err := Forge.ParserCopy(argumentos)
 		return fmt.Sprintf("%v", resultado), err
@@ -173,25 +275,66 @@ Lista de comandos disponibles en el sistema:
 
 GESTION DE DISCOS:
 - mkdisk: Genera un nuevo disco virtual
-  Sintaxis: mkdisk -size=100 -unit=M -fit=FF -path="/ruta/archivo.mia"
+  Sintaxis: mkdisk -size=100 -unit=M -fit=FF -path="/ruta/archivo.mia" [-table=MBR|GPT] [-prealloc] [-compat]
+  Nota: el disco se crea como archivo disperso; -prealloc reserva todo el espacio
+  Nota: con -table=GPT el disco admite 128 particiones primarias con nombre y GUID
 
 - rmdisk: Elimina un disco virtual existente
   Sintaxis: rmdisk -path="/ruta/archivo.mia"
 
 - fdisk: Administra particiones en el disco
   Sintaxis: fdisk -size=50 -unit=M -path="/ruta/archivo.mia" -type=P -name="Particion1"
+  Simulacion: fdisk -simulate -size=50 -unit=M -path="/ruta/archivo.mia" [-fit=BF]
+  Nota: -simulate muestra los huecos y el hueco que elegiria cada ajuste sin modificar el disco
 
 - mount: Monta una particion en el sistema
   Sintaxis: mount -path="/ruta/archivo.mia" -name="Particion1"
 
 - unmount: Desmonta una particion del sistema
   Sintaxis: unmount -id=vd1
+  Nota: escribe antes los inodos y bloques modificados que siguen en la cache
+
+- sync: Escribe en el disco los inodos y bloques modificados de las particiones montadas
+  Sintaxis: sync [-id=vd1]
+  Nota: sin -id sincroniza todas; tambien ocurre al desmontar y al cerrar el servidor
 
 - mounted: Lista las particiones montadas
   Sintaxis: mounted
 
+- df: Muestra los inodos y bloques totales, usados y libres de las particiones montadas
+  Sintaxis: df [-id=vd1] [-fix]
+  Nota: cuenta los bitmaps y avisa si los contadores del superbloque no coinciden;
+        -fix (solo root) los corrige
+
+- lsblk: Lista las particiones de un disco (MBR o GPT)
+  Sintaxis: lsblk -path="/ruta/archivo.mia"
+
+- defragdisk: Mueve las particiones hacia el inicio del disco eliminando huecos
+  Sintaxis: defragdisk -path="/ruta/archivo.mia"
+
+- ptdump: Exporta la tabla de particiones (MBR/GPT y cadena de EBR) a JSON
+  Sintaxis: ptdump -path="/ruta/archivo.mia" -out="/ruta/layout.json"
+
+- ptrestore: Reescribe la tabla de particiones desde un JSON de ptdump
+  Sintaxis: ptrestore -path="/ruta/archivo.mia" -in="/ruta/layout.json"
+  Nota: valida limites y solapamientos; no modifica el contenido de las particiones
+
+- verify: Revisa los checksums del MBR, los EBR y los superbloques de un disco
+  Sintaxis: verify -path="/ruta/archivo.mia"
+
+- hexdump: Muestra bytes del disco anotados con la estructura y el campo al que pertenecen
+  Sintaxis: hexdump -path="/ruta/archivo.mia" | -id=891A [-offset=0x200] [-len=256] [-inode=N] [-block=N]
+  Nota: con -id el offset es relativo al inicio de la particion; -inode y -block requieren -id
+
 - mkfs: Aplica formato a una particion
-  Sintaxis: mkfs -id=vd1 -type=full
+  Sintaxis: mkfs -id=vd1 -type=full [-fs=2fs|3fs] [-bs=64|128|256|512] [-ratio=N | -inodes=N] [-groups]
+  Nota: por defecto bloques de 64 bytes y 3 bloques por inodo; -inodes fija la cantidad de inodos
+  Nota: -groups divide la particion en grupos de bloques con sus propios bitmaps y tabla de inodos,
+        y guarda copias del superbloque en los grupos 1 y potencias de 3, 5 y 7
+
+- resizefs: Cambia el tamaño de una particion formateada conservando sus datos
+  Sintaxis: resizefs -id=891A -size=3 -unit=M
+  Nota: al reducir, se rechaza si los inodos o bloques en uso no caben
 
 ADMINISTRACION DE USUARIOS:
 - login: Accede al sistema con credenciales
@@ -215,12 +358,20 @@ ADMINISTRACION DE USUARIOS:
 - chgrp: Modifica el grupo de un usuario
   Sintaxis: chgrp -user=usuario1 -grp=usuarios
 
+- setquota: Fija limites suaves y duros de bloques e inodos de un usuario o grupo (0 = sin limite)
+  Sintaxis: setquota -user=usuario1 [-bsoft=N] [-bhard=N] [-isoft=N] [-ihard=N]
+            setquota -grp=usuarios [-bsoft=N] [-bhard=N] [-isoft=N] [-ihard=N]
+
+- repquota: Muestra el uso de bloques e inodos de cada usuario y grupo contra sus limites
+  Sintaxis: repquota
+
 MANEJO DE ARCHIVOS Y DIRECTORIOS:
 - mkdir: Genera un directorio
   Sintaxis: mkdir -path="/home/carpeta" -p
 
-- mkfile: Crea un nuevo archivo
+- mkfile: Crea un nuevo archivo; -file importa byte por byte un archivo del equipo
   Sintaxis: mkfile -path="/home/archivo.txt" -size=100 -cont="contenido"
+            mkfile -path="/home/imagen.png" -file="/ruta/local/imagen.png"
 
 - cat: Muestra el contenido de archivos
   Sintaxis: cat -file="/home/archivo.txt"
@@ -228,8 +379,11 @@ MANEJO DE ARCHIVOS Y DIRECTORIOS:
 - remove: Elimina archivos o directorios
   Sintaxis: remove -path="/home/archivo.txt"
 
-- edit: Modifica el contenido de un archivo
-  Sintaxis: edit -path="/home/archivo.txt" -cont="nuevo contenido"
+- edit: Modifica el contenido de un archivo; con -offset o -append solo escribe ese rango
+  Sintaxis: edit -ruta="/home/archivo.txt" -cont="/ruta/local/contenido.txt" [-offset=N | -append]
+
+- truncate: Recorta o extiende un archivo al tamaño indicado en bytes
+  Sintaxis: truncate -path="/home/archivo.txt" -size=N
 
 - rename: Cambia el nombre de archivos o directorios
   Sintaxis: rename -path="/home/archivo.txt" -name="nuevo_nombre.txt"
@@ -240,6 +394,27 @@ MANEJO DE ARCHIVOS Y DIRECTORIOS:
 - move: Mueve archivos o directorios
   Sintaxis: move -path="/home/archivo.txt" -dest="/home/nueva_ubicacion/"
 
+- ln: Crea un enlace duro o, con -s, un enlace simbolico que guarda la ruta -path
+  Sintaxis: ln -path="/home/archivo.txt" -dest="/home/enlace.txt" [-s]
+
+- stat: Muestra todos los campos del inodo con fechas legibles y nombres de dueño
+  Sintaxis: stat -path="/home/archivo.txt"
+
+- touch: Crea un archivo vacio o actualiza sus fechas de acceso y modificacion
+  Sintaxis: touch -path="/home/archivo.txt" [-date="2024-01-31 12:00:00"]
+
+- setxattr: Crea o reemplaza un atributo extendido sin cambiar el contenido
+  Sintaxis: setxattr -path="/home/foto.png" -name=mime -value="image/png"
+
+- getxattr: Muestra el valor de un atributo extendido
+  Sintaxis: getxattr -path="/home/foto.png" -name=mime
+
+- listxattr: Lista los atributos extendidos con sus valores
+  Sintaxis: listxattr -path="/home/foto.png"
+
+- rmxattr: Elimina un atributo extendido
+  Sintaxis: rmxattr -path="/home/foto.png" -name=mime
+
 - find: Busca archivos y directorios
   Sintaxis: find -path="/home" -name="archivo.txt"
 
@@ -0,0 +1,323 @@
+package Disk
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	Estructuras "backend/Estructuras"
+)
+
+// Dimension del buffer usado al mover los datos de una particion
+const tamanoBufferMovimiento = 1024 * 1024
+
+// DefragDisk representa el comando defragdisk con sus parametros
+type DefragDisk struct {
+	ruta string // Ubicacion del archivo del disco
+}
+
+// Procesa el comando defragdisk y retorna los mensajes generados
+func ParserDefragdisk(tokens []string) (string, error) {
+	var bufferSalida bytes.Buffer
+	cmd := &DefragDisk{}
+
+	argumentos := strings.Join(tokens, " ")
+	patron := regexp.MustCompile(`-path="[^"]+"|-path=[^\s]+`)
+	coincidencias := patron.FindAllString(argumentos, -1)
+
+	for _, coincidencia := range coincidencias {
+		claveValor := strings.SplitN(coincidencia, "=", 2)
+		if len(claveValor) != 2 {
+			return "", fmt.Errorf("formato de parametro invalido: %s", coincidencia)
+		}
+		clave, valor := strings.ToLower(claveValor[0]), claveValor[1]
+		if strings.HasPrefix(valor, "\"") && strings.HasSuffix(valor, "\"") {
+			valor = strings.Trim(valor, "\"")
+		}
+
+		switch clave {
+		case "-path":
+			if valor == "" {
+				return "", errors.New("la ruta no puede estar vacia")
+			}
+			cmd.ruta = valor
+		default:
+			return "", fmt.Errorf("parametro desconocido: %s", clave)
+		}
+	}
+
+	if cmd.ruta == "" {
+		return "", errors.New("faltan parametros requeridos: -path")
+	}
+
+	err := ejecutarComandoDefragdisk(cmd, &bufferSalida)
+	if err != nil {
+		return "", fmt.Errorf("error al compactar el disco: %v", err)
+	}
+
+	return bufferSalida.String(), nil
+}
+
+func ejecutarComandoDefragdisk(defrag *DefragDisk, bufferSalida *bytes.Buffer) error {
+	fmt.Fprintln(bufferSalida, "-------------------------- DEFRAGDISK --------------------------")
+
+	archivo, err := os.OpenFile(defrag.ruta, os.O_RDWR, 0644)
+	if err != nil {
+		return fmt.Errorf("error abriendo el archivo del disco en la ruta: %s: %v", defrag.ruta, err)
+	}
+	defer archivo.Close()
+
+	var mbr Estructuras.MBR
+	if err := mbr.Decodificar(archivo); err != nil {
+		return fmt.Errorf("error al deserializar el MBR: %v", err)
+	}
+
+	if mbr.EsProtectorGPT() {
+		err = compactarDiscoGPT(archivo, bufferSalida)
+	} else {
+		err = compactarDiscoMBR(archivo, &mbr, bufferSalida)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(bufferSalida, "Disco compactado correctamente.")
+	fmt.Fprintln(bufferSalida, "--------------------------------------------")
+	return nil
+}
+
+// Desliza las particiones del MBR hacia el inicio del disco conservando su orden
+func compactarDiscoMBR(archivo *os.File, mbr *Estructuras.MBR, bufferSalida *bytes.Buffer) error {
+	var ocupadas []*Estructuras.Particion
+	for i := range mbr.MbrPartitions {
+		if mbr.MbrPartitions[i].Part_start != -1 && mbr.MbrPartitions[i].Part_size > 0 {
+			ocupadas = append(ocupadas, &mbr.MbrPartitions[i])
+		}
+	}
+	sort.Slice(ocupadas, func(i, j int) bool { return ocupadas[i].Part_start < ocupadas[j].Part_start })
+
+	// Validar todas las estructuras antes de mover datos para no dejar el disco a medias
+	superbloques := make(map[int32]*Estructuras.SuperBlock)
+	for _, particion := range ocupadas {
+		nombre := strings.Trim(string(particion.Part_name[:]), "\x00 ")
+		if particion.Part_type[0] == 'E' {
+			if err := validarCadenaEBR(archivo, particion.Part_start); err != nil {
+				return fmt.Errorf("no se puede compactar la extendida '%s': %v", nombre, err)
+			}
+			continue
+		}
+		sb, err := leerSuperBlockExistente(archivo, particion.Part_start)
+		if err != nil {
+			return fmt.Errorf("no se puede compactar la particion '%s': %v", nombre, err)
+		}
+		superbloques[particion.Part_start] = sb
+	}
+
+	cursor := mbr.InicioUsable()
+	for _, particion := range ocupadas {
+		nombre := strings.Trim(string(particion.Part_name[:]), "\x00 ")
+		delta := cursor - particion.Part_start
+		sb := superbloques[particion.Part_start]
+
+		if delta < 0 {
+			err := moverParticion(archivo, particion.Part_start, cursor, particion.Part_size)
+			if err != nil {
+				return fmt.Errorf("error al mover la particion '%s': %v", nombre, err)
+			}
+			fmt.Fprintf(bufferSalida, "Particion '%s' movida de %d a %d\n", nombre, particion.Part_start, cursor)
+			particion.Part_start = cursor
+		}
+
+		if particion.Part_type[0] == 'E' {
+			if err := compactarCadenaEBR(archivo, particion.Part_start, delta, bufferSalida); err != nil {
+				return fmt.Errorf("error al compactar las particiones logicas de '%s': %v", nombre, err)
+			}
+		} else if delta < 0 && sb != nil {
+			if err := rebasarSuperBlock(archivo, sb, particion.Part_start, delta, nombre, bufferSalida); err != nil {
+				return err
+			}
+		}
+
+		cursor = particion.Part_start + particion.Part_size
+	}
+
+	if err := mbr.Codificar(archivo); err != nil {
+		return fmt.Errorf("error al actualizar el MBR en el disco: %v", err)
+	}
+
+	imprimirParticiones(mbr, bufferSalida)
+	return nil
+}
+
+// Desliza las entradas en uso de la tabla GPT hacia el primer byte usable
+func compactarDiscoGPT(archivo *os.File, bufferSalida *bytes.Buffer) error {
+	var gpt Estructuras.GPT
+	if err := gpt.Decodificar(archivo); err != nil {
+		return fmt.Errorf("error al deserializar la tabla GPT: %v", err)
+	}
+
+	var ocupadas []*Estructuras.EntradaGPT
+	for i := range gpt.Entradas {
+		if gpt.Entradas[i].EnUso() {
+			ocupadas = append(ocupadas, &gpt.Entradas[i])
+		}
+	}
+	sort.Slice(ocupadas, func(i, j int) bool { return ocupadas[i].Part_start < ocupadas[j].Part_start })
+
+	superbloques := make(map[int32]*Estructuras.SuperBlock)
+	for _, entrada := range ocupadas {
+		sb, err := leerSuperBlockExistente(archivo, entrada.Part_start)
+		if err != nil {
+			return fmt.Errorf("no se puede compactar la particion '%s': %v", entrada.Nombre(), err)
+		}
+		superbloques[entrada.Part_start] = sb
+	}
+
+	cursor := gpt.Encabezado.GptPrimerByteUsable
+	for _, entrada := range ocupadas {
+		delta := cursor - entrada.Part_start
+		sb := superbloques[entrada.Part_start]
+		if delta < 0 {
+			err := moverParticion(archivo, entrada.Part_start, cursor, entrada.Part_size)
+			if err != nil {
+				return fmt.Errorf("error al mover la particion '%s': %v", entrada.Nombre(), err)
+			}
+			fmt.Fprintf(bufferSalida, "Particion '%s' movida de %d a %d\n", entrada.Nombre(), entrada.Part_start, cursor)
+			entrada.Part_start = cursor
+
+			if sb != nil {
+				if err := rebasarSuperBlock(archivo, sb, entrada.Part_start, delta, entrada.Nombre(), bufferSalida); err != nil {
+					return err
+				}
+			}
+		}
+		cursor = entrada.Part_start + entrada.Part_size
+	}
+
+	if err := gpt.Codificar(archivo); err != nil {
+		return fmt.Errorf("error al actualizar la tabla GPT: %v", err)
+	}
+
+	imprimirParticionesGPT(&gpt, bufferSalida)
+	return nil
+}
+
+// Recorre la cadena de EBR de una extendida ya movida: corrige Ebr_start/Ebr_next
+// segun el desplazamiento y junta las particiones logicas eliminando los huecos
+func compactarCadenaEBR(archivo *os.File, inicioExtendida int32, delta int32, bufferSalida *bytes.Buffer) error {
+	posicion := inicioExtendida
+	for {
+		ebr, err := Estructuras.LeerEBR(posicion, archivo)
+		if err != nil {
+			return err
+		}
+		ebr.Ebr_start = posicion
+
+		if ebr.Ebr_next == -1 {
+			return ebr.Codificar(archivo, int64(posicion))
+		}
+
+		siguienteActual := ebr.Ebr_next + delta
+		siguienteNuevo := siguienteActual
+		if ebr.Ebr_size > 0 && posicion+ebr.Ebr_size < siguienteActual {
+			siguienteNuevo = posicion + ebr.Ebr_size
+		}
+
+		if siguienteNuevo < siguienteActual {
+			siguiente, err := Estructuras.LeerEBR(siguienteActual, archivo)
+			if err != nil {
+				return err
+			}
+			if err := moverParticion(archivo, siguienteActual, siguienteNuevo, siguiente.Ebr_size); err != nil {
+				return err
+			}
+			fmt.Fprintf(bufferSalida, "Particion logica '%s' movida de %d a %d\n",
+				strings.Trim(string(siguiente.Ebr_name[:]), "\x00 "), siguienteActual, siguienteNuevo)
+		}
+
+		ebr.Ebr_next = siguienteNuevo
+		if err := ebr.Codificar(archivo, int64(posicion)); err != nil {
+			return err
+		}
+		posicion = siguienteNuevo
+	}
+}
+
+// Copia el contenido de una particion a una posicion anterior del disco y limpia
+// con ceros la parte del origen que queda libre
+func moverParticion(archivo *os.File, origen int32, destino int32, tamano int32) error {
+	buffer := make([]byte, tamanoBufferMovimiento)
+	for copiado := int64(0); copiado < int64(tamano); {
+		porCopiar := int64(tamano) - copiado
+		if porCopiar > tamanoBufferMovimiento {
+			porCopiar = tamanoBufferMovimiento
+		}
+		if _, err := archivo.ReadAt(buffer[:porCopiar], int64(origen)+copiado); err != nil {
+			return fmt.Errorf("error leyendo en la posicion %d: %v", int64(origen)+copiado, err)
+		}
+		if _, err := archivo.WriteAt(buffer[:porCopiar], int64(destino)+copiado); err != nil {
+			return fmt.Errorf("error escribiendo en la posicion %d: %v", int64(destino)+copiado, err)
+		}
+		copiado += porCopiar
+	}
+
+	inicioLibre := destino + tamano
+	if inicioLibre < origen {
+		inicioLibre = origen
+	}
+	if _, err := archivo.WriteAt(make([]byte, origen+tamano-inicioLibre), int64(inicioLibre)); err != nil {
+		return fmt.Errorf("error limpiando el espacio liberado: %v", err)
+	}
+	return nil
+}
+
+// Lee el superbloque de una particion; retorna nil si la particion no esta formateada
+// y error si el superbloque existe pero esta corrupto
+func leerSuperBlockExistente(archivo *os.File, inicio int32) (*Estructuras.SuperBlock, error) {
+	var sb Estructuras.SuperBlock
+	err := sb.Decodificar(archivo, int64(inicio))
+	if errors.Is(err, Estructuras.ErrSinFormato) || (err == nil && sb.S_magic != 0xEF53) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &sb, nil
+}
+
+// Recorre la cadena de EBR comprobando que cada EBR sea legible y valido
+func validarCadenaEBR(archivo *os.File, inicio int32) error {
+	visitados := make(map[int32]bool)
+	for inicio != -1 {
+		if visitados[inicio] {
+			return fmt.Errorf("la cadena de EBR contiene un ciclo en %d", inicio)
+		}
+		visitados[inicio] = true
+		ebr, err := Estructuras.LeerEBR(inicio, archivo)
+		if err != nil {
+			return err
+		}
+		inicio = ebr.Ebr_next
+	}
+	return nil
+}
+
+// Corrige las posiciones del superbloque de una particion formateada que fue movida
+func rebasarSuperBlock(archivo *os.File, sb *Estructuras.SuperBlock, inicio int32, delta int32, nombre string, bufferSalida *bytes.Buffer) error {
+	sb.Desplazar(delta)
+	if err := sb.Codificar(archivo, int64(inicio)); err != nil {
+		return fmt.Errorf("error al escribir el superbloque de '%s': %v", nombre, err)
+	}
+	// Los respaldos de los grupos guardan las mismas posiciones absolutas
+	if sb.S_groups_count > 0 {
+		if err := sb.EscribirRespaldosGrupos(archivo); err != nil {
+			return fmt.Errorf("error al actualizar los respaldos del superbloque de '%s': %v", nombre, err)
+		}
+	}
+	fmt.Fprintf(bufferSalida, "Superbloque de '%s' actualizado (desplazamiento %d)\n", nombre, delta)
+	return nil
+}
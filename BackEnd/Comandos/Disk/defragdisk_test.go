@@ -0,0 +1,120 @@
+package Disk_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	Disk "backend/Comandos/Disk"
+	Forge "backend/Comandos/Forge"
+	Pruebas "backend/Comandos/Pruebas"
+	Estructuras "backend/Estructuras"
+)
+
+func TestDefragdisk(t *testing.T) {
+	casos := []struct {
+		tabla   string
+		movidas []string // particiones que deben quedar 512 KB antes
+	}{
+		{"MBR", []string{"C", "E"}},
+		{"GPT", []string{"C"}},
+	}
+	for _, caso := range casos {
+		t.Run(caso.tabla, func(t *testing.T) {
+			disco := filepath.Join(t.TempDir(), "disco.mia")
+			Pruebas.Ejecutar(t, Disk.ParserMkdisk, "-size=4 -unit=M -path="+disco+" -table="+caso.tabla)
+			for _, nombre := range []string{"A", "B", "C"} {
+				Pruebas.Ejecutar(t, Disk.ParserFdisk, "-size=256 -unit=K -path="+disco+" -name="+nombre)
+			}
+			if caso.tabla == "MBR" {
+				Pruebas.Ejecutar(t, Disk.ParserFdisk, "-size=1 -unit=M -type=E -path="+disco+" -name=E")
+				Pruebas.Ejecutar(t, Disk.ParserFdisk, "-size=256 -unit=K -type=L -path="+disco+" -name=L1")
+			}
+
+			id := Pruebas.Montar(t, disco, "C")
+			Pruebas.Formatear(t, id, "")
+			Pruebas.Ejecutar(t, Forge.ParserMkfile, "-path=/c.txt -size=150")
+			Pruebas.Ejecutar(t, Disk.ParserUnmount, "-id="+id)
+			antes := make(map[string]int32)
+			for _, nombre := range caso.movidas {
+				antes[nombre] = inicioParticion(t, disco, nombre)
+			}
+
+			Pruebas.Ejecutar(t, Disk.ParserFdisk, "-delete=fast -path="+disco+" -name=A")
+			Pruebas.Ejecutar(t, Disk.ParserFdisk, "-delete=fast -path="+disco+" -name=B")
+			if err := Pruebas.SinCache(Disk.ParserDefragdisk, "-path="+disco); err != nil {
+				t.Fatal(err)
+			}
+
+			if inicio, usable := inicioParticion(t, disco, "C"), primerByteUsable(t, disco); inicio != usable {
+				t.Errorf("C quedo en %d, se esperaba el primer byte usable %d", inicio, usable)
+			}
+			for _, nombre := range caso.movidas {
+				if inicio := inicioParticion(t, disco, nombre); inicio != antes[nombre]-2*256*1024 {
+					t.Errorf("%s quedo en %d, antes estaba en %d", nombre, inicio, antes[nombre])
+				}
+			}
+
+			// La cadena de EBR se mueve con la extendida
+			if caso.tabla == "MBR" {
+				archivo, err := os.Open(disco)
+				if err != nil {
+					t.Fatal(err)
+				}
+				inicioExtendida := inicioParticion(t, disco, "E")
+				ebr, err := Estructuras.LeerEBR(inicioExtendida, archivo)
+				archivo.Close()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if nombre := strings.Trim(string(ebr.Ebr_name[:]), "\x00"); nombre != "L1" || ebr.Ebr_start != inicioExtendida || ebr.Ebr_next != -1 {
+					t.Errorf("EBR en %d: %s, inicio %d, siguiente %d", inicioExtendida, nombre, ebr.Ebr_start, ebr.Ebr_next)
+				}
+			}
+
+			// El sistema de archivos movido se monta y conserva su contenido
+			id = Pruebas.Montar(t, disco, "C")
+			if contenido := leerArchivo(t, id, "c.txt"); len(contenido) != 150 {
+				t.Errorf("c.txt tiene %d bytes despues de compactar", len(contenido))
+			}
+		})
+	}
+}
+
+// inicioParticion busca la particion por nombre en la tabla del disco
+func inicioParticion(t *testing.T, disco, nombre string) int32 {
+	t.Helper()
+	archivo, err := os.Open(disco)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer archivo.Close()
+	particion, err := Estructuras.BuscarParticionPorNombre(archivo, nombre)
+	if err != nil {
+		t.Fatalf("%s: %v", nombre, err)
+	}
+	return particion.Part_start
+}
+
+// primerByteUsable es donde defragdisk deja la primera particion
+func primerByteUsable(t *testing.T, disco string) int32 {
+	t.Helper()
+	archivo, err := os.Open(disco)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer archivo.Close()
+	var mbr Estructuras.MBR
+	if err := mbr.Decodificar(archivo); err != nil {
+		t.Fatal(err)
+	}
+	if !mbr.EsProtectorGPT() {
+		return mbr.InicioUsable()
+	}
+	var gpt Estructuras.GPT
+	if err := gpt.Decodificar(archivo); err != nil {
+		t.Fatal(err)
+	}
+	return gpt.Encabezado.GptPrimerByteUsable
+}
@@ -0,0 +1,143 @@
+package Disk
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	Estructuras "backend/Estructuras"
+	Global "backend/Global"
+)
+
+// Df estructura para representar el comando df
+type Df struct {
+	id       string // ID de la partición a revisar; vacío para todas
+	corregir bool   // -fix: deja los contadores como indican los bitmaps
+}
+
+// ParserDf parsea el comando df
+func ParserDf(tokens []string) (string, error) {
+	var outputBuffer bytes.Buffer
+	cmd := &Df{}
+
+	for _, token := range tokens {
+		switch {
+		case strings.HasPrefix(strings.ToLower(token), "-id="):
+			cmd.id = token[len("-id="):]
+		case strings.ToLower(token) == "-fix":
+			cmd.corregir = true
+		default:
+			return "", fmt.Errorf("parametro desconocido: %s", token)
+		}
+	}
+
+	err := comandoDf(cmd, &outputBuffer)
+	if err != nil {
+		return "", err
+	}
+
+	return outputBuffer.String(), nil
+}
+
+// comandoDf muestra el espacio de cada partición montada contando los bitmaps y avisa si
+// los contadores del superbloque no coinciden
+func comandoDf(df *Df, outputBuffer *bytes.Buffer) error {
+	fmt.Fprintln(outputBuffer, "=========================== DF ===========================")
+
+	if df.corregir && (!Global.VerificarSesionActiva() || Global.UsuarioActual.Nombre != "root") {
+		return fmt.Errorf("solo el usuario root puede corregir los contadores con -fix")
+	}
+
+	ids := []string{df.id}
+	if df.id == "" {
+		ids = ids[:0]
+		for id := range Global.ParticionesMontadas {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		if len(ids) == 0 {
+			fmt.Fprintln(outputBuffer, "No hay particiones montadas")
+		}
+	} else if _, montada := Global.ParticionesMontadas[df.id]; !montada {
+		return fmt.Errorf("la partición '%s' no está montada", df.id)
+	}
+
+	fmt.Fprintf(outputBuffer, "%-8s %-8s %10s %10s %10s %6s\n", "ID", "Tipo", "Total", "Usados", "Libres", "Uso%")
+	for _, id := range ids {
+		if err := revisarParticionDf(id, df.corregir, outputBuffer); err != nil {
+			// Una partición sin formato o dañada no impide revisar las demás
+			fmt.Fprintf(outputBuffer, "%-8s %v\n", id, err)
+		}
+	}
+	fmt.Fprintln(outputBuffer, "===========================================================")
+
+	return nil
+}
+
+func revisarParticionDf(id string, corregir bool, outputBuffer *bytes.Buffer) error {
+	// Los descriptores de grupo se actualizan al sincronizar; sin esto parecerian desviados
+	if _, montada := Global.CachesMontadas[id]; montada {
+		if _, err := Global.SincronizarParticion(id); err != nil {
+			return fmt.Errorf("error sincronizando: %v", err)
+		}
+	}
+
+	sb, particion, ruta, err := Global.ObtenerSuperblockParticionMontada(id)
+	if err != nil {
+		return fmt.Errorf("sin sistema de archivos: %v", err)
+	}
+	if sb.S_magic != 0xEF53 {
+		return fmt.Errorf("sin sistema de archivos")
+	}
+
+	archivo, err := os.OpenFile(ruta, os.O_RDWR, 0666)
+	if err != nil {
+		return fmt.Errorf("no se puede abrir el disco: %v", err)
+	}
+	defer archivo.Close()
+
+	revision, err := sb.RevisarEspacio(archivo)
+	if err != nil {
+		return err
+	}
+	for _, fila := range []struct {
+		tipo string
+		uso  Estructuras.UsoEspacio
+	}{{"Inodos", revision.Inodos}, {"Bloques", revision.Bloques}} {
+		fmt.Fprintf(outputBuffer, "%-8s %-8s %10d %10d %10d %5.1f%%\n",
+			id, fila.tipo, fila.uso.Total, fila.uso.Usados, fila.uso.Libres, fila.uso.Porcentaje())
+	}
+
+	if revision.Consistente() {
+		return nil
+	}
+	reportarDiferenciaDf(outputBuffer, "inodos", revision.InodosSuper, revision.Inodos)
+	reportarDiferenciaDf(outputBuffer, "bloques", revision.BloquesSuper, revision.Bloques)
+	if len(revision.GruposDistintos) > 0 {
+		fmt.Fprintf(outputBuffer, "  Descriptores de los grupos %v no coinciden con los bitmaps\n", revision.GruposDistintos)
+	}
+	if !corregir {
+		fmt.Fprintln(outputBuffer, "  Use -fix para corregir los contadores")
+		return nil
+	}
+
+	if err := sb.CorregirEspacio(archivo, revision); err != nil {
+		return fmt.Errorf("error corrigiendo los contadores: %v", err)
+	}
+	if err := sb.Codificar(archivo, int64(particion.Part_start)); err != nil {
+		return fmt.Errorf("error guardando el superbloque: %v", err)
+	}
+	fmt.Fprintln(outputBuffer, "  Contadores corregidos según los bitmaps")
+	return nil
+}
+
+// reportarDiferenciaDf muestra lo que dice el superbloque cuando no coincide con el bitmap
+func reportarDiferenciaDf(outputBuffer *bytes.Buffer, tipo string, segunSuper, segunBitmap Estructuras.UsoEspacio) {
+	if segunSuper == segunBitmap {
+		return
+	}
+	fmt.Fprintf(outputBuffer, "  El superbloque indica %d %s usados y %d libres (total %d); el bitmap tiene %d usados de %d\n",
+		segunSuper.Usados, tipo, segunSuper.Libres, segunSuper.Total, segunBitmap.Usados, segunBitmap.Total)
+}
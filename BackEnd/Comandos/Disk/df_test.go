@@ -0,0 +1,104 @@
+package Disk_test
+
+import (
+	"math/bits"
+	"os"
+	"strings"
+	"testing"
+
+	Disk "backend/Comandos/Disk"
+	Forge "backend/Comandos/Forge"
+	Pruebas "backend/Comandos/Pruebas"
+	User "backend/Comandos/User"
+	Global "backend/Global"
+)
+
+// bloquesUsadosEnBitmap cuenta los bits encendidos del bitmap de bloques de la particion
+func bloquesUsadosEnBitmap(t *testing.T, id string) (usados, total int32) {
+	t.Helper()
+	sb, _, ruta, err := Global.ObtenerSuperblockParticionMontada(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	archivo, err := os.Open(ruta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer archivo.Close()
+	total = sb.S_blocks_count + sb.S_free_blocks_count
+	bitmap := make([]byte, (total+7)/8)
+	if _, err := archivo.ReadAt(bitmap, int64(sb.S_bm_block_start)); err != nil {
+		t.Fatal(err)
+	}
+	for i, valor := range bitmap {
+		if resto := total - int32(i)*8; resto < 8 {
+			valor &= byte(1)<<resto - 1
+		}
+		usados += int32(bits.OnesCount8(valor))
+	}
+	return usados, total
+}
+
+func TestDfFixCorrigeContadores(t *testing.T) {
+	id := Pruebas.MontarParticionFormateada(t, "")
+	Pruebas.Ejecutar(t, Forge.ParserMkfile, "-path=/datos.txt -size=300")
+	Pruebas.Ejecutar(t, Disk.ParserSync, "-id="+id)
+	usados, total := bloquesUsadosEnBitmap(t, id)
+
+	// Desviar el contador de bloques libres del superbloque en el disco
+	sb, particion, ruta, err := Global.ObtenerSuperblockParticionMontada(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sb.S_free_blocks_count != total-usados {
+		t.Fatalf("recien formateado el superbloque indica %d libres y el bitmap %d", sb.S_free_blocks_count, total-usados)
+	}
+	sb.S_free_blocks_count += 5
+	archivo, err := os.OpenFile(ruta, os.O_RDWR, 0666)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = sb.Codificar(archivo, int64(particion.Part_start))
+	archivo.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	salida := Pruebas.Ejecutar(t, Disk.ParserDf, "-id="+id)
+	if !strings.Contains(salida, "Use -fix") {
+		t.Fatalf("df no reporto la diferencia:\n%s", salida)
+	}
+
+	// Solo root puede corregir
+	Pruebas.Ejecutar(t, User.ParserMkgrp, "-name=ventas")
+	Pruebas.Ejecutar(t, User.ParserMkusr, "-user=ana -pass=abc -grp=ventas")
+	Global.Logout()
+	Pruebas.Ejecutar(t, User.ParserLogin, "-user=ana -pass=abc -id="+id)
+	if _, err := Disk.ParserDf([]string{"-id=" + id, "-fix"}); err == nil || !strings.Contains(err.Error(), "root") {
+		t.Errorf("df -fix como ana: %v, se esperaba el rechazo", err)
+	}
+	Global.Logout()
+	if _, err := Disk.ParserDf([]string{"-id=" + id, "-fix"}); err == nil {
+		t.Error("df -fix sin sesion no fue rechazado")
+	}
+	if sb, _, _, _ := Global.ObtenerSuperblockParticionMontada(id); sb.S_free_blocks_count != total-usados+5 {
+		t.Errorf("df -fix rechazado modifico el contador: %d", sb.S_free_blocks_count)
+	}
+
+	Pruebas.Ejecutar(t, User.ParserLogin, "-user=root -pass=123 -id="+id)
+	salida = Pruebas.Ejecutar(t, Disk.ParserDf, "-id="+id+" -fix")
+	if !strings.Contains(salida, "Contadores corregidos") {
+		t.Fatalf("df -fix no corrigio:\n%s", salida)
+	}
+	sb, _, _, err = Global.ObtenerSuperblockParticionMontada(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if usadosAhora, _ := bloquesUsadosEnBitmap(t, id); sb.S_blocks_count != usadosAhora || sb.S_free_blocks_count != total-usadosAhora {
+		t.Errorf("despues de -fix el superbloque indica %d usados y %d libres; el bitmap %d usados de %d",
+			sb.S_blocks_count, sb.S_free_blocks_count, usadosAhora, total)
+	}
+	if salida := Pruebas.Ejecutar(t, Disk.ParserDf, "-id="+id); strings.Contains(salida, "Use -fix") {
+		t.Errorf("df sigue reportando diferencias despues de -fix:\n%s", salida)
+	}
+}
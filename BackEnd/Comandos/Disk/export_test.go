@@ -0,0 +1,7 @@
+package Disk
+
+// Las pruebas son del paquete Disk_test para usar Pruebas, que importa Disk. Aqui se
+// exponen solo para ellas las funciones y constantes no exportadas que revisan
+var CalcularFragmentacion = calcularFragmentacion
+
+const BloquesPorInodoPorDefecto = bloquesPorInodoPorDefecto
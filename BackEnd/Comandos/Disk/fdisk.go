@@ -4,6 +4,7 @@ import (
     Estructuras "backend/Estructuras"
     Utils "backend/Utils"
     "bytes"
+    "encoding/binary"
     "errors"
     "fmt"
     "os"
@@ -22,6 +23,7 @@ type FDisk struct {
     nombre    string // Identificador de la particion
     agregar   int    // Espacio a agregar o quitar
     eliminar  string // Metodo de eliminacion (fast o full)
+    simular   bool   // Solo mostrar donde quedaria la particion con cada ajuste
 }
 
 // Procesa el comando fDisk y retorna los mensajes generados
@@ -30,10 +32,14 @@ func ParserFdisk(tokens []string) (string, error) {
     cmd := &FDisk{}
 
     argumentos := strings.Join(tokens, " ")
-    patron := regexp.MustCompile(`-size=\d+|-unit=[bBkKmM]|-fit=[bBfFwfW]{2}|-path="[^"]+"|-path=[^\s]+|-type=[pPeElL]|-name="[^"]+"|-name=[^\s]+|-add=[+-]?\d+|-delete=(fast|full)`)
+    patron := regexp.MustCompile(`-size=\d+|-unit=[bBkKmM]|-fit=[bBfFwfW]{2}|-path="[^"]+"|-path=[^\s]+|-type=[pPeElL]|-name="[^"]+"|-name=[^\s]+|-add=[+-]?\d+|-delete=(fast|full)|-simulate`)
     coincidencias := patron.FindAllString(argumentos, -1)
 
     for _, coincidencia := range coincidencias {
+        if strings.ToLower(coincidencia) == "-simulate" {
+            cmd.simular = true
+            continue
+        }
         claveValor := strings.SplitN(coincidencia, "=", 2)
         if len(claveValor) != 2 {
             return "", fmt.Errorf("formato de parametro invalido: %s", coincidencia)
@@ -95,7 +101,24 @@ func ParserFdisk(tokens []string) (string, error) {
         }
     }
 
-    // Identificar el tipo de operacion: add, delete o crear particion
+    // Identificar el tipo de operacion: simulate, add, delete o crear particion
+    if cmd.simular {
+        if cmd.capacidad == 0 {
+            return "", errors.New("faltan parametros requeridos: -size")
+        }
+        if cmd.ruta == "" {
+            return "", errors.New("faltan parametros requeridos: -path")
+        }
+        if cmd.unidad == "" {
+            cmd.unidad = "K"
+        }
+        err := simularAjustes(cmd, &bufferSalida)
+        if err != nil {
+            return "", fmt.Errorf("error al simular la particion: %v", err)
+        }
+        return bufferSalida.String(), nil
+    }
+
     if cmd.eliminar != "" {
         // Operacion de eliminacion de particion
         if cmd.ruta == "" {
@@ -168,6 +191,11 @@ func procesarEliminarParticion(cmd *FDisk, bufferSalida *bytes.Buffer) (string,
         return "", fmt.Errorf("error al deserializar el MBR: %v", err)
     }
 
+    // Los discos GPT guardan sus particiones en el arreglo de entradas
+    if mbr.EsProtectorGPT() {
+        return eliminarParticionGPT(archivo, cmd, bufferSalida)
+    }
+
     // Buscar la particion por nombre y eliminarla
     particion, _ := mbr.ObtenerParticionPorNombre(cmd.nombre)
     if particion == nil {
@@ -220,6 +248,11 @@ func procesarAgregarParticion(cmd *FDisk, bufferSalida *bytes.Buffer) (string, e
         return "", fmt.Errorf("error al deserializar el MBR: %v", err)
     }
 
+    // Los discos GPT guardan sus particiones en el arreglo de entradas
+    if mbr.EsProtectorGPT() {
+        return modificarParticionGPT(archivo, cmd, bufferSalida)
+    }
+
     // Buscar la particion por nombre
     particion, _ := mbr.ObtenerParticionPorNombre(cmd.nombre)
     if particion == nil {
@@ -304,21 +337,40 @@ func ejecutarComandoFdisk(fdisk *FDisk, bufferSalida *bytes.Buffer) error {
         return err
     }
 
-    switch fdisk.tipo {
-    case "P":
+    esGPT, err := Estructuras.EsDiscoGPT(archivo)
+    if err != nil {
+        return fmt.Errorf("error al deserializar el MBR: %v", err)
+    }
+    if esGPT && fdisk.tipo != "P" {
+        return errors.New("los discos GPT no usan particiones extendidas ni logicas, use -type=P")
+    }
+    if !esGPT {
+        if err := validarAlineacionCompatible(archivo, bytesCapacidad, fdisk.tipo); err != nil {
+            return err
+        }
+    }
+
+    switch {
+    case esGPT:
+        err = crearParticionGPT(archivo, fdisk, bytesCapacidad, bufferSalida)
+        if err != nil {
+            fmt.Println("Error generando particion GPT:", err)
+            return err
+        }
+    case fdisk.tipo == "P":
         err = crearParticionPrimaria(archivo, fdisk, bytesCapacidad, bufferSalida)
         if err != nil {
             fmt.Println("Error generando particion primaria:", err)
             return err
         }
-    case "E":
+    case fdisk.tipo == "E":
         fmt.Println("Generando particion extendida...")
         err = crearParticionExtendida(archivo, fdisk, bytesCapacidad, bufferSalida)
         if err != nil {
             fmt.Println("Error generando particion extendida:", err)
             return err
         }
-    case "L":
+    case fdisk.tipo == "L":
         fmt.Println("Generando particion logica...")
         err = crearParticionLogica(archivo, fdisk, bytesCapacidad, bufferSalida)
         if err != nil {
@@ -364,6 +416,122 @@ func crearParticionPrimaria(archivo *os.File, fdisk *FDisk, bytesCapacidad int,
     return nil
 }
 
+// Generar una particion en un disco con tabla GPT
+func crearParticionGPT(archivo *os.File, fdisk *FDisk, bytesCapacidad int, bufferSalida *bytes.Buffer) error {
+    fmt.Fprintf(bufferSalida, "Construyendo particion GPT con dimension %d %s\n", fdisk.capacidad, fdisk.unidad)
+
+    var gpt Estructuras.GPT
+    err := gpt.Decodificar(archivo)
+    if err != nil {
+        return fmt.Errorf("error al deserializar la tabla GPT: %v", err)
+    }
+
+    _, err = gpt.CrearParticion(int32(bytesCapacidad), fdisk.nombre)
+    if err != nil {
+        return fmt.Errorf("error al crear la particion GPT: %v", err)
+    }
+
+    err = gpt.Codificar(archivo)
+    if err != nil {
+        return fmt.Errorf("error al actualizar la tabla GPT en el disco: %v", err)
+    }
+
+    fmt.Fprintln(bufferSalida, "Particion GPT construida correctamente.")
+    return nil
+}
+
+// eliminarParticionGPT maneja la eliminacion de particiones en discos GPT
+func eliminarParticionGPT(archivo *os.File, cmd *FDisk, bufferSalida *bytes.Buffer) (string, error) {
+    var gpt Estructuras.GPT
+    err := gpt.Decodificar(archivo)
+    if err != nil {
+        return "", fmt.Errorf("error al deserializar la tabla GPT: %v", err)
+    }
+
+    entrada, _ := gpt.ObtenerParticionPorNombre(cmd.nombre)
+    if entrada == nil {
+        return "", fmt.Errorf("la particion '%s' no existe", cmd.nombre)
+    }
+
+    if cmd.eliminar == "full" {
+        err = entrada.ComoParticion().Sobrescribir(archivo)
+        if err != nil {
+            return "", fmt.Errorf("error al sobrescribir la particion: %v", err)
+        }
+    }
+    entrada.Limpiar()
+
+    err = gpt.Codificar(archivo)
+    if err != nil {
+        return "", fmt.Errorf("error al actualizar la tabla GPT en el disco: %v", err)
+    }
+
+    fmt.Fprintf(bufferSalida, "Particion '%s' eliminada exitosamente.\n", cmd.nombre)
+    fmt.Fprintf(bufferSalida, "===========================================================\n")
+
+    fmt.Fprintf(bufferSalida, "========================== PARTICIONES ==========================\n")
+    imprimirParticionesGPT(&gpt, bufferSalida)
+    fmt.Fprintf(bufferSalida, "===========================================================\n")
+
+    return bufferSalida.String(), nil
+}
+
+// modificarParticionGPT agrega o quita espacio a una particion de un disco GPT
+func modificarParticionGPT(archivo *os.File, cmd *FDisk, bufferSalida *bytes.Buffer) (string, error) {
+    var gpt Estructuras.GPT
+    err := gpt.Decodificar(archivo)
+    if err != nil {
+        return "", fmt.Errorf("error al deserializar la tabla GPT: %v", err)
+    }
+
+    entrada, _ := gpt.ObtenerParticionPorNombre(cmd.nombre)
+    if entrada == nil {
+        return "", fmt.Errorf("la particion '%s' no existe", cmd.nombre)
+    }
+
+    bytesAgregar, err := Utils.ConvertirABytes(cmd.agregar, cmd.unidad)
+    if err != nil {
+        return "", fmt.Errorf("error al convertir las unidades de -add: %v", err)
+    }
+
+    nuevoTamano := entrada.Part_size + int32(bytesAgregar)
+    if nuevoTamano <= 0 {
+        return "", fmt.Errorf("el tamaño de la partición no puede ser negativo")
+    }
+    if bytesAgregar > 0 && gpt.EspacioDisponibleParaParticion(entrada) < int32(bytesAgregar) {
+        return "", fmt.Errorf("no hay suficiente espacio disponible para agregar a la partición")
+    }
+    entrada.Part_size = nuevoTamano
+
+    err = gpt.Codificar(archivo)
+    if err != nil {
+        return "", fmt.Errorf("error al actualizar la tabla GPT en el disco: %v", err)
+    }
+
+    fmt.Fprintf(bufferSalida, "Espacio en la particion '%s' modificado exitosamente.\n", cmd.nombre)
+    fmt.Fprintf(bufferSalida, "===========================================================\n")
+
+    fmt.Fprintf(bufferSalida, "========================== PARTICIONES ==========================\n")
+    imprimirParticionesGPT(&gpt, bufferSalida)
+    fmt.Fprintf(bufferSalida, "===========================================================\n")
+
+    return bufferSalida.String(), nil
+}
+
+// imprimirParticionesGPT imprime las entradas en uso de la tabla GPT
+func imprimirParticionesGPT(gpt *Estructuras.GPT, bufferSalida *bytes.Buffer) {
+    enUso := 0
+    for i, entrada := range gpt.Entradas {
+        if !entrada.EnUso() {
+            continue
+        }
+        enUso++
+        fmt.Fprintf(bufferSalida, "Entrada %d: Nombre: %s | Inicio: %d | Tamaño: %d bytes | Estado: %c\n",
+            i+1, entrada.Nombre(), entrada.Part_start, entrada.Part_size, entrada.Part_status[0])
+    }
+    fmt.Fprintf(bufferSalida, "Entradas en uso: %d de %d\n", enUso, len(gpt.Entradas))
+}
+
 // Generar una particion extendida
 func crearParticionExtendida(archivo *os.File, fdisk *FDisk, bytesCapacidad int, bufferSalida *bytes.Buffer) error {
     fmt.Fprintf(bufferSalida, "Construyendo particion extendida con dimension %d %s\n", fdisk.capacidad, fdisk.unidad)
@@ -478,4 +646,167 @@ func crearParticionLogica(archivo *os.File, fdisk *FDisk, bytesCapacidad int, bu
 
     fmt.Fprintln(bufferSalida, "Particion logica construida correctamente.")
     return nil
+}
+
+// Simula la creacion de una particion mostrando los huecos del disco (y de la
+// extendida) y el hueco que escogeria cada ajuste, sin modificar el disco
+func simularAjustes(fdisk *FDisk, bufferSalida *bytes.Buffer) error {
+    fmt.Fprintf(bufferSalida, "---------------------------- FDisk (SIMULACION) ----------------------------\n")
+
+    archivo, err := os.Open(fdisk.ruta)
+    if err != nil {
+        return fmt.Errorf("error accediendo al archivo del disco: %v", err)
+    }
+    defer archivo.Close()
+
+    bytesCapacidad, err := Utils.ConvertirABytes(fdisk.capacidad, fdisk.unidad)
+    if err != nil {
+        return err
+    }
+    fmt.Fprintf(bufferSalida, "Particion simulada: %d bytes\n", bytesCapacidad)
+
+    var mbr Estructuras.MBR
+    if err := mbr.Decodificar(archivo); err != nil {
+        return fmt.Errorf("error al deserializar el MBR: %v", err)
+    }
+
+    if mbr.EsProtectorGPT() {
+        var gpt Estructuras.GPT
+        if err := gpt.Decodificar(archivo); err != nil {
+            return fmt.Errorf("error al deserializar la tabla GPT: %v", err)
+        }
+        imprimirSimulacion("Disco (GPT)", gpt.EspaciosLibres(), int32(bytesCapacidad), fdisk.ajuste, bufferSalida)
+        fmt.Fprintln(bufferSalida, "--------------------------------------------")
+        return nil
+    }
+
+    var ocupadas []Estructuras.EspacioLibre
+    var extendida *Estructuras.Particion
+    for i := range mbr.MbrPartitions {
+        particion := &mbr.MbrPartitions[i]
+        if particion.Part_start == -1 || particion.Part_size <= 0 {
+            continue
+        }
+        ocupadas = append(ocupadas, Estructuras.EspacioLibre{Inicio: particion.Part_start, Tamano: particion.Part_size})
+        if particion.Part_type[0] == 'E' {
+            extendida = particion
+        }
+    }
+    if libre, _, _ := mbr.ObtenerPrimeraParticionDisponible(); libre == nil {
+        fmt.Fprintln(bufferSalida, "Aviso: las 4 entradas del MBR estan ocupadas, no se puede crear otra particion primaria o extendida")
+    }
+    libres := Estructuras.CalcularEspaciosLibres(ocupadas, mbr.InicioUsable(), mbr.MbrSize)
+    imprimirSimulacion("Disco (MBR)", libres, int32(bytesCapacidad), fdisk.ajuste, bufferSalida)
+
+    if extendida != nil {
+        logicas, err := espaciosOcupadosExtendida(archivo, extendida)
+        if err != nil {
+            return err
+        }
+        libresExtendida := Estructuras.CalcularEspaciosLibres(logicas, extendida.Part_start, extendida.Part_start+extendida.Part_size)
+        titulo := fmt.Sprintf("Extendida '%s'", strings.Trim(string(extendida.Part_name[:]), "\x00 "))
+        imprimirSimulacion(titulo, libresExtendida, int32(bytesCapacidad), fdisk.ajuste, bufferSalida)
+    }
+
+    fmt.Fprintln(bufferSalida, "--------------------------------------------")
+    return nil
+}
+
+// Obtiene los rangos que ocupan las particiones logicas (y el EBR inicial vacio)
+func espaciosOcupadosExtendida(archivo *os.File, extendida *Estructuras.Particion) ([]Estructuras.EspacioLibre, error) {
+    var ocupadas []Estructuras.EspacioLibre
+    inicioEBR := extendida.Part_start
+    for inicioEBR != -1 {
+        ebr, err := Estructuras.LeerEBR(inicioEBR, archivo)
+        if err != nil {
+            return nil, fmt.Errorf("error al leer el EBR en %d: %v", inicioEBR, err)
+        }
+        tamano := ebr.Ebr_size
+        if tamano <= 0 {
+            tamano = int32(binary.Size(ebr))
+        }
+        ocupadas = append(ocupadas, Estructuras.EspacioLibre{Inicio: inicioEBR, Tamano: tamano})
+        inicioEBR = ebr.Ebr_next
+    }
+    return ocupadas, nil
+}
+
+// Imprime los huecos de una region y el resultado de aplicar cada ajuste
+func imprimirSimulacion(titulo string, libres []Estructuras.EspacioLibre, tamano int32, ajusteSolicitado string, bufferSalida *bytes.Buffer) {
+    fmt.Fprintf(bufferSalida, "== %s ==\n", titulo)
+    if len(libres) == 0 {
+        fmt.Fprintln(bufferSalida, "Sin espacios libres.")
+        return
+    }
+    for i, l := range libres {
+        fmt.Fprintf(bufferSalida, "  Hueco %d: Inicio: %d | Tamaño: %d bytes\n", i+1, l.Inicio, l.Tamano)
+    }
+    total, mayor, fragmentacion := calcularFragmentacion(libres)
+    fmt.Fprintf(bufferSalida, "  Libre total: %d bytes | Hueco mayor: %d bytes | Fragmentacion externa: %.1f%%\n", total, mayor, fragmentacion)
+
+    for _, ajuste := range []string{"FF", "BF", "WF"} {
+        marca := ""
+        if ajuste == ajusteSolicitado {
+            marca = " (ajuste solicitado)"
+        }
+        elegido, ok := Estructuras.SeleccionarEspacio(libres, tamano, ajuste[0])
+        if !ok {
+            fmt.Fprintf(bufferSalida, "  %s%s: ningun hueco tiene espacio suficiente\n", ajuste, marca)
+            continue
+        }
+
+        // Estado de los huecos si la particion se colocara en el elegido
+        var resultado []Estructuras.EspacioLibre
+        numeroHueco := 0
+        for i, l := range libres {
+            if l.Inicio != elegido.Inicio {
+                resultado = append(resultado, l)
+                continue
+            }
+            numeroHueco = i + 1
+            if l.Tamano > tamano {
+                resultado = append(resultado, Estructuras.EspacioLibre{Inicio: l.Inicio + tamano, Tamano: l.Tamano - tamano})
+            }
+        }
+        _, mayorDespues, fragmentacionDespues := calcularFragmentacion(resultado)
+        fmt.Fprintf(bufferSalida, "  %s%s: hueco %d (inicio %d) | Huecos restantes: %d | Hueco mayor: %d bytes | Fragmentacion externa: %.1f%%\n",
+            ajuste, marca, numeroHueco, elegido.Inicio, len(resultado), mayorDespues, fragmentacionDespues)
+    }
+}
+
+// Calcula el espacio libre total, el hueco mayor y el porcentaje de fragmentacion
+// externa (1 - hueco mayor / libre total)
+func calcularFragmentacion(libres []Estructuras.EspacioLibre) (int64, int32, float64) {
+    var total int64
+    var mayor int32
+    for _, l := range libres {
+        total += int64(l.Tamano)
+        if l.Tamano > mayor {
+            mayor = l.Tamano
+        }
+    }
+    if total == 0 {
+        return 0, 0, 0
+    }
+    return total, mayor, (1 - float64(mayor)/float64(total)) * 100
+}
+
+// En discos -compat las particiones deben ocupar sectores completos de 512 bytes
+// para que su inicio y tamaño se puedan expresar en LBA. Las logicas necesitan ademas
+// mas de un sector, porque el primero queda reservado para su EBR
+func validarAlineacionCompatible(archivo *os.File, bytesCapacidad int, tipo string) error {
+    var mbr Estructuras.MBR
+    if err := mbr.Decodificar(archivo); err != nil {
+        return fmt.Errorf("error al deserializar el MBR: %v", err)
+    }
+    if !mbr.EsCompatible() {
+        return nil
+    }
+    if bytesCapacidad%Estructuras.TamanoSector != 0 {
+        return fmt.Errorf("en discos compatibles el tamaño debe ser multiplo de %d bytes", Estructuras.TamanoSector)
+    }
+    if tipo == "L" && bytesCapacidad <= Estructuras.TamanoSector {
+        return fmt.Errorf("en discos compatibles una particion logica necesita mas de %d bytes: el primer sector es de su EBR", Estructuras.TamanoSector)
+    }
+    return nil
 }
\ No newline at end of file
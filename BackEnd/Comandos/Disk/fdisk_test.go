@@ -0,0 +1,95 @@
+package Disk_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	Disk "backend/Comandos/Disk"
+	Pruebas "backend/Comandos/Pruebas"
+	Estructuras "backend/Estructuras"
+)
+
+func TestFdiskSimulate(t *testing.T) {
+	for _, tabla := range []string{"MBR", "GPT"} {
+		t.Run(tabla, func(t *testing.T) {
+			// Huecos de 150 KB, 300 KB y el resto del disco al final
+			disco := filepath.Join(t.TempDir(), "disco.mia")
+			Pruebas.Ejecutar(t, Disk.ParserMkdisk, "-size=3 -unit=M -path="+disco+" -table="+tabla)
+			for _, particion := range []string{"-size=150 -name=A", "-size=50 -name=B", "-size=300 -name=C", "-size=50 -name=D"} {
+				Pruebas.Ejecutar(t, Disk.ParserFdisk, "-unit=K -path="+disco+" "+particion)
+			}
+			Pruebas.Ejecutar(t, Disk.ParserFdisk, "-delete=fast -path="+disco+" -name=A")
+			Pruebas.Ejecutar(t, Disk.ParserFdisk, "-delete=fast -path="+disco+" -name=C")
+			antes, err := os.ReadFile(disco)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			casos := []struct {
+				tamano string
+				huecos map[string]string // hueco que escoge cada ajuste
+			}{
+				{"-size=100", map[string]string{"FF": "1", "BF": "1", "WF": "3"}},
+				{"-size=200", map[string]string{"FF": "2", "BF": "2", "WF": "3"}},
+				{"-size=1000", map[string]string{"FF": "3", "BF": "3", "WF": "3"}},
+				{"-size=4000", map[string]string{}},
+			}
+			for _, caso := range casos {
+				salida := Pruebas.Ejecutar(t, Disk.ParserFdisk, "-simulate -unit=K -path="+disco+" "+caso.tamano)
+				for _, ajuste := range []string{"FF", "BF", "WF"} {
+					patron := regexp.MustCompile(`(?m)^  ` + ajuste + `[^:]*: (hueco (\d+)|ningun hueco)`)
+					coincidencia := patron.FindStringSubmatch(salida)
+					if coincidencia == nil {
+						t.Fatalf("%s: no se encontro el resultado de %s en:\n%s", caso.tamano, ajuste, salida)
+					}
+					if coincidencia[2] != caso.huecos[ajuste] {
+						t.Errorf("%s: %s escogio %q, se esperaba el hueco %q", caso.tamano, ajuste, coincidencia[1], caso.huecos[ajuste])
+					}
+				}
+			}
+
+			despues, err := os.ReadFile(disco)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(antes, despues) {
+				t.Errorf("-simulate modifico el disco")
+			}
+		})
+	}
+}
+
+func TestCalcularFragmentacion(t *testing.T) {
+	casos := []struct {
+		libres        []Estructuras.EspacioLibre
+		total         int64
+		mayor         int32
+		fragmentacion float64
+	}{
+		{nil, 0, 0, 0},
+		{[]Estructuras.EspacioLibre{{Inicio: 0, Tamano: 400}}, 400, 400, 0},
+		{[]Estructuras.EspacioLibre{{Inicio: 0, Tamano: 100}, {Inicio: 500, Tamano: 300}}, 400, 300, 25},
+		{[]Estructuras.EspacioLibre{{Inicio: 0, Tamano: 100}, {Inicio: 200, Tamano: 100}, {Inicio: 400, Tamano: 100}, {Inicio: 600, Tamano: 100}}, 400, 100, 75},
+	}
+	for _, caso := range casos {
+		total, mayor, fragmentacion := Disk.CalcularFragmentacion(caso.libres)
+		if total != caso.total || mayor != caso.mayor || fragmentacion != caso.fragmentacion {
+			t.Errorf("Disk.CalcularFragmentacion(%v) = %d, %d, %.1f", caso.libres, total, mayor, fragmentacion)
+		}
+	}
+}
+
+func TestFdiskLogicaCompatibleReservaSectorEBR(t *testing.T) {
+	disco := filepath.Join(t.TempDir(), "disco.mia")
+	Pruebas.Ejecutar(t, Disk.ParserMkdisk, "-size=1 -unit=M -compat -path="+disco)
+	Pruebas.Ejecutar(t, Disk.ParserFdisk, "-size=256 -unit=K -type=E -path="+disco+" -name=E")
+
+	if _, err := Disk.ParserFdisk(strings.Fields("-size=512 -unit=B -type=L -path=" + disco + " -name=L1")); err == nil {
+		t.Errorf("se creo una logica que solo ocupa el sector de su EBR")
+	}
+	Pruebas.Ejecutar(t, Disk.ParserFdisk, "-size=1024 -unit=B -type=L -path="+disco+" -name=L1")
+}
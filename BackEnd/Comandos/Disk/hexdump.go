@@ -0,0 +1,696 @@
+package Disk
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	Estructuras "backend/Estructuras"
+	Global "backend/Global"
+	Utils "backend/Utils"
+)
+
+const (
+	longitudHexPorDefecto = 256   // Bytes mostrados si no se indica -len
+	longitudHexMaxima     = 65536 // Limite para no generar salidas gigantes
+	bytesPorLineaHex      = 16
+)
+
+// HexDump representa el comando hexdump con sus parametros
+type HexDump struct {
+	ruta           string // Ubicacion del archivo del disco
+	id             string // ID de una particion montada
+	desplazamiento int64  // Primer byte a mostrar (relativo a la particion si se usa -id)
+	longitud       int64  // Cantidad de bytes a mostrar
+	inodo          int32  // Atajo: mostrar el inodo N (-1 si no se usa)
+	bloque         int32  // Atajo: mostrar el bloque N (-1 si no se usa)
+}
+
+// Rango de bytes del disco con la estructura y el campo al que pertenece
+type anotacion struct {
+	inicio   int64
+	fin      int64
+	etiqueta string
+	valor    string
+}
+
+// Recolecta las anotaciones que caen dentro de la ventana [desde, hasta)
+type anotador struct {
+	archivo     *os.File
+	desde       int64
+	hasta       int64
+	anotaciones []anotacion
+}
+
+// Procesa el comando hexdump y retorna el volcado anotado
+func ParserHexdump(tokens []string) (string, error) {
+	var bufferSalida bytes.Buffer
+	cmd := &HexDump{desplazamiento: -1, inodo: -1, bloque: -1}
+
+	argumentos := strings.Join(tokens, " ")
+	patron := regexp.MustCompile(`-path="[^"]+"|-path=[^\s]+|-id=[^\s]+|-offset=[^\s]+|-len=[^\s]+|-inode=[^\s]+|-block=[^\s]+`)
+	coincidencias := patron.FindAllString(argumentos, -1)
+
+	for _, coincidencia := range coincidencias {
+		claveValor := strings.SplitN(coincidencia, "=", 2)
+		if len(claveValor) != 2 {
+			return "", fmt.Errorf("formato de parametro invalido: %s", coincidencia)
+		}
+		clave, valor := strings.ToLower(claveValor[0]), claveValor[1]
+		if strings.HasPrefix(valor, "\"") && strings.HasSuffix(valor, "\"") {
+			valor = strings.Trim(valor, "\"")
+		}
+
+		switch clave {
+		case "-path":
+			cmd.ruta = valor
+		case "-id":
+			cmd.id = valor
+		case "-offset", "-len", "-inode", "-block":
+			// Se aceptan valores decimales o hexadecimales (0x...)
+			numero, err := strconv.ParseInt(valor, 0, 64)
+			if err != nil || numero < 0 {
+				return "", fmt.Errorf("el valor de %s debe ser un entero no negativo: %s", clave, valor)
+			}
+			switch clave {
+			case "-offset":
+				cmd.desplazamiento = numero
+			case "-len":
+				if numero == 0 || numero > longitudHexMaxima {
+					return "", fmt.Errorf("-len debe estar entre 1 y %d", longitudHexMaxima)
+				}
+				cmd.longitud = numero
+			case "-inode":
+				cmd.inodo = int32(numero)
+			case "-block":
+				cmd.bloque = int32(numero)
+			}
+		default:
+			return "", fmt.Errorf("parametro desconocido: %s", clave)
+		}
+	}
+
+	if (cmd.ruta == "") == (cmd.id == "") {
+		return "", errors.New("debe indicar exactamente uno de -path o -id")
+	}
+	atajos := 0
+	for _, usado := range []bool{cmd.desplazamiento >= 0, cmd.inodo >= 0, cmd.bloque >= 0} {
+		if usado {
+			atajos++
+		}
+	}
+	if atajos > 1 {
+		return "", errors.New("-offset, -inode y -block no se pueden combinar")
+	}
+	if (cmd.inodo >= 0 || cmd.bloque >= 0) && cmd.id == "" {
+		return "", errors.New("-inode y -block requieren -id de una particion formateada")
+	}
+
+	err := ejecutarComandoHexdump(cmd, &bufferSalida)
+	if err != nil {
+		return "", fmt.Errorf("error al generar el volcado: %v", err)
+	}
+
+	return bufferSalida.String(), nil
+}
+
+func ejecutarComandoHexdump(hexdump *HexDump, bufferSalida *bytes.Buffer) error {
+	fmt.Fprintln(bufferSalida, "--------------------------- HEXDUMP ---------------------------")
+
+	ruta := hexdump.ruta
+	var inicioRegion int64
+	var finRegion int64 = -1
+	if hexdump.id != "" {
+		particion, rutaDisco, err := Global.ObtenerParticionMontada(hexdump.id)
+		if err != nil {
+			return fmt.Errorf("error al obtener la particion montada con ID %s: %v", hexdump.id, err)
+		}
+		ruta = rutaDisco
+		inicioRegion = int64(particion.Part_start)
+		finRegion = inicioRegion + int64(particion.Part_size)
+	}
+
+	archivo, err := os.Open(ruta)
+	if err != nil {
+		return fmt.Errorf("error abriendo el archivo del disco en la ruta: %s: %v", ruta, err)
+	}
+	defer archivo.Close()
+
+	info, err := archivo.Stat()
+	if err != nil {
+		return fmt.Errorf("error al obtener la informacion del disco: %v", err)
+	}
+	if finRegion == -1 {
+		finRegion = info.Size()
+	}
+
+	desde := inicioRegion
+	longitud := hexdump.longitud
+	switch {
+	case hexdump.inodo >= 0 || hexdump.bloque >= 0:
+		var sb Estructuras.SuperBlock
+		if err := Utils.LeerDeArchivo(archivo, inicioRegion, &sb); err != nil || sb.S_magic != 0xEF53 {
+			return errors.New("la particion no tiene un sistema de archivos, ejecute mkfs primero")
+		}
+		if hexdump.inodo >= 0 {
+			if total := sb.S_inodes_count + sb.S_free_inodes_count; hexdump.inodo >= total {
+				return fmt.Errorf("el inodo %d no existe, la particion tiene %d inodos", hexdump.inodo, total)
+			}
+			desde = sb.CalcularDesplazamientoInodo(hexdump.inodo)
+			if longitud == 0 {
+				longitud = int64(sb.S_inode_size)
+			}
+		} else {
+			if total := sb.S_blocks_count + sb.S_free_blocks_count; hexdump.bloque >= total {
+				return fmt.Errorf("el bloque %d no existe, la particion tiene %d bloques", hexdump.bloque, total)
+			}
+			desde = int64(sb.S_block_start) + int64(hexdump.bloque)*int64(sb.S_block_size)
+			if longitud == 0 {
+				longitud = int64(sb.S_block_size)
+			}
+		}
+	case hexdump.desplazamiento >= 0:
+		desde = inicioRegion + hexdump.desplazamiento
+	}
+	if longitud == 0 {
+		longitud = longitudHexPorDefecto
+	}
+	if desde >= finRegion {
+		return fmt.Errorf("el desplazamiento %d esta fuera de la region (%d bytes)", desde-inicioRegion, finRegion-inicioRegion)
+	}
+	hasta := desde + longitud
+	if hasta > finRegion {
+		hasta = finRegion
+	}
+
+	datos := make([]byte, hasta-desde)
+	if _, err := archivo.ReadAt(datos, desde); err != nil {
+		return fmt.Errorf("error al leer el disco: %v", err)
+	}
+
+	a := &anotador{archivo: archivo, desde: desde, hasta: hasta}
+	a.anotarDisco()
+
+	fmt.Fprintf(bufferSalida, "Disco: %s | Rango: 0x%08X - 0x%08X (%d bytes)\n", ruta, desde, hasta, hasta-desde)
+	imprimirVolcado(bufferSalida, datos, desde, a.ordenadas())
+	fmt.Fprintln(bufferSalida, "--------------------------------------------")
+	return nil
+}
+
+// Retorna true si el rango [inicio, fin) se cruza con la ventana del volcado
+func (a *anotador) intersecta(inicio, fin int64) bool {
+	return inicio < a.hasta && fin > a.desde
+}
+
+func (a *anotador) agregar(inicio, fin int64, etiqueta, valor string) {
+	if a.intersecta(inicio, fin) {
+		a.anotaciones = append(a.anotaciones, anotacion{inicio: inicio, fin: fin, etiqueta: etiqueta, valor: valor})
+	}
+}
+
+// Recorre los campos de la estructura con reflexion y anota cada uno con su nombre y
+// valor. Los campos que pasan de limite no se anotan (bloques mas pequeños que su struct)
+func (a *anotador) estructura(base, limite int64, prefijo string, v reflect.Value) {
+	tamano := tamanoValor(v)
+	if base >= limite || !a.intersecta(base, base+tamano) {
+		return
+	}
+
+	switch {
+	case v.Kind() == reflect.Struct:
+		desplazamiento := base
+		for i := 0; i < v.NumField(); i++ {
+			campo := v.Field(i)
+			a.estructura(desplazamiento, limite, prefijo+"."+v.Type().Field(i).Name, campo)
+			desplazamiento += tamanoValor(campo)
+		}
+	case (v.Kind() == reflect.Array || v.Kind() == reflect.Slice) && v.Type().Elem().Kind() != reflect.Uint8:
+		if v.Len() == 0 {
+			return
+		}
+		tamanoElemento := tamano / int64(v.Len())
+		for i := 0; i < v.Len(); i++ {
+			a.estructura(base+int64(i)*tamanoElemento, limite, fmt.Sprintf("%s[%d]", prefijo, i), v.Index(i))
+		}
+	default:
+		fin := base + tamano
+		if fin > limite {
+			fin = limite
+		}
+		a.agregar(base, fin, prefijo, valorCampo(v))
+	}
+}
+
+// Bytes que ocupa el valor serializado. binary.Size no acepta structs con slices
+// (los bloques dependen de S_block_size), por eso los structs se suman campo a campo
+func tamanoValor(v reflect.Value) int64 {
+	if v.Kind() != reflect.Struct {
+		return int64(binary.Size(v.Interface()))
+	}
+	var tamano int64
+	for i := 0; i < v.NumField(); i++ {
+		tamano += tamanoValor(v.Field(i))
+	}
+	return tamano
+}
+
+// Representacion legible del valor de un campo
+func valorCampo(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return fmt.Sprintf("%d (0x%X)", v.Uint(), v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64)
+	case reflect.Uint8:
+		return fmt.Sprintf("0x%02X", v.Uint())
+	case reflect.Array, reflect.Slice:
+		contenido := make([]byte, v.Len())
+		reflect.Copy(reflect.ValueOf(contenido), v)
+		texto := strings.TrimRight(string(contenido), "\x00")
+		if texto == "" {
+			if len(contenido) == 1 {
+				return fmt.Sprintf("0x%02X", contenido[0])
+			}
+			return ""
+		}
+		for _, c := range []byte(texto) {
+			if c < 0x20 || c > 0x7E {
+				return ""
+			}
+		}
+		if len(contenido) == 1 || len(texto) <= 36 {
+			return strconv.Quote(texto)
+		}
+		return strconv.Quote(texto[:33] + "...")
+	}
+	return ""
+}
+
+// anotarDisco anota la tabla de particiones y el sistema de archivos de cada particion
+func (a *anotador) anotarDisco() {
+	var mbr Estructuras.MBR
+	if err := Utils.LeerDeArchivo(a.archivo, 0, &mbr); err != nil {
+		return
+	}
+	a.estructura(0, a.hasta, "MBR", reflect.ValueOf(mbr))
+
+	if mbr.EsCompatible() {
+		a.anotarTablaEstandar(0, "MBR estandar")
+	}
+
+	if mbr.EsProtectorGPT() {
+		var gpt Estructuras.GPT
+		if err := gpt.Decodificar(a.archivo); err != nil {
+			return
+		}
+		a.estructura(Estructuras.InicioEncabezadoGPT(), a.hasta, "GPT", reflect.ValueOf(gpt.Encabezado))
+		for i, entrada := range gpt.Entradas {
+			posicion := int64(gpt.Encabezado.GptInicioEntradas) + int64(i)*int64(gpt.Encabezado.GptTamanoEntrada)
+			a.estructura(posicion, a.hasta, fmt.Sprintf("GPT.Entradas[%d]", i), reflect.ValueOf(entrada))
+			if entrada.EnUso() {
+				a.anotarSistemaArchivos(int64(entrada.Part_start), entrada.Nombre())
+			}
+		}
+		return
+	}
+
+	for _, particion := range mbr.MbrPartitions {
+		if particion.Part_start == -1 || particion.Part_size <= 0 {
+			continue
+		}
+		nombre := strings.Trim(string(particion.Part_name[:]), "\x00 ")
+		if particion.Part_type[0] != 'E' {
+			a.anotarSistemaArchivos(int64(particion.Part_start), nombre)
+			continue
+		}
+
+		visitados := make(map[int32]bool)
+		for inicioEBR := particion.Part_start; inicioEBR != -1 && !visitados[inicioEBR]; {
+			visitados[inicioEBR] = true
+			var ebr Estructuras.EBR
+			if err := Utils.LeerDeArchivo(a.archivo, int64(inicioEBR), &ebr); err != nil {
+				break
+			}
+			a.estructura(int64(inicioEBR), a.hasta, fmt.Sprintf("EBR@%d", inicioEBR), reflect.ValueOf(ebr))
+			if mbr.EsCompatible() {
+				a.anotarTablaEstandar(int64(inicioEBR), fmt.Sprintf("EBR@%d estandar", inicioEBR))
+			}
+			inicioEBR = ebr.Ebr_next
+		}
+	}
+}
+
+// Anota la firma y las entradas de 16 bytes del formato MBR estandar (mkdisk -compat)
+func (a *anotador) anotarTablaEstandar(base int64, prefijo string) {
+	if base == 0 {
+		a.agregar(0x1B8, 0x1BC, prefijo+".FirmaDisco", "")
+	}
+	for i := int64(0); i < 4; i++ {
+		a.agregar(base+0x1BE+16*i, base+0x1CE+16*i, fmt.Sprintf("%s.Entrada[%d]", prefijo, i), "")
+	}
+	a.agregar(base+0x1FE, base+0x200, prefijo+".Firma", "0x55AA")
+}
+
+// anotarSistemaArchivos anota superbloque, journal, bitmaps, inodos y bloques de la particion
+func (a *anotador) anotarSistemaArchivos(inicio int64, nombre string) {
+	var sb Estructuras.SuperBlock
+	if err := Utils.LeerDeArchivo(a.archivo, inicio, &sb); err != nil || sb.S_magic != 0xEF53 {
+		return
+	}
+	a.estructura(inicio, a.hasta, fmt.Sprintf("SuperBlock('%s')", nombre), reflect.ValueOf(sb))
+
+	totalInodos := int64(sb.S_inodes_count + sb.S_free_inodes_count)
+	totalBloques := int64(sb.S_blocks_count + sb.S_free_blocks_count)
+
+	if sb.S_filesystem_type == 3 {
+		tamJournal := int64(binary.Size(Estructuras.Journal{}))
+		inicioJournal := int64(sb.S_bm_inode_start) - tamJournal*Estructuras.ENTRADAS_JOURNAL
+		for i := int64(0); i < Estructuras.ENTRADAS_JOURNAL; i++ {
+			posicion := inicioJournal + i*tamJournal
+			if !a.intersecta(posicion, posicion+tamJournal) {
+				continue
+			}
+			var journal Estructuras.Journal
+			if err := Utils.LeerDeArchivo(a.archivo, posicion, &journal); err == nil {
+				a.estructura(posicion, a.hasta, fmt.Sprintf("Journal[%d]", i), reflect.ValueOf(journal))
+			}
+		}
+	}
+
+	if sb.S_groups_count > 0 {
+		a.anotarGrupos(&sb)
+	} else {
+		a.anotarBitmap(int64(sb.S_bm_inode_start), int64(sb.S_bm_block_start), 0, totalInodos, "Bitmap de inodos", "inodos")
+		a.anotarBitmap(int64(sb.S_bm_block_start), int64(sb.S_inode_start), 0, totalBloques, "Bitmap de bloques", "bloques")
+		a.anotarTablaInodos(&sb, int64(sb.S_inode_start), 0, totalInodos)
+	}
+
+	primero, ultimo := a.indicesEnVentana(int64(sb.S_block_start), int64(sb.S_block_size), totalBloques)
+	if primero >= ultimo {
+		return
+	}
+	tipos := clasificarBloques(a.archivo, &sb, totalInodos, totalBloques)
+	for i := primero; i < ultimo; i++ {
+		posicion := int64(sb.S_block_start) + i*int64(sb.S_block_size)
+		fin := posicion + int64(sb.S_block_size)
+		etiqueta := fmt.Sprintf("Bloque[%d]", i)
+		if esMetadatoDeGrupo(&sb, int32(i)) {
+			continue // ya anotado como bitmap, tabla de inodos o respaldo de su grupo
+		}
+		if !bitOcupado(a.archivo, &sb, sb.S_bm_block_start, i) {
+			a.agregar(posicion, fin, etiqueta+"(libre)", "")
+			continue
+		}
+		switch tipos[int32(i)] {
+		case tipoBloqueCarpeta:
+			carpeta := Estructuras.NuevoFolderBlock(sb.S_block_size)
+			if err := carpeta.Decodificar(a.archivo, posicion); err == nil {
+				a.estructura(posicion, fin, etiqueta+"(carpeta)", reflect.ValueOf(*carpeta))
+			}
+		case tipoBloqueApuntadores:
+			// Los bloques de apuntadores se serializan en big endian
+			apuntadores := Estructuras.NuevoPointerBlock(sb.S_block_size)
+			if err := apuntadores.Decodificar(a.archivo, posicion); err == nil {
+				a.estructura(posicion, fin, etiqueta+"(apuntadores)", reflect.ValueOf(*apuntadores))
+			}
+		case tipoBloqueArchivo:
+			a.agregar(posicion, fin, etiqueta+"(archivo).B_cont", "")
+		default:
+			a.agregar(posicion, fin, etiqueta+"(ocupado, sin referencia)", "")
+		}
+	}
+}
+
+// anotarTablaInodos anota los inodos de una tabla que empieza en 'inicio'; el primero es
+// el inodo primerIndice (con grupos cada grupo tiene su propia tabla)
+func (a *anotador) anotarTablaInodos(sb *Estructuras.SuperBlock, inicio, primerIndice, cantidad int64) {
+	primero, ultimo := a.indicesEnVentana(inicio, int64(sb.S_inode_size), cantidad)
+	for i := primero; i < ultimo; i++ {
+		posicion := inicio + i*int64(sb.S_inode_size)
+		var inodo Estructuras.INodo
+		if err := inodo.DecodificarEnDisco(a.archivo, posicion, sb.S_inode_size); err != nil {
+			continue
+		}
+		etiqueta := fmt.Sprintf("Inodo[%d]", primerIndice+i)
+		if !bitOcupado(a.archivo, sb, sb.S_bm_inode_start, primerIndice+i) {
+			etiqueta += "(libre)"
+		}
+		a.estructura(posicion, posicion+int64(sb.S_inode_size), etiqueta, reflect.ValueOf(inodo))
+	}
+}
+
+// anotarGrupos anota la tabla de descriptores y, por cada grupo, su respaldo del
+// superbloque, sus bitmaps y su tabla de inodos
+func (a *anotador) anotarGrupos(sb *Estructuras.SuperBlock) {
+	tamDescriptor := int64(binary.Size(Estructuras.DescriptorGrupo{}))
+	primero, ultimo := a.indicesEnVentana(int64(sb.S_gdt_start), tamDescriptor, int64(sb.S_groups_count))
+	for i := primero; i < ultimo; i++ {
+		posicion := int64(sb.S_gdt_start) + i*tamDescriptor
+		var descriptor Estructuras.DescriptorGrupo
+		if err := Utils.LeerDeArchivo(a.archivo, posicion, &descriptor); err == nil {
+			a.estructura(posicion, posicion+tamDescriptor, fmt.Sprintf("Descriptor[%d]", i), reflect.ValueOf(descriptor))
+		}
+	}
+
+	bloque := func(indice int32) int64 {
+		return int64(sb.S_block_start) + int64(indice)*int64(sb.S_block_size)
+	}
+	for grupo := int32(0); grupo < sb.S_groups_count; grupo++ {
+		d := sb.DisposicionGrupo(grupo)
+		if !a.intersecta(bloque(d.PrimerBloque), bloque(d.PrimerDato)) {
+			continue
+		}
+		if d.Respaldo {
+			var respaldo Estructuras.SuperBlock
+			if err := Utils.LeerDeArchivo(a.archivo, bloque(d.PrimerBloque), &respaldo); err == nil {
+				a.estructura(bloque(d.PrimerBloque), bloque(d.BitmapInodos), fmt.Sprintf("Grupo[%d].SuperBlock(respaldo)", grupo), reflect.ValueOf(respaldo))
+			}
+			inicioTabla := bloque(d.PrimerBloque) + int64(binary.Size(Estructuras.SuperBlock{}))
+			a.agregar(inicioTabla, inicioTabla+int64(sb.TamanoDescriptores()), fmt.Sprintf("Grupo[%d].Descriptores(respaldo)", grupo), "")
+		}
+		a.anotarBitmap(bloque(d.BitmapInodos), bloque(d.BitmapBloques), int64(grupo*sb.S_inodes_per_group),
+			int64(sb.S_inodes_per_group), fmt.Sprintf("Grupo[%d] bitmap de inodos", grupo), "inodos")
+		a.anotarBitmap(bloque(d.BitmapBloques), bloque(d.TablaInodos), int64(d.PrimerBloque),
+			int64(d.Bloques), fmt.Sprintf("Grupo[%d] bitmap de bloques", grupo), "bloques")
+		a.anotarTablaInodos(sb, bloque(d.TablaInodos), int64(grupo*sb.S_inodes_per_group), int64(sb.S_inodes_per_group))
+	}
+}
+
+// esMetadatoDeGrupo indica si el bloque es uno de los bloques de metadatos de su grupo
+func esMetadatoDeGrupo(sb *Estructuras.SuperBlock, indice int32) bool {
+	if sb.S_groups_count == 0 {
+		return false
+	}
+	return indice < sb.DisposicionGrupo(sb.GrupoDeBloque(indice)).PrimerDato
+}
+
+// Anota el bitmap en grupos de 4 bytes (32 inodos o bloques) mostrando sus bits; el
+// primer bit es la posicion primerIndice. Los bytes sobrantes hasta la siguiente region
+// quedan como relleno
+func (a *anotador) anotarBitmap(inicio, finRegion, primerIndice, cantidad int64, nombre, unidad string) {
+	const bytesPorGrupo = 4
+	bytesUsados := (cantidad + 7) / 8
+	grupos := (bytesUsados + bytesPorGrupo - 1) / bytesPorGrupo
+	primero, ultimo := a.indicesEnVentana(inicio, bytesPorGrupo, grupos)
+	for i := primero; i < ultimo; i++ {
+		posicion := inicio + i*bytesPorGrupo
+		fin := posicion + bytesPorGrupo
+		if fin > inicio+bytesUsados {
+			fin = inicio + bytesUsados
+		}
+		valores := make([]byte, fin-posicion)
+		if _, err := a.archivo.ReadAt(valores, posicion); err != nil {
+			return
+		}
+		bitsGrupo := make([]string, len(valores))
+		for j, valor := range valores {
+			bitsGrupo[j] = fmt.Sprintf("%08b", valor)
+		}
+		hastaIndice := (fin-inicio)*8 - 1
+		if hastaIndice >= cantidad {
+			hastaIndice = cantidad - 1
+		}
+		a.agregar(posicion, fin, fmt.Sprintf("%s: %s %d-%d", nombre, unidad, primerIndice+(posicion-inicio)*8, primerIndice+hastaIndice),
+			strings.Join(bitsGrupo, " ")+" (bit 0 a la derecha)")
+	}
+	if inicio+bytesUsados < finRegion {
+		a.agregar(inicio+bytesUsados, finRegion, nombre+" (relleno)", "")
+	}
+}
+
+// Rango de indices [primero, ultimo) de una tabla de elementos que se cruzan con la ventana
+func (a *anotador) indicesEnVentana(inicio, tamano, cantidad int64) (int64, int64) {
+	if tamano <= 0 || cantidad <= 0 {
+		return 0, 0
+	}
+	primero := (a.desde - inicio) / tamano
+	if primero < 0 {
+		primero = 0
+	}
+	ultimo := (a.hasta - inicio + tamano - 1) / tamano
+	if ultimo > cantidad {
+		ultimo = cantidad
+	}
+	return primero, ultimo
+}
+
+// Indica si el bit del indice esta en 1 en el bitmap que empieza en inicio
+func bitOcupado(archivo *os.File, sb *Estructuras.SuperBlock, inicio int32, indice int64) bool {
+	var valor byte
+	if err := Utils.LeerDeArchivo(archivo, sb.DesplazamientoEnBitmap(inicio, int32(indice)), &valor); err != nil {
+		return false
+	}
+	return valor&(1<<(indice%8)) != 0
+}
+
+const (
+	tipoBloqueDesconocido = iota
+	tipoBloqueCarpeta
+	tipoBloqueArchivo
+	tipoBloqueApuntadores
+)
+
+// clasificarBloques recorre los inodos en uso para saber que tipo de contenido tiene cada
+// bloque: carpeta, archivo o apuntadores (indirectos simple, doble y triple)
+func clasificarBloques(archivo *os.File, sb *Estructuras.SuperBlock, totalInodos, totalBloques int64) map[int32]int {
+	tipos := make(map[int32]int)
+
+	var marcar func(indice int64, nivel int, tipoDatos int)
+	marcar = func(indice int64, nivel int, tipoDatos int) {
+		if indice < 0 || indice >= totalBloques || tipos[int32(indice)] != tipoBloqueDesconocido {
+			return
+		}
+		if nivel == 0 {
+			tipos[int32(indice)] = tipoDatos
+			return
+		}
+		tipos[int32(indice)] = tipoBloqueApuntadores
+		apuntadores := Estructuras.NuevoPointerBlock(sb.S_block_size)
+		if err := apuntadores.Decodificar(archivo, int64(sb.S_block_start)+indice*int64(sb.S_block_size)); err != nil {
+			return
+		}
+		for _, apuntador := range apuntadores.B_apuntadores {
+			marcar(apuntador, nivel-1, tipoDatos)
+		}
+	}
+
+	for i := int64(0); i < totalInodos; i++ {
+		if !bitOcupado(archivo, sb, sb.S_bm_inode_start, i) {
+			continue
+		}
+		var inodo Estructuras.INodo
+		if err := inodo.DecodificarEnDisco(archivo, sb.CalcularDesplazamientoInodo(int32(i)), sb.S_inode_size); err != nil {
+			continue
+		}
+		tipoDatos := tipoBloqueArchivo
+		if inodo.I_type[0] == '0' {
+			tipoDatos = tipoBloqueCarpeta
+		}
+		for j, bloque := range inodo.I_block {
+			nivel := 0
+			if j >= 12 {
+				nivel = j - 11
+			}
+			marcar(int64(bloque), nivel, tipoDatos)
+		}
+	}
+	return tipos
+}
+
+// imprimirVolcado escribe el volcado en lineas de hasta 16 bytes. Cada campo anotado
+// empieza una linea nueva con su etiqueta; las lineas repetidas sin estructura se
+// resumen con '*' como hace hexdump -C
+func imprimirVolcado(bufferSalida *bytes.Buffer, datos []byte, desde int64, anotaciones []anotacion) {
+	hasta := desde + int64(len(datos))
+	var ultimaLibre []byte
+	resumida := false
+
+	imprimirLinea := func(inicio, fin int64, etiqueta string) {
+		linea := datos[inicio-desde : fin-desde]
+		var hex, ascii strings.Builder
+		for i := 0; i < bytesPorLineaHex; i++ {
+			if i < len(linea) {
+				fmt.Fprintf(&hex, "%02x ", linea[i])
+				if linea[i] >= 0x20 && linea[i] <= 0x7E {
+					ascii.WriteByte(linea[i])
+				} else {
+					ascii.WriteByte('.')
+				}
+			} else {
+				hex.WriteString("   ")
+			}
+		}
+		fmt.Fprintf(bufferSalida, "0x%08X  %s %-16s  %s\n", inicio, hex.String(), ascii.String(), etiqueta)
+	}
+
+	// Segmento [inicio, fin) partido en lineas; la etiqueta solo va en la primera
+	imprimirSegmento := func(inicio, fin int64, etiqueta string, libre bool) {
+		for posicion := inicio; posicion < fin; posicion += bytesPorLineaHex {
+			finLinea := posicion + bytesPorLineaHex
+			if finLinea > fin {
+				finLinea = fin
+			}
+			linea := datos[posicion-desde : finLinea-desde]
+			if libre && posicion != inicio && bytes.Equal(linea, ultimaLibre) && finLinea != fin {
+				if !resumida {
+					fmt.Fprintln(bufferSalida, "*")
+					resumida = true
+				}
+				continue
+			}
+			resumida = false
+			ultimaLibre = nil
+			if libre {
+				ultimaLibre = linea
+			}
+			imprimirLinea(posicion, finLinea, etiqueta)
+			etiqueta = ""
+		}
+	}
+
+	posicion := desde
+	for _, an := range anotaciones {
+		inicio, fin := an.inicio, an.fin
+		if inicio < posicion {
+			inicio = posicion
+		}
+		if fin > hasta {
+			fin = hasta
+		}
+		if inicio >= fin {
+			continue
+		}
+		if posicion < inicio {
+			imprimirSegmento(posicion, inicio, "(sin estructura)", true)
+		}
+		etiqueta := an.etiqueta
+		if an.inicio < inicio {
+			etiqueta += " (cont.)"
+		}
+		if an.valor != "" {
+			etiqueta += " = " + an.valor
+		}
+		imprimirSegmento(inicio, fin, etiqueta, false)
+		posicion = fin
+	}
+	if posicion < hasta {
+		imprimirSegmento(posicion, hasta, "(sin estructura)", true)
+	}
+}
+
+// Anotaciones ordenadas por posicion de inicio
+func (a *anotador) ordenadas() []anotacion {
+	sort.SliceStable(a.anotaciones, func(i, j int) bool {
+		return a.anotaciones[i].inicio < a.anotaciones[j].inicio
+	})
+	return a.anotaciones
+}
@@ -0,0 +1,58 @@
+package Disk_test
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	Disk "backend/Comandos/Disk"
+	Pruebas "backend/Comandos/Pruebas"
+)
+
+func TestHexdumpAnotado(t *testing.T) {
+	disco := filepath.Join(t.TempDir(), "disco.mia")
+	Pruebas.Ejecutar(t, Disk.ParserMkdisk, "-size=3 -unit=M -path="+disco)
+	Pruebas.Ejecutar(t, Disk.ParserFdisk, "-size=1 -unit=M -path="+disco+" -name=Part1")
+	id := Pruebas.Montar(t, disco, "Part1")
+	Pruebas.Formatear(t, id, "")
+
+	casos := []struct {
+		parametros  string
+		anotaciones []string
+	}{
+		{"-path=" + disco + " -len=64", []string{
+			"MBR.MbrSize = 3145728",
+			`MBR.MbrPartitions[0].Part_name = "Part1"`,
+			"MBR.MbrPartitions[1].Part_start = -1",
+		}},
+		{"-id=" + id + " -len=0x60", []string{"SuperBlock('Part1').S_magic = 61267", "SuperBlock('Part1').S_block_size = 64"}},
+		{"-id=" + id + " -inode=1", []string{
+			"Inodo[1].I_size = 27",
+			`Inodo[1].I_type = "1"`,
+			"Inodo[1].I_block[0] = 1",
+			"Inodo[1].I_xattr = -1",
+		}},
+		{"-id=" + id + " -block=0 -len=32", []string{`Bloque[0](carpeta).B_cont[1].B_name = ".."`}},
+		{"-id=" + id + " -block=1 -len=16", []string{"Bloque[1](archivo)"}},
+	}
+	for _, caso := range casos {
+		salida := Pruebas.Ejecutar(t, Disk.ParserHexdump, caso.parametros)
+		for _, anotacion := range caso.anotaciones {
+			if !strings.Contains(salida, anotacion) {
+				t.Errorf("hexdump %s: falta %q en:\n%s", caso.parametros, anotacion, salida)
+			}
+		}
+	}
+
+	for _, parametros := range []string{
+		"-len=16",
+		"-path=" + disco + " -id=" + id,
+		"-id=" + id + " -inode=100000",
+		"-path=" + disco + " -len=70000",
+		"-path=" + disco + " -offset=0x400000",
+	} {
+		if _, err := Disk.ParserHexdump(strings.Fields(parametros)); err == nil {
+			t.Errorf("hexdump %s no fallo", parametros)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package Disk
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	Estructuras "backend/Estructuras"
+
+	"github.com/google/uuid"
+)
+
+// Lsblk representa el comando lsblk con sus parametros
+type Lsblk struct {
+	ruta string // Ubicacion del archivo del disco
+}
+
+// Procesa el comando lsblk y retorna el listado de particiones del disco
+func ParserLsblk(tokens []string) (string, error) {
+	var bufferSalida bytes.Buffer
+	cmd := &Lsblk{}
+
+	argumentos := strings.Join(tokens, " ")
+	patron := regexp.MustCompile(`-path="[^"]+"|-path=[^\s]+`)
+	coincidencias := patron.FindAllString(argumentos, -1)
+
+	for _, coincidencia := range coincidencias {
+		claveValor := strings.SplitN(coincidencia, "=", 2)
+		if len(claveValor) != 2 {
+			return "", fmt.Errorf("formato de parametro invalido: %s", coincidencia)
+		}
+		clave, valor := strings.ToLower(claveValor[0]), claveValor[1]
+		if strings.HasPrefix(valor, "\"") && strings.HasSuffix(valor, "\"") {
+			valor = strings.Trim(valor, "\"")
+		}
+
+		switch clave {
+		case "-path":
+			if valor == "" {
+				return "", errors.New("la ruta no puede estar vacia")
+			}
+			cmd.ruta = valor
+		default:
+			return "", fmt.Errorf("parametro desconocido: %s", clave)
+		}
+	}
+
+	if cmd.ruta == "" {
+		return "", errors.New("faltan parametros requeridos: -path")
+	}
+
+	err := ejecutarComandoLsblk(cmd, &bufferSalida)
+	if err != nil {
+		return "", err
+	}
+
+	return bufferSalida.String(), nil
+}
+
+func ejecutarComandoLsblk(lsblk *Lsblk, bufferSalida *bytes.Buffer) error {
+	fmt.Fprintln(bufferSalida, "========================== LSBLK ==========================")
+
+	archivo, err := os.Open(lsblk.ruta)
+	if err != nil {
+		return fmt.Errorf("error abriendo el archivo del disco en la ruta: %s: %v", lsblk.ruta, err)
+	}
+	defer archivo.Close()
+
+	var mbr Estructuras.MBR
+	if err := mbr.Decodificar(archivo); err != nil {
+		return fmt.Errorf("error deserializando el MBR: %v", err)
+	}
+
+	fmt.Fprintf(bufferSalida, "Disco: %s | Capacidad: %d bytes\n", lsblk.ruta, mbr.MbrSize)
+	if mbr.EsProtectorGPT() {
+		err = listarParticionesGPT(archivo, bufferSalida)
+	} else {
+		err = listarParticionesMBR(archivo, &mbr, bufferSalida)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(bufferSalida, "===========================================================")
+	return nil
+}
+
+// Lista las particiones primarias, extendidas y logicas de un disco MBR
+func listarParticionesMBR(archivo *os.File, mbr *Estructuras.MBR, bufferSalida *bytes.Buffer) error {
+	fmt.Fprintln(bufferSalida, "Tabla: MBR")
+	fmt.Fprintf(bufferSalida, "%-18s %-6s %-10s %-10s %-6s %s\n", "NOMBRE", "TIPO", "INICIO", "TAMAÑO", "ESTADO", "ID")
+
+	for _, particion := range mbr.MbrPartitions {
+		if particion.Part_start == -1 {
+			continue
+		}
+		fmt.Fprintf(bufferSalida, "%-18s %-6c %-10d %-10d %-6c %s\n",
+			strings.Trim(string(particion.Part_name[:]), "\x00 "),
+			particion.Part_type[0],
+			particion.Part_start,
+			particion.Part_size,
+			particion.Part_status[0],
+			strings.Trim(string(particion.Part_id[:]), "\x00 "))
+
+		if particion.Part_type[0] != 'E' {
+			continue
+		}
+
+		// Recorrer la cadena de EBR de la particion extendida
+		inicioEBR := particion.Part_start
+		for inicioEBR != -1 {
+			ebr, err := Estructuras.LeerEBR(inicioEBR, archivo)
+			if err != nil {
+				return fmt.Errorf("error al leer el EBR en %d: %v", inicioEBR, err)
+			}
+			if ebr.Ebr_size > 0 {
+				fmt.Fprintf(bufferSalida, "└─%-16s %-6s %-10d %-10d %-6c\n",
+					strings.Trim(string(ebr.Ebr_name[:]), "\x00 "), "L", ebr.Ebr_start, ebr.Ebr_size, ebr.Ebr_mount[0])
+			}
+			inicioEBR = ebr.Ebr_next
+		}
+	}
+	return nil
+}
+
+// Lista las entradas en uso de un disco GPT
+func listarParticionesGPT(archivo *os.File, bufferSalida *bytes.Buffer) error {
+	var gpt Estructuras.GPT
+	if err := gpt.Decodificar(archivo); err != nil {
+		return fmt.Errorf("error deserializando la tabla GPT: %v", err)
+	}
+
+	guidDisco, _ := uuid.FromBytes(gpt.Encabezado.GptDiskGuid[:])
+	fmt.Fprintf(bufferSalida, "Tabla: GPT | GUID: %s\n", guidDisco)
+	fmt.Fprintf(bufferSalida, "%-18s %-10s %-10s %-6s %-8s %s\n", "NOMBRE", "INICIO", "TAMAÑO", "ESTADO", "ID", "GUID")
+
+	for _, entrada := range gpt.Entradas {
+		if !entrada.EnUso() {
+			continue
+		}
+		guid, _ := uuid.FromBytes(entrada.Part_guid[:])
+		fmt.Fprintf(bufferSalida, "%-18s %-10d %-10d %-6c %-8s %s\n",
+			entrada.Nombre(),
+			entrada.Part_start,
+			entrada.Part_size,
+			entrada.Part_status[0],
+			strings.Trim(string(entrada.Part_id[:]), "\x00 "),
+			guid)
+	}
+	return nil
+}
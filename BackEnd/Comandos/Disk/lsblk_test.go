@@ -0,0 +1,123 @@
+package Disk_test
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	Disk "backend/Comandos/Disk"
+	Pruebas "backend/Comandos/Pruebas"
+	Estructuras "backend/Estructuras"
+
+	"github.com/google/uuid"
+)
+
+// filasLsblk separa en campos las filas de la tabla que imprime lsblk
+func filasLsblk(t *testing.T, salida string) [][]string {
+	t.Helper()
+	var filas [][]string
+	enTabla := false
+	for _, linea := range strings.Split(salida, "\n") {
+		switch {
+		case strings.HasPrefix(linea, "NOMBRE"):
+			enTabla = true
+		case strings.HasPrefix(linea, "====="):
+			enTabla = false
+		case enTabla:
+			filas = append(filas, strings.Fields(linea))
+		}
+	}
+	if len(filas) == 0 {
+		t.Fatalf("lsblk no mostro ninguna particion:\n%s", salida)
+	}
+	return filas
+}
+
+func compararFilasLsblk(t *testing.T, salida string, esperadas [][]string) {
+	t.Helper()
+	filas := filasLsblk(t, salida)
+	if len(filas) != len(esperadas) {
+		t.Fatalf("lsblk mostro %d particiones, se esperaban %d:\n%s", len(filas), len(esperadas), salida)
+	}
+	for i := range esperadas {
+		if strings.Join(filas[i], " ") != strings.Join(esperadas[i], " ") {
+			t.Errorf("fila %d: %q, se esperaba %q", i, filas[i], esperadas[i])
+		}
+	}
+}
+
+func TestLsblkMBRConLogicas(t *testing.T) {
+	disco := filepath.Join(t.TempDir(), "disco.mia")
+	Pruebas.Ejecutar(t, Disk.ParserMkdisk, "-size=4 -unit=M -path="+disco)
+	for _, parametros := range []string{
+		"-size=512 -unit=K -name=P1",
+		"-size=2 -unit=M -type=E -name=E",
+		"-size=256 -unit=K -type=L -name=L1",
+		"-size=256 -unit=K -type=L -name=L2",
+		"-size=256 -unit=K -name=P2",
+	} {
+		Pruebas.Ejecutar(t, Disk.ParserFdisk, parametros+" -path="+disco)
+	}
+	id := Pruebas.Montar(t, disco, "P1")
+
+	salida := Pruebas.Ejecutar(t, Disk.ParserLsblk, "-path="+disco)
+	if !strings.Contains(salida, "Capacidad: 4194304 bytes") || !strings.Contains(salida, "Tabla: MBR") {
+		t.Errorf("encabezado de lsblk:\n%s", salida)
+	}
+
+	// Las logicas van debajo de su extendida, una tras otra desde su inicio
+	inicio := func(nombre string) string { return strconv.Itoa(int(inicioParticion(t, disco, nombre))) }
+	inicioExtendida := inicioParticion(t, disco, "E")
+	compararFilasLsblk(t, salida, [][]string{
+		{"P1", "P", inicio("P1"), "524288", "1", id},
+		{"E", "E", inicio("E"), "2097152", "1"},
+		{"└─L1", "L", strconv.Itoa(int(inicioExtendida)), "262144", "1"},
+		{"└─L2", "L", strconv.Itoa(int(inicioExtendida) + 262144), "262144", "1"},
+		{"P2", "P", inicio("P2"), "262144", "1"},
+	})
+}
+
+func TestLsblkGPT(t *testing.T) {
+	disco := filepath.Join(t.TempDir(), "disco.mia")
+	Pruebas.Ejecutar(t, Disk.ParserMkdisk, "-size=4 -unit=M -table=GPT -path="+disco)
+	for _, nombre := range []string{"A", "B", "C"} {
+		Pruebas.Ejecutar(t, Disk.ParserFdisk, "-size=256 -unit=K -name="+nombre+" -path="+disco)
+	}
+	Pruebas.Ejecutar(t, Disk.ParserFdisk, "-delete=fast -name=B -path="+disco)
+	id := Pruebas.Montar(t, disco, "C")
+
+	archivo, err := os.Open(disco)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer archivo.Close()
+	var gpt Estructuras.GPT
+	if err := gpt.Decodificar(archivo); err != nil {
+		t.Fatal(err)
+	}
+	guid := func(bytes []byte) string {
+		valor, err := uuid.FromBytes(bytes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return valor.String()
+	}
+	entradaA, _ := gpt.ObtenerParticionPorNombre("A")
+	entradaC, _ := gpt.ObtenerParticionPorNombre("C")
+	if entradaA == nil || entradaC == nil {
+		t.Fatal("la tabla GPT no tiene las particiones A y C")
+	}
+
+	salida := Pruebas.Ejecutar(t, Disk.ParserLsblk, "-path="+disco)
+	if !strings.Contains(salida, "Tabla: GPT | GUID: "+guid(gpt.Encabezado.GptDiskGuid[:])) {
+		t.Errorf("lsblk no muestra el GUID del disco:\n%s", salida)
+	}
+
+	// La entrada eliminada no se lista
+	compararFilasLsblk(t, salida, [][]string{
+		{"A", strconv.Itoa(int(entradaA.Part_start)), "262144", "1", guid(entradaA.Part_guid[:])},
+		{"C", strconv.Itoa(int(entradaC.Part_start)), "262144", "1", id, guid(entradaC.Part_guid[:])},
+	})
+}
@@ -22,6 +22,9 @@ const (
 	FitBF = "BF"
 	FitFF = "FF"
 	FitWF = "WF"
+
+	TablaMBR = "MBR"
+	TablaGPT = "GPT"
 )
 
 type MkDisk struct {
@@ -33,6 +36,12 @@ type MkDisk struct {
 	fit string
 	/* Ruta del disco */
 	path string
+	/* Reservar todo el espacio en el host (sin archivo disperso) */
+	prealloc bool
+	/* Tabla de particiones (MBR o GPT) */
+	table string
+	/* Escribir tambien un MBR estandar legible por las herramientas del host */
+	compat bool
 }
 
 func ParserMkdisk(tokens []string) (string, error) {
@@ -40,11 +49,19 @@ func ParserMkdisk(tokens []string) (string, error) {
 	var outputBuffer bytes.Buffer // Capturar los prints
 
 	args := strings.Join(tokens, " ")
-	re := regexp.MustCompile(`-size=\d+|-unit=[kKmM]|-fit=[bBfFwW]{2}|-path="[^"]+"|-path=[^\s]+`)
+	re := regexp.MustCompile(`-size=\d+|-unit=[kKmM]|-fit=[bBfFwW]{2}|-path="[^"]+"|-path=[^\s]+|-prealloc|-table=[a-zA-Z]+|-compat`)
 	matches := re.FindAllString(args, -1)
 
 	for _, match := range matches {
 		kv := strings.SplitN(match, "=", 2)
+		if strings.ToLower(kv[0]) == "-prealloc" {
+			cmd.prealloc = true
+			continue
+		}
+		if strings.ToLower(kv[0]) == "-compat" {
+			cmd.compat = true
+			continue
+		}
 		if len(kv) != 2 {
 			return "", fmt.Errorf("formato de parámetro inválido: %s", match)
 		}
@@ -81,6 +98,12 @@ func ParserMkdisk(tokens []string) (string, error) {
 				return "", errors.New("el archivo debe tener la extensión .mia")
 			}
 			cmd.path = value
+		case "-table":
+			value = strings.ToUpper(value)
+			if value != TablaMBR && value != TablaGPT {
+				return "", errors.New("la tabla debe ser MBR o GPT")
+			}
+			cmd.table = value
 		default:
 			return "", fmt.Errorf("parámetro desconocido: %s", key)
 		}
@@ -98,6 +121,12 @@ func ParserMkdisk(tokens []string) (string, error) {
 	if cmd.fit == "" {
 		cmd.fit = FitFF
 	}
+	if cmd.table == "" {
+		cmd.table = TablaMBR
+	}
+	if cmd.compat && cmd.table == TablaGPT {
+		return "", errors.New("-compat solo aplica a discos con tabla MBR")
+	}
 
 	// Crear el disco con los parámetros proporcionados y capturar la salida en el buffer
 	err := commandMkdisk(cmd, &outputBuffer)
@@ -128,10 +157,14 @@ func commandMkdisk(mkdisk *MkDisk, outputBuffer *bytes.Buffer) error {
 		return err
 	}
 
-	// Crear el MBR con el tamaño proporcionado
-	err = createMBR(mkdisk, sizeBytes, outputBuffer)
+	// Crear la tabla de particiones con el tamaño proporcionado
+	if mkdisk.table == TablaGPT {
+		err = createGPT(mkdisk, sizeBytes, outputBuffer)
+	} else {
+		err = createMBR(mkdisk, sizeBytes, outputBuffer)
+	}
 	if err != nil {
-		fmt.Fprintln(outputBuffer, "Error creando el MBR:", err)
+		fmt.Fprintln(outputBuffer, "Error creando la tabla de particiones:", err)
 		return err
 	}
 
@@ -155,7 +188,17 @@ func createDisk(mkdisk *MkDisk, sizeBytes int, outputBuffer *bytes.Buffer) error
 	}
 	defer file.Close()
 
-	// Escribir en el archivo usando un buffer de 1 MB
+	// Por defecto el disco es un archivo disperso: solo se fija su tamaño y
+	// el sistema de archivos del host devuelve ceros en las zonas no escritas
+	if !mkdisk.prealloc {
+		if err := file.Truncate(int64(sizeBytes)); err != nil {
+			return err
+		}
+		fmt.Fprintln(outputBuffer, "Disco creado exitosamente:", mkdisk.path)
+		return nil
+	}
+
+	// Con -prealloc se escribe el archivo completo usando un buffer de 1 MB
 	buffer := make([]byte, 1024*1024) // Crea un buffer de 1 MB
 	for sizeBytes > 0 {
 		writeSize := len(buffer)
@@ -194,6 +237,10 @@ func createMBR(mkdisk *MkDisk, sizeBytes int, outputBuffer *bytes.Buffer) error
 		},
 	}
 
+	if mkdisk.compat {
+		mbr.MarcarCompatible()
+	}
+
 	// Serializar el MBR en el archivo usando el puntero de archivo `file`
 	err = mbr.Codificar(file)
 	if err != nil {
@@ -203,9 +250,47 @@ func createMBR(mkdisk *MkDisk, sizeBytes int, outputBuffer *bytes.Buffer) error
 
 	// Agregar mensajes al buffer
 	fmt.Fprintln(outputBuffer, "MBR creado exitosamente en el disco.")
+	if mkdisk.compat {
+		fmt.Fprintln(outputBuffer, "MBR estandar (0x55AA) escrito: el disco es legible con fdisk -l / sfdisk.")
+	}
 	// Imprimir el MBR creado
 	mbr.Imprimir()
 	fmt.Println("--------------------------------------------")
 
 	return nil
 }
+
+func createGPT(mkdisk *MkDisk, sizeBytes int, outputBuffer *bytes.Buffer) error {
+	// Abrir el archivo del disco para escritura
+	file, err := os.OpenFile(mkdisk.path, os.O_WRONLY|os.O_CREATE, 0644)
+	if err != nil {
+		fmt.Fprintln(outputBuffer, "Error abriendo el archivo:", err)
+		return err
+	}
+	defer file.Close()
+
+	// MBR protector: una sola entrada que cubre todo el disco
+	mbr := Estructuras.NuevoMBRProtector(int32(sizeBytes), rand.Int31(), mkdisk.fit[0])
+	gpt := Estructuras.NuevaGPT(int32(sizeBytes), mkdisk.fit[0])
+	if gpt.Encabezado.GptPrimerByteUsable >= int32(sizeBytes) {
+		return fmt.Errorf("el disco es demasiado pequeño para una tabla GPT")
+	}
+
+	err = mbr.Codificar(file)
+	if err != nil {
+		fmt.Fprintln(outputBuffer, "Error serializando el MBR protector en el archivo:", err)
+		return err
+	}
+
+	err = gpt.Codificar(file)
+	if err != nil {
+		fmt.Fprintln(outputBuffer, "Error serializando la tabla GPT en el archivo:", err)
+		return err
+	}
+
+	fmt.Fprintf(outputBuffer, "Tabla GPT creada exitosamente en el disco (%d entradas).\n", gpt.Encabezado.GptNumeroEntradas)
+	gpt.Imprimir()
+	fmt.Println("--------------------------------------------")
+
+	return nil
+}
@@ -0,0 +1,58 @@
+//go:build unix
+
+package Disk_test
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	Disk "backend/Comandos/Disk"
+	Pruebas "backend/Comandos/Pruebas"
+	Estructuras "backend/Estructuras"
+)
+
+func TestMkdiskDispersoYPrealloc(t *testing.T) {
+	const tamano = 8 * 1024 * 1024
+	casos := []struct {
+		parametros string
+		prealloc   bool
+	}{
+		{"-table=MBR", false},
+		{"-table=GPT", false},
+		{"-table=MBR -prealloc", true},
+		{"-table=GPT -prealloc", true},
+	}
+	for _, caso := range casos {
+		disco := filepath.Join(t.TempDir(), "disco.mia")
+		Pruebas.Ejecutar(t, Disk.ParserMkdisk, "-size=8 -unit=M -path="+disco+" "+caso.parametros)
+
+		info, err := os.Stat(disco)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Size() != tamano {
+			t.Errorf("%s: el disco mide %d bytes, se esperaba %d", caso.parametros, info.Size(), tamano)
+		}
+		// st_blocks cuenta bloques de 512 bytes realmente reservados en el host
+		reservados := info.Sys().(*syscall.Stat_t).Blocks * 512
+		if caso.prealloc && reservados < tamano {
+			t.Errorf("%s: solo se reservaron %d bytes", caso.parametros, reservados)
+		}
+		if !caso.prealloc && reservados >= tamano/2 {
+			t.Errorf("%s: el disco disperso reserva %d bytes", caso.parametros, reservados)
+		}
+
+		archivo, err := os.Open(disco)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var mbr Estructuras.MBR
+		err = mbr.Decodificar(archivo)
+		archivo.Close()
+		if err != nil || mbr.MbrSize != tamano {
+			t.Errorf("%s: MBR con tamaño %d: %v", caso.parametros, mbr.MbrSize, err)
+		}
+	}
+}
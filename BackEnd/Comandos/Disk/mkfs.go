@@ -8,6 +8,7 @@ import (
 	"math"
 	"os"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -16,9 +17,28 @@ import (
 )
 
 type MKFS struct {
-	id   string // ID disco
-	tipo string // Formato
-	fs   string // Tipo de sistema de archivos
+	id           string // ID disco
+	tipo         string // Formato
+	fs           string // Tipo de sistema de archivos
+	tamanoBloque int32  // Dimension de cada bloque en bytes (-bs)
+	ratio        int32  // Bloques por inodo (-ratio)
+	inodos       int32  // Cantidad fija de inodos (-inodes), excluye a -ratio
+	grupos       bool   // Divide la particion en grupos de bloques (-groups)
+}
+
+// Bloques por inodo cuando no se indica -ratio ni -inodes
+const bloquesPorInodoPorDefecto = 3
+
+// Geometria del sistema de archivos: cantidad de inodos y bloques y la dimension de cada bloque
+type geometriaFS struct {
+	inodos       int32
+	bloques      int32
+	tamanoBloque int32
+
+	// Solo con -groups; grupos en 0 es la disposicion lineal de siempre
+	grupos          int32
+	bloquesPorGrupo int32
+	inodosPorGrupo  int32
 }
 
 func ParserMkfs(tokens []string) (string, error) {
@@ -27,10 +47,15 @@ func ParserMkfs(tokens []string) (string, error) {
 	cmd := &MKFS{}
 
 	argumentos := strings.Join(tokens, " ")
-	re := regexp.MustCompile(`-id=[^\s]+|-type=[^\s]+|-fs=[^\s]+`)
+	re := regexp.MustCompile(`-id=[^\s]+|-type=[^\s]+|-fs=[^\s]+|-bs=[^\s]+|-ratio=[^\s]+|-inodes=[^\s]+|-groups\b`)
 	coincidencias := re.FindAllString(argumentos, -1)
 
 	for _, coincidencia := range coincidencias {
+		if strings.ToLower(coincidencia) == "-groups" {
+			cmd.grupos = true
+			continue
+		}
+
 		clavValor := strings.SplitN(coincidencia, "=", 2)
 		if len(clavValor) != 2 {
 			return "", fmt.Errorf("formato de parametro invalido: %s", coincidencia)
@@ -56,6 +81,24 @@ func ParserMkfs(tokens []string) (string, error) {
 				return "", errors.New("el sistema de archivos debe ser 2fs o 3fs")
 			}
 			cmd.fs = valor
+		case "-bs":
+			tamano, err := strconv.Atoi(valor)
+			if err != nil || (tamano != 64 && tamano != 128 && tamano != 256 && tamano != 512) {
+				return "", errors.New("la dimension de bloque debe ser 64, 128, 256 o 512")
+			}
+			cmd.tamanoBloque = int32(tamano)
+		case "-ratio":
+			ratio, err := strconv.Atoi(valor)
+			if err != nil || ratio < 1 {
+				return "", errors.New("el ratio debe ser un numero entero de bloques por inodo mayor a 0")
+			}
+			cmd.ratio = int32(ratio)
+		case "-inodes":
+			inodos, err := strconv.Atoi(valor)
+			if err != nil || inodos < 2 {
+				return "", errors.New("la cantidad de inodos debe ser un numero entero mayor a 1")
+			}
+			cmd.inodos = int32(inodos)
 		default:
 			return "", fmt.Errorf("parametro desconocido: %s", clave)
 		}
@@ -73,6 +116,16 @@ func ParserMkfs(tokens []string) (string, error) {
 		cmd.fs = "2fs"
 	}
 
+	if cmd.ratio != 0 && cmd.inodos != 0 {
+		return "", errors.New("-ratio y -inodes no se pueden combinar")
+	}
+	if cmd.tamanoBloque == 0 {
+		cmd.tamanoBloque = Estructuras.DimensionBloque
+	}
+	if cmd.ratio == 0 {
+		cmd.ratio = bloquesPorInodoPorDefecto
+	}
+
 	err := comandoMkfs(cmd, &bufferSalida)
 	if err != nil {
 		fmt.Println("Error:", err)
@@ -103,14 +156,49 @@ func comandoMkfs(mkfs *MKFS, bufferSalida *bytes.Buffer) error {
 	fmt.Println("\nParticion montada:")
 	particionMontada.Imprimir()
 
-	// Calcular el valor de n
-	n := calcularN(particionMontada, mkfs.fs)
-	fmt.Println("\nValor de n:", n)
+	// Calcular la geometria antes de tocar el disco
+	var geometria geometriaFS
+	ratio := float64(mkfs.ratio)
+	if mkfs.inodos != 0 {
+		ratio = 0
+	}
+	if mkfs.grupos {
+		geometria, err = calcularGeometriaGrupos(particionMontada, mkfs.fs, mkfs.tamanoBloque, ratio, mkfs.inodos)
+	} else {
+		geometria, err = calcularGeometria(particionMontada, mkfs.fs, mkfs.tamanoBloque, ratio, mkfs.inodos)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Formateo completo: limpiar explicitamente el espacio de la particion,
+	// el disco puede ser disperso y no se asume que ya contenga ceros
+	if mkfs.tipo == "full" {
+		err = particionMontada.Sobrescribir(archivo)
+		if err != nil {
+			return fmt.Errorf("error limpiando la particion: %v", err)
+		}
+		fmt.Fprintln(bufferSalida, "Espacio de la particion limpiado con ceros.")
+	}
+
+	fmt.Println("\nValor de n:", geometria.inodos)
+	fmt.Fprintf(bufferSalida, "Dimension de bloque: %d bytes | Inodos: %d | Bloques: %d\n",
+		geometria.tamanoBloque, geometria.inodos, geometria.bloques)
+	if geometria.grupos > 0 {
+		fmt.Fprintf(bufferSalida, "Grupos de bloques: %d de %d bloques y %d inodos\n",
+			geometria.grupos, geometria.bloquesPorGrupo, geometria.inodosPorGrupo)
+	}
 
 	// Crear el superblock
-	superBloque := crearSuperBlock(particionMontada, n, mkfs.fs)
+	superBloque := crearSuperBlock(particionMontada, geometria, mkfs.fs)
 	fmt.Println("\nSuperBlock:")
 	superBloque.Imprimir()
+	// Los inodos se leen y escriben con el S_inode_size del superbloque en el disco; si la
+	// region tenia un formato anterior deben escribirse ya con el actual
+	err = superBloque.Codificar(archivo, int64(particionMontada.Part_start))
+	if err != nil {
+		return fmt.Errorf("error escribiendo el superbloque: %v", err)
+	}
 
 	// Crear bitmaps
 	err = superBloque.CrearBitMaps(archivo)
@@ -119,10 +207,18 @@ func comandoMkfs(mkfs *MKFS, bufferSalida *bytes.Buffer) error {
 	}
 	fmt.Fprintln(bufferSalida, "Bitmaps generados correctamente.")
 
+	// Con grupos los primeros bloques de cada grupo son sus metadatos
+	if superBloque.S_groups_count > 0 {
+		err = superBloque.ReservarMetadatosGrupos(archivo)
+		if err != nil {
+			return fmt.Errorf("error reservando los metadatos de los grupos: %v", err)
+		}
+	}
+
 	// Archivo users.txt
 
 	if mkfs.fs == "3fs" {
-		err = superBloque.CrearArchivoUsuariosExt3(archivo, int64(particionMontada.Part_start+int32(binary.Size(Estructuras.SuperBlock{}))))
+		err = superBloque.CrearArchivoUsuariosExt3(archivo, int64(superBloque.InicioJournal()))
 	} else {
 		err = superBloque.CrearArchivoUsuarios(archivo)
 	}
@@ -132,6 +228,18 @@ func comandoMkfs(mkfs *MKFS, bufferSalida *bytes.Buffer) error {
 	}
 	fmt.Fprintln(bufferSalida, "Archivo users.txt generado correctamente.")
 
+	if superBloque.S_groups_count > 0 {
+		err = superBloque.EscribirDescriptoresGrupo(archivo)
+		if err == nil {
+			err = superBloque.EscribirRespaldosGrupos(archivo)
+		}
+		if err != nil {
+			return fmt.Errorf("error escribiendo los descriptores de grupo: %v", err)
+		}
+		fmt.Fprintf(bufferSalida, "Descriptores de grupo escritos; superbloques de respaldo en los grupos %v.\n",
+			superBloque.GruposConRespaldo())
+	}
+
 	// Serializar el superbloque
 	err = superBloque.Codificar(archivo, int64(particionMontada.Part_start))
 	if err != nil {
@@ -143,32 +251,147 @@ func comandoMkfs(mkfs *MKFS, bufferSalida *bytes.Buffer) error {
 	return nil
 }
 
-func calcularN(particion *Estructuras.Particion, fs string) int32 {
-	numerador := int(particion.Part_size) - binary.Size(Estructuras.SuperBlock{})
-	baseDenominador := 4 + binary.Size(Estructuras.INodo{}) + 3*binary.Size(Estructuras.FileBlock{}) 
-	temp := 0
+// calcularGeometria reparte el espacio de la particion entre inodos y bloques. Cada
+// inodo ocupa su byte de bitmap, su estructura y en 3fs la reserva de journal de la
+// formula original; cada bloque ocupa su byte de bitmap y tamanoBloque bytes. Si
+// inodosFijos es mayor a 0 se usa esa cantidad y el resto se llena de bloques,
+// si no se crean ratio bloques por inodo
+func calcularGeometria(particion *Estructuras.Particion, fs string, tamanoBloque int32, ratio float64, inodosFijos int32) (geometriaFS, error) {
+	disponible := float64(int(particion.Part_size) - binary.Size(Estructuras.SuperBlock{}))
+	porInodo := float64(1 + binary.Size(Estructuras.INodo{}))
 	if fs == "3fs" {
-		// Acá
-		temp = binary.Size(Estructuras.Journal{}) * Estructuras.ENTRADAS_JOURNAL
+		porInodo += float64(binary.Size(Estructuras.Journal{}) * Estructuras.ENTRADAS_JOURNAL)
 	}
-	denominador := baseDenominador + temp
-	n := math.Floor(float64(numerador) / float64(denominador))
+	porBloque := float64(1 + tamanoBloque)
 
-	return int32(n)
+	geometria := geometriaFS{tamanoBloque: tamanoBloque}
+	if inodosFijos > 0 {
+		geometria.inodos = inodosFijos
+		geometria.bloques = int32(math.Floor((disponible - float64(inodosFijos)*porInodo) / porBloque))
+	} else {
+		geometria.inodos = int32(math.Floor(disponible / (porInodo + ratio*porBloque)))
+		geometria.bloques = int32(math.Floor(float64(geometria.inodos) * ratio))
+	}
+
+	// La raiz y users.txt necesitan al menos dos inodos y dos bloques
+	if geometria.inodos < 2 || geometria.bloques < 2 {
+		return geometria, fmt.Errorf("la particion de %d bytes no alcanza para %d inodos y %d bloques de %d bytes",
+			particion.Part_size, geometria.inodos, geometria.bloques, tamanoBloque)
+	}
+	return geometria, nil
+}
+
+// calcularGeometriaGrupos reparte la particion en grupos de bloques como ext2: cada grupo
+// tiene 8*tamanoBloque bloques (lo que cubre un bloque de bitmap), salvo en particiones
+// chicas donde se achican para tener al menos cuatro grupos. Los metadatos de cada grupo
+// salen de sus propios bloques, asi que los inodos por grupo se eligen para que queden
+// ratio bloques de datos por inodo; con inodosFijos se reparten entre los grupos
+func calcularGeometriaGrupos(particion *Estructuras.Particion, fs string, tamanoBloque int32, ratio float64, inodosFijos int32) (geometriaFS, error) {
+	reservado := int32(binary.Size(Estructuras.SuperBlock{}))
+	if fs == "3fs" {
+		reservado += int32(binary.Size(Estructuras.Journal{}) * Estructuras.ENTRADAS_JOURNAL)
+	}
+	tamanoDescriptor := int32(binary.Size(Estructuras.DescriptorGrupo{}))
+
+	geometria := geometriaFS{tamanoBloque: tamanoBloque, bloquesPorGrupo: 8 * tamanoBloque}
+	bloques := (particion.Part_size - reservado) / tamanoBloque
+	if bloques/4 < geometria.bloquesPorGrupo {
+		geometria.bloquesPorGrupo = bloques / 4 / 64 * 64
+	}
+	if geometria.bloquesPorGrupo < 64 {
+		return geometria, fmt.Errorf("la particion de %d bytes es muy chica para dividirla en grupos de bloques", particion.Part_size)
+	}
+
+	// La tabla de descriptores tambien ocupa espacio: se recalcula con la cantidad de grupos
+	grupos := (bloques + geometria.bloquesPorGrupo - 1) / geometria.bloquesPorGrupo
+	bloques = (particion.Part_size - reservado - grupos*tamanoDescriptor) / tamanoBloque / 8 * 8
+	geometria.grupos = (bloques + geometria.bloquesPorGrupo - 1) / geometria.bloquesPorGrupo
+	geometria.bloques = bloques
+
+	if inodosFijos > 0 {
+		geometria.inodosPorGrupo = (inodosFijos + geometria.grupos - 1) / geometria.grupos
+		geometria.inodosPorGrupo = (geometria.inodosPorGrupo + 7) / 8 * 8
+	} else {
+		porInodo := ratio + float64(binary.Size(Estructuras.INodo{}))/float64(tamanoBloque)
+		geometria.inodosPorGrupo = int32(float64(geometria.bloquesPorGrupo-2)/porInodo) / 8 * 8
+		if geometria.inodosPorGrupo < 8 {
+			geometria.inodosPorGrupo = 8
+		}
+	}
+
+	// El ultimo grupo puede quedar tan corto que no le caben sus metadatos: se descarta
+	sb := disponerGrupos(particion, geometria, fs)
+	ultimo := sb.DisposicionGrupo(geometria.grupos - 1)
+	if ultimo.PrimerDato >= ultimo.PrimerBloque+ultimo.Bloques && geometria.grupos > 1 {
+		geometria.grupos--
+		geometria.bloques = geometria.grupos * geometria.bloquesPorGrupo
+		sb = disponerGrupos(particion, geometria, fs)
+	}
+	for grupo := int32(0); grupo < geometria.grupos; grupo++ {
+		disposicion := sb.DisposicionGrupo(grupo)
+		if disposicion.PrimerBloque+disposicion.Bloques-disposicion.PrimerDato < 2 {
+			return geometria, fmt.Errorf("los metadatos de %d inodos por grupo no caben en grupos de %d bloques de %d bytes",
+				geometria.inodosPorGrupo, geometria.bloquesPorGrupo, tamanoBloque)
+		}
+	}
+	geometria.inodos = geometria.grupos * geometria.inodosPorGrupo
+	return geometria, nil
+}
+
+// disponerGrupos arma el superbloque de una particion con grupos: superbloque, tabla de
+// descriptores, journal en 3fs y despues los grupos. Los campos de bitmaps y tabla de
+// inodos apuntan a los del grupo 0, que no lleva respaldo, asi que el journal sigue
+// terminando justo donde empieza el bitmap de inodos
+func disponerGrupos(particion *Estructuras.Particion, geometria geometriaFS, fs string) *Estructuras.SuperBlock {
+	sb := &Estructuras.SuperBlock{
+		S_filesystem_type:   2,
+		S_free_inodes_count: geometria.grupos * geometria.inodosPorGrupo,
+		S_free_blocks_count: geometria.bloques,
+		S_mtime:             float64(time.Now().Unix()),
+		S_umtime:            float64(time.Now().Unix()),
+		S_mnt_count:         1,
+		S_magic:             0xEF53,
+		S_inode_size:        int32(binary.Size(Estructuras.INodo{})),
+		S_block_size:        geometria.tamanoBloque,
+		S_groups_count:      geometria.grupos,
+		S_blocks_per_group:  geometria.bloquesPorGrupo,
+		S_inodes_per_group:  geometria.inodosPorGrupo,
+		S_gdt_start:         particion.Part_start + int32(binary.Size(Estructuras.SuperBlock{})),
+	}
+	sb.S_block_start = sb.S_gdt_start + sb.TamanoDescriptores()
+	if fs == "3fs" {
+		sb.S_filesystem_type = 3
+		sb.S_block_start += int32(binary.Size(Estructuras.Journal{}) * Estructuras.ENTRADAS_JOURNAL)
+	}
+
+	grupo0 := sb.DisposicionGrupo(0)
+	sb.S_bm_inode_start = sb.S_block_start + grupo0.BitmapInodos*sb.S_block_size
+	sb.S_bm_block_start = sb.S_block_start + grupo0.BitmapBloques*sb.S_block_size
+	sb.S_inode_start = sb.S_block_start + grupo0.TablaInodos*sb.S_block_size
+	sb.S_first_ino = sb.S_inode_start
+	sb.S_first_blo = sb.S_block_start + grupo0.PrimerDato*sb.S_block_size
+	return sb
 }
 
 // Calcular punteros de las estructuras
-func crearSuperBlock(particion *Estructuras.Particion, n int32, fs string) *Estructuras.SuperBlock {
-	InicioJournal, InicioBMInodo, InicioBMBloque, InicioInodo, InicioBloque := calcularInicioPosiciones(particion, fs, n)
+func crearSuperBlock(particion *Estructuras.Particion, geometria geometriaFS, fs string) *Estructuras.SuperBlock {
+	if geometria.grupos > 0 {
+		superBloque := disponerGrupos(particion, geometria, fs)
+		fmt.Println("\nInicio de los descriptores de grupo:", superBloque.S_gdt_start)
+		fmt.Println("\nInicio del primer grupo:", superBloque.S_block_start)
+		return superBloque
+	}
+
+	InicioJournal, InicioBMInodo, InicioBMBloque, InicioInodo, InicioBloque := calcularInicioPosiciones(particion, fs, geometria)
 
 	fmt.Println("\nInicio del SuperBlock:", particion.Part_start)
 	fmt.Println("\nFin del SuperBlock:", particion.Part_start+int32(binary.Size(Estructuras.SuperBlock{})))
 	fmt.Println("\nInicio del Journal:", InicioJournal)
 	fmt.Println("\nFin del Journal:", InicioJournal+int32(binary.Size(Estructuras.Journal{})))
 	fmt.Println("\nInicio del Bitmap de Inodos:", InicioBMInodo)
-	fmt.Println("\nFin del Bitmap de Inodos:", InicioBMInodo+n)
+	fmt.Println("\nFin del Bitmap de Inodos:", InicioBMInodo+(geometria.inodos+7)/8)
 	fmt.Println("\nInicio del Bitmap de Bloques:", InicioBMBloque)
-	fmt.Println("\nFin del Bitmap de Bloques:", InicioBMBloque+(3*n))
+	fmt.Println("\nFin del Bitmap de Bloques:", InicioBMBloque+(geometria.bloques+7)/8)
 	fmt.Println("\nInicio de Inodos:", InicioInodo)
 
 	var fsType int32
@@ -183,14 +406,14 @@ func crearSuperBlock(particion *Estructuras.Particion, n int32, fs string) *Estr
 		S_filesystem_type:   fsType,
 		S_inodes_count:      0,
 		S_blocks_count:      0,
-		S_free_inodes_count: int32(n),
-		S_free_blocks_count: int32(n * 3),
+		S_free_inodes_count: geometria.inodos,
+		S_free_blocks_count: geometria.bloques,
 		S_mtime:             float64(time.Now().Unix()),
 		S_umtime:            float64(time.Now().Unix()),
 		S_mnt_count:         1,
 		S_magic:             0xEF53,
 		S_inode_size:        int32(binary.Size(Estructuras.INodo{})),
-		S_block_size:        int32(binary.Size(Estructuras.FileBlock{})),
+		S_block_size:        geometria.tamanoBloque,
 		S_first_ino:         InicioInodo,
 		S_first_blo:         InicioBloque,
 		S_bm_inode_start:    InicioBMInodo,
@@ -201,7 +424,8 @@ func crearSuperBlock(particion *Estructuras.Particion, n int32, fs string) *Estr
 	return superBloque
 }
 
-func calcularInicioPosiciones(particion *Estructuras.Particion, fs string, n int32) (int32, int32, int32, int32, int32) {
+func calcularInicioPosiciones(particion *Estructuras.Particion, fs string, geometria geometriaFS) (int32, int32, int32, int32, int32) {
+    n := geometria.inodos
     tamSuperBlock := int32(binary.Size(Estructuras.SuperBlock{}))
     tamJournal := int32(binary.Size(Estructuras.Journal{}))
     tamInodo := int32(binary.Size(Estructuras.INodo{}))
@@ -217,14 +441,14 @@ func calcularInicioPosiciones(particion *Estructuras.Particion, fs string, n int
         InicioJournal = particion.Part_start + tamSuperBlock
         InicioBMInodo = InicioJournal + (tamJournal * Estructuras.ENTRADAS_JOURNAL)
         InicioBMBloque = InicioBMInodo + n
-        InicioInodo = InicioBMBloque + (3 * n)
+        InicioInodo = InicioBMBloque + geometria.bloques
         InicioBloque = InicioInodo + (tamInodo * n)
     } else {
         // Para EXT2 sin journaling
         InicioJournal = 0 // No se usa
         InicioBMInodo = particion.Part_start + tamSuperBlock
         InicioBMBloque = InicioBMInodo + n
-        InicioInodo = InicioBMBloque + (3 * n)
+        InicioInodo = InicioBMBloque + geometria.bloques
         InicioBloque = InicioInodo + (tamInodo * n)
     }
 	
@@ -0,0 +1,88 @@
+package Disk_test
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	Disk "backend/Comandos/Disk"
+	Forge "backend/Comandos/Forge"
+	Pruebas "backend/Comandos/Pruebas"
+	Global "backend/Global"
+)
+
+func TestMkfsGeometria(t *testing.T) {
+	casos := []struct {
+		parametros string
+		bloque     int32
+		ratio      int32 // bloques por inodo esperados, 0 si la cantidad de inodos es fija
+		inodos     int32
+	}{
+		{"", 64, Disk.BloquesPorInodoPorDefecto, 0},
+		{"-bs=128", 128, Disk.BloquesPorInodoPorDefecto, 0},
+		{"-bs=512 -ratio=1", 512, 1, 0},
+		{"-bs=256 -ratio=8", 256, 8, 0},
+		{"-inodes=100", 64, 0, 100},
+		{"-fs=3fs -bs=128 -inodes=50", 128, 0, 50},
+	}
+	for _, caso := range casos {
+		id := Pruebas.MontarParticionFormateada(t, caso.parametros)
+		particion, _, err := Global.ObtenerParticionMontada(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sb, _, _, err := Global.ObtenerSuperblockParticionMontada(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// S_*_count son los creados, sumados a los libres dan el total
+		inodos := sb.S_inodes_count + sb.S_free_inodes_count
+		bloques := sb.S_blocks_count + sb.S_free_blocks_count
+		if sb.S_block_size != caso.bloque {
+			t.Errorf("%q: bloques de %d bytes, se esperaban %d", caso.parametros, sb.S_block_size, caso.bloque)
+		}
+		if caso.ratio != 0 && bloques != inodos*caso.ratio {
+			t.Errorf("%q: %d bloques para %d inodos, se esperaba un ratio de %d", caso.parametros, bloques, inodos, caso.ratio)
+		}
+		if caso.inodos != 0 && inodos != caso.inodos {
+			t.Errorf("%q: %d inodos, se esperaban %d", caso.parametros, inodos, caso.inodos)
+		}
+		if fin := int64(sb.S_block_start) + int64(bloques)*int64(sb.S_block_size); fin > int64(particion.Part_start+particion.Part_size) {
+			t.Errorf("%q: la tabla de bloques termina en %d, fuera de la particion", caso.parametros, fin)
+		}
+
+		// El sistema de archivos formateado se usa normalmente
+		Pruebas.Ejecutar(t, Forge.ParserMkfile, "-path=/prueba.txt -size=700")
+		if contenido := leerArchivo(t, id, "prueba.txt"); len(contenido) != 700 {
+			t.Errorf("%q: prueba.txt tiene %d bytes", caso.parametros, len(contenido))
+		}
+		if salida := Pruebas.Ejecutar(t, Disk.ParserDf, "-id="+id); strings.Contains(salida, "Use -fix") {
+			t.Errorf("%q: contadores desviados despues de formatear:\n%s", caso.parametros, salida)
+		}
+	}
+}
+
+func TestMkfsRechazaGeometriasInvalidas(t *testing.T) {
+	disco := filepath.Join(t.TempDir(), "disco.mia")
+	Pruebas.Ejecutar(t, Disk.ParserMkdisk, "-size=3 -unit=M -path="+disco)
+	Pruebas.Ejecutar(t, Disk.ParserFdisk, "-size=1 -unit=M -path="+disco+" -name=Part1")
+	id := Pruebas.Montar(t, disco, "Part1")
+
+	casos := []struct {
+		parametros string
+		mensaje    string
+	}{
+		{"-bs=100", "64, 128, 256 o 512"},
+		{"-ratio=0", "ratio"},
+		{"-inodes=1", "mayor a 1"},
+		{"-ratio=2 -inodes=10", "no se pueden combinar"},
+		{"-bs=512 -inodes=20000", "no alcanza"},
+	}
+	for _, caso := range casos {
+		err := Pruebas.SinCache(Disk.ParserMkfs, "-id="+id+" "+caso.parametros)
+		if err == nil || !strings.Contains(err.Error(), caso.mensaje) {
+			t.Errorf("mkfs %s: error %v, se esperaba uno con %q", caso.parametros, err, caso.mensaje)
+		}
+	}
+}
@@ -82,6 +82,10 @@ func ejecutarComandoMount(mount *Mount, bufferSalida *bytes.Buffer) error {
 		return fmt.Errorf("error deserializando el MBR: %v", err)
 	}
 
+	if mbr.EsProtectorGPT() {
+		return montarParticionGPT(archivo, mount, bufferSalida)
+	}
+
 	particion, indiceParticion := mbr.ObtenerParticionPorNombre(mount.nombre)
 	if particion == nil {
 		return fmt.Errorf("error: la partición '%s' no existe en el disco", mount.nombre)
@@ -103,6 +107,44 @@ func ejecutarComandoMount(mount *Mount, bufferSalida *bytes.Buffer) error {
 	if err := mbr.Codificar(archivo); err != nil {
 		return fmt.Errorf("error serializando el MBR de vuelta al disco: %v", err)
 	}
+	if err := Global.RegistrarMontaje(idParticion, mount.ruta); err != nil {
+		return fmt.Errorf("error abriendo la cache de la partición: %v", err)
+	}
+
+	imprimirParticionesMontadas(bufferSalida, mount.nombre, idParticion)
+	return nil
+}
+
+// Monta una partición de un disco con tabla GPT
+func montarParticionGPT(archivo *os.File, mount *Mount, bufferSalida *bytes.Buffer) error {
+	var gpt Estructuras.GPT
+	if err := gpt.Decodificar(archivo); err != nil {
+		return fmt.Errorf("error deserializando la tabla GPT: %v", err)
+	}
+
+	entrada, indiceEntrada := gpt.ObtenerParticionPorNombre(mount.nombre)
+	if entrada == nil {
+		return fmt.Errorf("error: la partición '%s' no existe en el disco", mount.nombre)
+	}
+
+	if err := verificarParticionYaMontada(mount); err != nil {
+		return err
+	}
+
+	idParticion, err := GenerarIdParticion(mount, indiceEntrada)
+	if err != nil {
+		return fmt.Errorf("error generando el ID de la partición: %v", err)
+	}
+
+	Global.ParticionesMontadas[idParticion] = mount.ruta
+	entrada.MontarParticion(indiceEntrada, idParticion)
+
+	if err := gpt.Codificar(archivo); err != nil {
+		return fmt.Errorf("error serializando la tabla GPT de vuelta al disco: %v", err)
+	}
+	if err := Global.RegistrarMontaje(idParticion, mount.ruta); err != nil {
+		return fmt.Errorf("error abriendo la cache de la partición: %v", err)
+	}
 
 	imprimirParticionesMontadas(bufferSalida, mount.nombre, idParticion)
 	return nil
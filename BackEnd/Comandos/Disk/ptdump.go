@@ -0,0 +1,273 @@
+package Disk
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	Estructuras "backend/Estructuras"
+
+	"github.com/google/uuid"
+)
+
+// LayoutDisco es la representacion en JSON de la tabla de particiones de un disco
+type LayoutDisco struct {
+	Tabla       string            `json:"tabla"` // MBR o GPT
+	Tamano      int32             `json:"tamano"`
+	Creacion    float32           `json:"creacion"`
+	Firma       int32             `json:"firma"`
+	Ajuste      string            `json:"ajuste"`
+	Compatible  bool              `json:"compatible,omitempty"`
+	Particiones []LayoutParticion `json:"particiones,omitempty"`
+	Logicas     []LayoutEBR       `json:"logicas,omitempty"`
+	GPT         *LayoutGPT        `json:"gpt,omitempty"`
+}
+
+// LayoutParticion representa una entrada del MBR
+type LayoutParticion struct {
+	Estado      string `json:"estado"`
+	Tipo        string `json:"tipo"`
+	Ajuste      string `json:"ajuste"`
+	Inicio      int32  `json:"inicio"`
+	Tamano      int32  `json:"tamano"`
+	Nombre      string `json:"nombre"`
+	Correlativo int32  `json:"correlativo"`
+	ID          string `json:"id"`
+}
+
+// LayoutEBR representa un EBR de la cadena de la particion extendida
+type LayoutEBR struct {
+	Montaje   string `json:"montaje"`
+	Ajuste    string `json:"ajuste"`
+	Inicio    int32  `json:"inicio"`
+	Tamano    int32  `json:"tamano"`
+	Siguiente int32  `json:"siguiente"`
+	Nombre    string `json:"nombre"`
+}
+
+// LayoutGPT representa el encabezado GPT y sus entradas en uso
+type LayoutGPT struct {
+	Guid             string             `json:"guid"`
+	Creacion         float32            `json:"creacion"`
+	Ajuste           string             `json:"ajuste"`
+	PrimerByteUsable int32              `json:"primerByteUsable"`
+	UltimoByteUsable int32              `json:"ultimoByteUsable"`
+	Entradas         []LayoutEntradaGPT `json:"entradas"`
+}
+
+// LayoutEntradaGPT representa una entrada en uso del arreglo GPT
+type LayoutEntradaGPT struct {
+	Indice      int    `json:"indice"`
+	TipoGuid    string `json:"tipoGuid"`
+	Guid        string `json:"guid"`
+	Estado      string `json:"estado"`
+	Ajuste      string `json:"ajuste"`
+	Inicio      int32  `json:"inicio"`
+	Tamano      int32  `json:"tamano"`
+	Nombre      string `json:"nombre"`
+	Correlativo int32  `json:"correlativo"`
+	ID          string `json:"id"`
+}
+
+// PtDump representa el comando ptdump con sus parametros
+type PtDump struct {
+	ruta   string // Ubicacion del archivo del disco
+	salida string // Archivo JSON donde se exporta la tabla
+}
+
+// Procesa el comando ptdump y retorna los mensajes generados
+func ParserPtdump(tokens []string) (string, error) {
+	var bufferSalida bytes.Buffer
+	cmd := &PtDump{}
+
+	argumentos := strings.Join(tokens, " ")
+	patron := regexp.MustCompile(`-path="[^"]+"|-path=[^\s]+|-out="[^"]+"|-out=[^\s]+`)
+	coincidencias := patron.FindAllString(argumentos, -1)
+
+	for _, coincidencia := range coincidencias {
+		claveValor := strings.SplitN(coincidencia, "=", 2)
+		if len(claveValor) != 2 {
+			return "", fmt.Errorf("formato de parametro invalido: %s", coincidencia)
+		}
+		clave, valor := strings.ToLower(claveValor[0]), claveValor[1]
+		if strings.HasPrefix(valor, "\"") && strings.HasSuffix(valor, "\"") {
+			valor = strings.Trim(valor, "\"")
+		}
+
+		switch clave {
+		case "-path":
+			cmd.ruta = valor
+		case "-out":
+			cmd.salida = valor
+		default:
+			return "", fmt.Errorf("parametro desconocido: %s", clave)
+		}
+	}
+
+	if cmd.ruta == "" {
+		return "", errors.New("faltan parametros requeridos: -path")
+	}
+	if cmd.salida == "" {
+		return "", errors.New("faltan parametros requeridos: -out")
+	}
+
+	err := ejecutarComandoPtdump(cmd, &bufferSalida)
+	if err != nil {
+		return "", fmt.Errorf("error al exportar la tabla de particiones: %v", err)
+	}
+
+	return bufferSalida.String(), nil
+}
+
+func ejecutarComandoPtdump(ptdump *PtDump, bufferSalida *bytes.Buffer) error {
+	fmt.Fprintln(bufferSalida, "---------------------------- PTDUMP ----------------------------")
+
+	archivo, err := os.Open(ptdump.ruta)
+	if err != nil {
+		return fmt.Errorf("error abriendo el archivo del disco en la ruta: %s: %v", ptdump.ruta, err)
+	}
+	defer archivo.Close()
+
+	layout, err := leerLayout(archivo)
+	if err != nil {
+		return err
+	}
+
+	contenido, err := json.MarshalIndent(layout, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error al serializar la tabla: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(ptdump.salida), os.ModePerm); err != nil {
+		return fmt.Errorf("error al crear el directorio de salida: %v", err)
+	}
+	if err := os.WriteFile(ptdump.salida, contenido, 0644); err != nil {
+		return fmt.Errorf("error al escribir %s: %v", ptdump.salida, err)
+	}
+
+	fmt.Fprintf(bufferSalida, "Tabla %s exportada en: %s\n", layout.Tabla, ptdump.salida)
+	fmt.Fprintf(bufferSalida, "Particiones: %d | Logicas: %d\n", len(layout.Particiones), len(layout.Logicas))
+	fmt.Fprintln(bufferSalida, "--------------------------------------------")
+	return nil
+}
+
+// Lee el MBR, la cadena de EBR y, si aplica, la tabla GPT del disco
+func leerLayout(archivo *os.File) (*LayoutDisco, error) {
+	var mbr Estructuras.MBR
+	if err := mbr.Decodificar(archivo); err != nil {
+		return nil, fmt.Errorf("error al deserializar el MBR: %v", err)
+	}
+
+	layout := &LayoutDisco{
+		Tabla:      TablaMBR,
+		Tamano:     mbr.MbrSize,
+		Creacion:   mbr.MbrCreacionDate,
+		Firma:      mbr.MbrDiskSignature,
+		Ajuste:     byteACadena(mbr.MbrDiskFit[0]),
+		Compatible: mbr.EsCompatible(),
+	}
+
+	if mbr.EsProtectorGPT() {
+		var gpt Estructuras.GPT
+		if err := gpt.Decodificar(archivo); err != nil {
+			return nil, fmt.Errorf("error al deserializar la tabla GPT: %v", err)
+		}
+		layout.Tabla = TablaGPT
+		layout.GPT = layoutDesdeGPT(&gpt)
+		return layout, nil
+	}
+
+	for _, p := range mbr.MbrPartitions {
+		layout.Particiones = append(layout.Particiones, LayoutParticion{
+			Estado:      byteACadena(p.Part_status[0]),
+			Tipo:        byteACadena(p.Part_type[0]),
+			Ajuste:      byteACadena(p.Part_fit[0]),
+			Inicio:      p.Part_start,
+			Tamano:      p.Part_size,
+			Nombre:      strings.Trim(string(p.Part_name[:]), "\x00 "),
+			Correlativo: p.Part_correlative,
+			ID:          strings.Trim(string(p.Part_id[:]), "\x00 "),
+		})
+
+		if p.Part_type[0] != 'E' || p.Part_start == -1 {
+			continue
+		}
+		visitados := make(map[int32]bool)
+		inicioEBR := p.Part_start
+		for inicioEBR != -1 {
+			if visitados[inicioEBR] {
+				return nil, fmt.Errorf("la cadena de EBR contiene un ciclo en %d", inicioEBR)
+			}
+			visitados[inicioEBR] = true
+			ebr, err := Estructuras.LeerEBR(inicioEBR, archivo)
+			if err != nil {
+				return nil, fmt.Errorf("error al leer el EBR en %d: %v", inicioEBR, err)
+			}
+			layout.Logicas = append(layout.Logicas, LayoutEBR{
+				Montaje:   byteACadena(ebr.Ebr_mount[0]),
+				Ajuste:    byteACadena(ebr.Ebr_fit[0]),
+				Inicio:    ebr.Ebr_start,
+				Tamano:    ebr.Ebr_size,
+				Siguiente: ebr.Ebr_next,
+				Nombre:    strings.Trim(string(ebr.Ebr_name[:]), "\x00 "),
+			})
+			inicioEBR = ebr.Ebr_next
+		}
+	}
+	return layout, nil
+}
+
+func layoutDesdeGPT(gpt *Estructuras.GPT) *LayoutGPT {
+	guidDisco, _ := uuid.FromBytes(gpt.Encabezado.GptDiskGuid[:])
+	resultado := &LayoutGPT{
+		Guid:             guidDisco.String(),
+		Creacion:         gpt.Encabezado.GptCreacionDate,
+		Ajuste:           byteACadena(gpt.Encabezado.GptDiskFit[0]),
+		PrimerByteUsable: gpt.Encabezado.GptPrimerByteUsable,
+		UltimoByteUsable: gpt.Encabezado.GptUltimoByteUsable,
+		Entradas:         []LayoutEntradaGPT{},
+	}
+	for i, e := range gpt.Entradas {
+		if !e.EnUso() {
+			continue
+		}
+		tipo, _ := uuid.FromBytes(e.Part_type_guid[:])
+		guid, _ := uuid.FromBytes(e.Part_guid[:])
+		resultado.Entradas = append(resultado.Entradas, LayoutEntradaGPT{
+			Indice:      i,
+			TipoGuid:    tipo.String(),
+			Guid:        guid.String(),
+			Estado:      byteACadena(e.Part_status[0]),
+			Ajuste:      byteACadena(e.Part_fit[0]),
+			Inicio:      e.Part_start,
+			Tamano:      e.Part_size,
+			Nombre:      e.Nombre(),
+			Correlativo: e.Part_correlative,
+			ID:          strings.Trim(string(e.Part_id[:]), "\x00 "),
+		})
+	}
+	return resultado
+}
+
+// Convierte un campo de un byte en texto (cadena vacia si es nulo)
+func byteACadena(b byte) string {
+	if b == 0 {
+		return ""
+	}
+	return string(b)
+}
+
+// Convierte un texto de a lo sumo un caracter en el campo de un byte
+func cadenaAByte(s string) ([1]byte, error) {
+	if len(s) > 1 {
+		return [1]byte{}, fmt.Errorf("se esperaba un solo caracter y se recibio '%s'", s)
+	}
+	if s == "" {
+		return [1]byte{}, nil
+	}
+	return [1]byte{s[0]}, nil
+}
@@ -0,0 +1,342 @@
+package Disk
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	Estructuras "backend/Estructuras"
+
+	"github.com/google/uuid"
+)
+
+// PtRestore representa el comando ptrestore con sus parametros
+type PtRestore struct {
+	ruta    string // Ubicacion del archivo del disco
+	entrada string // Archivo JSON generado por ptdump
+}
+
+// Procesa el comando ptrestore y retorna los mensajes generados
+func ParserPtrestore(tokens []string) (string, error) {
+	var bufferSalida bytes.Buffer
+	cmd := &PtRestore{}
+
+	argumentos := strings.Join(tokens, " ")
+	patron := regexp.MustCompile(`-path="[^"]+"|-path=[^\s]+|-in="[^"]+"|-in=[^\s]+`)
+	coincidencias := patron.FindAllString(argumentos, -1)
+
+	for _, coincidencia := range coincidencias {
+		claveValor := strings.SplitN(coincidencia, "=", 2)
+		if len(claveValor) != 2 {
+			return "", fmt.Errorf("formato de parametro invalido: %s", coincidencia)
+		}
+		clave, valor := strings.ToLower(claveValor[0]), claveValor[1]
+		if strings.HasPrefix(valor, "\"") && strings.HasSuffix(valor, "\"") {
+			valor = strings.Trim(valor, "\"")
+		}
+
+		switch clave {
+		case "-path":
+			cmd.ruta = valor
+		case "-in":
+			cmd.entrada = valor
+		default:
+			return "", fmt.Errorf("parametro desconocido: %s", clave)
+		}
+	}
+
+	if cmd.ruta == "" {
+		return "", errors.New("faltan parametros requeridos: -path")
+	}
+	if cmd.entrada == "" {
+		return "", errors.New("faltan parametros requeridos: -in")
+	}
+
+	err := ejecutarComandoPtrestore(cmd, &bufferSalida)
+	if err != nil {
+		return "", fmt.Errorf("error al restaurar la tabla de particiones: %v", err)
+	}
+
+	return bufferSalida.String(), nil
+}
+
+func ejecutarComandoPtrestore(ptrestore *PtRestore, bufferSalida *bytes.Buffer) error {
+	fmt.Fprintln(bufferSalida, "--------------------------- PTRESTORE ---------------------------")
+
+	contenido, err := os.ReadFile(ptrestore.entrada)
+	if err != nil {
+		return fmt.Errorf("error al leer %s: %v", ptrestore.entrada, err)
+	}
+	var layout LayoutDisco
+	if err := json.Unmarshal(contenido, &layout); err != nil {
+		return fmt.Errorf("el archivo %s no contiene un layout valido: %v", ptrestore.entrada, err)
+	}
+
+	archivo, err := os.OpenFile(ptrestore.ruta, os.O_RDWR, 0644)
+	if err != nil {
+		return fmt.Errorf("error abriendo el archivo del disco en la ruta: %s: %v", ptrestore.ruta, err)
+	}
+	defer archivo.Close()
+
+	info, err := archivo.Stat()
+	if err != nil {
+		return fmt.Errorf("error al obtener la informacion del disco: %v", err)
+	}
+	if layout.Tamano <= 0 || int64(layout.Tamano) > info.Size() {
+		return fmt.Errorf("el tamaño del layout (%d bytes) no corresponde al disco (%d bytes)", layout.Tamano, info.Size())
+	}
+	ajuste, err := cadenaAByte(layout.Ajuste)
+	if err != nil {
+		return err
+	}
+
+	switch strings.ToUpper(layout.Tabla) {
+	case TablaGPT:
+		err = restaurarGPT(archivo, &layout, ajuste[0])
+	case TablaMBR:
+		err = restaurarMBR(archivo, &layout, ajuste)
+	default:
+		err = fmt.Errorf("tabla desconocida en el layout: '%s'", layout.Tabla)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(bufferSalida, "Tabla %s restaurada en: %s\n", strings.ToUpper(layout.Tabla), ptrestore.ruta)
+	fmt.Fprintln(bufferSalida, "--------------------------------------------")
+	return nil
+}
+
+// Valida y escribe el MBR y la cadena de EBR descritos en el layout
+func restaurarMBR(archivo *os.File, layout *LayoutDisco, ajuste [1]byte) error {
+	if len(layout.Particiones) != 4 {
+		return fmt.Errorf("el MBR debe tener 4 entradas y el layout tiene %d", len(layout.Particiones))
+	}
+
+	mbr := Estructuras.MBR{
+		MbrSize:          layout.Tamano,
+		MbrCreacionDate:  layout.Creacion,
+		MbrDiskSignature: layout.Firma,
+		MbrDiskFit:       ajuste,
+	}
+	if layout.Compatible {
+		mbr.MarcarCompatible()
+	}
+	inicioUsable := mbr.InicioUsable()
+
+	var ocupadas []Estructuras.EspacioLibre
+	var extendida *Estructuras.Particion
+	for i, lp := range layout.Particiones {
+		p := &mbr.MbrPartitions[i]
+		var err error
+		if p.Part_status, err = cadenaAByte(lp.Estado); err != nil {
+			return err
+		}
+		if p.Part_type, err = cadenaAByte(lp.Tipo); err != nil {
+			return err
+		}
+		if p.Part_fit, err = cadenaAByte(lp.Ajuste); err != nil {
+			return err
+		}
+		if len(lp.Nombre) > len(p.Part_name) || len(lp.ID) > len(p.Part_id) {
+			return fmt.Errorf("la entrada %d tiene un nombre o ID demasiado largo", i+1)
+		}
+		p.Part_start = lp.Inicio
+		p.Part_size = lp.Tamano
+		p.Part_correlative = lp.Correlativo
+		copy(p.Part_name[:], lp.Nombre)
+		copy(p.Part_id[:], lp.ID)
+
+		if p.Part_start == -1 {
+			continue
+		}
+		if p.Part_size <= 0 || p.Part_start < inicioUsable || p.Part_start+p.Part_size > mbr.MbrSize {
+			return fmt.Errorf("la particion '%s' (%d, %d bytes) esta fuera de los limites del disco", lp.Nombre, p.Part_start, p.Part_size)
+		}
+		if p.Part_type[0] == 'E' {
+			if extendida != nil {
+				return errors.New("el layout tiene mas de una particion extendida")
+			}
+			extendida = p
+		}
+		ocupadas = append(ocupadas, Estructuras.EspacioLibre{Inicio: p.Part_start, Tamano: p.Part_size})
+	}
+	if err := validarSolapamientos(ocupadas); err != nil {
+		return err
+	}
+
+	ebrs, err := construirCadenaEBR(layout.Logicas, extendida)
+	if err != nil {
+		return err
+	}
+
+	if err := mbr.Codificar(archivo); err != nil {
+		return fmt.Errorf("error al escribir el MBR: %v", err)
+	}
+	for i := range ebrs {
+		if err := ebrs[i].Codificar(archivo, int64(ebrs[i].Ebr_start)); err != nil {
+			return fmt.Errorf("error al escribir el EBR en %d: %v", ebrs[i].Ebr_start, err)
+		}
+	}
+	return nil
+}
+
+// Valida que la cadena de EBR empiece en la extendida, este enlazada en orden
+// y que cada particion logica quede dentro de la extendida sin solaparse
+func construirCadenaEBR(logicas []LayoutEBR, extendida *Estructuras.Particion) ([]Estructuras.EBR, error) {
+	if extendida == nil {
+		if len(logicas) > 0 {
+			return nil, errors.New("el layout tiene particiones logicas pero ninguna extendida")
+		}
+		return nil, nil
+	}
+	if len(logicas) == 0 {
+		return nil, errors.New("la particion extendida no tiene EBR inicial en el layout")
+	}
+	if logicas[0].Inicio != extendida.Part_start {
+		return nil, fmt.Errorf("el primer EBR (%d) debe estar al inicio de la extendida (%d)", logicas[0].Inicio, extendida.Part_start)
+	}
+
+	finExtendida := extendida.Part_start + extendida.Part_size
+	var ebrs []Estructuras.EBR
+	var ocupadas []Estructuras.EspacioLibre
+	for i, l := range logicas {
+		siguienteEsperado := int32(-1)
+		if i+1 < len(logicas) {
+			siguienteEsperado = logicas[i+1].Inicio
+		}
+		if l.Siguiente != siguienteEsperado {
+			return nil, fmt.Errorf("el EBR en %d apunta a %d pero el siguiente esta en %d", l.Inicio, l.Siguiente, siguienteEsperado)
+		}
+		if l.Tamano < 0 || (l.Tamano == 0 && i > 0) {
+			return nil, fmt.Errorf("el EBR en %d tiene un tamaño invalido: %d", l.Inicio, l.Tamano)
+		}
+		tamano := l.Tamano
+		if tamano == 0 {
+			tamano = int32(binary.Size(Estructuras.EBR{}))
+		}
+		if l.Inicio < extendida.Part_start || l.Inicio+tamano > finExtendida {
+			return nil, fmt.Errorf("la particion logica '%s' esta fuera de la extendida", l.Nombre)
+		}
+		ocupadas = append(ocupadas, Estructuras.EspacioLibre{Inicio: l.Inicio, Tamano: tamano})
+
+		var ebr Estructuras.EBR
+		var err error
+		if ebr.Ebr_mount, err = cadenaAByte(l.Montaje); err != nil {
+			return nil, err
+		}
+		if ebr.Ebr_fit, err = cadenaAByte(l.Ajuste); err != nil {
+			return nil, err
+		}
+		if len(l.Nombre) > len(ebr.Ebr_name) {
+			return nil, fmt.Errorf("el nombre de la particion logica '%s' es demasiado largo", l.Nombre)
+		}
+		ebr.Ebr_start = l.Inicio
+		ebr.Ebr_size = l.Tamano
+		ebr.Ebr_next = l.Siguiente
+		copy(ebr.Ebr_name[:], l.Nombre)
+		ebrs = append(ebrs, ebr)
+	}
+
+	if err := validarSolapamientos(ocupadas); err != nil {
+		return nil, err
+	}
+	return ebrs, nil
+}
+
+// Valida y escribe el MBR protector y la tabla GPT descritos en el layout
+func restaurarGPT(archivo *os.File, layout *LayoutDisco, ajuste byte) error {
+	if layout.GPT == nil {
+		return errors.New("el layout GPT no contiene la seccion 'gpt'")
+	}
+
+	gpt := Estructuras.NuevaGPT(layout.Tamano, ajuste)
+	guidDisco, err := uuid.Parse(layout.GPT.Guid)
+	if err != nil {
+		return fmt.Errorf("GUID de disco invalido: %v", err)
+	}
+	ajusteGPT, err := cadenaAByte(layout.GPT.Ajuste)
+	if err != nil {
+		return err
+	}
+	gpt.Encabezado.GptDiskGuid = guidDisco
+	gpt.Encabezado.GptCreacionDate = layout.GPT.Creacion
+	gpt.Encabezado.GptDiskFit = ajusteGPT
+	if layout.GPT.PrimerByteUsable != gpt.Encabezado.GptPrimerByteUsable || layout.GPT.UltimoByteUsable != gpt.Encabezado.GptUltimoByteUsable {
+		return errors.New("los limites usables del layout no corresponden al tamaño del disco")
+	}
+
+	var ocupadas []Estructuras.EspacioLibre
+	for _, le := range layout.GPT.Entradas {
+		if le.Indice < 0 || le.Indice >= Estructuras.EntradasGPT {
+			return fmt.Errorf("indice de entrada GPT invalido: %d", le.Indice)
+		}
+		e := &gpt.Entradas[le.Indice]
+		if e.EnUso() {
+			return fmt.Errorf("la entrada GPT %d aparece repetida", le.Indice)
+		}
+		tipo, err := uuid.Parse(le.TipoGuid)
+		if err != nil {
+			return fmt.Errorf("GUID de tipo invalido en la entrada %d: %v", le.Indice, err)
+		}
+		guid, err := uuid.Parse(le.Guid)
+		if err != nil {
+			return fmt.Errorf("GUID invalido en la entrada %d: %v", le.Indice, err)
+		}
+		if tipo == uuid.Nil {
+			return fmt.Errorf("la entrada %d no tiene GUID de tipo", le.Indice)
+		}
+		if le.Tamano <= 0 || le.Inicio < gpt.Encabezado.GptPrimerByteUsable || le.Inicio+le.Tamano > gpt.Encabezado.GptUltimoByteUsable+1 {
+			return fmt.Errorf("la particion '%s' (%d, %d bytes) esta fuera de los limites del disco", le.Nombre, le.Inicio, le.Tamano)
+		}
+		if len(le.Nombre) > len(e.Part_name) || len(le.ID) > len(e.Part_id) {
+			return fmt.Errorf("la entrada %d tiene un nombre o ID demasiado largo", le.Indice)
+		}
+		if e.Part_status, err = cadenaAByte(le.Estado); err != nil {
+			return err
+		}
+		if e.Part_fit, err = cadenaAByte(le.Ajuste); err != nil {
+			return err
+		}
+		e.Part_type_guid = tipo
+		e.Part_guid = guid
+		e.Part_start = le.Inicio
+		e.Part_size = le.Tamano
+		e.Part_correlative = le.Correlativo
+		copy(e.Part_name[:], le.Nombre)
+		copy(e.Part_id[:], le.ID)
+		ocupadas = append(ocupadas, Estructuras.EspacioLibre{Inicio: le.Inicio, Tamano: le.Tamano})
+	}
+	if err := validarSolapamientos(ocupadas); err != nil {
+		return err
+	}
+
+	mbr := Estructuras.NuevoMBRProtector(layout.Tamano, layout.Firma, ajuste)
+	mbr.MbrCreacionDate = layout.Creacion
+	if err := mbr.Codificar(archivo); err != nil {
+		return fmt.Errorf("error al escribir el MBR protector: %v", err)
+	}
+	if err := gpt.Codificar(archivo); err != nil {
+		return fmt.Errorf("error al escribir la tabla GPT: %v", err)
+	}
+	return nil
+}
+
+// Verifica que ningun par de rangos se solape
+func validarSolapamientos(rangos []Estructuras.EspacioLibre) error {
+	sort.Slice(rangos, func(i, j int) bool { return rangos[i].Inicio < rangos[j].Inicio })
+	for i := 1; i < len(rangos); i++ {
+		anterior := rangos[i-1]
+		if anterior.Inicio+anterior.Tamano > rangos[i].Inicio {
+			return fmt.Errorf("los rangos [%d, %d) y [%d, %d) se solapan",
+				anterior.Inicio, anterior.Inicio+anterior.Tamano, rangos[i].Inicio, rangos[i].Inicio+rangos[i].Tamano)
+		}
+	}
+	return nil
+}
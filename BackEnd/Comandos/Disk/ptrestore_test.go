@@ -0,0 +1,112 @@
+package Disk_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	Disk "backend/Comandos/Disk"
+	Pruebas "backend/Comandos/Pruebas"
+)
+
+// discoConParticiones crea un disco con primarias y, en MBR, una extendida con dos logicas
+func discoConParticiones(t *testing.T, tabla string) string {
+	t.Helper()
+	disco := filepath.Join(t.TempDir(), "disco.mia")
+	Pruebas.Ejecutar(t, Disk.ParserMkdisk, "-size=3 -unit=M -path="+disco+" -table="+tabla)
+	Pruebas.Ejecutar(t, Disk.ParserFdisk, "-size=300 -unit=K -path="+disco+" -name=P1")
+	Pruebas.Ejecutar(t, Disk.ParserFdisk, "-size=200 -unit=K -path="+disco+" -name=P2 -fit=BF")
+	if tabla == "MBR" {
+		Pruebas.Ejecutar(t, Disk.ParserFdisk, "-size=1 -unit=M -type=E -path="+disco+" -name=E")
+		Pruebas.Ejecutar(t, Disk.ParserFdisk, "-size=100 -unit=K -type=L -path="+disco+" -name=L1")
+		Pruebas.Ejecutar(t, Disk.ParserFdisk, "-size=200 -unit=K -type=L -path="+disco+" -name=L2")
+	}
+	return disco
+}
+
+func TestPtdumpPtrestore(t *testing.T) {
+	for _, tabla := range []string{"MBR", "GPT"} {
+		t.Run(tabla, func(t *testing.T) {
+			disco := discoConParticiones(t, tabla)
+			volcado := filepath.Join(t.TempDir(), "tabla.json")
+			Pruebas.Ejecutar(t, Disk.ParserPtdump, "-path="+disco+" -out="+volcado)
+			original, err := os.ReadFile(volcado)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Un disco nuevo con la misma ruta pierde la tabla; ptrestore la recupera completa
+			Pruebas.Ejecutar(t, Disk.ParserMkdisk, "-size=3 -unit=M -path="+disco+" -table="+tabla)
+			Pruebas.Ejecutar(t, Disk.ParserPtrestore, "-path="+disco+" -in="+volcado)
+			Pruebas.Ejecutar(t, Disk.ParserPtdump, "-path="+disco+" -out="+volcado)
+			restaurado, err := os.ReadFile(volcado)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(original, restaurado) {
+				t.Errorf("la tabla restaurada no coincide:\n%s\nse esperaba:\n%s", restaurado, original)
+			}
+
+			// La tabla restaurada se puede seguir usando
+			Pruebas.Ejecutar(t, Disk.ParserFdisk, "-size=100 -unit=K -path="+disco+" -name=P3")
+		})
+	}
+}
+
+func TestPtrestoreRechazaLayoutsInvalidos(t *testing.T) {
+	disco := discoConParticiones(t, "MBR")
+	volcado := filepath.Join(t.TempDir(), "tabla.json")
+	Pruebas.Ejecutar(t, Disk.ParserPtdump, "-path="+disco+" -out="+volcado)
+	contenido, err := os.ReadFile(volcado)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leerLayoutJSON := func() Disk.LayoutDisco {
+		var layout Disk.LayoutDisco
+		if err := json.Unmarshal(contenido, &layout); err != nil {
+			t.Fatal(err)
+		}
+		return layout
+	}
+	if base := leerLayoutJSON(); len(base.Particiones) != 4 || len(base.Logicas) != 2 || base.Particiones[2].Tipo != "E" {
+		t.Fatalf("layout inesperado: %+v", base)
+	}
+
+	casos := []struct {
+		nombre    string
+		modificar func(*Disk.LayoutDisco)
+		mensaje   string
+	}{
+		{"particiones solapadas", func(l *Disk.LayoutDisco) { l.Particiones[1].Inicio = l.Particiones[0].Inicio + 10 }, "se solapan"},
+		{"fuera del disco", func(l *Disk.LayoutDisco) { l.Particiones[0].Tamano = l.Tamano }, "fuera de los limites"},
+		{"mas grande que el disco", func(l *Disk.LayoutDisco) { l.Tamano *= 2 }, "no corresponde al disco"},
+		{"tabla desconocida", func(l *Disk.LayoutDisco) { l.Tabla = "APM" }, "tabla desconocida"},
+		{"cadena de EBR rota", func(l *Disk.LayoutDisco) { l.Logicas[0].Siguiente = -1 }, "apunta a"},
+		{"logica fuera de la extendida", func(l *Disk.LayoutDisco) { l.Logicas[1].Tamano = l.Particiones[2].Tamano }, "fuera de la extendida"},
+		{"logicas sin extendida", func(l *Disk.LayoutDisco) { l.Particiones[2].Tipo = "P" }, "ninguna extendida"},
+	}
+	for _, caso := range casos {
+		layout := leerLayoutJSON()
+		caso.modificar(&layout)
+		datos, err := json.Marshal(layout)
+		if err != nil {
+			t.Fatal(err)
+		}
+		invalido := filepath.Join(t.TempDir(), "invalido.json")
+		if err := os.WriteFile(invalido, datos, 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		antes, _ := os.ReadFile(disco)
+		_, err = Disk.ParserPtrestore(strings.Fields("-path=" + disco + " -in=" + invalido))
+		if err == nil || !strings.Contains(err.Error(), caso.mensaje) {
+			t.Errorf("%s: error %v, se esperaba uno con %q", caso.nombre, err, caso.mensaje)
+		}
+		if despues, _ := os.ReadFile(disco); !bytes.Equal(antes, despues) {
+			t.Errorf("%s: ptrestore modifico el disco aunque rechazo el layout", caso.nombre)
+		}
+	}
+}
@@ -0,0 +1,306 @@
+package Disk
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/bits"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	Estructuras "backend/Estructuras"
+	Global "backend/Global"
+	Utils "backend/Utils"
+)
+
+// ResizeFs representa el comando resizefs con sus parametros
+type ResizeFs struct {
+	id        string // ID de la particion montada
+	capacidad int    // Nueva dimension de la particion
+	unidad    string // Unidad de medida (B, K o M)
+}
+
+// Procesa el comando resizefs y retorna los mensajes generados
+func ParserResizefs(tokens []string) (string, error) {
+	var bufferSalida bytes.Buffer
+	cmd := &ResizeFs{}
+
+	argumentos := strings.Join(tokens, " ")
+	patron := regexp.MustCompile(`-id=[^\s]+|-size=\d+|-unit=[bBkKmM]`)
+	coincidencias := patron.FindAllString(argumentos, -1)
+
+	for _, coincidencia := range coincidencias {
+		claveValor := strings.SplitN(coincidencia, "=", 2)
+		if len(claveValor) != 2 {
+			return "", fmt.Errorf("formato de parametro invalido: %s", coincidencia)
+		}
+		clave, valor := strings.ToLower(claveValor[0]), claveValor[1]
+
+		switch clave {
+		case "-id":
+			cmd.id = valor
+		case "-size":
+			dimension, err := strconv.Atoi(valor)
+			if err != nil || dimension <= 0 {
+				return "", errors.New("la dimension debe ser un numero entero positivo")
+			}
+			cmd.capacidad = dimension
+		case "-unit":
+			cmd.unidad = strings.ToUpper(valor)
+		default:
+			return "", fmt.Errorf("parametro desconocido: %s", clave)
+		}
+	}
+
+	if cmd.id == "" {
+		return "", errors.New("faltan parametros requeridos: -id")
+	}
+	if cmd.capacidad == 0 {
+		return "", errors.New("faltan parametros requeridos: -size")
+	}
+	if cmd.unidad == "" {
+		cmd.unidad = "K"
+	}
+
+	err := ejecutarComandoResizefs(cmd, &bufferSalida)
+	if err != nil {
+		return "", fmt.Errorf("error al redimensionar el sistema de archivos: %v", err)
+	}
+
+	return bufferSalida.String(), nil
+}
+
+func ejecutarComandoResizefs(resize *ResizeFs, bufferSalida *bytes.Buffer) error {
+	fmt.Fprintln(bufferSalida, "--------------------------- RESIZEFS ---------------------------")
+
+	particion, rutaDisco, err := Global.ObtenerParticionMontada(resize.id)
+	if err != nil {
+		return fmt.Errorf("error al obtener la particion montada con ID %s: %v", resize.id, err)
+	}
+
+	nuevoTamano, err := Utils.ConvertirABytes(resize.capacidad, resize.unidad)
+	if err != nil {
+		return err
+	}
+
+	archivo, err := os.OpenFile(rutaDisco, os.O_RDWR, 0644)
+	if err != nil {
+		return fmt.Errorf("error abriendo el archivo del disco: %v", err)
+	}
+	defer archivo.Close()
+
+	var sb Estructuras.SuperBlock
+	if err := sb.Decodificar(archivo, int64(particion.Part_start)); err != nil {
+		return fmt.Errorf("error al leer el superbloque: %v", err)
+	}
+	if sb.S_magic != 0xEF53 {
+		return errors.New("la particion no tiene un sistema de archivos, ejecute mkfs primero")
+	}
+	// Con grupos la tabla de descriptores crece con la particion y moveria todos los grupos
+	if sb.S_groups_count > 0 {
+		return errors.New("resizefs no soporta sistemas de archivos con grupos de bloques (mkfs -groups)")
+	}
+
+	fs := "2fs"
+	if sb.S_filesystem_type == 3 {
+		fs = "3fs"
+	}
+
+	fmt.Fprintf(bufferSalida, "Particion: %s | Tamaño actual: %d bytes | Nuevo tamaño: %d bytes\n",
+		strings.Trim(string(particion.Part_name[:]), "\x00 "), particion.Part_size, nuevoTamano)
+
+	// Validar primero que la tabla de particiones tenga espacio para crecer
+	if err := ajustarTamanoEnTabla(archivo, resize.id, int32(nuevoTamano), false); err != nil {
+		return err
+	}
+
+	// Se conservan la dimension de bloque y la proporcion de bloques por inodo de mkfs
+	nueva := *particion
+	nueva.Part_size = int32(nuevoTamano)
+	ratio := float64(sb.S_blocks_count+sb.S_free_blocks_count) / float64(sb.S_inodes_count+sb.S_free_inodes_count)
+	geometria, err := calcularGeometria(&nueva, fs, sb.S_block_size, ratio, 0)
+	if err != nil {
+		return err
+	}
+
+	err = reubicarEstructuras(archivo, &sb, &nueva, fs, geometria)
+	if err != nil {
+		return err
+	}
+
+	if err := ajustarTamanoEnTabla(archivo, resize.id, int32(nuevoTamano), true); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(bufferSalida, "Inodos: %d (%d libres) | Bloques: %d (%d libres)\n",
+		geometria.inodos, sb.S_free_inodes_count, geometria.bloques, sb.S_free_blocks_count)
+	fmt.Fprintln(bufferSalida, "Sistema de archivos redimensionado correctamente.")
+	fmt.Fprintln(bufferSalida, "--------------------------------------------")
+	return nil
+}
+
+// reubicarEstructuras reescribe bitmaps, inodos y bloques en las posiciones que
+// corresponden a la nueva geometria. Al reducir, los inodos y bloques ocupados que quedan
+// fuera se mueven antes a posiciones libres dentro del nuevo tamaño y se actualizan sus
+// referencias; los demas indices se conservan, asi users.txt y el journal quedan intactos.
+func reubicarEstructuras(archivo *os.File, sb *Estructuras.SuperBlock, particion *Estructuras.Particion, fs string, geometria geometriaFS) error {
+	n, m := geometria.inodos, geometria.bloques
+
+	bmInodos, bmBloques, err := leerBitmapsResize(archivo, sb)
+	if err != nil {
+		return err
+	}
+	totalInodos := sb.S_inodes_count + sb.S_free_inodes_count
+	totalBloques := sb.S_blocks_count + sb.S_free_blocks_count
+	ultimoInodo, inodosUsados := ultimoBitOcupado(bmInodos, totalInodos)
+	ultimoBloque, bloquesUsados := ultimoBitOcupado(bmBloques, totalBloques)
+
+	if inodosUsados > n {
+		return fmt.Errorf("no se puede reducir: hay %d inodos en uso y el nuevo tamaño solo admite %d inodos", inodosUsados, n)
+	}
+	if bloquesUsados > m {
+		return fmt.Errorf("no se puede reducir: hay %d bloques en uso y el nuevo tamaño solo admite %d bloques", bloquesUsados, m)
+	}
+	if ultimoInodo >= n || ultimoBloque >= m {
+		if err := sb.CompactarIndices(archivo, n, m); err != nil {
+			return fmt.Errorf("no se puede reducir: %v", err)
+		}
+		if bmInodos, bmBloques, err = leerBitmapsResize(archivo, sb); err != nil {
+			return err
+		}
+		ultimoInodo, _ = ultimoBitOcupado(bmInodos, totalInodos)
+		ultimoBloque, _ = ultimoBitOcupado(bmBloques, totalBloques)
+	}
+
+	// Cargar en memoria los inodos y bloques ocupados antes de sobrescribir
+	datosInodos := make([]byte, int64(ultimoInodo+1)*int64(sb.S_inode_size))
+	if _, err := archivo.ReadAt(datosInodos, int64(sb.S_inode_start)); err != nil {
+		return fmt.Errorf("error leyendo la tabla de inodos: %v", err)
+	}
+	datosBloques := make([]byte, int64(ultimoBloque+1)*int64(sb.S_block_size))
+	if _, err := archivo.ReadAt(datosBloques, int64(sb.S_block_start)); err != nil {
+		return fmt.Errorf("error leyendo el area de bloques: %v", err)
+	}
+
+	_, inicioBMInodo, inicioBMBloque, inicioInodo, inicioBloque := calcularInicioPosiciones(particion, fs, geometria)
+
+	// Limpiar todo el area desde los bitmaps hasta el nuevo final de la particion;
+	// el superbloque y el journal no cambian de posicion
+	finParticion := int64(particion.Part_start) + int64(particion.Part_size)
+	if _, err := archivo.WriteAt(make([]byte, finParticion-int64(inicioBMInodo)), int64(inicioBMInodo)); err != nil {
+		return fmt.Errorf("error limpiando el area del sistema de archivos: %v", err)
+	}
+
+	nuevoBMInodos := make([]byte, (n+7)/8)
+	copy(nuevoBMInodos, bmInodos)
+	nuevoBMBloques := make([]byte, (m+7)/8)
+	copy(nuevoBMBloques, bmBloques)
+
+	escrituras := []struct {
+		datos  []byte
+		inicio int32
+	}{
+		{nuevoBMInodos, inicioBMInodo},
+		{nuevoBMBloques, inicioBMBloque},
+		{datosInodos, inicioInodo},
+		{datosBloques, inicioBloque},
+	}
+	for _, e := range escrituras {
+		if _, err := archivo.WriteAt(e.datos, int64(e.inicio)); err != nil {
+			return fmt.Errorf("error escribiendo en la posicion %d: %v", e.inicio, err)
+		}
+	}
+
+	// Rebasar los punteros del superbloque a las nuevas posiciones
+	sb.S_first_ino = sb.S_first_ino - sb.S_inode_start + inicioInodo
+	sb.S_first_blo = sb.S_first_blo - sb.S_block_start + inicioBloque
+	sb.S_bm_inode_start = inicioBMInodo
+	sb.S_bm_block_start = inicioBMBloque
+	sb.S_inode_start = inicioInodo
+	sb.S_block_start = inicioBloque
+	sb.S_inodes_count = inodosUsados
+	sb.S_blocks_count = bloquesUsados
+	sb.S_free_inodes_count = n - inodosUsados
+	sb.S_free_blocks_count = m - bloquesUsados
+
+	if err := sb.Codificar(archivo, int64(particion.Part_start)); err != nil {
+		return fmt.Errorf("error al escribir el superbloque: %v", err)
+	}
+	return nil
+}
+
+// leerBitmapsResize lee del disco los bitmaps de inodos y de bloques completos
+func leerBitmapsResize(archivo *os.File, sb *Estructuras.SuperBlock) ([]byte, []byte, error) {
+	bmInodos := make([]byte, (sb.S_inodes_count+sb.S_free_inodes_count+7)/8)
+	if _, err := archivo.ReadAt(bmInodos, int64(sb.S_bm_inode_start)); err != nil {
+		return nil, nil, fmt.Errorf("error leyendo el bitmap de inodos: %v", err)
+	}
+	bmBloques := make([]byte, (sb.S_blocks_count+sb.S_free_blocks_count+7)/8)
+	if _, err := archivo.ReadAt(bmBloques, int64(sb.S_bm_block_start)); err != nil {
+		return nil, nil, fmt.Errorf("error leyendo el bitmap de bloques: %v", err)
+	}
+	return bmInodos, bmBloques, nil
+}
+
+// ultimoBitOcupado devuelve el indice del ultimo bit en 1 y la cantidad de bits en 1
+func ultimoBitOcupado(bitmap []byte, cantidad int32) (int32, int32) {
+	ultimo, ocupados := int32(-1), int32(0)
+	for i := int32(0); i < cantidad; i++ {
+		if bitmap[i/8]&(1<<(i%8)) != 0 {
+			ultimo = i
+		}
+	}
+	for _, b := range bitmap {
+		ocupados += int32(bits.OnesCount8(b))
+	}
+	return ultimo, ocupados
+}
+
+// ajustarTamanoEnTabla valida (y si aplicar es true, escribe) el nuevo tamaño de la
+// particion montada en la tabla del disco, sea MBR o GPT
+func ajustarTamanoEnTabla(archivo *os.File, id string, nuevoTamano int32, aplicar bool) error {
+	var mbr Estructuras.MBR
+	if err := mbr.Decodificar(archivo); err != nil {
+		return fmt.Errorf("error al deserializar el MBR: %v", err)
+	}
+
+	if mbr.EsProtectorGPT() {
+		var gpt Estructuras.GPT
+		if err := gpt.Decodificar(archivo); err != nil {
+			return fmt.Errorf("error al deserializar la tabla GPT: %v", err)
+		}
+		entrada, _ := gpt.ObtenerParticionPorID(id)
+		if entrada == nil {
+			return fmt.Errorf("la particion con ID '%s' no existe en la tabla GPT", id)
+		}
+		if crecimiento := nuevoTamano - entrada.Part_size; crecimiento > gpt.EspacioDisponibleParaParticion(entrada) {
+			return errors.New("no hay suficiente espacio libre despues de la particion para crecer")
+		}
+		if !aplicar {
+			return nil
+		}
+		entrada.Part_size = nuevoTamano
+		return gpt.Codificar(archivo)
+	}
+
+	particion, err := mbr.ObtenerParticionPorID(id)
+	if err != nil {
+		return err
+	}
+	if crecimiento := nuevoTamano - particion.Part_size; crecimiento > 0 {
+		disponible, err := mbr.CalcularEspacioDisponibleParaParticion(particion)
+		if err != nil {
+			return err
+		}
+		if crecimiento > disponible {
+			return errors.New("no hay suficiente espacio libre despues de la particion para crecer")
+		}
+	}
+	if !aplicar {
+		return nil
+	}
+	particion.Part_size = nuevoTamano
+	return mbr.Codificar(archivo)
+}
@@ -0,0 +1,160 @@
+package Disk_test
+
+import (
+	"fmt"
+	"io/fs"
+	"strings"
+	"testing"
+
+	Disk "backend/Comandos/Disk"
+	Forge "backend/Comandos/Forge"
+	Pruebas "backend/Comandos/Pruebas"
+	Global "backend/Global"
+)
+
+func TestResizefs(t *testing.T) {
+	id := Pruebas.MontarParticionFormateada(t, "")
+	Pruebas.Ejecutar(t, Forge.ParserMkdir, "-p -path=/home/docs")
+	Pruebas.Ejecutar(t, Forge.ParserMkfile, "-path=/home/docs/a.txt -size=300")
+	original := leerArchivo(t, id, "home/docs/a.txt")
+
+	casos := []struct {
+		tamano string
+		falla  bool
+	}{
+		{"-size=1536 -unit=K", false},
+		{"-size=512 -unit=K", false},
+		{"-size=1024 -unit=K", false},
+		{"-size=8 -unit=K", false}, // los ocupados estan al inicio y caben
+		{"-size=1 -unit=K", true},  // los inodos y bloques ocupados ya no caben
+		{"-size=4 -unit=M", true},  // mas grande que el disco
+		{"-size=1023 -unit=K", false},
+	}
+	for _, caso := range casos {
+		err := Pruebas.SinCache(Disk.ParserResizefs, "-id="+id+" "+caso.tamano)
+		if (err != nil) != caso.falla {
+			t.Fatalf("resizefs %s: %v", caso.tamano, err)
+		}
+
+		particion, _, err := Global.ObtenerParticionMontada(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		salida := Pruebas.Ejecutar(t, Disk.ParserDf, "-id="+id)
+		if strings.Contains(salida, "Use -fix") {
+			t.Errorf("resizefs %s dejo contadores desviados (particion de %d bytes):\n%s", caso.tamano, particion.Part_size, salida)
+		}
+		if contenido := leerArchivo(t, id, "home/docs/a.txt"); contenido != original {
+			t.Errorf("resizefs %s cambio el contenido de a.txt", caso.tamano)
+		}
+	}
+
+	// El sistema de archivos redimensionado sigue aceptando archivos nuevos
+	Pruebas.Ejecutar(t, Forge.ParserMkfile, "-path=/home/docs/b.txt -size=200")
+	if contenido := leerArchivo(t, id, "home/docs/b.txt"); len(contenido) != 200 {
+		t.Errorf("b.txt tiene %d bytes", len(contenido))
+	}
+}
+
+func TestResizefsReubicaOcupados(t *testing.T) {
+	id := Pruebas.MontarParticionFormateada(t, "")
+
+	// Los archivos de /tmp ocupan los primeros inodos y bloques; al borrarlos lo que se
+	// conserva queda al final y para reducir hay que moverlo
+	Pruebas.Ejecutar(t, Forge.ParserMkdir, "-path=/tmp")
+	for i := 0; i < 40; i++ {
+		Pruebas.Ejecutar(t, Forge.ParserMkfile, fmt.Sprintf("-path=/tmp/t%02d -size=200", i))
+	}
+	Pruebas.Ejecutar(t, Forge.ParserMkdir, "-p -path=/home/docs")
+	for i := 0; i < 40; i++ { // suficientes para que la carpeta tenga indice hash
+		Pruebas.Ejecutar(t, Forge.ParserMkfile, fmt.Sprintf("-path=/home/docs/n%02d.txt -size=%d", i, i))
+	}
+	Pruebas.Ejecutar(t, Forge.ParserMkfile, "-path=/home/grande.txt -size=3000") // usa el indirecto doble
+	Pruebas.Ejecutar(t, Forge.ParserLn, "-s -path=/home/grande.txt -dest=/home/atajo")
+	Pruebas.Ejecutar(t, Forge.ParserLn, "-path=/home/grande.txt -dest=/home/duro")
+	Pruebas.Ejecutar(t, Forge.ParserSetxattr, "-path=/home/grande.txt -name=user.autor -value=ana")
+	Pruebas.Ejecutar(t, Forge.ParserRemove, "-path=/tmp")
+	original := contenidoSistema(t, id)
+
+	// Con 20 KB caben los 47 inodos y 128 bloques en uso, pero no en sus posiciones actuales
+	if err := Pruebas.SinCache(Disk.ParserResizefs, "-id="+id+" -size=20 -unit=K"); err != nil {
+		t.Fatalf("resizefs -size=20 -unit=K: %v", err)
+	}
+	if salida := Pruebas.Ejecutar(t, Disk.ParserDf, "-id="+id); strings.Contains(salida, "Use -fix") {
+		t.Errorf("resizefs dejo contadores desviados:\n%s", salida)
+	}
+	reubicado := contenidoSistema(t, id)
+	for ruta, esperado := range original {
+		if reubicado[ruta] != esperado {
+			t.Errorf("%s cambio al reducir: %q", ruta, reubicado[ruta])
+		}
+	}
+	if len(reubicado) != len(original) {
+		t.Errorf("quedaron %d elementos, habia %d", len(reubicado), len(original))
+	}
+	if salida := Pruebas.Ejecutar(t, Forge.ParserGetxattr, "-path=/home/duro -name=user.autor"); !strings.Contains(salida, "ana") {
+		t.Errorf("se perdio el atributo de grande.txt: %s", salida)
+	}
+
+	// Con 12 KB ya no caben los inodos en uso
+	if err := Pruebas.SinCache(Disk.ParserResizefs, "-id="+id+" -size=12 -unit=K"); err == nil {
+		t.Errorf("resizefs -size=12 -unit=K redujo por debajo de los inodos en uso")
+	}
+
+	Pruebas.Ejecutar(t, Forge.ParserMkfile, "-path=/home/docs/nuevo.txt -size=100")
+	if contenido := leerArchivo(t, id, "home/docs/nuevo.txt"); len(contenido) != 100 {
+		t.Errorf("nuevo.txt tiene %d bytes", len(contenido))
+	}
+}
+
+// contenidoSistema lee todos los archivos de la particion montada, los enlaces como su destino
+func contenidoSistema(t *testing.T, id string) map[string]string {
+	t.Helper()
+	if _, err := Global.SincronizarParticion(id); err != nil {
+		t.Fatal(err)
+	}
+	sistema, err := Global.SistemaArchivosMontado(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sistema.Close()
+	contenido := make(map[string]string)
+	err = fs.WalkDir(sistema, ".", func(ruta string, entrada fs.DirEntry, err error) error {
+		switch {
+		case err != nil:
+			return err
+		case entrada.Type()&fs.ModeSymlink != 0:
+			destino, err := sistema.ReadLink(ruta)
+			contenido[ruta] = "-> " + destino
+			return err
+		case !entrada.IsDir():
+			datos, err := fs.ReadFile(sistema, ruta)
+			contenido[ruta] = string(datos)
+			return err
+		}
+		contenido[ruta] = "carpeta"
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return contenido
+}
+
+// leerArchivo lee un archivo de la particion montada por medio de io/fs
+func leerArchivo(t *testing.T, id, ruta string) string {
+	t.Helper()
+	if _, err := Global.SincronizarParticion(id); err != nil {
+		t.Fatal(err)
+	}
+	sistema, err := Global.SistemaArchivosMontado(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sistema.Close()
+	contenido, err := fs.ReadFile(sistema, ruta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(contenido)
+}
@@ -0,0 +1,67 @@
+package Disk
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	Global "backend/Global"
+)
+
+// Sync estructura para representar el comando sync
+type Sync struct {
+	id string // ID de la partición a sincronizar; vacío para todas
+}
+
+// ParserSync parsea el comando sync
+func ParserSync(tokens []string) (string, error) {
+	var outputBuffer bytes.Buffer
+	cmd := &Sync{}
+
+	for _, token := range tokens {
+		switch {
+		case strings.HasPrefix(strings.ToLower(token), "-id="):
+			cmd.id = token[len("-id="):]
+		default:
+			return "", fmt.Errorf("parametro desconocido: %s", token)
+		}
+	}
+
+	err := comandoSync(cmd, &outputBuffer)
+	if err != nil {
+		return "", err
+	}
+
+	return outputBuffer.String(), nil
+}
+
+// comandoSync escribe en el disco los inodos y bloques modificados que siguen en la cache
+func comandoSync(sync *Sync, outputBuffer *bytes.Buffer) error {
+	fmt.Fprintln(outputBuffer, "========================== SYNC ==========================")
+
+	ids := []string{sync.id}
+	if sync.id == "" {
+		ids = ids[:0]
+		for id := range Global.CachesMontadas {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		if len(ids) == 0 {
+			fmt.Fprintln(outputBuffer, "No hay particiones montadas")
+		}
+	}
+
+	for _, id := range ids {
+		escritas, err := Global.SincronizarParticion(id)
+		if err != nil {
+			return fmt.Errorf("error sincronizando la partición '%s': %v", id, err)
+		}
+		cache := Global.CachesMontadas[id]
+		fmt.Fprintf(outputBuffer, "Partición %s: %d estructuras escritas (aciertos: %d, fallos: %d)\n",
+			id, escritas, cache.Aciertos, cache.Fallos)
+	}
+	fmt.Fprintln(outputBuffer, "===========================================================")
+
+	return nil
+}
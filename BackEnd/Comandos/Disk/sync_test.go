@@ -0,0 +1,80 @@
+package Disk_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"testing"
+
+	Disk "backend/Comandos/Disk"
+	Forge "backend/Comandos/Forge"
+	Pruebas "backend/Comandos/Pruebas"
+	Global "backend/Global"
+)
+
+func TestSyncEscribeLoPendiente(t *testing.T) {
+	disco := filepath.Join(t.TempDir(), "disco.mia")
+	Pruebas.Ejecutar(t, Disk.ParserMkdisk, "-size=3 -unit=M -path="+disco)
+	Pruebas.Ejecutar(t, Disk.ParserFdisk, "-size=1 -unit=M -path="+disco+" -name=Part1")
+	id := Pruebas.Montar(t, disco, "Part1")
+	Pruebas.Formatear(t, id, "")
+
+	escritas := func() int {
+		t.Helper()
+		salida := Pruebas.Ejecutar(t, Disk.ParserSync, "-id="+id)
+		coincidencia := regexp.MustCompile(`(\d+) estructuras escritas`).FindStringSubmatch(salida)
+		if coincidencia == nil {
+			t.Fatalf("salida de sync inesperada:\n%s", salida)
+		}
+		cantidad, _ := strconv.Atoi(coincidencia[1])
+		return cantidad
+	}
+	leerDisco := func() []byte {
+		t.Helper()
+		contenido, err := os.ReadFile(disco)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return contenido
+	}
+	escritas()
+
+	// La entrada de la carpeta nueva queda en la cache hasta sync
+	Pruebas.Ejecutar(t, Forge.ParserMkdir, "-path=/carpeta_nueva")
+	Pruebas.Ejecutar(t, Forge.ParserMkfile, "-path=/carpeta_nueva/a.txt -size=100")
+	nombre := []byte("carpeta_nuev")
+	if bytes.Contains(leerDisco(), nombre) {
+		t.Fatalf("la entrada de /carpeta_nueva ya esta en el disco antes de sync")
+	}
+	if cantidad := escritas(); cantidad == 0 {
+		t.Errorf("sync no escribio estructuras pendientes")
+	}
+	antes := leerDisco()
+	if !bytes.Contains(antes, nombre) {
+		t.Fatalf("la entrada de /carpeta_nueva no llego al disco con sync")
+	}
+
+	// Sin cambios nuevos sync no escribe nada
+	if cantidad := escritas(); cantidad != 0 {
+		t.Errorf("un segundo sync escribio %d estructuras", cantidad)
+	}
+	if !bytes.Equal(antes, leerDisco()) {
+		t.Errorf("un segundo sync modifico el disco")
+	}
+
+	// unmount cierra la cache; al volver a montar se lee lo que quedo en el disco
+	Pruebas.Ejecutar(t, Forge.ParserMkfile, "-path=/carpeta_nueva/b.txt -size=30")
+	Global.Logout()
+	Pruebas.Ejecutar(t, Disk.ParserUnmount, "-id="+id)
+	if _, existe := Global.CachesMontadas[id]; existe {
+		t.Fatalf("la cache de %s sigue abierta despues de unmount", id)
+	}
+	id = Pruebas.Montar(t, disco, "Part1")
+	for ruta, tamano := range map[string]int{"carpeta_nueva/a.txt": 100, "carpeta_nueva/b.txt": 30} {
+		if contenido := leerArchivo(t, id, ruta); len(contenido) != tamano {
+			t.Errorf("%s tiene %d bytes despues de volver a montar", ruta, len(contenido))
+		}
+	}
+}
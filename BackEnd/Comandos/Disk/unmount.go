@@ -52,6 +52,11 @@ func comandoUnmount(unmount *Unmount, outputBuffer *bytes.Buffer) error {
 		return fmt.Errorf("error: la partición con ID '%s' no está montada", unmount.id)
 	}
 
+	// Escribir los inodos y bloques que siguen en la cache y liberar el disco abierto
+	if err := Global.CerrarMontaje(unmount.id); err != nil {
+		return fmt.Errorf("error escribiendo los cambios pendientes de la partición: %v", err)
+	}
+
 	// Abrir el archivo del disco
 	file, err := os.OpenFile(mountedPath, os.O_RDWR, 0644)
 	if err != nil {
@@ -66,9 +71,15 @@ func comandoUnmount(unmount *Unmount, outputBuffer *bytes.Buffer) error {
 		return fmt.Errorf("error deserializando el MBR: %v", err)
 	}
 
-	// Buscar la partición en el MBR que tiene el ID especificado
+	// Buscar la partición en la tabla del disco que tiene el ID especificado
 	found := false
-	for i := range mbr.MbrPartitions {
+	if mbr.EsProtectorGPT() {
+		found, err = desmontarParticionGPT(file, unmount.id)
+		if err != nil {
+			return err
+		}
+	}
+	for i := 0; !found && i < len(mbr.MbrPartitions); i++ {
 		partition := &mbr.MbrPartitions[i] // Obtener referencia a la partición
 		partitionID := strings.TrimSpace(string(partition.Part_id[:]))
 		if partitionID == unmount.id {
@@ -115,3 +126,22 @@ func comandoUnmount(unmount *Unmount, outputBuffer *bytes.Buffer) error {
 
 	return nil
 }
+
+// desmontarParticionGPT limpia el ID y correlativo de la entrada GPT montada
+func desmontarParticionGPT(file *os.File, id string) (bool, error) {
+	var gpt Estructuras.GPT
+	if err := gpt.Decodificar(file); err != nil {
+		return false, fmt.Errorf("error deserializando la tabla GPT: %v", err)
+	}
+
+	entrada, _ := gpt.ObtenerParticionPorID(id)
+	if entrada == nil {
+		return false, nil
+	}
+
+	entrada.MontarParticion(-1, "")
+	if err := gpt.Codificar(file); err != nil {
+		return false, fmt.Errorf("error al actualizar la tabla GPT en el disco: %v", err)
+	}
+	return true, nil
+}
@@ -0,0 +1,210 @@
+package Disk
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	Estructuras "backend/Estructuras"
+)
+
+// Verify representa el comando verify con sus parametros
+type Verify struct {
+	ruta string // Ubicacion del archivo del disco
+}
+
+// Resultado acumulado de la verificacion de un disco
+type resultadoVerificacion struct {
+	correctas    int
+	corruptas    int
+	sinFormato   int
+	sinVerificar int // formato anterior a los checksums
+}
+
+// Procesa el comando verify y retorna el reporte de integridad del disco
+func ParserVerify(tokens []string) (string, error) {
+	var bufferSalida bytes.Buffer
+	cmd := &Verify{}
+
+	argumentos := strings.Join(tokens, " ")
+	patron := regexp.MustCompile(`-path="[^"]+"|-path=[^\s]+`)
+	coincidencias := patron.FindAllString(argumentos, -1)
+
+	for _, coincidencia := range coincidencias {
+		claveValor := strings.SplitN(coincidencia, "=", 2)
+		if len(claveValor) != 2 {
+			return "", fmt.Errorf("formato de parametro invalido: %s", coincidencia)
+		}
+		clave, valor := strings.ToLower(claveValor[0]), claveValor[1]
+		if strings.HasPrefix(valor, "\"") && strings.HasSuffix(valor, "\"") {
+			valor = strings.Trim(valor, "\"")
+		}
+
+		switch clave {
+		case "-path":
+			if valor == "" {
+				return "", errors.New("la ruta no puede estar vacia")
+			}
+			cmd.ruta = valor
+		default:
+			return "", fmt.Errorf("parametro desconocido: %s", clave)
+		}
+	}
+
+	if cmd.ruta == "" {
+		return "", errors.New("faltan parametros requeridos: -path")
+	}
+
+	err := ejecutarComandoVerify(cmd, &bufferSalida)
+	if err != nil {
+		return "", fmt.Errorf("error al verificar el disco: %v", err)
+	}
+
+	return bufferSalida.String(), nil
+}
+
+func ejecutarComandoVerify(verify *Verify, bufferSalida *bytes.Buffer) error {
+	fmt.Fprintln(bufferSalida, "---------------------------- VERIFY ----------------------------")
+
+	archivo, err := os.Open(verify.ruta)
+	if err != nil {
+		return fmt.Errorf("error abriendo el archivo del disco en la ruta: %s: %v", verify.ruta, err)
+	}
+	defer archivo.Close()
+
+	resultado := &resultadoVerificacion{}
+
+	var mbr Estructuras.MBR
+	err = mbr.Decodificar(archivo)
+	reportarEstructura(bufferSalida, resultado, "MBR", sinChecksum(err, mbr.SinChecksum()))
+	if err != nil {
+		// Sin un MBR valido no se puede confiar en las posiciones de las particiones
+		fmt.Fprintln(bufferSalida, "No se revisan las particiones: el MBR no es confiable (use ptrestore).")
+	} else if mbr.EsProtectorGPT() {
+		verificarParticionesGPT(archivo, bufferSalida, resultado)
+	} else {
+		verificarParticionesMBR(archivo, &mbr, bufferSalida, resultado)
+	}
+
+	fmt.Fprintf(bufferSalida, "Resumen: %d correctas | %d corruptas | %d sin formato | %d sin verificar\n",
+		resultado.correctas, resultado.corruptas, resultado.sinFormato, resultado.sinVerificar)
+	fmt.Fprintln(bufferSalida, "--------------------------------------------")
+	return nil
+}
+
+func verificarParticionesMBR(archivo *os.File, mbr *Estructuras.MBR, bufferSalida *bytes.Buffer, resultado *resultadoVerificacion) {
+	for _, particion := range mbr.MbrPartitions {
+		if particion.Part_start == -1 {
+			continue
+		}
+		nombre := strings.Trim(string(particion.Part_name[:]), "\x00 ")
+
+		if particion.Part_type[0] != 'E' {
+			verificarSuperBlock(archivo, particion.Part_start, particion.Part_size, nombre, bufferSalida, resultado)
+			continue
+		}
+
+		visitados := make(map[int32]bool)
+		inicioEBR := particion.Part_start
+		for inicioEBR != -1 {
+			if visitados[inicioEBR] {
+				reportarEstructura(bufferSalida, resultado, fmt.Sprintf("EBR en %d", inicioEBR), errors.New("la cadena de EBR contiene un ciclo"))
+				break
+			}
+			visitados[inicioEBR] = true
+
+			ebr, err := Estructuras.LeerEBR(inicioEBR, archivo)
+			reportarEstructura(bufferSalida, resultado, fmt.Sprintf("EBR en %d ('%s')", inicioEBR, nombre), sinChecksum(err, err == nil && ebr.SinChecksum()))
+			if err != nil {
+				// El enlace al siguiente EBR no es confiable
+				break
+			}
+			inicioEBR = ebr.Ebr_next
+		}
+	}
+}
+
+func verificarParticionesGPT(archivo *os.File, bufferSalida *bytes.Buffer, resultado *resultadoVerificacion) {
+	var gpt Estructuras.GPT
+	err := gpt.Decodificar(archivo)
+	reportarEstructura(bufferSalida, resultado, "Encabezado GPT", err)
+	if err != nil {
+		return
+	}
+	for _, entrada := range gpt.Entradas {
+		if entrada.EnUso() {
+			verificarSuperBlock(archivo, entrada.Part_start, entrada.Part_size, entrada.Nombre(), bufferSalida, resultado)
+		}
+	}
+}
+
+// Verifica el checksum del superbloque y que sus posiciones caigan dentro de la particion
+func verificarSuperBlock(archivo *os.File, inicio int32, tamano int32, nombre string, bufferSalida *bytes.Buffer, resultado *resultadoVerificacion) {
+	etiqueta := fmt.Sprintf("SuperBlock de '%s'", nombre)
+
+	var sb Estructuras.SuperBlock
+	err := sb.Decodificar(archivo, int64(inicio))
+	if err == nil && sb.S_magic != 0xEF53 {
+		err = fmt.Errorf("numero magico invalido: 0x%X", sb.S_magic)
+	}
+	if err == nil {
+		fin := inicio + tamano
+		for _, posicion := range []int32{sb.S_bm_inode_start, sb.S_bm_block_start, sb.S_inode_start, sb.S_block_start} {
+			if posicion < inicio || posicion >= fin {
+				err = fmt.Errorf("la posicion %d esta fuera de la particion [%d, %d)", posicion, inicio, fin)
+				break
+			}
+		}
+	}
+	reportarEstructura(bufferSalida, resultado, etiqueta, sinChecksum(err, sb.SinChecksum()))
+
+	if err == nil && sb.S_groups_count > 0 {
+		verificarGrupos(archivo, &sb, nombre, bufferSalida, resultado)
+	}
+}
+
+// Verifica los descriptores de grupo y que cada superbloque de respaldo sea valido y
+// tenga la misma geometria que el principal
+func verificarGrupos(archivo *os.File, sb *Estructuras.SuperBlock, nombre string, bufferSalida *bytes.Buffer, resultado *resultadoVerificacion) {
+	_, err := sb.LeerDescriptoresGrupo(archivo)
+	reportarEstructura(bufferSalida, resultado, fmt.Sprintf("Descriptores de grupo de '%s' (%d grupos)", nombre, sb.S_groups_count), err)
+
+	for _, grupo := range sb.GruposConRespaldo() {
+		respaldo, err := sb.LeerRespaldoGrupo(archivo, grupo)
+		if err == nil && (respaldo.S_groups_count != sb.S_groups_count || respaldo.S_blocks_per_group != sb.S_blocks_per_group ||
+			respaldo.S_inodes_per_group != sb.S_inodes_per_group || respaldo.S_block_start != sb.S_block_start) {
+			err = errors.New("la geometria no coincide con la del superbloque principal")
+		}
+		reportarEstructura(bufferSalida, resultado, fmt.Sprintf("SuperBlock de respaldo del grupo %d de '%s'", grupo, nombre), err)
+	}
+}
+
+// sinChecksum marca como no verificada una estructura que se leyo bien pero esta en el
+// formato anterior a los checksums
+func sinChecksum(err error, anterior bool) error {
+	if err == nil && anterior {
+		return Estructuras.ErrSinChecksum
+	}
+	return err
+}
+
+// Escribe el estado de una estructura y actualiza los contadores del resultado
+func reportarEstructura(bufferSalida *bytes.Buffer, resultado *resultadoVerificacion, etiqueta string, err error) {
+	switch {
+	case err == nil:
+		resultado.correctas++
+		fmt.Fprintf(bufferSalida, "[OK]          %s\n", etiqueta)
+	case errors.Is(err, Estructuras.ErrSinFormato):
+		resultado.sinFormato++
+		fmt.Fprintf(bufferSalida, "[SIN FORMATO] %s\n", etiqueta)
+	case errors.Is(err, Estructuras.ErrSinChecksum):
+		resultado.sinVerificar++
+		fmt.Fprintf(bufferSalida, "[SIN VERIFICAR] %s: %v\n", etiqueta, err)
+	default:
+		resultado.corruptas++
+		fmt.Fprintf(bufferSalida, "[CORRUPTO]    %s: %v\n", etiqueta, err)
+	}
+}
@@ -0,0 +1,73 @@
+package Disk_test
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	Disk "backend/Comandos/Disk"
+	Pruebas "backend/Comandos/Pruebas"
+	Estructuras "backend/Estructuras"
+)
+
+func TestVerifyReportaCorrupcion(t *testing.T) {
+	disco := Pruebas.Disco(t, "")
+	Pruebas.Ejecutar(t, Disk.ParserFdisk, "-size=1 -unit=M -type=E -path="+disco+" -name=E")
+	Pruebas.Ejecutar(t, Disk.ParserFdisk, "-size=256 -unit=K -type=L -path="+disco+" -name=L1")
+	id := Pruebas.Montar(t, disco, "Part1")
+	Pruebas.Formatear(t, id, "")
+	Pruebas.Ejecutar(t, Disk.ParserUnmount, "-id="+id)
+
+	inicioSuperBloque := int64(inicioParticion(t, disco, "Part1"))
+	inicioEBR := int64(inicioParticion(t, disco, "E"))
+	etiquetaEBR := fmt.Sprintf("EBR en %d", inicioEBR)
+
+	salida := Pruebas.Ejecutar(t, Disk.ParserVerify, "-path="+disco)
+	for _, linea := range []string{"[OK]          MBR", "[OK]          SuperBlock de 'Part1'", "[OK]          " + etiquetaEBR, "| 0 corruptas |"} {
+		if !strings.Contains(salida, linea) {
+			t.Fatalf("el disco recien creado no muestra %q:\n%s", linea, salida)
+		}
+	}
+
+	// Cada caso agrega un dano al disco; los anteriores se siguen reportando
+	casos := []struct {
+		nombre    string
+		posicion  int64
+		datos     []byte
+		corruptas []string
+	}{
+		{"EBR con un byte alterado", inicioEBR + 6, []byte{0x7F}, []string{etiquetaEBR}},
+		// Un checksum en cero fuera del formato anterior tambien es corrupcion
+		{"SuperBlock con el checksum borrado", inicioSuperBloque + int64(binary.Size(Estructuras.SuperBlock{})) - 4, make([]byte, 4),
+			[]string{etiquetaEBR, "SuperBlock de 'Part1'"}},
+		// Sin un MBR confiable ya no se revisan las particiones
+		{"MBR con un byte alterado", 0, []byte{0x01}, []string{"MBR"}},
+	}
+	for _, caso := range casos {
+		escribirEnDisco(t, disco, caso.posicion, caso.datos)
+		salida := Pruebas.Ejecutar(t, Disk.ParserVerify, "-path="+disco)
+		for _, etiqueta := range caso.corruptas {
+			if !strings.Contains(salida, "[CORRUPTO]    "+etiqueta) {
+				t.Errorf("%s: no se reporto %s como corrupto:\n%s", caso.nombre, etiqueta, salida)
+			}
+		}
+		if resumen := fmt.Sprintf("| %d corruptas |", len(caso.corruptas)); !strings.Contains(salida, resumen) {
+			t.Errorf("%s: el resumen no muestra %q:\n%s", caso.nombre, resumen, salida)
+		}
+	}
+}
+
+// escribirEnDisco sobrescribe 'datos' en la posicion del disco
+func escribirEnDisco(t *testing.T, disco string, posicion int64, datos []byte) {
+	t.Helper()
+	archivo, err := os.OpenFile(disco, os.O_RDWR, 0666)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer archivo.Close()
+	if _, err := archivo.WriteAt(datos, posicion); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -10,7 +10,6 @@ import (
 
 	Estructuras "backend/Estructuras"
 	Global "backend/Global"
-	Utils "backend/Utils"
 )
 
 // Estructura del comando CAT con parametros
@@ -88,7 +87,7 @@ func comandoCat(cat *CAT, bufferSalida *bytes.Buffer) error {
 			continue
 		}
 
-		bufferSalida.WriteString(contenido)
+		bufferSalida.Write(contenido)
 		bufferSalida.WriteString("\n") // Separar contenido con salto de linea
 		fmt.Fprint(bufferSalida, "--------------------------------------------\n")
 	}
@@ -97,174 +96,60 @@ func comandoCat(cat *CAT, bufferSalida *bytes.Buffer) error {
 }
 
 // Se busca el archivo en el sistema y lee su contenido
-func leerContenidoArchivo(rutaArchivo string) (string, error) {
+func leerContenidoArchivo(rutaArchivo string) ([]byte, error) {
 	// Obtener SuperBlock y particion montada asociada
 	idParticion := Global.UsuarioActual.Id
 	superBloqueParticion, _, rutaParticion, err := Global.ObtenerSuperblockParticionMontada(idParticion)
 	if err != nil {
-		return "", fmt.Errorf("error al obtener la particion montada: %v", err)
+		return nil, fmt.Errorf("error al obtener la particion montada: %v", err)
 	}
 
-	// Abrir archivo de particion para lectura
-	archivo, err := os.OpenFile(rutaParticion, os.O_RDONLY, 0666)
+	// Abrir archivo de particion; leer actualiza la fecha de acceso del inodo
+	archivo, err := os.OpenFile(rutaParticion, os.O_RDWR, 0666)
 	if err != nil {
-		return "", fmt.Errorf("error al abrir el archivo de particion: %v", err)
+		return nil, fmt.Errorf("error al abrir el archivo de particion: %v", err)
 	}
 	defer archivo.Close()
 
-	// Convertir ruta del archivo en array de carpetas
-	directoriosPadre, nombreArchivo := Utils.ObtenerDirectoriosPadre(rutaArchivo)
-
 	// Buscar archivo en sistema de archivos
-	indiceInodo, err := buscarInodoArchivo(archivo, superBloqueParticion, directoriosPadre, nombreArchivo)
+	credenciales := Global.CredencialesActuales(archivo, superBloqueParticion)
+	resuelta, err := superBloqueParticion.ResolverRuta(archivo, rutaArchivo, credenciales)
 	if err != nil {
-		return "", fmt.Errorf("error al encontrar el archivo: %v", err)
+		return nil, fmt.Errorf("error al encontrar el archivo: %w", err)
 	}
 
-	contenido, err := leerArchivoDesdeInodo(archivo, superBloqueParticion, indiceInodo)
+	contenido, err := leerArchivoDesdeInodo(archivo, superBloqueParticion, resuelta.Inodo)
 	if err != nil {
-		return "", fmt.Errorf("error al leer el contenido del archivo: %v", err)
+		return nil, fmt.Errorf("error al leer el contenido del archivo: %v", err)
 	}
 
 	return contenido, nil
 }
 
-// Verifica si un directorio o archivo existe en el inodo dado
-func directorioExiste(sb *Estructuras.SuperBlock, archivo *os.File, indiceInodo int32, nombreDirectorio string) (bool, int32, error) {
-	// Deserializar inodo correspondiente
+// leerArchivoDesdeInodo lee exactamente los I_size bytes de un archivo desde su inodo y
+// registra el acceso
+func leerArchivoDesdeInodo(archivo *os.File, sb *Estructuras.SuperBlock, indiceInodo int32) ([]byte, error) {
 	inodo := &Estructuras.INodo{}
-	err := inodo.Decodificar(archivo, int64(sb.S_inode_start+(indiceInodo*sb.S_inode_size)))
+	err := inodo.Decodificar(archivo, sb.CalcularDesplazamientoInodo(indiceInodo))
 	if err != nil {
-		return false, -1, fmt.Errorf("error al deserializar inodo %d: %v", indiceInodo, err)
-	}
-
-	// Verificar si el inodo es de tipo carpeta (I_type == '0') para continuar
-	if inodo.I_type[0] != '0' {
-		return false, -1, fmt.Errorf("el inodo %d no es una carpeta", indiceInodo)
-	}
-
-	// Recorrer bloques del inodo para buscar directorio o archivo
-	for _, indiceBloques := range inodo.I_block {
-		if indiceBloques == -1 {
-			break // Si no hay mas bloques asignados, terminar busqueda
-		}
-
-		// Deserializar bloque de directorio
-		bloque := &Estructuras.FolderBlock{}
-		err := bloque.Decodificar(archivo, int64(sb.S_block_start+(indiceBloques*sb.S_block_size)))
-		if err != nil {
-			return false, -1, fmt.Errorf("error al deserializar bloque %d: %v", indiceBloques, err)
-		}
-
-		// Recorrer contenidos del bloque para verificar coincidencia de nombre
-		for _, contenido := range bloque.B_cont {
-			nombreContenido := strings.Trim(string(contenido.B_name[:]), "\x00 ") // Convertir nombre y quitar caracteres nulos
-			if strings.EqualFold(nombreContenido, nombreDirectorio) && contenido.B_inodo != -1 {
-				return true, contenido.B_inodo, nil // Devolver true si directorio/archivo fue encontrado
-			}
-		}
+		return nil, fmt.Errorf("error al deserializar el inodo %d: %v", indiceInodo, err)
 	}
 
-	// No se encontro directorio/archivo
-	fmt.Printf("Directorio o archivo '%s' no encontrado en inodo %d\n", nombreDirectorio, indiceInodo)
-	return false, -1, nil
-}
-
-// buscarInodoArchivo busca el inodo de un archivo dado su ruta
-func buscarInodoArchivo(archivo *os.File, sb *Estructuras.SuperBlock, directoriosPadre []string, nombreArchivo string) (int32, error) {
-	// Empezar busqueda en inodo raiz
-	indiceInodo := int32(0)
-
-	// Navegar por directorios padre para llegar al archivo
-	for len(directoriosPadre) > 0 {
-		nombreDirectorio := directoriosPadre[0]
-		encontrado, nuevoIndiceInodo, err := directorioExiste(sb, archivo, indiceInodo, nombreDirectorio)
-		if err != nil {
-			return -1, err
-		}
-		if !encontrado {
-			return -1, fmt.Errorf("directorio '%s' no encontrado", nombreDirectorio)
-		}
-		indiceInodo = nuevoIndiceInodo
-		directoriosPadre = directoriosPadre[1:]
+	if inodo.I_type[0] != '1' {
+		return nil, fmt.Errorf("el inodo %d no corresponde a un archivo", indiceInodo)
 	}
 
-	// Buscar archivo en ultimo directorio
-	encontrado, indiceInodoArchivo, err := directorioExiste(sb, archivo, indiceInodo, nombreArchivo)
+	// Concatenar bloques de contenido del archivo, incluidos los indirectos, hasta I_size
+	contenido, err := inodo.LeerDatos(archivo, sb)
 	if err != nil {
-		return -1, err
+		return nil, fmt.Errorf("error al leer los bloques del inodo %d: %v", indiceInodo, err)
 	}
-	if !encontrado {
-		return -1, fmt.Errorf("archivo '%s' no encontrado", nombreArchivo)
-	}
-
-	return indiceInodoArchivo, nil
-}
 
-// leerArchivoDesdeInodo lee contenido de un archivo desde su inodo
-func leerArchivoDesdeInodo(archivo *os.File, sb *Estructuras.SuperBlock, indiceInodo int32) (string, error) {
-	inodo := &Estructuras.INodo{}
-	err := inodo.Decodificar(archivo, int64(sb.S_inode_start+(indiceInodo*sb.S_inode_size)))
+	inodo.ActualizarTiempoAcceso()
+	err = inodo.Codificar(archivo, sb.CalcularDesplazamientoInodo(indiceInodo))
 	if err != nil {
-		return "", fmt.Errorf("error al deserializar el inodo %d: %v", indiceInodo, err)
-	}
-
-	if inodo.I_type[0] != '1' {
-		return "", fmt.Errorf("el inodo %d no corresponde a un archivo", indiceInodo)
+		return nil, fmt.Errorf("error actualizando la fecha de acceso del inodo %d: %v", indiceInodo, err)
 	}
 
-	// Concatenar bloques de contenido del archivo
-	var constructorContenido strings.Builder
-	for _, indiceBloques := range inodo.I_block {
-		if indiceBloques == -1 {
-			break
-		}
-
-		bloqueArchivo := &Estructuras.FileBlock{}
-		err := bloqueArchivo.Decodificar(archivo, int64(sb.S_block_start+(indiceBloques*sb.S_block_size)))
-		if err != nil {
-			return "", fmt.Errorf("error al deserializar el bloque %d: %v", indiceBloques, err)
-		}
-
-		constructorContenido.WriteString(string(bloqueArchivo.B_cont[:]))
-	}
-
-	return constructorContenido.String(), nil
-}
-
-// buscarInodoCarpeta busca el inodo de una carpeta dado su ruta
-func buscarInodoCarpeta(archivo *os.File, sb *Estructuras.SuperBlock, directoriosPadre []string) (int32, error) {
-    // Si no hay directorios padre, retornar el inodo raiz (directorio root)
-    if len(directoriosPadre) == 0 {
-        return 0, nil
-    }
-    
-    // Empezar busqueda en inodo raiz
-    indiceInodo := int32(0)
-
-    // Navegar por cada directorio en la ruta
-    for _, nombreDirectorio := range directoriosPadre {
-        encontrado, nuevoIndiceInodo, err := directorioExiste(sb, archivo, indiceInodo, nombreDirectorio)
-        if err != nil {
-            return -1, err
-        }
-        if !encontrado {
-            return -1, fmt.Errorf("directorio '%s' no encontrado", nombreDirectorio)
-        }
-        
-        // Verificar que efectivamente sea un directorio
-        inodo := &Estructuras.INodo{}
-        err = inodo.Decodificar(archivo, int64(sb.S_inode_start+(nuevoIndiceInodo*sb.S_inode_size)))
-        if err != nil {
-            return -1, fmt.Errorf("error al leer inodo %d: %v", nuevoIndiceInodo, err)
-        }
-        
-        if inodo.I_type[0] != '0' {
-            return -1, fmt.Errorf("'%s' no es un directorio", nombreDirectorio)
-        }
-        
-        indiceInodo = nuevoIndiceInodo
-    }
-
-    return indiceInodo, nil
+	return contenido, nil
 }
\ No newline at end of file
@@ -11,7 +11,6 @@ import (
 
     Estructuras "backend/Estructuras"
     Global "backend/Global"
-    Utils "backend/Utils"
 )
 
 // CHMOD estructura del comando chmod con parámetros
@@ -113,12 +112,13 @@ func comandoChmod(comandoChmod *CHMOD, bufferSalida *bytes.Buffer) error {
         return fmt.Errorf("formato de permisos inválido: '%s'. Debe ser 3 dígitos del 0-7", comandoChmod.ugo)
     }
 
-    // Localizar el archivo o directorio usando buscarInodoArchivo de cat.go
-    directoriosPadre, nombreElemento := Utils.ObtenerDirectoriosPadre(comandoChmod.path)
-    indiceInodoElemento, err := buscarInodoArchivo(archivo, superBloqueParticion, directoriosPadre, nombreElemento)
+    // Localizar el archivo o directorio
+    credenciales := Global.CredencialesActuales(archivo, superBloqueParticion)
+    resuelta, err := superBloqueParticion.ResolverRuta(archivo, comandoChmod.path, credenciales)
     if err != nil {
-        return fmt.Errorf("error: la ruta '%s' no existe: %w", comandoChmod.path, err)
+        return fmt.Errorf("error al resolver la ruta '%s': %w", comandoChmod.path, err)
     }
+    indiceInodoElemento := resuelta.Inodo
 
     // Ejecutar cambio de permisos
     if comandoChmod.recursivo {
@@ -159,7 +159,7 @@ func validarFormatoUGO(ugo string) bool {
 func cambiarPermisosElemento(archivo *os.File, sb *Estructuras.SuperBlock, indiceInodo int32, nuevosPermisos string, rutaElemento string, bufferSalida *bytes.Buffer) error {
     // Cargar información del inodo
     inodo := &Estructuras.INodo{}
-    err := inodo.Decodificar(archivo, int64(sb.S_inode_start+(indiceInodo*sb.S_inode_size)))
+    err := inodo.Decodificar(archivo, sb.CalcularDesplazamientoInodo(indiceInodo))
     if err != nil {
         return fmt.Errorf("error al cargar inodo: %w", err)
     }
@@ -170,9 +170,10 @@ func cambiarPermisosElemento(archivo *os.File, sb *Estructuras.SuperBlock, indic
 
     // Establecer nuevos permisos
     copy(inodo.I_perm[:], nuevosPermisos)
+    inodo.ActualizarTiempoPermisos()
 
     // Almacenar cambios en el inodo
-    offsetInodo := int64(sb.S_inode_start + indiceInodo*sb.S_inode_size)
+    offsetInodo := sb.CalcularDesplazamientoInodo(indiceInodo)
     err = inodo.Codificar(archivo, offsetInodo)
     if err != nil {
         return fmt.Errorf("error al guardar cambios del inodo: %w", err)
@@ -191,7 +192,7 @@ func cambiarPermisosRecursivo(archivo *os.File, sb *Estructuras.SuperBlock, indi
 
     // Verificar si es un directorio usando lógica inline
     inodo := &Estructuras.INodo{}
-    err = inodo.Decodificar(archivo, int64(sb.S_inode_start+(indiceInodo*sb.S_inode_size)))
+    err = inodo.Decodificar(archivo, sb.CalcularDesplazamientoInodo(indiceInodo))
     if err != nil {
         return fmt.Errorf("error al cargar inodo del directorio: %w", err)
     }
@@ -201,45 +202,32 @@ func cambiarPermisosRecursivo(archivo *os.File, sb *Estructuras.SuperBlock, indi
         return nil
     }
 
-    // Recorrer todos los bloques del directorio
-    for _, indiceBloques := range inodo.I_block {
-        if indiceBloques == -1 {
-            break // No hay más bloques asignados
+    // Recorrer todas las entradas del directorio (con su nombre completo)
+    directorio, err := sb.LeerDirectorio(archivo, indiceInodo)
+    if err != nil {
+        return fmt.Errorf("error al cargar las entradas del directorio: %w", err)
+    }
+
+    for _, contenido := range directorio.Entradas() {
+        nombreContenido := contenido.Nombre
+
+        // Omitir referencias de directorio especiales
+        if nombreContenido == "." || nombreContenido == ".." {
+            continue
         }
 
-        // Cargar contenido del bloque de directorio
-        bloque := &Estructuras.FolderBlock{}
-        err := bloque.Decodificar(archivo, int64(sb.S_block_start+(indiceBloques*sb.S_block_size)))
-        if err != nil {
-            return fmt.Errorf("error al cargar bloque del directorio: %w", err)
+        // Verificar si el elemento pertenece al usuario actual antes de procesar
+        if !esElementoDelUsuarioActual(archivo, sb, contenido.Inodo) {
+            fmt.Fprintf(bufferSalida, "Saltando '%s/%s' - no pertenece al usuario actual\n", rutaActual, nombreContenido)
+            continue
         }
 
-        // Examinar cada entrada del bloque
-        for _, contenido := range bloque.B_cont {
-            if contenido.B_inodo == -1 {
-                continue // Saltar entradas vacías
-            }
-
-            nombreContenido := strings.Trim(string(contenido.B_name[:]), "\x00 ")
-
-            // Omitir referencias de directorio especiales
-            if nombreContenido == "." || nombreContenido == ".." {
-                continue
-            }
-
-            // Verificar si el elemento pertenece al usuario actual antes de procesar
-            if !esElementoDelUsuarioActual(archivo, sb, contenido.B_inodo) {
-                fmt.Fprintf(bufferSalida, "Saltando '%s/%s' - no pertenece al usuario actual\n", rutaActual, nombreContenido)
-                continue
-            }
-
-            // Aplicar cambio recursivamente
-            nuevaRuta := rutaActual + "/" + nombreContenido
-            err = cambiarPermisosRecursivo(archivo, sb, contenido.B_inodo, nuevosPermisos, nuevaRuta, bufferSalida)
-            if err != nil {
-                fmt.Fprintf(bufferSalida, "Error en '%s': %v\n", nuevaRuta, err)
-                continue
-            }
+        // Aplicar cambio recursivamente
+        nuevaRuta := rutaActual + "/" + nombreContenido
+        err = cambiarPermisosRecursivo(archivo, sb, contenido.Inodo, nuevosPermisos, nuevaRuta, bufferSalida)
+        if err != nil {
+            fmt.Fprintf(bufferSalida, "Error en '%s': %v\n", nuevaRuta, err)
+            continue
         }
     }
 
@@ -249,7 +237,7 @@ func cambiarPermisosRecursivo(archivo *os.File, sb *Estructuras.SuperBlock, indi
 // esElementoDelUsuarioActual verifica si un elemento pertenece al usuario actual
 func esElementoDelUsuarioActual(archivo *os.File, sb *Estructuras.SuperBlock, indiceInodo int32) bool {
     inodo := &Estructuras.INodo{}
-    err := inodo.Decodificar(archivo, int64(sb.S_inode_start+(indiceInodo*sb.S_inode_size)))
+    err := inodo.Decodificar(archivo, sb.CalcularDesplazamientoInodo(indiceInodo))
     if err != nil {
         return false
     }
@@ -11,7 +11,6 @@ import (
 
     Estructuras "backend/Estructuras"
     Global "backend/Global"
-    Utils "backend/Utils"
 )
 
 // CHOWN estructura del comando chown con parámetros
@@ -112,12 +111,13 @@ func comandoChown(comandoChown *CHOWN, bufferSalida *bytes.Buffer) error {
         return fmt.Errorf("el usuario '%s' no existe en el sistema", comandoChown.usuario)
     }
 
-    // Localizar el archivo o directorio usando buscarInodoArchivo de cat.go
-    directoriosPadre, nombreElemento := Utils.ObtenerDirectoriosPadre(comandoChown.path)
-    indiceInodoElemento, err := buscarInodoArchivo(archivo, superBloqueParticion, directoriosPadre, nombreElemento)
+    // Localizar el archivo o directorio
+    credenciales := Global.CredencialesActuales(archivo, superBloqueParticion)
+    resuelta, err := superBloqueParticion.ResolverRuta(archivo, comandoChown.path, credenciales)
     if err != nil {
-        return fmt.Errorf("error: la ruta '%s' no existe: %w", comandoChown.path, err)
+        return fmt.Errorf("error al resolver la ruta '%s': %w", comandoChown.path, err)
     }
+    indiceInodoElemento := resuelta.Inodo
 
     // Validar permisos para realizar el cambio de propietario
     if !validarPermisosChown(archivo, superBloqueParticion, indiceInodoElemento) {
@@ -143,23 +143,20 @@ func comandoChown(comandoChown *CHOWN, bufferSalida *bytes.Buffer) error {
 
 // validarUsuarioExisteOptimizado usa funciones existentes de cat.go
 func validarUsuarioExisteOptimizado(archivo *os.File, sb *Estructuras.SuperBlock, nombreUsuario string) (bool, error) {
-    // Usar directorioExiste de cat.go para encontrar users.txt
-    encontrado, indiceInodoUsers, err := directorioExiste(sb, archivo, 0, "users.txt")
+    // Localizar users.txt; es un archivo del sistema y no depende de los permisos del usuario
+    usuarios, err := sb.ResolverRuta(archivo, "/users.txt", Estructuras.CredencialesRoot)
     if err != nil {
         return false, fmt.Errorf("error al buscar users.txt: %w", err)
     }
-    if !encontrado {
-        return false, fmt.Errorf("archivo users.txt no encontrado")
-    }
 
     // Usar leerArchivoDesdeInodo de cat.go para obtener contenido
-    contenidoUsers, err := leerArchivoDesdeInodo(archivo, sb, indiceInodoUsers)
+    contenidoUsers, err := leerArchivoDesdeInodo(archivo, sb, usuarios.Inodo)
     if err != nil {
         return false, fmt.Errorf("error al leer users.txt: %w", err)
     }
 
     // Examinar cada línea buscando el usuario
-    lineas := strings.Split(contenidoUsers, "\n")
+    lineas := strings.Split(string(contenidoUsers), "\n")
     for _, linea := range lineas {
         if strings.TrimSpace(linea) == "" {
             continue
@@ -183,7 +180,7 @@ func validarPermisosChown(archivo *os.File, sb *Estructuras.SuperBlock, indiceIn
 
     // Otros usuarios solo pueden cambiar sus propios archivos
     inodo := &Estructuras.INodo{}
-    err := inodo.Decodificar(archivo, int64(sb.S_inode_start+(indiceInodo*sb.S_inode_size)))
+    err := inodo.Decodificar(archivo, sb.CalcularDesplazamientoInodo(indiceInodo))
     if err != nil {
         return false
     }
@@ -202,7 +199,7 @@ func validarPermisosChown(archivo *os.File, sb *Estructuras.SuperBlock, indiceIn
 func cambiarPropietarioElemento(archivo *os.File, sb *Estructuras.SuperBlock, indiceInodo int32, nuevoPropietario string, rutaElemento string, bufferSalida *bytes.Buffer) error {
     // Cargar información del inodo
     inodo := &Estructuras.INodo{}
-    err := inodo.Decodificar(archivo, int64(sb.S_inode_start+(indiceInodo*sb.S_inode_size)))
+    err := inodo.Decodificar(archivo, sb.CalcularDesplazamientoInodo(indiceInodo))
     if err != nil {
         return fmt.Errorf("error al cargar inodo: %w", err)
     }
@@ -218,9 +215,10 @@ func cambiarPropietarioElemento(archivo *os.File, sb *Estructuras.SuperBlock, in
     }
     // Establecer nuevo propietario (UID numérico)
     inodo.I_uid = nuevoId
+    inodo.ActualizarTiempoPermisos()
 
     // Almacenar cambios en el inodo
-    offsetInodo := int64(sb.S_inode_start + indiceInodo*sb.S_inode_size)
+    offsetInodo := sb.CalcularDesplazamientoInodo(indiceInodo)
     err = inodo.Codificar(archivo, offsetInodo)
     if err != nil {
         return fmt.Errorf("error al guardar cambios del inodo: %w", err)
@@ -239,7 +237,7 @@ func cambiarPropietarioRecursivo(archivo *os.File, sb *Estructuras.SuperBlock, i
 
     // Verificar si es un directorio usando lógica inline
     inodo := &Estructuras.INodo{}
-    err = inodo.Decodificar(archivo, int64(sb.S_inode_start+(indiceInodo*sb.S_inode_size)))
+    err = inodo.Decodificar(archivo, sb.CalcularDesplazamientoInodo(indiceInodo))
     if err != nil {
         return fmt.Errorf("error al cargar inodo del directorio: %w", err)
     }
@@ -249,45 +247,32 @@ func cambiarPropietarioRecursivo(archivo *os.File, sb *Estructuras.SuperBlock, i
         return nil
     }
 
-    // Recorrer todos los bloques del directorio
-    for _, indiceBloques := range inodo.I_block {
-        if indiceBloques == -1 {
-            break // No hay más bloques asignados
-        }
-
-        // Cargar contenido del bloque de directorio
-        bloque := &Estructuras.FolderBlock{}
-        err := bloque.Decodificar(archivo, int64(sb.S_block_start+(indiceBloques*sb.S_block_size)))
-        if err != nil {
-            return fmt.Errorf("error al cargar bloque del directorio: %w", err)
-        }
-
-        // Examinar cada entrada del bloque
-        for _, contenido := range bloque.B_cont {
-            if contenido.B_inodo == -1 {
-                continue // Saltar entradas vacías
-            }
+    // Recorrer todas las entradas del directorio (con su nombre completo)
+    directorio, err := sb.LeerDirectorio(archivo, indiceInodo)
+    if err != nil {
+        return fmt.Errorf("error al cargar las entradas del directorio: %w", err)
+    }
 
-            nombreContenido := strings.Trim(string(contenido.B_name[:]), "\x00 ")
+    for _, contenido := range directorio.Entradas() {
+        nombreContenido := contenido.Nombre
 
-            // Omitir referencias de directorio especiales
-            if nombreContenido == "." || nombreContenido == ".." {
-                continue
-            }
+        // Omitir referencias de directorio especiales
+        if nombreContenido == "." || nombreContenido == ".." {
+            continue
+        }
 
-            // Validar permisos de lectura antes de procesar
-            if !validarPermisosLecturaChown(archivo, sb, contenido.B_inodo) {
-                fmt.Fprintf(bufferSalida, "Saltando '%s/%s' - sin permisos de lectura\n", rutaActual, nombreContenido)
-                continue
-            }
+        // Validar permisos de lectura antes de procesar
+        if !validarPermisosLecturaChown(archivo, sb, contenido.Inodo) {
+            fmt.Fprintf(bufferSalida, "Saltando '%s/%s' - sin permisos de lectura\n", rutaActual, nombreContenido)
+            continue
+        }
 
-            // Aplicar cambio recursivamente
-            nuevaRuta := rutaActual + "/" + nombreContenido
-            err = cambiarPropietarioRecursivo(archivo, sb, contenido.B_inodo, nuevoPropietario, nuevaRuta, bufferSalida)
-            if err != nil {
-                fmt.Fprintf(bufferSalida, "Error en '%s': %v\n", nuevaRuta, err)
-                continue
-            }
+        // Aplicar cambio recursivamente
+        nuevaRuta := rutaActual + "/" + nombreContenido
+        err = cambiarPropietarioRecursivo(archivo, sb, contenido.Inodo, nuevoPropietario, nuevaRuta, bufferSalida)
+        if err != nil {
+            fmt.Fprintf(bufferSalida, "Error en '%s': %v\n", nuevaRuta, err)
+            continue
         }
     }
 
@@ -297,30 +282,29 @@ func cambiarPropietarioRecursivo(archivo *os.File, sb *Estructuras.SuperBlock, i
 // validarPermisosLecturaChown verifica si se tienen permisos de lectura sobre un elemento
 func validarPermisosLecturaChown(archivo *os.File, sb *Estructuras.SuperBlock, indiceInodo int32) bool {
     inodo := &Estructuras.INodo{}
-    err := inodo.Decodificar(archivo, int64(sb.S_inode_start+(indiceInodo*sb.S_inode_size)))
+    err := inodo.Decodificar(archivo, sb.CalcularDesplazamientoInodo(indiceInodo))
     if err != nil {
         return false
     }
 
-    // Evaluar permisos según el owner y group
-    permisos := string(inodo.I_perm[:])
-    return strings.Contains(permisos, "6") || strings.Contains(permisos, "4")
+    // Evaluar el digito de propietario, grupo u otros que corresponde al usuario
+    return Global.CredencialesActuales(archivo, sb).Permite(inodo, Estructuras.PermisoLectura)
 }
 
 // obtenerIdUsuarioPorNombre busca el ID numérico de un usuario por su nombre en users.txt
 func obtenerIdUsuarioPorNombre(sb *Estructuras.SuperBlock, archivo *os.File, nombre string) (int32, error) {
-    // Usar directorioExiste para localizar users.txt (inodo 1 normalmente)
-    encontrado, indiceInodoUsers, err := directorioExiste(sb, archivo, 0, "users.txt")
-    if err != nil || !encontrado {
+    // Localizar users.txt (inodo 1 normalmente)
+    usuarios, err := sb.ResolverRuta(archivo, "/users.txt", Estructuras.CredencialesRoot)
+    if err != nil {
         return -1, fmt.Errorf("users.txt no encontrado: %w", err)
     }
 
-    contenidoUsers, err := leerArchivoDesdeInodo(archivo, sb, indiceInodoUsers)
+    contenidoUsers, err := leerArchivoDesdeInodo(archivo, sb, usuarios.Inodo)
     if err != nil {
         return -1, fmt.Errorf("error leyendo users.txt: %w", err)
     }
 
-    lineas := strings.Split(contenidoUsers, "\n")
+    lineas := strings.Split(string(contenidoUsers), "\n")
     for _, linea := range lineas {
         campos := strings.Split(linea, ",")
         if len(campos) >= 4 && campos[1] == "U" {
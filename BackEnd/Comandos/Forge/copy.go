@@ -10,7 +10,6 @@ import (
 
     Estructuras "backend/Estructuras"
     Global "backend/Global"
-    Utils "backend/Utils"
 )
 
 // COPY estructura del comando copy con parámetros
@@ -78,7 +77,7 @@ func comandoCopy(comandoCopy *COPY, bufferSalida *bytes.Buffer) error {
     idParticion := Global.UsuarioActual.Id
 
     // Obtener la partición montada asociada al usuario logueado
-    superBloqueParticion, _, rutaParticion, err := Global.ObtenerSuperblockParticionMontada(idParticion)
+    superBloqueParticion, particionMontada, rutaParticion, err := Global.ObtenerSuperblockParticionMontada(idParticion)
     if err != nil {
         return fmt.Errorf("error al obtener la partición montada: %w", err)
     }
@@ -94,11 +93,15 @@ func comandoCopy(comandoCopy *COPY, bufferSalida *bytes.Buffer) error {
     fmt.Fprintf(bufferSalida, "Hacia destino: %s\n", comandoCopy.destino)
 
     // Verificar que la ruta origen existe y obtener su tipo
-    directoriosPadreOrigen, nombreOrigen := Utils.ObtenerDirectoriosPadre(comandoCopy.path)
-    indiceInodoOrigen, esDirectorio, err := buscarElemento(archivo, superBloqueParticion, directoriosPadreOrigen, nombreOrigen)
+    credenciales := Global.CredencialesActuales(archivo, superBloqueParticion)
+    origen, err := superBloqueParticion.ResolverRuta(archivo, comandoCopy.path, credenciales)
     if err != nil {
-        return fmt.Errorf("error: la ruta origen '%s' no existe: %w", comandoCopy.path, err)
+        return fmt.Errorf("error al resolver la ruta origen '%s': %w", comandoCopy.path, err)
     }
+    if origen.Inodo == 0 || origen.Nombre == "." || origen.Nombre == ".." {
+        return fmt.Errorf("error: la ruta origen '%s' no nombra un elemento que se pueda copiar", comandoCopy.path)
+    }
+    indiceInodoOrigen, esDirectorio, nombreOrigen := origen.Inodo, origen.EsDirectorio, origen.Nombre
 
     // Verificar permisos de lectura en el elemento origen
     if !verificarPermisosLectura(archivo, superBloqueParticion, indiceInodoOrigen) {
@@ -106,10 +109,12 @@ func comandoCopy(comandoCopy *COPY, bufferSalida *bytes.Buffer) error {
     }
 
     // Verificar que el directorio destino existe
-    directoriosPadreDestino, _ := Utils.ObtenerDirectoriosPadre(comandoCopy.destino)
-    indiceInodoDestino, err := buscarInodoCarpeta(archivo, superBloqueParticion, directoriosPadreDestino)
+    indiceInodoDestino, err := superBloqueParticion.ResolverDirectorio(archivo, comandoCopy.destino, credenciales)
     if err != nil {
-        return fmt.Errorf("error: el directorio destino '%s' no existe: %w", comandoCopy.destino, err)
+        return fmt.Errorf("error al resolver el directorio destino '%s': %w", comandoCopy.destino, err)
+    }
+    if err := credenciales.RevisarArchivoSistema(comandoCopy.destino, indiceInodoDestino, nombreOrigen); err != nil {
+        return err
     }
 
     // Verificar permisos de escritura en el directorio destino
@@ -128,66 +133,40 @@ func comandoCopy(comandoCopy *COPY, bufferSalida *bytes.Buffer) error {
         return fmt.Errorf("error durante la copia: %w", err)
     }
 
-    fmt.Fprintf(bufferSalida, "Copia completada exitosamente\n")
-    fmt.Fprint(bufferSalida, "=====================================================\n")
-
-    return nil
-}
-
-// buscarElemento busca un archivo o directorio y determina su tipo
-func buscarElemento(archivo *os.File, sb *Estructuras.SuperBlock, directoriosPadre []string, nombreElemento string) (int32, bool, error) {
-    // Buscar el directorio padre
-    indiceInodoPadre, err := buscarInodoCarpeta(archivo, sb, directoriosPadre)
+    // Guardar los contadores de inodos y bloques asignados durante la copia
+    err = superBloqueParticion.Codificar(archivo, int64(particionMontada.Part_start))
     if err != nil {
-        return -1, false, err
+        return fmt.Errorf("error al guardar el superbloque: %w", err)
     }
 
-    // Buscar el elemento en el directorio padre
-    encontrado, indiceInodoElemento, err := directorioExiste(sb, archivo, indiceInodoPadre, nombreElemento)
-    if err != nil {
-        return -1, false, err
-    }
-    if !encontrado {
-        return -1, false, fmt.Errorf("elemento '%s' no encontrado", nombreElemento)
-    }
-
-    // Verificar el tipo del elemento
-    inodo := &Estructuras.INodo{}
-    err = inodo.Decodificar(archivo, int64(sb.S_inode_start+(indiceInodoElemento*sb.S_inode_size)))
-    if err != nil {
-        return -1, false, fmt.Errorf("error al leer inodo del elemento: %w", err)
-    }
+    fmt.Fprintf(bufferSalida, "Copia completada exitosamente\n")
+    fmt.Fprint(bufferSalida, "=====================================================\n")
 
-    esDirectorio := inodo.I_type[0] == '0'
-    return indiceInodoElemento, esDirectorio, nil
+    return nil
 }
 
 // verificarPermisosLectura verifica si el usuario actual tiene permisos de lectura
 func verificarPermisosLectura(archivo *os.File, sb *Estructuras.SuperBlock, indiceInodo int32) bool {
     inodo := &Estructuras.INodo{}
-    err := inodo.Decodificar(archivo, int64(sb.S_inode_start+(indiceInodo*sb.S_inode_size)))
+    err := inodo.Decodificar(archivo, sb.CalcularDesplazamientoInodo(indiceInodo))
     if err != nil {
         return false
     }
 
-    // Verificar permisos según el owner y group
-    // Para este ejemplo, asumimos que el permiso '6' incluye lectura (4)
-    permisos := string(inodo.I_perm[:])
-    return strings.Contains(permisos, "6") || strings.Contains(permisos, "4")
+    // Evaluar el digito de propietario, grupo u otros que corresponde al usuario
+    return Global.CredencialesActuales(archivo, sb).Permite(inodo, Estructuras.PermisoLectura)
 }
 
 // verificarPermisosEscritura verifica si el usuario actual tiene permisos de escritura
 func verificarPermisosEscritura(archivo *os.File, sb *Estructuras.SuperBlock, indiceInodo int32) bool {
     inodo := &Estructuras.INodo{}
-    err := inodo.Decodificar(archivo, int64(sb.S_inode_start+(indiceInodo*sb.S_inode_size)))
+    err := inodo.Decodificar(archivo, sb.CalcularDesplazamientoInodo(indiceInodo))
     if err != nil {
         return false
     }
 
-    // Verificar permisos según el owner y group
-    // Para este ejemplo, asumimos que el permiso '6' incluye escritura (2)
-    permisos := string(inodo.I_perm[:])
-    return strings.Contains(permisos, "6") || strings.Contains(permisos, "2")
+    // Evaluar el digito de propietario, grupo u otros que corresponde al usuario
+    return Global.CredencialesActuales(archivo, sb).Permite(inodo, Estructuras.PermisoEscritura)
 }
 
 // copiarArchivo copia un archivo individual
@@ -203,33 +182,39 @@ func copiarArchivo(archivo *os.File, sb *Estructuras.SuperBlock, indiceInodoOrig
     // Crear nuevo inodo para el archivo destino usando NuevoInodoVacio
     nuevoInodo := Estructuras.NuevoInodoVacio()
     nuevoInodo.I_type[0] = '1' // Tipo archivo
-    nuevoInodo.I_size = int32(len(contenido))
     copy(nuevoInodo.I_perm[:], "664")
 
-    // Buscar inodo libre para el nuevo archivo
-    nuevoIndiceInodo, err := sb.BuscarSiguienteInodoLibre(archivo)
+    // Buscar inodo libre para el nuevo archivo, en el grupo del directorio destino
+    nuevoIndiceInodo, err := sb.BuscarInodoLibreCercaDe(archivo, indiceInodoDestino)
     if err != nil {
         return fmt.Errorf("error al buscar inodo libre: %w", err)
     }
 
-    // Escribir contenido en bloques del nuevo archivo
-    err = nuevoInodo.EscribirDatos(archivo, sb, []byte(contenido))
+    // Escribir contenido en bloques del nuevo archivo; EscribirDatos asigna los bloques y
+    // registra I_size
+    err = nuevoInodo.EscribirDatos(archivo, sb, contenido)
     if err != nil {
         return fmt.Errorf("error al escribir datos del archivo: %w", err)
     }
 
     // Guardar el nuevo inodo
-    offsetInodo := int64(sb.S_inode_start + nuevoIndiceInodo*sb.S_inode_size)
+    offsetInodo := sb.CalcularDesplazamientoInodo(nuevoIndiceInodo)
     err = nuevoInodo.Codificar(archivo, offsetInodo)
     if err != nil {
         return fmt.Errorf("error al guardar el nuevo inodo: %w", err)
     }
 
+    // La copia conserva los atributos extendidos del origen
+    if err := sb.CopiarAtributos(archivo, indiceInodoOrigen, nuevoIndiceInodo); err != nil {
+        return fmt.Errorf("error al copiar los atributos: %w", err)
+    }
+
     // Actualizar bitmap de inodos
     err = sb.ActualizarBitmapInodo(archivo, nuevoIndiceInodo, true)
     if err != nil {
         return fmt.Errorf("error al actualizar bitmap de inodos: %w", err)
     }
+    sb.ActualizarSuperblockDespuesAsignacionInodo()
 
     // Agregar entrada al directorio destino
     err = agregarEntradaDirectorio(archivo, sb, indiceInodoDestino, nombreArchivo, nuevoIndiceInodo)
@@ -251,22 +236,25 @@ func copiarDirectorio(archivo *os.File, sb *Estructuras.SuperBlock, indiceInodoO
     copy(nuevoInodo.I_perm[:], "664")
 
     // Buscar inodo libre para el nuevo directorio
-    nuevoIndiceInodo, err := sb.BuscarSiguienteInodoLibre(archivo)
+    nuevoIndiceInodo, err := sb.BuscarInodoLibreParaCarpeta(archivo)
     if err != nil {
         return fmt.Errorf("error al buscar inodo libre: %w", err)
     }
 
+    // Guardar el nuevo inodo antes de asignarle su bloque inicial
+    offsetInodo := sb.CalcularDesplazamientoInodo(nuevoIndiceInodo)
+    err = nuevoInodo.Codificar(archivo, offsetInodo)
+    if err != nil {
+        return fmt.Errorf("error al guardar el nuevo inodo: %w", err)
+    }
+
     // Crear bloque inicial para el directorio con entradas . y ..
     err = crearBloqueDirectorioInicial(archivo, sb, nuevoIndiceInodo, indiceInodoDestino)
     if err != nil {
         return fmt.Errorf("error al crear bloque inicial del directorio: %w", err)
     }
-
-    // Guardar el nuevo inodo
-    offsetInodo := int64(sb.S_inode_start + nuevoIndiceInodo*sb.S_inode_size)
-    err = nuevoInodo.Codificar(archivo, offsetInodo)
-    if err != nil {
-        return fmt.Errorf("error al guardar el nuevo inodo: %w", err)
+    if err := sb.CopiarAtributos(archivo, indiceInodoOrigen, nuevoIndiceInodo); err != nil {
+        return fmt.Errorf("error al copiar los atributos: %w", err)
     }
 
     // Actualizar bitmap de inodos
@@ -274,6 +262,7 @@ func copiarDirectorio(archivo *os.File, sb *Estructuras.SuperBlock, indiceInodoO
     if err != nil {
         return fmt.Errorf("error al actualizar bitmap de inodos: %w", err)
     }
+    sb.ActualizarSuperblockDespuesAsignacionInodo()
 
     // Agregar entrada al directorio destino padre
     err = agregarEntradaDirectorio(archivo, sb, indiceInodoDestino, nombreDirectorio, nuevoIndiceInodo)
@@ -292,72 +281,63 @@ func copiarDirectorio(archivo *os.File, sb *Estructuras.SuperBlock, indiceInodoO
 
 // copiarContenidoDirectorio copia recursivamente el contenido de un directorio
 func copiarContenidoDirectorio(archivo *os.File, sb *Estructuras.SuperBlock, indiceInodoOrigen int32, indiceInodoDestino int32, bufferSalida *bytes.Buffer) error {
-    // Cargar inodo del directorio origen
-    inodo := &Estructuras.INodo{}
-    err := inodo.Decodificar(archivo, int64(sb.S_inode_start+(indiceInodoOrigen*sb.S_inode_size)))
+    // Cargar las entradas del directorio origen
+    directorio, err := sb.LeerDirectorio(archivo, indiceInodoOrigen)
     if err != nil {
-        return fmt.Errorf("error al cargar inodo origen: %w", err)
+        return fmt.Errorf("error al cargar el directorio origen: %w", err)
     }
 
-    // Recorrer todos los bloques del directorio origen
-    for _, indiceBloques := range inodo.I_block {
-        if indiceBloques == -1 {
-            break
+    // Procesar cada entrada del directorio (saltar . y ..)
+    for _, entrada := range directorio.Entradas() {
+        if entrada.EsEspecial() {
+            continue
         }
 
-        // Cargar bloque del directorio
-        bloque := &Estructuras.FolderBlock{}
-        err := bloque.Decodificar(archivo, int64(sb.S_block_start+(indiceBloques*sb.S_block_size)))
+        nombreEntrada := entrada.Nombre
+
+        // Verificar permisos de lectura en la entrada
+        if !verificarPermisosLectura(archivo, sb, entrada.Inodo) {
+            fmt.Fprintf(bufferSalida, "Saltando '%s' - sin permisos de lectura\n", nombreEntrada)
+            continue
+        }
+
+        // Determinar si es archivo, directorio o enlace simbólico
+        tipo, esDirectorio, err := determinarTipoElemento(archivo, sb, entrada.Inodo)
         if err != nil {
-            return fmt.Errorf("error al cargar bloque del directorio: %w", err)
+            fmt.Fprintf(bufferSalida, "Error al determinar tipo de '%s': %v\n", nombreEntrada, err)
+            continue
+        }
+
+        // Copiar elemento recursivamente; los enlaces se copian como enlaces
+        if esDirectorio {
+            err = copiarDirectorio(archivo, sb, entrada.Inodo, indiceInodoDestino, nombreEntrada, bufferSalida)
+        } else if tipo == "enlace" {
+            err = copiarEnlace(archivo, sb, entrada.Inodo, indiceInodoDestino, nombreEntrada, bufferSalida)
+        } else {
+            err = copiarArchivo(archivo, sb, entrada.Inodo, indiceInodoDestino, nombreEntrada, bufferSalida)
         }
 
-        // Procesar cada entrada del bloque (saltar . y ..)
-        for i, entrada := range bloque.B_cont {
-            if i < 2 || entrada.B_inodo == -1 {
-                continue // Saltar entradas . y .. y entradas vacías
-            }
-
-            nombreEntrada := strings.Trim(string(entrada.B_name[:]), "\x00 ")
-            
-            // Verificar permisos de lectura en la entrada
-            if !verificarPermisosLectura(archivo, sb, entrada.B_inodo) {
-                fmt.Fprintf(bufferSalida, "Saltando '%s' - sin permisos de lectura\n", nombreEntrada)
-                continue
-            }
-
-            // Determinar si es archivo o directorio
-            _, esDirectorio, err := determinarTipoElemento(archivo, sb, entrada.B_inodo)
-            if err != nil {
-                fmt.Fprintf(bufferSalida, "Error al determinar tipo de '%s': %v\n", nombreEntrada, err)
-                continue
-            }
-
-            // Copiar elemento recursivamente
-            if esDirectorio {
-                err = copiarDirectorio(archivo, sb, entrada.B_inodo, indiceInodoDestino, nombreEntrada, bufferSalida)
-            } else {
-                err = copiarArchivo(archivo, sb, entrada.B_inodo, indiceInodoDestino, nombreEntrada, bufferSalida)
-            }
-
-            if err != nil {
-                fmt.Fprintf(bufferSalida, "Error al copiar '%s': %v\n", nombreEntrada, err)
-                continue
-            }
+        if err != nil {
+            fmt.Fprintf(bufferSalida, "Error al copiar '%s': %v\n", nombreEntrada, err)
+            continue
         }
     }
 
     return nil
 }
 
-// determinarTipoElemento determina si un inodo es archivo o directorio
+// determinarTipoElemento determina si un inodo es archivo, directorio o enlace simbólico
 func determinarTipoElemento(archivo *os.File, sb *Estructuras.SuperBlock, indiceInodo int32) (string, bool, error) {
     inodo := &Estructuras.INodo{}
-    err := inodo.Decodificar(archivo, int64(sb.S_inode_start+(indiceInodo*sb.S_inode_size)))
+    err := inodo.Decodificar(archivo, sb.CalcularDesplazamientoInodo(indiceInodo))
     if err != nil {
         return "", false, err
     }
 
+    if inodo.EsEnlaceSimbolico() {
+        return "enlace", false, nil
+    }
+
     esDirectorio := inodo.I_type[0] == '0'
     if esDirectorio {
         return "directorio", true, nil
@@ -365,16 +345,40 @@ func determinarTipoElemento(archivo *os.File, sb *Estructuras.SuperBlock, indice
     return "archivo", false, nil
 }
 
+// copiarEnlace crea en el directorio destino un enlace simbólico con el mismo destino
+func copiarEnlace(archivo *os.File, sb *Estructuras.SuperBlock, indiceInodoOrigen int32, indiceInodoDestino int32, nombreEnlace string, bufferSalida *bytes.Buffer) error {
+    fmt.Fprintf(bufferSalida, "Copiando enlace: %s\n", nombreEnlace)
+
+    inodo := &Estructuras.INodo{}
+    if err := inodo.Decodificar(archivo, sb.CalcularDesplazamientoInodo(indiceInodoOrigen)); err != nil {
+        return fmt.Errorf("error al cargar el enlace origen: %w", err)
+    }
+    destino, err := sb.LeerDestinoEnlace(archivo, inodo)
+    if err != nil {
+        return err
+    }
+
+    nuevoIndiceInodo, err := sb.CrearEnlaceSimbolico(archivo, indiceInodoDestino, nombreEnlace, destino, inodo.I_uid, inodo.I_gid)
+    if err != nil {
+        return fmt.Errorf("error al crear el enlace: %w", err)
+    }
+    return sb.CopiarAtributos(archivo, indiceInodoOrigen, nuevoIndiceInodo)
+}
+
 // crearBloqueDirectorioInicial crea el bloque inicial de un directorio con entradas . y ..
 func crearBloqueDirectorioInicial(archivo *os.File, sb *Estructuras.SuperBlock, indiceInodoActual int32, indiceInodoPadre int32) error {
-    // Buscar bloque libre
+    // Buscar bloque libre cerca de los del padre o, con grupos, en el grupo del directorio
+    padre := &Estructuras.INodo{}
+    if err := padre.Decodificar(archivo, sb.CalcularDesplazamientoInodo(indiceInodoPadre)); err == nil {
+        sb.PreferirBloquesCercaDe(archivo, sb.BloqueReferenciaCarpeta(indiceInodoActual, padre))
+    }
     indiceBloque, err := sb.BuscarSiguienteBloqueLibre(archivo)
     if err != nil {
         return fmt.Errorf("error al buscar bloque libre: %w", err)
     }
 
     // Crear bloque de directorio
-    bloque := &Estructuras.FolderBlock{}
+    bloque := Estructuras.NuevoFolderBlock(sb.S_block_size)
     
     // Entrada . (directorio actual)
     copy(bloque.B_cont[0].B_name[:], ".")
@@ -401,10 +405,11 @@ func crearBloqueDirectorioInicial(archivo *os.File, sb *Estructuras.SuperBlock,
     if err != nil {
         return fmt.Errorf("error al actualizar bitmap de bloques: %w", err)
     }
+    sb.ActualizarSuperblockDespuesAsignacionBloque()
 
     // Asignar bloque al inodo
     inodo := &Estructuras.INodo{}
-    offsetInodo := int64(sb.S_inode_start + indiceInodoActual*sb.S_inode_size)
+    offsetInodo := sb.CalcularDesplazamientoInodo(indiceInodoActual)
     err = inodo.Decodificar(archivo, offsetInodo)
     if err != nil {
         return fmt.Errorf("error al cargar inodo: %w", err)
@@ -419,46 +424,20 @@ func crearBloqueDirectorioInicial(archivo *os.File, sb *Estructuras.SuperBlock,
     return nil
 }
 
-// agregarEntradaDirectorio agrega una nueva entrada a un directorio
+// agregarEntradaDirectorio agrega una nueva entrada a un directorio. Los nombres largos
+// ocupan varios contenidos y el directorio crece con bloques nuevos si hace falta
 func agregarEntradaDirectorio(archivo *os.File, sb *Estructuras.SuperBlock, indiceInodoDirectorio int32, nombreEntrada string, indiceInodoEntrada int32) error {
-    // Cargar inodo del directorio
-    inodo := &Estructuras.INodo{}
-    err := inodo.Decodificar(archivo, int64(sb.S_inode_start+(indiceInodoDirectorio*sb.S_inode_size)))
+    directorio, err := sb.LeerDirectorio(archivo, indiceInodoDirectorio)
     if err != nil {
         return fmt.Errorf("error al cargar inodo del directorio: %w", err)
     }
 
-    // Buscar espacio libre en los bloques existentes
-    for _, indiceBloques := range inodo.I_block {
-        if indiceBloques == -1 {
-            break
-        }
-
-        // Cargar bloque
-        bloque := &Estructuras.FolderBlock{}
-        err := bloque.Decodificar(archivo, int64(sb.S_block_start+(indiceBloques*sb.S_block_size)))
-        if err != nil {
-            continue
-        }
-
-        // Buscar entrada libre
-        for i := range bloque.B_cont {
-            if bloque.B_cont[i].B_inodo == -1 {
-                // Entrada libre encontrada
-                copy(bloque.B_cont[i].B_name[:], nombreEntrada)
-                bloque.B_cont[i].B_inodo = indiceInodoEntrada
-
-                // Guardar bloque modificado
-                err = bloque.Codificar(archivo, int64(sb.S_block_start+(indiceBloques*sb.S_block_size)))
-                if err != nil {
-                    return fmt.Errorf("error al guardar bloque modificado: %w", err)
-                }
-
-                return nil
-            }
-        }
+    if err := directorio.Agregar(archivo, sb, nombreEntrada, indiceInodoEntrada); err != nil {
+        return err
     }
 
-    // Si llegamos aquí, necesitamos un nuevo bloque
-    return fmt.Errorf("no hay espacio libre en el directorio para agregar la entrada")
+    if err := directorio.Guardar(archivo, sb); err != nil {
+        return fmt.Errorf("error al guardar bloque modificado: %w", err)
+    }
+    return nil
 }
\ No newline at end of file
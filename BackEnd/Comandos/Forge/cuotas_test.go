@@ -0,0 +1,155 @@
+package Forge
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	Pruebas "backend/Comandos/Pruebas"
+	User "backend/Comandos/User"
+	Estructuras "backend/Estructuras"
+	Global "backend/Global"
+)
+
+// ejecutarComando reinicia las cuotas antes del comando, como lo hace el analizador
+func ejecutarComando(parser func([]string) (string, error), linea string) error {
+	Estructuras.ReiniciarCuotas()
+	_, err := parser(strings.Fields(linea))
+	return err
+}
+
+func TestCuotasDeUsuario(t *testing.T) {
+	id := Pruebas.MontarParticionFormateada(t, "")
+	Pruebas.Ejecutar(t, User.ParserMkgrp, "-name=ventas")
+	Pruebas.Ejecutar(t, User.ParserMkusr, "-user=ana -pass=abc -grp=ventas")
+	Pruebas.Ejecutar(t, User.ParserMkusr, "-user=beto -pass=abc -grp=ventas")
+	Pruebas.Ejecutar(t, ParserMkdir, "-path=/home")
+	Pruebas.Ejecutar(t, ParserChmod, "-path=/home -ugo=777")
+	Pruebas.Ejecutar(t, User.ParserSetquota, "-user=ana -isoft=2 -ihard=3")
+	Pruebas.Ejecutar(t, User.ParserSetquota, "-user=beto -bhard=4")
+
+	casos := []struct {
+		usuario     string
+		clave       string
+		comando     string
+		excede      bool
+		advertencia bool
+	}{
+		{"ana", "abc", "-path=/home/a1 -size=10", false, false},
+		{"ana", "abc", "-path=/home/a2 -size=10", false, false},
+		{"ana", "abc", "-path=/home/a3 -size=10", false, true}, // pasa el limite suave de inodos
+		{"ana", "abc", "-path=/home/a4 -size=10", true, false},
+		{"beto", "abc", "-path=/home/b1 -size=1000", true, false}, // necesita mas de 4 bloques
+		{"beto", "abc", "-path=/home/b2 -size=100", false, false},
+		{"beto", "abc", "-path=/home/b3 -size=200", true, false},
+		{"root", "123", "-path=/home/r1 -size=1000", false, false}, // root no tiene cuotas
+	}
+	for _, caso := range casos {
+		Global.Logout()
+		Pruebas.Ejecutar(t, User.ParserLogin, "-user="+caso.usuario+" -pass="+caso.clave+" -id="+id)
+
+		err := ejecutarComando(ParserMkfile, caso.comando)
+		if caso.excede != errors.Is(err, Estructuras.ErrCuotaExcedida) {
+			t.Errorf("%s mkfile %s: %v", caso.usuario, caso.comando, err)
+		}
+		if !caso.excede && err != nil {
+			t.Errorf("%s mkfile %s: %v", caso.usuario, caso.comando, err)
+		}
+		if advertencias := Estructuras.AdvertenciasCuotas(); (len(advertencias) > 0) != caso.advertencia {
+			t.Errorf("%s mkfile %s: advertencias %v", caso.usuario, caso.comando, advertencias)
+		}
+	}
+
+	// El uso que queda en disco no incluye los archivos rechazados
+	sb, _, ruta, err := Global.ObtenerSuperblockParticionMontada(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	archivo, err := os.OpenFile(ruta, os.O_RDWR, 0666)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer archivo.Close()
+	usuarios, _, err := sb.CalcularUsoCuotas(archivo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for ruta, esperado := range map[string]int32{"/home/a1": 3, "/home/b2": 1} {
+		resuelta, err := sb.ResolverRuta(archivo, ruta, Estructuras.CredencialesRoot)
+		if err != nil {
+			t.Fatal(err)
+		}
+		inodo := &Estructuras.INodo{}
+		if err := inodo.Decodificar(archivo, sb.CalcularDesplazamientoInodo(resuelta.Inodo)); err != nil {
+			t.Fatal(err)
+		}
+		if uso := usuarios[inodo.I_uid]; uso.Inodos != esperado {
+			t.Errorf("el dueño de %s usa %d inodos, se esperaba %d", ruta, uso.Inodos, esperado)
+		}
+	}
+	for _, rechazado := range []string{"/home/a4", "/home/b1", "/home/b3"} {
+		if _, err := sb.ResolverRuta(archivo, rechazado, Estructuras.CredencialesRoot); !errors.Is(err, Estructuras.ErrRutaNoEncontrada) {
+			t.Errorf("%s: %v, el archivo rechazado no debe quedar creado", rechazado, err)
+		}
+	}
+}
+
+func TestArchivoCuotasProtegido(t *testing.T) {
+	id := Pruebas.MontarParticionFormateada(t, "")
+	Pruebas.Ejecutar(t, User.ParserMkgrp, "-name=ventas")
+	Pruebas.Ejecutar(t, User.ParserMkusr, "-user=ana -pass=abc -grp=ventas")
+	Pruebas.Ejecutar(t, User.ParserSetquota, "-user=ana -ihard=1")
+	Global.Logout()
+	Pruebas.Ejecutar(t, User.ParserLogin, "-user=ana -pass=abc -id="+id)
+
+	// Para un usuario normal el archivo de cuotas no existe y los archivos del sistema no se tocan
+	rechazados := []struct {
+		parser  func([]string) (string, error)
+		comando string
+	}{
+		{ParserRemove, "-path=/.quota"},
+		{ParserRemove, "-path=/users.txt"},
+		{ParserRename, "-path=/users.txt -name=otro.txt"},
+		{ParserEdit, "-ruta=/.quota -cont=0"},
+		{ParserMkfile, "-path=/.quota -size=0"},
+	}
+	for _, caso := range rechazados {
+		if err := ejecutarComando(caso.parser, caso.comando); err == nil {
+			t.Errorf("%s: se esperaba un error", caso.comando)
+		}
+	}
+	if salida, _ := ParserCat(strings.Fields("-file1=/.quota")); !strings.Contains(salida, "Error al leer") {
+		t.Errorf("cat leyo el archivo de cuotas: %q", salida)
+	}
+	if salida, err := ParserFind(strings.Fields("-path=/ -name=*")); err != nil || strings.Contains(salida, ".quota") {
+		t.Errorf("find lista el archivo de cuotas: %q %v", salida, err)
+	}
+
+	sistema, err := Global.SistemaArchivosMontado(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sistema.Close()
+	entradas, err := fs.ReadDir(sistema, ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entrada := range entradas {
+		if entrada.Name() == ".quota" {
+			t.Errorf("ReadDir lista el archivo de cuotas")
+		}
+	}
+
+	// Si el usuario desaparece de users.txt no puede asignar en lugar de quedar sin cuota
+	contenido := filepath.Join(t.TempDir(), "usuarios.txt")
+	if err := os.WriteFile(contenido, []byte("1,G,root\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	Pruebas.Ejecutar(t, ParserEdit, "-ruta=/users.txt -cont="+contenido)
+	if err := ejecutarComando(ParserMkdir, "-path=/libre"); err == nil {
+		t.Errorf("un usuario fuera de users.txt pudo crear /libre")
+	}
+}
@@ -0,0 +1,123 @@
+package Forge
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	Pruebas "backend/Comandos/Pruebas"
+	Estructuras "backend/Estructuras"
+	Global "backend/Global"
+)
+
+// directorioConArchivos crea /grande con la cantidad de archivos vacios indicada en la
+// particion montada y retorna el disco abierto, el superbloque y el inodo del directorio
+func directorioConArchivos(t *testing.T, id string, cantidad int) (*os.File, *Estructuras.SuperBlock, int32) {
+	t.Helper()
+	Pruebas.Ejecutar(t, ParserMkdir, "-path=/grande")
+	for i := 0; i < cantidad; i++ {
+		Pruebas.Ejecutar(t, ParserMkfile, fmt.Sprintf("-path=/grande/archivo_%03d.txt", i))
+	}
+
+	sb, _, ruta, err := Global.ObtenerSuperblockParticionMontada(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	archivo, err := os.OpenFile(ruta, os.O_RDWR, 0666)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { archivo.Close() })
+	resuelta, err := sb.ResolverRuta(archivo, "/grande", Estructuras.CredencialesRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return archivo, sb, resuelta.Inodo
+}
+
+func TestIndiceDirectorio(t *testing.T) {
+	const cantidad = 60
+	archivo, sb, grande := directorioConArchivos(t, Pruebas.MontarParticionFormateada(t, ""), cantidad)
+
+	directorio, err := sb.LeerDirectorio(archivo, grande)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !directorio.TieneIndice() {
+		t.Fatalf("el directorio con %d archivos no tiene indice", cantidad)
+	}
+
+	// El indice devuelve lo mismo que el recorrido lineal
+	comprobar := func(nombre string, existe bool) {
+		t.Helper()
+		lineal, existeLineal := directorio.Buscar(nombre)
+		entrada, encontrada, err := sb.BuscarEntrada(archivo, grande, nombre)
+		if err != nil {
+			t.Fatalf("BuscarEntrada(%s): %v", nombre, err)
+		}
+		if encontrada != existe || existeLineal != existe || entrada.Inodo != lineal.Inodo {
+			t.Errorf("BuscarEntrada(%s) = %d, %v; lineal %d, %v; se esperaba existe=%v", nombre, entrada.Inodo, encontrada, lineal.Inodo, existeLineal, existe)
+		}
+	}
+	for i := 0; i < cantidad; i++ {
+		comprobar(fmt.Sprintf("archivo_%03d.txt", i), true)
+	}
+	comprobar("ARCHIVO_007.TXT", true)
+	comprobar("no_existe.txt", false)
+
+	// Las entradas borradas salen del indice y las demas se siguen encontrando
+	for i := 0; i < cantidad; i += 2 {
+		Pruebas.Ejecutar(t, ParserRemove, fmt.Sprintf("-path=/grande/archivo_%03d.txt", i))
+	}
+	Pruebas.Ejecutar(t, ParserMkfile, "-path=/grande/nuevo_con_nombre_largo.txt")
+	if directorio, err = sb.LeerDirectorio(archivo, grande); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < cantidad; i++ {
+		comprobar(fmt.Sprintf("archivo_%03d.txt", i), i%2 == 1)
+	}
+	comprobar("nuevo_con_nombre_largo.txt", true)
+}
+
+func TestDirectorioConIndirectos(t *testing.T) {
+	const cantidad = 60
+	id := Pruebas.MontarParticionFormateada(t, "")
+	bloquesUsados := func() int32 {
+		t.Helper()
+		sb, _, _, err := Global.ObtenerSuperblockParticionMontada(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sb.S_blocks_count
+	}
+	usadosAntes := bloquesUsados()
+	archivo, sb, grande := directorioConArchivos(t, id, cantidad)
+
+	inodo := &Estructuras.INodo{}
+	if err := inodo.Decodificar(archivo, sb.CalcularDesplazamientoInodo(grande)); err != nil {
+		t.Fatal(err)
+	}
+	if inodo.I_block[11] == -1 || inodo.I_block[12] == -1 {
+		t.Fatalf("el directorio no llega al bloque indirecto: %v", inodo.I_block)
+	}
+
+	directorio, err := sb.LeerDirectorio(archivo, grande)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vistos := make(map[string]bool)
+	for _, entrada := range directorio.Entradas() {
+		vistos[entrada.Nombre] = true
+	}
+	for i := 0; i < cantidad; i++ {
+		if nombre := fmt.Sprintf("archivo_%03d.txt", i); !vistos[nombre] {
+			t.Errorf("falta %s en el directorio", nombre)
+		}
+	}
+
+	// Al borrar el directorio se liberan tambien sus bloques de apuntadores
+	Pruebas.Ejecutar(t, ParserRemove, "-path=/grande")
+	if usados := bloquesUsados(); usados != usadosAntes {
+		t.Errorf("quedaron %d bloques usados, antes de crear el directorio habia %d", usados, usadosAntes)
+	}
+}
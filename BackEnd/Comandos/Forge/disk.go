@@ -38,6 +38,14 @@ func (dc *DiskCommand) ShowDisk(diskPath string) (string, error) {
 
 	// Convertir la información de las particiones en JSON para mostrar
 	partitions := mbr.ListPartitions()
+	if gpt, esGPT := dc.DiskManager.PartitionGPTs[diskPath]; esGPT {
+		partitions = partitions[:0]
+		for i := range gpt.Entradas {
+			if gpt.Entradas[i].EnUso() {
+				partitions = append(partitions, map[string]interface{}{"name": gpt.Entradas[i].Nombre()})
+			}
+		}
+	}
 	partitionsJSON, err := json.MarshalIndent(partitions, "", "  ")
 	if err != nil {
 		return "", fmt.Errorf("error serializando las particiones a JSON: %v", err)
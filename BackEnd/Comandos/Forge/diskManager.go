@@ -5,7 +5,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
-	"strings"
 
 	Estructuras "backend/Estructuras"
 	Global "backend/Global"
@@ -15,6 +14,7 @@ import (
 type DiskManager struct {
 	disks         map[string]*os.File
 	PartitionMBRs map[string]*Estructuras.MBR
+	PartitionGPTs map[string]*Estructuras.GPT // solo los discos con MBR protector
 }
 
 // NewDiskManager crea un nuevo gestor de discos
@@ -22,6 +22,7 @@ func NewDiskManager() *DiskManager {
 	return &DiskManager{
 		disks:         make(map[string]*os.File),
 		PartitionMBRs: make(map[string]*Estructuras.MBR),
+		PartitionGPTs: make(map[string]*Estructuras.GPT),
 	}
 }
 
@@ -41,6 +42,16 @@ func (dm *DiskManager) LoadDisk(diskPath string) error {
 		return fmt.Errorf("error al leer el MBR del disco: %w", err)
 	}
 
+	// En un disco GPT el MBR es solo el protector; las particiones estan en la tabla GPT
+	if mbr.EsProtectorGPT() {
+		gpt := &Estructuras.GPT{}
+		if err := gpt.Decodificar(file); err != nil {
+			file.Close()
+			return fmt.Errorf("error al leer la tabla GPT del disco: %w", err)
+		}
+		dm.PartitionGPTs[diskPath] = gpt
+	}
+
 	// Guardar el archivo y el MBR en las estructuras del DiskManager
 	dm.disks[diskPath] = file
 	dm.PartitionMBRs[diskPath] = mbr
@@ -54,6 +65,7 @@ func (dm *DiskManager) CloseDisk(diskPath string) error {
 		file.Close()
 		delete(dm.disks, diskPath)
 		delete(dm.PartitionMBRs, diskPath)
+		delete(dm.PartitionGPTs, diskPath)
 		fmt.Printf("Disco '%s' cerrado exitosamente.\n", diskPath)
 		return nil
 	}
@@ -94,8 +106,7 @@ func (dm *DiskManager) GetPartitionTree(diskPath string, partitionName string) (
 	if !exists {
 		return nil, fmt.Errorf("disco '%s' no está cargado", diskPath)
 	}
-	partition, err := dm.MountPartition(diskPath, partitionName)
-	if err != nil {
+	if _, err := dm.MountPartition(diskPath, partitionName); err != nil {
 		return nil, err
 	}
 
@@ -105,7 +116,7 @@ func (dm *DiskManager) GetPartitionTree(diskPath string, partitionName string) (
 	}
 	defer treeService.Close()
 
-	tree, err := treeService.GetDirectoryTree(fmt.Sprintf("/partition/%s", strings.TrimRight(strings.TrimSpace(string(partition.Part_name[:])), "\x00")))
+	tree, err := treeService.GetDirectoryTree(fmt.Sprintf("/partition/%s", partitionName))
 	if err != nil {
 		return nil, fmt.Errorf("error obteniendo el árbol de directorios: %v", err)
 	}
@@ -3,29 +3,31 @@ package Forge
 import (
     Estructuras "backend/Estructuras"
     Global "backend/Global"
-    Utils "backend/Utils"
 	
     "bytes"
     "errors"
     "fmt"
     "os"
     "regexp"
+    "strconv"
     "strings"
 )
 
 // EDIT estructura que representa el comando EDIT para modificar archivos
 type EDIT struct {
-    ruta      string // Ruta completa del archivo a modificar
-    contenido string // Ruta del archivo que contiene el nuevo contenido
+    ruta           string // Ruta completa del archivo a modificar
+    contenido      string // Ruta del archivo que contiene el nuevo contenido
+    desplazamiento int64  // Posicion donde se escribe el contenido (-offset); -1 reemplaza todo
+    agregar        bool   // Escribir el contenido al final del archivo (-append)
 }
 
 // ParserEdit analiza el comando edit y retorna una instancia de EDIT configurada
 func ParserEdit(tokens []string) (string, error) {
-    comando := &EDIT{}            // Crear nueva instancia del comando EDIT
-    var bufferSalida bytes.Buffer // Buffer para recopilar mensajes de salida
+    comando := &EDIT{desplazamiento: -1} // Crear nueva instancia del comando EDIT
+    var bufferSalida bytes.Buffer        // Buffer para recopilar mensajes de salida
 
-    // Expresion regular para extraer parametros -ruta y -contenido
-    expresionRegular := regexp.MustCompile(`-ruta="[^"]+"|-ruta=[^\s]+|-contenido="[^"]+"|-contenido=[^\s]+`)
+    // Expresion regular para extraer parametros -ruta, -contenido (o -cont), -offset y -append
+    expresionRegular := regexp.MustCompile(`-ruta="[^"]+"|-ruta=[^\s]+|-(?:contenido|cont)="[^"]+"|-(?:contenido|cont)=[^\s]+|-offset=[^\s]+|-append\b`)
     coincidencias := expresionRegular.FindAllString(strings.Join(tokens, " "), -1)
 
     // Validar que se proporcionaron los parametros minimos requeridos
@@ -35,6 +37,10 @@ func ParserEdit(tokens []string) (string, error) {
 
     // Procesar cada coincidencia encontrada para extraer valores
     for _, coincidencia := range coincidencias {
+        if strings.ToLower(coincidencia) == "-append" {
+            comando.agregar = true
+            continue
+        }
         parClaveValor := strings.SplitN(coincidencia, "=", 2)
         clave := strings.ToLower(parClaveValor[0])
         valor := strings.Trim(parClaveValor[1], "\"") // Remover comillas si las hay
@@ -43,11 +49,21 @@ func ParserEdit(tokens []string) (string, error) {
         switch clave {
         case "-ruta":
             comando.ruta = valor
-        case "-contenido":
+        case "-contenido", "-cont":
             comando.contenido = valor
+        case "-offset":
+            desplazamiento, err := strconv.ParseInt(valor, 10, 64)
+            if err != nil || desplazamiento < 0 {
+                return "", fmt.Errorf("el parametro -offset debe ser un entero no negativo: %s", valor)
+            }
+            comando.desplazamiento = desplazamiento
         }
     }
 
+    if comando.agregar && comando.desplazamiento >= 0 {
+        return "", errors.New("los parametros -offset y -append no pueden usarse juntos")
+    }
+
     // Verificar que ambos parametros fueron proporcionados
     if comando.ruta == "" || comando.contenido == "" {
         return "", errors.New("ambos parametros -ruta y -contenido son requeridos")
@@ -75,7 +91,7 @@ func comandoEdit(cmdEdit *EDIT, bufferSalida *bytes.Buffer) error {
     idParticion := Global.UsuarioActual.Id
 
     // Recuperar informacion de la particion montada
-    superBloqueParticion, _, rutaParticion, err := Global.GetMountedPartitionSuperblock(idParticion)
+    superBloqueParticion, particionMontada, rutaParticion, err := Global.GetMountedPartitionSuperblock(idParticion)
     if err != nil {
         return fmt.Errorf("error obteniendo particion montada: %w", err)
     }
@@ -87,14 +103,13 @@ func comandoEdit(cmdEdit *EDIT, bufferSalida *bytes.Buffer) error {
     }
     defer archivo.Close() // Asegurar cierre del archivo
 
-    // Analizar ruta para obtener directorios padre y nombre del archivo
-    directoriosPadre, nombreArchivo := Utils.ObtenerDirectoriosPadre(cmdEdit.ruta)
-
     // Localizar el inodo del archivo objetivo
-    indiceInodo, err := buscarInodoArchivo(archivo, superBloqueParticion, directoriosPadre, nombreArchivo)
+    credenciales := Global.CredencialesActuales(archivo, superBloqueParticion)
+    resuelta, err := superBloqueParticion.ResolverRuta(archivo, cmdEdit.ruta, credenciales)
     if err != nil {
-        return fmt.Errorf("archivo no encontrado: %v", err)
+        return fmt.Errorf("archivo no encontrado: %w", err)
     }
+    indiceInodo, nombreArchivo := resuelta.Inodo, resuelta.Nombre
 
     // Leer contenido del archivo de reemplazo desde el sistema operativo
     contenidoNuevo, err := os.ReadFile(cmdEdit.contenido)
@@ -102,12 +117,23 @@ func comandoEdit(cmdEdit *EDIT, bufferSalida *bytes.Buffer) error {
         return fmt.Errorf("error leyendo archivo de contenido '%s': %v", cmdEdit.contenido, err)
     }
 
-    // Aplicar modificaciones al archivo en el sistema de archivos simulado
-    err = modificarContenidoArchivo(archivo, superBloqueParticion, indiceInodo, contenidoNuevo)
+    // Aplicar modificaciones al archivo en el sistema de archivos simulado. Con -offset o
+    // -append solo se tocan los bloques del rango escrito
+    if cmdEdit.agregar || cmdEdit.desplazamiento >= 0 {
+        err = escribirEnArchivo(archivo, superBloqueParticion, indiceInodo, contenidoNuevo, cmdEdit.desplazamiento)
+    } else {
+        err = modificarContenidoArchivo(archivo, superBloqueParticion, indiceInodo, contenidoNuevo)
+    }
     if err != nil {
         return fmt.Errorf("error modificando contenido del archivo: %v", err)
     }
 
+    // Guardar el superbloque, el contenido pudo requerir bloques nuevos
+    err = superBloqueParticion.Codificar(archivo, int64(particionMontada.Part_start))
+    if err != nil {
+        return fmt.Errorf("error al serializar el superbloque: %v", err)
+    }
+
     fmt.Fprintf(bufferSalida, "Archivo '%s' modificado exitosamente\n", nombreArchivo)
     fmt.Fprint(bufferSalida, "===================================================\n")
 
@@ -118,7 +144,7 @@ func comandoEdit(cmdEdit *EDIT, bufferSalida *bytes.Buffer) error {
 func modificarContenidoArchivo(archivo *os.File, sb *Estructuras.SuperBlock, indiceInodo int32, contenidoNuevo []byte) error {
     // Cargar inodo del archivo
     inodo := &Estructuras.INodo{}
-    err := inodo.Decodificar(archivo, int64(sb.S_inode_start+(indiceInodo*sb.S_inode_size)))
+    err := inodo.Decodificar(archivo, sb.CalcularDesplazamientoInodo(indiceInodo))
     if err != nil {
         return fmt.Errorf("error deserializando inodo %d: %v", indiceInodo, err)
     }
@@ -128,99 +154,49 @@ func modificarContenidoArchivo(archivo *os.File, sb *Estructuras.SuperBlock, ind
         return fmt.Errorf("inodo %d no es un archivo valido", indiceInodo)
     }
 
-    // Limpiar bloques actuales del archivo para sobrescribir completamente
-    for _, indiceBloques := range inodo.I_block {
-        if indiceBloques != -1 {
-            bloqueArchivo := &Estructuras.FileBlock{}
-            bloqueArchivo.LimpiarContenido() // Vaciar contenido del bloque
-            err := bloqueArchivo.Codificar(archivo, int64(sb.S_block_start+(indiceBloques*sb.S_block_size)))
-            if err != nil {
-                return fmt.Errorf("error limpiando bloque %d: %v", indiceBloques, err)
-            }
-        }
+    // Sobrescribir los bloques del archivo con el nuevo contenido; los bloques que
+    // falten se asignan como directos o mediante apuntadores indirectos y los que
+    // sobren se liberan al recortar el archivo al nuevo tamano
+    _, err = inodo.WriteAt(archivo, sb, contenidoNuevo, 0)
+    if err != nil {
+        return fmt.Errorf("error escribiendo contenido: %v", err)
     }
-
-    // Fragmentar el nuevo contenido en bloques de 64 bytes
-    bloques, err := Estructuras.DividirContenido(string(contenidoNuevo))
+    err = inodo.Truncate(archivo, sb, int64(len(contenidoNuevo)))
     if err != nil {
-        return fmt.Errorf("error fragmentando contenido: %v", err)
-    }
-
-    // Escribir los nuevos bloques de contenido
-    cantidadBloques := len(bloques)
-    for i := 0; i < cantidadBloques; i++ {
-        if i < len(inodo.I_block) {
-            // Usar bloques ya asignados si existen
-            indiceBloques := inodo.I_block[i]
-            if indiceBloques == -1 {
-                // Asignar nuevo bloque si no existe
-                indiceBloques, err = sb.AsignarNuevoBloque(archivo, inodo, i)
-                if err != nil {
-                    return fmt.Errorf("error asignando bloque nuevo: %v", err)
-                }
-            }
-
-            // Escribir contenido en el bloque actual
-            err := bloques[i].Codificar(archivo, int64(sb.S_block_start+(indiceBloques*sb.S_block_size)))
-            if err != nil {
-                return fmt.Errorf("error escribiendo bloque %d: %v", indiceBloques, err)
-            }
-        } else {
-            // Manejar bloques adicionales usando bloques de apuntadores
-            indicePointerBlock := inodo.I_block[len(inodo.I_block)-1]
-            if indicePointerBlock == -1 {
-                // Asignar nuevo bloque de apuntadores si no existe
-                indicePointerBlock, err = sb.AsignarNuevoBloque(archivo, inodo, len(inodo.I_block)-1)
-                if err != nil {
-                    return fmt.Errorf("error asignando bloque de apuntadores: %v", err)
-                }
-            }
-
-            // Cargar bloque de apuntadores existente
-            pointerBlock := &Estructuras.PointerBlock{}
-            err := pointerBlock.Decodificar(archivo, int64(sb.S_block_start+(indicePointerBlock*sb.S_block_size)))
-            if err != nil {
-                return fmt.Errorf("error decodificando bloque de apuntadores: %v", err)
-            }
-
-            // Encontrar posicion libre en el bloque de apuntadores
-            indiceLibre, err := pointerBlock.BuscarApuntadorLibre()
-            if err != nil {
-                return fmt.Errorf("sin apuntadores libres disponibles: %v", err)
-            }
+        return fmt.Errorf("error ajustando el tamano del archivo: %v", err)
+    }
 
-            // Asignar nuevo bloque para contenido adicional
-            nuevoIndiceBloques, err := sb.AsignarNuevoBloque(archivo, inodo, indiceLibre)
-            if err != nil {
-                return fmt.Errorf("error asignando bloque adicional: %v", err)
-            }
+    err = inodo.Codificar(archivo, sb.CalcularDesplazamientoInodo(indiceInodo))
+    if err != nil {
+        return fmt.Errorf("error actualizando inodo %d: %v", indiceInodo, err)
+    }
 
-            // Actualizar apuntador en el bloque de apuntadores
-            err = pointerBlock.EstablecerApuntador(indiceLibre, int64(nuevoIndiceBloques))
-            if err != nil {
-                return fmt.Errorf("error actualizando apuntador: %v", err)
-            }
+    return nil
+}
 
-            // Guardar bloque de apuntadores modificado
-            err = pointerBlock.Codificar(archivo, int64(sb.S_block_start+(indicePointerBlock*sb.S_block_size)))
-            if err != nil {
-                return fmt.Errorf("error guardando bloque de apuntadores: %v", err)
-            }
+// escribirEnArchivo escribe el contenido desde el desplazamiento indicado sin reescribir
+// el resto del archivo; un desplazamiento negativo agrega el contenido al final
+func escribirEnArchivo(archivo *os.File, sb *Estructuras.SuperBlock, indiceInodo int32, contenido []byte, desplazamiento int64) error {
+    inodo := &Estructuras.INodo{}
+    err := inodo.Decodificar(archivo, sb.CalcularDesplazamientoInodo(indiceInodo))
+    if err != nil {
+        return fmt.Errorf("error deserializando inodo %d: %v", indiceInodo, err)
+    }
+    if inodo.I_type[0] != '1' {
+        return fmt.Errorf("inodo %d no es un archivo valido", indiceInodo)
+    }
 
-            // Escribir contenido en el nuevo bloque asignado
-            err = bloques[i].Codificar(archivo, int64(sb.S_block_start+(nuevoIndiceBloques*sb.S_block_size)))
-            if err != nil {
-                return fmt.Errorf("error escribiendo nuevo bloque %d: %v", nuevoIndiceBloques, err)
-            }
-        }
+    if desplazamiento < 0 {
+        desplazamiento = int64(inodo.I_size)
+    }
+    _, err = inodo.WriteAt(archivo, sb, contenido, desplazamiento)
+    if err != nil {
+        return fmt.Errorf("error escribiendo en el desplazamiento %d: %v", desplazamiento, err)
     }
 
-    // Actualizar tamano del archivo en el inodo
-    inodo.I_size = int32(len(contenidoNuevo))
-    err = inodo.Codificar(archivo, int64(sb.S_inode_start+(indiceInodo*sb.S_inode_size)))
+    err = inodo.Codificar(archivo, sb.CalcularDesplazamientoInodo(indiceInodo))
     if err != nil {
         return fmt.Errorf("error actualizando inodo %d: %v", indiceInodo, err)
     }
-
     return nil
 }
\ No newline at end of file
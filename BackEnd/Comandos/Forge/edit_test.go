@@ -0,0 +1,134 @@
+package Forge
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	Pruebas "backend/Comandos/Pruebas"
+	Estructuras "backend/Estructuras"
+	Global "backend/Global"
+)
+
+func TestAccesoAleatorioFueraDelFinal(t *testing.T) {
+	id := Pruebas.MontarParticionFormateada(t, "")
+	Pruebas.Ejecutar(t, ParserMkfile, "-path=/vacio.txt")
+
+	sb, _, ruta, err := Global.ObtenerSuperblockParticionMontada(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	archivo, err := os.OpenFile(ruta, os.O_RDWR, 0666)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer archivo.Close()
+	resuelta, err := sb.ResolverRuta(archivo, "/vacio.txt", Estructuras.CredencialesRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inodo := &Estructuras.INodo{}
+	if err := inodo.Decodificar(archivo, sb.CalcularDesplazamientoInodo(resuelta.Inodo)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Escribir despues del final llena el hueco con ceros; 14 bloques pasan a la indireccion simple
+	lejos := int64(14 * sb.S_block_size)
+	for _, escritura := range []struct {
+		desplazamiento int64
+		datos          string
+	}{{0, "hola"}, {lejos, "fin"}, {2, "LA"}} {
+		if n, err := inodo.WriteAt(archivo, sb, []byte(escritura.datos), escritura.desplazamiento); err != nil || n != len(escritura.datos) {
+			t.Fatalf("WriteAt(%q, %d) = %d, %v", escritura.datos, escritura.desplazamiento, n, err)
+		}
+	}
+	if inodo.I_size != int32(lejos)+3 {
+		t.Fatalf("I_size = %d, se esperaba %d", inodo.I_size, lejos+3)
+	}
+	if _, err := inodo.WriteAt(archivo, sb, []byte("x"), -1); err == nil {
+		t.Errorf("WriteAt acepto un desplazamiento negativo")
+	}
+
+	casos := []struct {
+		desplazamiento int64
+		largo          int
+		datos          string
+		err            error
+	}{
+		{0, 4, "hoLA", nil},
+		{3, 4, "A\x00\x00\x00", nil},
+		{lejos - 2, 5, "\x00\x00fin", nil},
+		{lejos - 2, 10, "\x00\x00fin", io.EOF}, // lectura parcial al final
+		{lejos + 3, 1, "", io.EOF},
+		{lejos + 100, 1, "", io.EOF},
+		{lejos + 100, 0, "", nil},
+	}
+	for _, caso := range casos {
+		buffer := make([]byte, caso.largo)
+		n, err := inodo.ReadAt(archivo, sb, buffer, caso.desplazamiento)
+		if err != caso.err || !bytes.Equal(buffer[:n], []byte(caso.datos)) {
+			t.Errorf("ReadAt(%d, %d) = %q, %v; se esperaba %q, %v",
+				caso.desplazamiento, caso.largo, buffer[:n], err, caso.datos, caso.err)
+		}
+	}
+	if _, err := inodo.ReadAt(archivo, sb, make([]byte, 1), -1); err == nil {
+		t.Errorf("ReadAt acepto un desplazamiento negativo")
+	}
+
+	// El contenido completo coincide con lo que arma la lectura secuencial
+	completo, err := inodo.LeerDatos(archivo, sb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	esperado := append([]byte("hoLA"), make([]byte, lejos-4)...)
+	esperado = append(esperado, "fin"...)
+	if !bytes.Equal(completo, esperado) {
+		t.Errorf("LeerDatos retorno %d bytes distintos a los escritos", len(completo))
+	}
+}
+
+func TestContenidoBinario(t *testing.T) {
+	id := Pruebas.MontarParticionFormateada(t, "")
+	Pruebas.Ejecutar(t, ParserMkfile, "-path=/datos.bin")
+
+	todos := make([]byte, 256)
+	for i := range todos {
+		todos[i] = byte(i)
+	}
+	casos := []struct {
+		nombre    string
+		contenido []byte
+	}{
+		{"ceros al final", []byte("abc\x00\x00\x00")},
+		{"solo ceros", make([]byte, 200)},
+		{"todos los bytes", bytes.Repeat(todos, 3)},
+		{"espacios al final", []byte("texto   ")},
+	}
+	for _, caso := range casos {
+		reemplazo := filepath.Join(t.TempDir(), "reemplazo.bin")
+		if err := os.WriteFile(reemplazo, caso.contenido, 0644); err != nil {
+			t.Fatal(err)
+		}
+		Pruebas.Ejecutar(t, ParserEdit, "-ruta=/datos.bin -contenido="+reemplazo)
+		if contenido := leerContenido(t, id, "/datos.bin"); contenido != string(caso.contenido) {
+			t.Errorf("%s: se leyeron %d bytes %q", caso.nombre, len(contenido), contenido)
+		}
+	}
+
+	// truncate respeta I_size al recortar y al crecer con ceros
+	for _, caso := range []struct {
+		tamano    string
+		contenido string
+	}{
+		{"-size=5", "texto"},
+		{"-size=8", "texto\x00\x00\x00"},
+		{"-size=0", ""},
+	} {
+		Pruebas.Ejecutar(t, ParserTruncate, "-path=/datos.bin "+caso.tamano)
+		if contenido := leerContenido(t, id, "/datos.bin"); contenido != caso.contenido {
+			t.Errorf("truncate %s: se leyo %q", caso.tamano, contenido)
+		}
+	}
+}
@@ -10,7 +10,6 @@ import (
 
     Estructuras "backend/Estructuras"
     Global "backend/Global"
-    Utils "backend/Utils"
 )
 
 // FIND estructura del comando find con parámetros
@@ -87,17 +86,12 @@ func comandoFind(comandoFind *FIND, bufferSalida *bytes.Buffer) error {
     defer archivo.Close() // Liberar recurso al finalizar
 
     // Determinar inodo de inicio según el path
-    var indiceInodoRaiz int32
-    if comandoFind.path == "/" {
-        indiceInodoRaiz = 0 // Inodo raíz del sistema
-    } else {
-        // Analizar ruta y localizar el inodo correspondiente
-        directoriosPadre, nombreDirectorio := Utils.ObtenerDirectoriosPadre(comandoFind.path)
-        indiceInodoRaiz, err = buscarInodoArchivo(archivo, superBloqueParticion, directoriosPadre, nombreDirectorio)
-        if err != nil {
-            return fmt.Errorf("error al encontrar el directorio inicial: %v", err)
-        }
+    credenciales := Global.CredencialesActuales(archivo, superBloqueParticion)
+    inicio, err := superBloqueParticion.ResolverRuta(archivo, comandoFind.path, credenciales)
+    if err != nil {
+        return fmt.Errorf("error al encontrar el directorio inicial: %w", err)
     }
+    indiceInodoRaiz := inicio.Inodo
 
     // Transformar patrón de búsqueda a expresión regular
     patron, err := comodinARegex(comandoFind.name)
@@ -118,7 +112,7 @@ func comandoFind(comandoFind *FIND, bufferSalida *bytes.Buffer) error {
 func busquedaRecursiva(archivo *os.File, sb *Estructuras.SuperBlock, indiceInodo int32, patron *regexp.Regexp, rutaActual string, bufferSalida *bytes.Buffer) error {
     // Cargar información del inodo actual
     inodo := &Estructuras.INodo{}
-    err := inodo.Decodificar(archivo, int64(sb.S_inode_start+(indiceInodo*sb.S_inode_size)))
+    err := inodo.Decodificar(archivo, sb.CalcularDesplazamientoInodo(indiceInodo))
     if err != nil {
         return fmt.Errorf("error al deserializar el inodo %d: %v", indiceInodo, err)
     }
@@ -128,49 +122,49 @@ func busquedaRecursiva(archivo *os.File, sb *Estructuras.SuperBlock, indiceInodo
         return nil // Salir si no es directorio
     }
 
-    // Recorrer todos los bloques del directorio
-    for _, indiceBloques := range inodo.I_block {
-        if indiceBloques == -1 {
-            break // No hay más bloques asignados
+    // Recorrer todas las entradas del directorio
+    directorio, err := sb.LeerDirectorio(archivo, indiceInodo)
+    if err != nil {
+        return err
+    }
+
+    for _, contenido := range directorio.Entradas() {
+        nombreContenido := contenido.Nombre
+
+        // Omitir referencias de directorio especiales y el archivo de cuotas
+        if contenido.EsEspecial() || directorio.EsOculta(contenido) {
+            continue
         }
 
-        // Cargar contenido del bloque de directorio
-        bloque := &Estructuras.FolderBlock{}
-        err := bloque.Decodificar(archivo, int64(sb.S_block_start+(indiceBloques*sb.S_block_size)))
-        if err != nil {
-            return fmt.Errorf("error al deserializar el bloque %d: %v", indiceBloques, err)
+        // Evaluar si el nombre cumple con el patrón
+        if patron.MatchString(nombreContenido) {
+            fmt.Fprintf(bufferSalida, "%s/%s%s\n", rutaActual, nombreContenido, destinoEnlace(archivo, sb, contenido.Inodo))
         }
 
-        // Examinar cada entrada del bloque
-        for _, contenido := range bloque.B_cont {
-            if contenido.B_inodo == -1 {
-                continue // Saltar entradas vacías
-            }
-
-            nombreContenido := strings.Trim(string(contenido.B_name[:]), "\x00 ")
-
-            // Omitir referencias de directorio especiales
-            if nombreContenido == "." || nombreContenido == ".." {
-                continue
-            }
-
-            // Evaluar si el nombre cumple con el patrón
-            if patron.MatchString(nombreContenido) {
-                fmt.Fprintf(bufferSalida, "%s/%s\n", rutaActual, nombreContenido)
-            }
-
-            // Continuar búsqueda en subdirectorios
-            nuevoIndiceInodo := contenido.B_inodo
-            err = busquedaRecursiva(archivo, sb, nuevoIndiceInodo, patron, rutaActual+"/"+nombreContenido, bufferSalida)
-            if err != nil {
-                return err
-            }
+        // Continuar búsqueda en subdirectorios (los enlaces simbólicos no se siguen)
+        nuevoIndiceInodo := contenido.Inodo
+        err = busquedaRecursiva(archivo, sb, nuevoIndiceInodo, patron, rutaActual+"/"+nombreContenido, bufferSalida)
+        if err != nil {
+            return err
         }
     }
 
     return nil
 }
 
+// destinoEnlace retorna " -> destino" si el inodo es un enlace simbólico
+func destinoEnlace(archivo *os.File, sb *Estructuras.SuperBlock, indiceInodo int32) string {
+    inodo := &Estructuras.INodo{}
+    if err := inodo.Decodificar(archivo, sb.CalcularDesplazamientoInodo(indiceInodo)); err != nil || !inodo.EsEnlaceSimbolico() {
+        return ""
+    }
+    destino, err := sb.LeerDestinoEnlace(archivo, inodo)
+    if err != nil {
+        return ""
+    }
+    return " -> " + destino
+}
+
 func comodinARegex(patron string) (*regexp.Regexp, error) {
     // Convertir caracteres especiales a regex válido
     patron = strings.ReplaceAll(patron, ".", "\\.")
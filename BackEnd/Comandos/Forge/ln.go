@@ -0,0 +1,122 @@
+package Forge
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	Global "backend/Global"
+)
+
+// LN estructura del comando ln con sus parametros
+type LN struct {
+	ruta      string // Elemento existente (enlace duro) o destino guardado en el enlace (-s)
+	destino   string // Ruta del nuevo nombre
+	simbolico bool   // Crear un enlace simbolico en lugar de uno duro
+}
+
+// ParserLn parsea el comando ln y ejecuta la creacion del enlace
+func ParserLn(tokens []string) (string, error) {
+	cmd := &LN{}
+	var bufferSalida bytes.Buffer
+
+	// Expresion regular para capturar los parametros -path, -dest y la bandera -s
+	re := regexp.MustCompile(`-path="[^"]+"|-path=[^\s]+|-dest="[^"]+"|-dest=[^\s]+|-s\b`)
+	matches := re.FindAllString(strings.Join(tokens, " "), -1)
+	if len(matches) != len(tokens) {
+		for _, token := range tokens {
+			if !re.MatchString(token) {
+				return "", fmt.Errorf("parametro invalido: %s", token)
+			}
+		}
+	}
+
+	for _, match := range matches {
+		kv := strings.SplitN(match, "=", 2)
+		key := strings.ToLower(kv[0])
+		if key == "-s" {
+			cmd.simbolico = true
+			continue
+		}
+		value := strings.Trim(kv[1], "\"")
+		switch key {
+		case "-path":
+			cmd.ruta = value
+		case "-dest":
+			cmd.destino = value
+		}
+	}
+
+	if cmd.ruta == "" || cmd.destino == "" {
+		return "", errors.New("los parametros -path y -dest son obligatorios")
+	}
+
+	err := comandoLn(cmd, &bufferSalida)
+	if err != nil {
+		return "", err
+	}
+	return bufferSalida.String(), nil
+}
+
+// comandoLn crea el nuevo nombre en la carpeta que contiene a -dest
+func comandoLn(ln *LN, bufferSalida *bytes.Buffer) error {
+	fmt.Fprint(bufferSalida, "========================= LN =========================\n")
+
+	if !Global.VerificarSesionActiva() {
+		return fmt.Errorf("no hay un usuario logueado")
+	}
+
+	idParticion := Global.UsuarioActual.Id
+	superBloqueParticion, particionMontada, rutaParticion, err := Global.ObtenerSuperblockParticionMontada(idParticion)
+	if err != nil {
+		return fmt.Errorf("error al obtener la particion montada: %w", err)
+	}
+
+	archivo, err := os.OpenFile(rutaParticion, os.O_RDWR, 0666)
+	if err != nil {
+		return fmt.Errorf("error al abrir el archivo de particion: %w", err)
+	}
+	defer archivo.Close()
+
+	// Carpeta que recibira el nuevo nombre; se necesita permiso de escritura sobre ella
+	credenciales := Global.CredencialesActuales(archivo, superBloqueParticion)
+	indiceCarpeta, nombre, err := superBloqueParticion.ResolverPadre(archivo, ln.destino, credenciales)
+	if err != nil {
+		return fmt.Errorf("error al resolver la carpeta destino de '%s': %w", ln.destino, err)
+	}
+	if !verificarPermisosEscritura(archivo, superBloqueParticion, indiceCarpeta) {
+		return fmt.Errorf("no tiene permisos de escritura sobre la carpeta de '%s'", ln.destino)
+	}
+
+	if ln.simbolico {
+		// El destino se guarda tal cual; puede no existir todavia
+		indiceEnlace, err := superBloqueParticion.CrearEnlaceSimbolico(archivo, indiceCarpeta, nombre, ln.ruta, credenciales.Uid, credenciales.Gid)
+		if err != nil {
+			return fmt.Errorf("error al crear el enlace simbolico '%s': %w", ln.destino, err)
+		}
+		fmt.Fprintf(bufferSalida, "Enlace simbolico '%s' -> '%s' creado (inodo %d)\n", ln.destino, ln.ruta, indiceEnlace)
+	} else {
+		// Un enlace duro a un enlace simbolico nombra al enlace, no a su destino
+		origen, err := superBloqueParticion.ResolverRutaSinSeguir(archivo, ln.ruta, credenciales)
+		if err != nil {
+			return fmt.Errorf("error al resolver '%s': %w", ln.ruta, err)
+		}
+		if origen.EsDirectorio {
+			return fmt.Errorf("'%s' es una carpeta: no se permiten enlaces duros a carpetas", ln.ruta)
+		}
+		if err := superBloqueParticion.CrearEnlaceDuro(archivo, indiceCarpeta, nombre, origen.Inodo); err != nil {
+			return fmt.Errorf("error al crear el enlace '%s': %w", ln.destino, err)
+		}
+		fmt.Fprintf(bufferSalida, "Enlace duro '%s' creado hacia el inodo %d de '%s'\n", ln.destino, origen.Inodo, ln.ruta)
+	}
+
+	if err := superBloqueParticion.Codificar(archivo, int64(particionMontada.Part_start)); err != nil {
+		return fmt.Errorf("error al guardar el superbloque: %w", err)
+	}
+
+	fmt.Fprint(bufferSalida, "======================================================\n")
+	return nil
+}
@@ -0,0 +1,155 @@
+package Forge
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	Pruebas "backend/Comandos/Pruebas"
+	Estructuras "backend/Estructuras"
+	Global "backend/Global"
+)
+
+func TestSistemaArchivosConEnlaces(t *testing.T) {
+	id := Pruebas.MontarParticionFormateada(t, "")
+	Pruebas.Ejecutar(t, ParserMkdir, "-p -path=/home/docs")
+	Pruebas.Ejecutar(t, ParserMkfile, "-path=/home/docs/a.txt -size=30")
+	Pruebas.Ejecutar(t, ParserLn, "-s -path=/home/docs/a.txt -dest=/home/atajo")
+	Pruebas.Ejecutar(t, ParserLn, "-s -path=/home/docs -dest=/home/carpeta")
+
+	sistema, err := Global.SistemaArchivosMontado(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sistema.Close()
+
+	if err := fstest.TestFS(sistema, "users.txt", "home/docs/a.txt", "home/atajo", "home/carpeta"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Un enlace roto se lista igual que en os.DirFS, aunque Open no lo pueda abrir
+	Pruebas.Ejecutar(t, ParserLn, "-s -path=/home/noexiste -dest=/home/roto")
+	entradas, err := fs.ReadDir(sistema, "home")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var nombres []string
+	for _, entrada := range entradas {
+		nombres = append(nombres, entrada.Name())
+		enlace := entrada.Name() != "docs"
+		if (entrada.Type()&fs.ModeSymlink != 0) != enlace || entrada.IsDir() == enlace {
+			t.Errorf("ReadDir(home): %s tiene el tipo %v", entrada.Name(), entrada.Type())
+		}
+	}
+	if strings.Join(nombres, ",") != "atajo,carpeta,docs,roto" {
+		t.Errorf("ReadDir(home) = %v", nombres)
+	}
+	if _, err := sistema.Open("home/roto"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Open(home/roto) = %v, se esperaba fs.ErrNotExist", err)
+	}
+	if destino, err := sistema.ReadLink("home/roto"); err != nil || destino != "/home/noexiste" {
+		t.Errorf("ReadLink(home/roto) = %q, %v", destino, err)
+	}
+
+	// WalkDir no entra en el enlace a la carpeta
+	var recorridas []string
+	err = fs.WalkDir(sistema, "home", func(ruta string, entrada fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		recorridas = append(recorridas, ruta)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(recorridas, ",") != "home,home/atajo,home/carpeta,home/docs,home/docs/a.txt,home/roto" {
+		t.Errorf("WalkDir(home) = %v", recorridas)
+	}
+}
+
+func TestEnlacesDuros(t *testing.T) {
+	id := Pruebas.MontarParticionFormateada(t, "")
+	Pruebas.Ejecutar(t, ParserMkdir, "-path=/docs")
+	Pruebas.Ejecutar(t, ParserMkfile, "-path=/docs/a.txt -size=20")
+	Pruebas.Ejecutar(t, ParserLn, "-path=/docs/a.txt -dest=/copia.txt")
+	Pruebas.Ejecutar(t, ParserLn, "-path=/copia.txt -dest=/docs/otra.txt")
+	if _, err := ParserLn(strings.Fields("-path=/docs -dest=/carpeta")); err == nil {
+		t.Errorf("ln permitio un enlace duro a una carpeta")
+	}
+
+	sb, _, ruta, err := Global.ObtenerSuperblockParticionMontada(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	archivo, err := os.OpenFile(ruta, os.O_RDWR, 0666)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer archivo.Close()
+	enlaces := func(ruta string) (int32, int32) {
+		t.Helper()
+		resuelta, err := sb.ResolverRuta(archivo, ruta, Estructuras.CredencialesRoot)
+		if err != nil {
+			t.Fatalf("%s: %v", ruta, err)
+		}
+		inodo := &Estructuras.INodo{}
+		if err := inodo.Decodificar(archivo, sb.CalcularDesplazamientoInodo(resuelta.Inodo)); err != nil {
+			t.Fatal(err)
+		}
+		return resuelta.Inodo, inodo.I_links
+	}
+	original, links := enlaces("/docs/a.txt")
+	if links != 3 {
+		t.Errorf("a.txt tiene %d enlaces, se esperaban 3", links)
+	}
+
+	// Editar por un nombre cambia el contenido de todos
+	reemplazo := filepath.Join(t.TempDir(), "reemplazo.txt")
+	if err := os.WriteFile(reemplazo, []byte("contenido compartido"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	Pruebas.Ejecutar(t, ParserEdit, "-ruta=/docs/otra.txt -contenido="+reemplazo)
+
+	// Cada remove quita un enlace; el inodo se libera con el ultimo
+	usados := sb.S_inodes_count
+	nombres := []string{"/docs/a.txt", "/copia.txt", "/docs/otra.txt"}
+	for i, nombre := range nombres {
+		restantes := nombres[i:]
+		for _, ruta := range restantes {
+			if inodo, links := enlaces(ruta); inodo != original || links != int32(len(restantes)) {
+				t.Errorf("%s: inodo %d con %d enlaces, se esperaba %d con %d", ruta, inodo, links, original, len(restantes))
+			}
+			if contenido := leerContenido(t, id, ruta); contenido != "contenido compartido" {
+				t.Errorf("%s contiene %q", ruta, contenido)
+			}
+		}
+		Pruebas.Ejecutar(t, ParserRemove, "-path="+nombre)
+	}
+	sb, _, _, err = Global.ObtenerSuperblockParticionMontada(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sb.S_inodes_count != usados-1 {
+		t.Errorf("quedan %d inodos usados, se esperaban %d", sb.S_inodes_count, usados-1)
+	}
+}
+
+// leerContenido lee un archivo de la particion montada por medio de io/fs
+func leerContenido(t *testing.T, id, ruta string) string {
+	t.Helper()
+	sistema, err := Global.SistemaArchivosMontado(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sistema.Close()
+	contenido, err := fs.ReadFile(sistema, strings.TrimPrefix(ruta, "/"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(contenido)
+}
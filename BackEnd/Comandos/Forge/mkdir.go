@@ -116,8 +116,16 @@ func comandoMkdir(mkdir *MKDIR, bufferSalida *bytes.Buffer) error {
 }
 
 func crearDirectorio(rutaDirectorio string, crearPadres bool, sb *Estructuras.SuperBlock, archivo *os.File, particionMontada *Estructuras.Particion) error {
+	credenciales := Global.CredencialesActuales(archivo, sb)
+
 	// Si el parámetro -p está habilitado, crear los directorios intermedios recursivamente
 	if crearPadres {
+		// Basta con poder recorrer la parte de la ruta que ya existe
+		_, err := sb.ResolverRuta(archivo, rutaDirectorio, credenciales)
+		if err != nil && !errors.Is(err, Estructuras.ErrRutaNoEncontrada) {
+			return err
+		}
+
 		// Utilizamos `CrearCarpetaRecursivamente` para crear los directorios si no existen
 		if err := sb.CrearCarpetaRecursivamente(archivo, rutaDirectorio, true); err != nil {
 			return fmt.Errorf("error al crear los directorios recursivamente: %w", err)
@@ -138,11 +146,10 @@ func crearDirectorio(rutaDirectorio string, crearPadres bool, sb *Estructuras.Su
 	}
 
 	// Si no se habilita el parámetro -p, asegurarse de que los directorios padres existan
-	directoriosPadre, directorioDestino := Utils.ObtenerDirectoriosPadre(rutaDirectorio)
-	// Verificar que todos los directorios padres existen
-	if err := verifyParentDirectoriesExist(sb, archivo, directoriosPadre); err != nil {
+	if _, _, err := sb.ResolverPadre(archivo, rutaDirectorio, credenciales); err != nil {
 		return err
 	}
+	directoriosPadre, directorioDestino := Utils.ObtenerDirectoriosPadre(rutaDirectorio)
 
 	// Crear el directorio final
 	if err := sb.CrearCarpeta(archivo, directoriosPadre, directorioDestino, true); err != nil {
@@ -163,11 +170,3 @@ func crearDirectorio(rutaDirectorio string, crearPadres bool, sb *Estructuras.Su
 	return nil
 }
 
-// verifyParentDirectoriesExist verifica que cada directorio en la lista exista en la partición
-func verifyParentDirectoriesExist(sb *Estructuras.SuperBlock, archivo *os.File, directoriosPadre []string) error {
-	_, err := sb.BuscarInodoDirectorio(archivo, directoriosPadre)
-	if err != nil {
-		return err
-	}
-	return nil
-}
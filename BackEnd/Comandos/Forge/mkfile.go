@@ -21,6 +21,7 @@ type MKFILE struct {
 	r         bool   // Opcion recursiva
 	tamaño    int    // Dimension del archivo
 	contenido string // Contenido del archivo
+	origen    string // Archivo del sistema anfitrion cuyo contenido se importa tal cual
 }
 
 // Procesa el comando mkfile y devuelve una instancia de MKFILE
@@ -29,7 +30,7 @@ func ParserMkfile(tokens []string) (string, error) {
 	var bufferSalida bytes.Buffer // Buffer para capturar mensajes importantes
 
 	argumentos := strings.Join(tokens, " ")
-	re := regexp.MustCompile(`-path="[^"]+"|-path=[^\s]+|-r|-size=\d+|-cont="[^"]+"|-cont=[^\s]+`)
+	re := regexp.MustCompile(`-path="[^"]+"|-path=[^\s]+|-r|-size=\d+|-cont="[^"]+"|-cont=[^\s]+|-file="[^"]+"|-file=[^\s]+`)
 	coincidencias := re.FindAllString(argumentos, -1)
 
 	if len(coincidencias) != len(tokens) {
@@ -73,6 +74,11 @@ func ParserMkfile(tokens []string) (string, error) {
 				return "", errors.New("el contenido no puede estar vacio")
 			}
 			cmd.contenido = valor
+		case "-file":
+			if valor == "" {
+				return "", errors.New("la ruta del archivo a importar no puede estar vacia")
+			}
+			cmd.origen = valor
 		default:
 			return "", fmt.Errorf("parametro desconocido: %s", clave)
 		}
@@ -81,6 +87,9 @@ func ParserMkfile(tokens []string) (string, error) {
 	if cmd.ruta == "" {
 		return "", errors.New("faltan parametros requeridos: -path")
 	}
+	if cmd.contenido != "" && cmd.origen != "" {
+		return "", errors.New("los parametros -cont y -file no pueden usarse juntos")
+	}
 
 	if cmd.tamaño == 0 {
 		cmd.tamaño = 0
@@ -116,9 +125,19 @@ func comandoMkfile(mkfile *MKFILE, bufferSalida *bytes.Buffer) error {
 		return fmt.Errorf("error al obtener la particion montada: %w", err)
 	}
 
-	// Generar contenido del archivo si no se proporciono
-	if mkfile.contenido == "" {
-		mkfile.contenido = generarContenido(mkfile.tamaño)
+	// El contenido importado se copia byte por byte; sin -cont ni -file se genera
+	var contenido []byte
+	switch {
+	case mkfile.origen != "":
+		contenido, err = os.ReadFile(mkfile.origen)
+		if err != nil {
+			return fmt.Errorf("error al leer el archivo a importar '%s': %w", mkfile.origen, err)
+		}
+		mkfile.tamaño = len(contenido)
+	case mkfile.contenido != "":
+		contenido = []byte(mkfile.contenido)
+	default:
+		contenido = []byte(generarContenido(mkfile.tamaño))
 	}
 
 	// Abrir archivo de particion para operar
@@ -137,7 +156,14 @@ func comandoMkfile(mkfile *MKFILE, bufferSalida *bytes.Buffer) error {
     directoriosPadre, _ := Utils.ObtenerDirectoriosPadre(mkfile.ruta)
 
     // Verificar si el directorio existe, y si -r está habilitado, crearlo recursivamente
+    credenciales := Global.CredencialesActuales(archivo, superBloqueParticion)
     if mkfile.r {
+        // Basta con poder recorrer la parte de la ruta que ya existe
+        _, err := superBloqueParticion.ResolverRuta(archivo, mkfile.ruta, credenciales)
+        if err != nil && !errors.Is(err, Estructuras.ErrRutaNoEncontrada) {
+            return err
+        }
+
         fmt.Fprintf(bufferSalida, "Creando directorios intermedios si es necesario: %s\n", strings.Join(directoriosPadre, "/"))
         err = superBloqueParticion.CrearCarpetaRecursivamente(archivo, strings.Join(directoriosPadre, "/"), true)
         if err != nil {
@@ -145,13 +171,13 @@ func comandoMkfile(mkfile *MKFILE, bufferSalida *bytes.Buffer) error {
         }
     } else {
         fmt.Fprintf(bufferSalida, "Verificando si el directorio '%s' existe...\n", strings.Join(directoriosPadre, "/"))
-        _, err := buscarInodoCarpeta(archivo, superBloqueParticion, directoriosPadre)
+        _, _, err := superBloqueParticion.ResolverPadre(archivo, mkfile.ruta, credenciales)
         if err != nil {
             return fmt.Errorf("el directorio '%s' no existe y no se ha especificado la opcion -r: %w", strings.Join(directoriosPadre, "/"), err)
         }
     }
 
-	err = crearArchivo(mkfile.ruta, mkfile.tamaño, mkfile.contenido, superBloqueParticion, archivo, particionMontada, bufferSalida)
+	err = crearArchivo(mkfile.ruta, mkfile.tamaño, contenido, superBloqueParticion, archivo, particionMontada, bufferSalida)
 	if err != nil {
 		return fmt.Errorf("error al crear el archivo: %w", err)
 	}
@@ -172,17 +198,15 @@ func generarContenido(dimension int) string {
 }
 
 // crearArchivo utiliza archivo de particion ya abierto
-func crearArchivo(rutaArchivo string, dimension int, contenido string, sb *Estructuras.SuperBlock, archivo *os.File, particionMontada *Estructuras.Particion, bufferSalida *bytes.Buffer) error {
+func crearArchivo(rutaArchivo string, dimension int, contenido []byte, sb *Estructuras.SuperBlock, archivo *os.File, particionMontada *Estructuras.Particion, bufferSalida *bytes.Buffer) error {
 	fmt.Fprintf(bufferSalida, "Creando archivo en la ruta: %s\n", rutaArchivo)
 
 	// Obtener directorios padre y destino
 	directoriosPadre, directorioDestino := Utils.ObtenerDirectoriosPadre(rutaArchivo)
-	// Obtener contenido por fragmentos
-	fragmentos := Utils.DividirCadenaEnChunks(contenido)
-	fmt.Fprintf(bufferSalida, "Contenido generado: %v\n", fragmentos)
+	fmt.Fprintf(bufferSalida, "Contenido: %d bytes\n", len(contenido))
 
 	// Crear archivo en sistema de archivos
-	err := sb.CrearArchivo(archivo, directoriosPadre, directorioDestino, dimension, fragmentos, false)
+	err := sb.CrearArchivo(archivo, directoriosPadre, directorioDestino, dimension, contenido, false)
 	if err != nil {
 		return fmt.Errorf("error al crear el archivo: %w", err)
 	}
@@ -10,7 +10,6 @@ import (
 
     Estructuras "backend/Estructuras"
     Global "backend/Global"
-    Utils "backend/Utils"
 )
 
 // MOVE estructura del comando move con parámetros
@@ -78,7 +77,7 @@ func comandoMove(comandoMove *MOVE, bufferSalida *bytes.Buffer) error {
     idParticion := Global.UsuarioActual.Id
 
     // Obtener la partición montada asociada al usuario logueado
-    superBloqueParticion, _, rutaParticion, err := Global.ObtenerSuperblockParticionMontada(idParticion)
+    superBloqueParticion, particionMontada, rutaParticion, err := Global.ObtenerSuperblockParticionMontada(idParticion)
     if err != nil {
         return fmt.Errorf("error al obtener la partición montada: %w", err)
     }
@@ -93,11 +92,19 @@ func comandoMove(comandoMove *MOVE, bufferSalida *bytes.Buffer) error {
     fmt.Fprintf(bufferSalida, "Moviendo desde: %s\n", comandoMove.path)
     fmt.Fprintf(bufferSalida, "Hacia destino: %s\n", comandoMove.destino)
 
-    // Verificar que la ruta origen existe y obtener información
-    directoriosPadreOrigen, nombreOrigen := Utils.ObtenerDirectoriosPadre(comandoMove.path)
-    indiceInodoOrigen, err := buscarElementoParaMove(archivo, superBloqueParticion, directoriosPadreOrigen, nombreOrigen)
+    // Verificar que la ruta origen existe y obtener información (un enlace simbólico se
+    // mueve a sí mismo, no a su destino)
+    credenciales := Global.CredencialesActuales(archivo, superBloqueParticion)
+    origen, err := superBloqueParticion.ResolverRutaSinSeguir(archivo, comandoMove.path, credenciales)
     if err != nil {
-        return fmt.Errorf("error: la ruta origen '%s' no existe: %w", comandoMove.path, err)
+        return fmt.Errorf("error al resolver la ruta origen '%s': %w", comandoMove.path, err)
+    }
+    if origen.Inodo == 0 || origen.Nombre == "." || origen.Nombre == ".." {
+        return fmt.Errorf("error: la ruta origen '%s' no nombra un elemento que se pueda mover", comandoMove.path)
+    }
+    indiceInodoOrigen, nombreOrigen := origen.Inodo, origen.Nombre
+    if err := credenciales.RevisarArchivoSistema(comandoMove.path, origen.Padre, nombreOrigen); err != nil {
+        return err
     }
 
     // Verificar permisos de escritura en el elemento origen
@@ -105,17 +112,16 @@ func comandoMove(comandoMove *MOVE, bufferSalida *bytes.Buffer) error {
         return fmt.Errorf("error: no tiene permisos de escritura sobre '%s'", comandoMove.path)
     }
 
-    // Obtener el inodo del directorio padre origen
-    indiceInodoPadreOrigen, err := buscarInodoCarpeta(archivo, superBloqueParticion, directoriosPadreOrigen)
-    if err != nil {
-        return fmt.Errorf("error al encontrar directorio padre origen: %w", err)
-    }
+    // El directorio padre origen es el que contiene la entrada resuelta
+    indiceInodoPadreOrigen := origen.Padre
 
     // Verificar que el directorio destino existe
-    directoriosPadreDestino, _ := Utils.ObtenerDirectoriosPadre(comandoMove.destino)
-    indiceInodoDestino, err := buscarInodoCarpeta(archivo, superBloqueParticion, directoriosPadreDestino)
+    indiceInodoDestino, err := superBloqueParticion.ResolverDirectorio(archivo, comandoMove.destino, credenciales)
     if err != nil {
-        return fmt.Errorf("error: el directorio destino '%s' no existe: %w", comandoMove.destino, err)
+        return fmt.Errorf("error al resolver el directorio destino '%s': %w", comandoMove.destino, err)
+    }
+    if err := credenciales.RevisarArchivoSistema(comandoMove.destino, indiceInodoDestino, nombreOrigen); err != nil {
+        return err
     }
 
     // Verificar permisos de escritura en el directorio destino
@@ -124,7 +130,7 @@ func comandoMove(comandoMove *MOVE, bufferSalida *bytes.Buffer) error {
     }
 
     // Verificar que no exista un elemento con el mismo nombre en el destino
-    encontrado, _, err := directorioExiste(superBloqueParticion, archivo, indiceInodoDestino, nombreOrigen)
+    _, encontrado, err := superBloqueParticion.BuscarEntrada(archivo, indiceInodoDestino, nombreOrigen)
     if err != nil {
         return fmt.Errorf("error al verificar destino: %w", err)
     }
@@ -138,44 +144,28 @@ func comandoMove(comandoMove *MOVE, bufferSalida *bytes.Buffer) error {
         return fmt.Errorf("error durante el movimiento: %w", err)
     }
 
-    fmt.Fprintf(bufferSalida, "Movimiento completado exitosamente\n")
-    fmt.Fprint(bufferSalida, "=====================================================\n")
-
-    return nil
-}
-
-// buscarElementoParaMove busca un archivo o directorio y retorna su inodo
-func buscarElementoParaMove(archivo *os.File, sb *Estructuras.SuperBlock, directoriosPadre []string, nombreElemento string) (int32, error) {
-    // Buscar el directorio padre
-    indiceInodoPadre, err := buscarInodoCarpeta(archivo, sb, directoriosPadre)
+    // Agregar la entrada en el destino puede asignar un bloque de directorio
+    err = superBloqueParticion.Codificar(archivo, int64(particionMontada.Part_start))
     if err != nil {
-        return -1, err
+        return fmt.Errorf("error al guardar el superbloque: %w", err)
     }
 
-    // Buscar el elemento en el directorio padre
-    encontrado, indiceInodoElemento, err := directorioExiste(sb, archivo, indiceInodoPadre, nombreElemento)
-    if err != nil {
-        return -1, err
-    }
-    if !encontrado {
-        return -1, fmt.Errorf("elemento '%s' no encontrado", nombreElemento)
-    }
+    fmt.Fprintf(bufferSalida, "Movimiento completado exitosamente\n")
+    fmt.Fprint(bufferSalida, "=====================================================\n")
 
-    return indiceInodoElemento, nil
+    return nil
 }
 
 // verificarPermisosEscrituraMove verifica si el usuario actual tiene permisos de escritura
 func verificarPermisosEscrituraMove(archivo *os.File, sb *Estructuras.SuperBlock, indiceInodo int32) bool {
     inodo := &Estructuras.INodo{}
-    err := inodo.Decodificar(archivo, int64(sb.S_inode_start+(indiceInodo*sb.S_inode_size)))
+    err := inodo.Decodificar(archivo, sb.CalcularDesplazamientoInodo(indiceInodo))
     if err != nil {
         return false
     }
 
-    // Verificar permisos según el owner y group
-    // Para este ejemplo, asumimos que el permiso '6' y '2' incluyen escritura
-    permisos := string(inodo.I_perm[:])
-    return strings.Contains(permisos, "6") || strings.Contains(permisos, "2")
+    // Evaluar el digito de propietario, grupo u otros que corresponde al usuario
+    return Global.CredencialesActuales(archivo, sb).Permite(inodo, Estructuras.PermisoEscritura)
 }
 
 // moverElemento realiza el movimiento cambiando las referencias entre directorios
@@ -207,100 +197,46 @@ func moverElemento(archivo *os.File, sb *Estructuras.SuperBlock, indiceInodoPadr
     return nil
 }
 
-// eliminarEntradaDirectorio elimina una entrada específica de un directorio
+// eliminarEntradaDirectorio elimina una entrada específica de un directorio junto con
+// los contenidos de continuación de su nombre
 func eliminarEntradaDirectorio(archivo *os.File, sb *Estructuras.SuperBlock, indiceInodoDirectorio int32, nombreEntrada string) error {
-    // Cargar inodo del directorio
-    inodo := &Estructuras.INodo{}
-    err := inodo.Decodificar(archivo, int64(sb.S_inode_start+(indiceInodoDirectorio*sb.S_inode_size)))
+    directorio, err := sb.LeerDirectorio(archivo, indiceInodoDirectorio)
     if err != nil {
         return fmt.Errorf("error al cargar inodo del directorio: %w", err)
     }
 
-    // Buscar la entrada en los bloques del directorio
-    for _, indiceBloques := range inodo.I_block {
-        if indiceBloques == -1 {
-            break
-        }
-
-        // Cargar bloque
-        bloque := &Estructuras.FolderBlock{}
-        err := bloque.Decodificar(archivo, int64(sb.S_block_start+(indiceBloques*sb.S_block_size)))
-        if err != nil {
-            continue
-        }
-
-        // Buscar la entrada a eliminar
-        for i := range bloque.B_cont {
-            nombreContenido := strings.Trim(string(bloque.B_cont[i].B_name[:]), "\x00 ")
-            if strings.EqualFold(nombreContenido, nombreEntrada) && bloque.B_cont[i].B_inodo != -1 {
-                // Eliminar entrada (marcar como libre)
-                bloque.B_cont[i].B_inodo = -1
-                for j := range bloque.B_cont[i].B_name {
-                    bloque.B_cont[i].B_name[j] = 0
-                }
-
-                // Guardar bloque modificado
-                err = bloque.Codificar(archivo, int64(sb.S_block_start+(indiceBloques*sb.S_block_size)))
-                if err != nil {
-                    return fmt.Errorf("error al guardar bloque modificado: %w", err)
-                }
-
-                return nil
-            }
-        }
+    entrada, existe := directorio.Buscar(nombreEntrada)
+    if !existe || entrada.EsEspecial() {
+        return fmt.Errorf("entrada '%s' no encontrada para eliminar", nombreEntrada)
     }
 
-    return fmt.Errorf("entrada '%s' no encontrada para eliminar", nombreEntrada)
+    directorio.Eliminar(entrada)
+    if err := directorio.Guardar(archivo, sb); err != nil {
+        return fmt.Errorf("error al guardar bloque modificado: %w", err)
+    }
+    return nil
 }
 
 // agregarEntradaDirectorioMove agrega una nueva entrada a un directorio (específica para move)
 func agregarEntradaDirectorioMove(archivo *os.File, sb *Estructuras.SuperBlock, indiceInodoDirectorio int32, nombreEntrada string, indiceInodoEntrada int32) error {
-    // Cargar inodo del directorio
-    inodo := &Estructuras.INodo{}
-    err := inodo.Decodificar(archivo, int64(sb.S_inode_start+(indiceInodoDirectorio*sb.S_inode_size)))
+    directorio, err := sb.LeerDirectorio(archivo, indiceInodoDirectorio)
     if err != nil {
         return fmt.Errorf("error al cargar inodo del directorio: %w", err)
     }
 
-    // Buscar espacio libre en los bloques existentes
-    for _, indiceBloques := range inodo.I_block {
-        if indiceBloques == -1 {
-            break
-        }
-
-        // Cargar bloque
-        bloque := &Estructuras.FolderBlock{}
-        err := bloque.Decodificar(archivo, int64(sb.S_block_start+(indiceBloques*sb.S_block_size)))
-        if err != nil {
-            continue
-        }
-
-        // Buscar entrada libre
-        for i := range bloque.B_cont {
-            if bloque.B_cont[i].B_inodo == -1 {
-                // Entrada libre encontrada
-                copy(bloque.B_cont[i].B_name[:], nombreEntrada)
-                bloque.B_cont[i].B_inodo = indiceInodoEntrada
-
-                // Guardar bloque modificado
-                err = bloque.Codificar(archivo, int64(sb.S_block_start+(indiceBloques*sb.S_block_size)))
-                if err != nil {
-                    return fmt.Errorf("error al guardar bloque modificado: %w", err)
-                }
-
-                return nil
-            }
-        }
+    if err := directorio.Agregar(archivo, sb, nombreEntrada, indiceInodoEntrada); err != nil {
+        return err
     }
-
-    // Si llegamos aquí, necesitamos un nuevo bloque (simplificado: error)
-    return fmt.Errorf("no hay espacio libre en el directorio destino")
+    if err := directorio.Guardar(archivo, sb); err != nil {
+        return fmt.Errorf("error al guardar bloque modificado: %w", err)
+    }
+    return nil
 }
 
 // esDirectorio verifica si un inodo corresponde a un directorio
 func esDirectorio(archivo *os.File, sb *Estructuras.SuperBlock, indiceInodo int32) bool {
     inodo := &Estructuras.INodo{}
-    err := inodo.Decodificar(archivo, int64(sb.S_inode_start+(indiceInodo*sb.S_inode_size)))
+    err := inodo.Decodificar(archivo, sb.CalcularDesplazamientoInodo(indiceInodo))
     if err != nil {
         return false
     }
@@ -310,38 +246,20 @@ func esDirectorio(archivo *os.File, sb *Estructuras.SuperBlock, indiceInodo int3
 
 // actualizarEntradaPadreDotDot actualiza la entrada ".." de un directorio para que apunte al nuevo padre
 func actualizarEntradaPadreDotDot(archivo *os.File, sb *Estructuras.SuperBlock, indiceInodoDirectorio int32, nuevoIndiceInodoPadre int32) error {
-    // Cargar inodo del directorio
-    inodo := &Estructuras.INodo{}
-    err := inodo.Decodificar(archivo, int64(sb.S_inode_start+(indiceInodoDirectorio*sb.S_inode_size)))
+    directorio, err := sb.LeerDirectorio(archivo, indiceInodoDirectorio)
     if err != nil {
         return fmt.Errorf("error al cargar inodo del directorio: %w", err)
     }
 
-    // Buscar en el primer bloque (donde debería estar "..")
-    if inodo.I_block[0] != -1 {
-        // Cargar primer bloque
-        bloque := &Estructuras.FolderBlock{}
-        err := bloque.Decodificar(archivo, int64(sb.S_block_start+(inodo.I_block[0]*sb.S_block_size)))
-        if err != nil {
-            return fmt.Errorf("error al cargar primer bloque: %w", err)
-        }
-
-        // Actualizar entrada ".." (debería estar en índice 1)
-        if len(bloque.B_cont) > 1 {
-            nombreSegundaEntrada := strings.Trim(string(bloque.B_cont[1].B_name[:]), "\x00 ")
-            if nombreSegundaEntrada == ".." {
-                bloque.B_cont[1].B_inodo = nuevoIndiceInodoPadre
-
-                // Guardar bloque modificado
-                err = bloque.Codificar(archivo, int64(sb.S_block_start+(inodo.I_block[0]*sb.S_block_size)))
-                if err != nil {
-                    return fmt.Errorf("error al guardar bloque con entrada padre actualizada: %w", err)
-                }
-
-                return nil
-            }
-        }
+    // Actualizar la entrada "..", que siempre ocupa un solo contenido
+    entrada, existe := directorio.Buscar("..")
+    if !existe {
+        return fmt.Errorf("no se pudo encontrar la entrada '..' para actualizar")
     }
+    directorio.ActualizarInodo(entrada, nuevoIndiceInodoPadre)
 
-    return fmt.Errorf("no se pudo encontrar la entrada '..' para actualizar")
+    if err := directorio.Guardar(archivo, sb); err != nil {
+        return fmt.Errorf("error al guardar bloque con entrada padre actualizada: %w", err)
+    }
+    return nil
 }
\ No newline at end of file
@@ -93,18 +93,28 @@ func comandoRemove(cmdRemover *REMOVE, bufferSalida *bytes.Buffer) error {
 
 // eliminarArchivoOCarpeta elimina un archivo o carpeta dada la ruta
 func eliminarArchivoOCarpeta(rutaCompleta string, sb *Estructuras.SuperBlock, archivo *os.File) error {
+    // Resolver el elemento para saber si es archivo o carpeta; un enlace simbolico se
+    // elimina a si mismo, no a su destino (aunque la ruta termine en '/')
+    credenciales := Global.CredencialesActuales(archivo, sb)
+    elemento, err := sb.ResolverRutaSinSeguir(archivo, strings.TrimRight(rutaCompleta, "/"), credenciales)
+    if err != nil {
+        return fmt.Errorf("error al resolver '%s': %w", rutaCompleta, err)
+    }
+    if elemento.Inodo == 0 || elemento.Nombre == "." || elemento.Nombre == ".." {
+        return fmt.Errorf("la ruta '%s' no nombra un elemento que se pueda eliminar", rutaCompleta)
+    }
+    if err := credenciales.RevisarArchivoSistema(rutaCompleta, elemento.Padre, elemento.Nombre); err != nil {
+        return err
+    }
+
     // Convertir el path del archivo o carpeta en un arreglo de carpetas
-    directoriosPadre, nombreArchivo := Utils.ObtenerDirectoriosPadre(rutaCompleta)
+    directoriosPadre, _ := Utils.ObtenerDirectoriosPadre(rutaCompleta)
 
-    // Intentar eliminar el archivo
-    err := eliminarArchivo(sb, archivo, directoriosPadre, nombreArchivo)
-    if err == nil {
-        // Si el archivo se elimino correctamente, regresar
-        return nil
+    if elemento.EsDirectorio {
+        err = eliminarDirectorio(sb, archivo, directoriosPadre, elemento.Nombre)
+    } else {
+        err = eliminarArchivo(sb, archivo, directoriosPadre, elemento.Nombre)
     }
-
-    // Si no es un archivo, intentar eliminarlo como carpeta
-    err = eliminarDirectorio(sb, archivo, directoriosPadre, nombreArchivo)
     if err != nil {
         return fmt.Errorf("error al eliminar archivo o carpeta '%s': %v", rutaCompleta, err)
     }
@@ -112,17 +122,10 @@ func eliminarArchivoOCarpeta(rutaCompleta string, sb *Estructuras.SuperBlock, ar
     return nil
 }
 
-// eliminarArchivo intenta eliminar un archivo dado su path
+// eliminarArchivo elimina un archivo ya resuelto dentro de sus directorios padre
 func eliminarArchivo(sb *Estructuras.SuperBlock, archivo *os.File, directoriosPadre []string, nombreArchivo string) error {
-    // Buscar el inodo del archivo
-    _, err := buscarInodoArchivo(archivo, sb, directoriosPadre, nombreArchivo)
-    if err != nil {
-        // No se encontro el archivo
-        return fmt.Errorf("archivo '%s' no encontrado: %v", nombreArchivo, err)
-    }
-
     // Llamar a la funcion que elimina el archivo
-    err = sb.EliminarArchivo(archivo, directoriosPadre, nombreArchivo)
+    err := sb.EliminarArchivo(archivo, directoriosPadre, nombreArchivo)
     if err != nil {
         return fmt.Errorf("error al eliminar el archivo '%s': %v", nombreArchivo, err)
     }
@@ -131,19 +134,10 @@ func eliminarArchivo(sb *Estructuras.SuperBlock, archivo *os.File, directoriosPa
     return nil
 }
 
-// eliminarDirectorio intenta eliminar una carpeta dada su path
+// eliminarDirectorio elimina una carpeta ya resuelta dentro de sus directorios padre
 func eliminarDirectorio(sb *Estructuras.SuperBlock, archivo *os.File, directoriosPadre []string, nombreDirectorio string) error {
-	rutaCarpetaCompleta := append(directoriosPadre, nombreDirectorio)
-
-	// Buscar el inodo de la carpeta
-    _, err := buscarInodoCarpeta(archivo, sb, rutaCarpetaCompleta)
-    if err != nil {
-        // No se encontro la carpeta
-        return fmt.Errorf("carpeta '%s' no encontrada: %v", nombreDirectorio, err)
-    }
-
     // Llamar a la funcion que elimina la carpeta
-    err = sb.EliminarCarpeta(archivo, directoriosPadre, nombreDirectorio)
+    err := sb.EliminarCarpeta(archivo, directoriosPadre, nombreDirectorio)
     if err != nil {
         return fmt.Errorf("error al eliminar la carpeta '%s': %v", nombreDirectorio, err)
     }
@@ -8,9 +8,7 @@ import (
     "regexp"
     "strings"
 
-    Estructuras "backend/Estructuras"
     Global "backend/Global"
-    Utils "backend/Utils"
 )
 
 // RENAME estructura del comando rename con parámetros
@@ -75,7 +73,7 @@ func comandoRename(comandoRename *RENAME, bufferSalida *bytes.Buffer) error {
     idParticion := Global.UsuarioActual.Id
 
     // Obtener la partición montada asociada al usuario logueado
-    superBloqueParticion, _, rutaParticion, err := Global.ObtenerSuperblockParticionMontada(idParticion)
+    superBloqueParticion, particionMontada, rutaParticion, err := Global.ObtenerSuperblockParticionMontada(idParticion)
     if err != nil {
         return fmt.Errorf("error al obtener la partición montada: %w", err)
     }
@@ -87,50 +85,43 @@ func comandoRename(comandoRename *RENAME, bufferSalida *bytes.Buffer) error {
     }
     defer archivo.Close() // Cerrar el archivo cuando ya no sea necesario
 
-    // Desglosar el path en directorios y el archivo/carpeta a renombrar
-    directoriosPadre, nombreAntiguo := Utils.ObtenerDirectoriosPadre(comandoRename.ruta)
-
-    // Buscar el inodo del directorio donde está el archivo/carpeta usando las funciones de cat.go
-    // Cargar el FolderBlock del directorio padre (asumir primer bloque como en el original)
-    indiceInodo, err := buscarInodoCarpeta(archivo, superBloqueParticion, directoriosPadre)
+    // Buscar el inodo del directorio donde está el archivo/carpeta a renombrar
+    credenciales := Global.CredencialesActuales(archivo, superBloqueParticion)
+    indiceInodo, nombreAntiguo, err := superBloqueParticion.ResolverPadre(archivo, comandoRename.ruta, credenciales)
     if err != nil {
-        return fmt.Errorf("error al encontrar el directorio padre: %v", err)
+        return fmt.Errorf("error al encontrar el directorio padre: %w", err)
+    }
+    if err := credenciales.RevisarArchivoSistema(comandoRename.ruta, indiceInodo, comandoRename.nombre); err != nil {
+        return err
     }
 
-    // Cargar el FolderBlock del directorio padre (usando solo el primer bloque como en el original)
-    bloqueCarpeta := &Estructuras.FolderBlock{}
-    err = bloqueCarpeta.Decodificar(archivo, int64(superBloqueParticion.S_block_start+(indiceInodo*superBloqueParticion.S_block_size)))
+    // Cargar todas las entradas del directorio padre
+    directorio, err := superBloqueParticion.LeerDirectorio(archivo, indiceInodo)
     if err != nil {
-        return fmt.Errorf("error al deserializar el bloque de carpeta: %v", err)
+        return fmt.Errorf("error al cargar el directorio padre: %v", err)
     }
 
     // Verificar que no exista un archivo/carpeta con el nuevo nombre
-    for _, contenido := range bloqueCarpeta.B_cont {
-        if strings.EqualFold(strings.Trim(string(contenido.B_name[:]), "\x00 "), comandoRename.nombre) {
-            return fmt.Errorf("ya existe un archivo o carpeta con el nombre '%s'", comandoRename.nombre)
-        }
+    if _, existe := directorio.Buscar(comandoRename.nombre); existe {
+        return fmt.Errorf("ya existe un archivo o carpeta con el nombre '%s'", comandoRename.nombre)
     }
 
-    // Renombrar el archivo/carpeta: buscar entrada y reemplazar nombre
-    renombrado := false
-    for i, contenido := range bloqueCarpeta.B_cont {
-        nombre := strings.Trim(string(contenido.B_name[:]), "\x00 ")
-        if strings.EqualFold(nombre, nombreAntiguo) {
-            // Reemplazar el nombre en la entrada
-            copy(bloqueCarpeta.B_cont[i].B_name[:], comandoRename.nombre)
-            renombrado = true
-            break
-        }
-    }
-    if !renombrado {
+    // Renombrar el archivo/carpeta: el nuevo nombre puede ocupar otra cantidad de contenidos
+    entrada, existe := directorio.Buscar(nombreAntiguo)
+    if !existe || entrada.EsEspecial() {
         return fmt.Errorf("archivo o carpeta '%s' no encontrado para renombrar", nombreAntiguo)
     }
+    if err := directorio.Renombrar(archivo, superBloqueParticion, entrada, comandoRename.nombre); err != nil {
+        return fmt.Errorf("error al renombrar '%s': %v", nombreAntiguo, err)
+    }
 
-    // Guardar el bloque modificado de nuevo en el archivo
-    err = bloqueCarpeta.Codificar(archivo, int64(superBloqueParticion.S_block_start+(indiceInodo*superBloqueParticion.S_block_size)))
-    if err != nil {
+    // Guardar los bloques modificados y el superbloque (el directorio pudo crecer)
+    if err := directorio.Guardar(archivo, superBloqueParticion); err != nil {
         return fmt.Errorf("error al guardar el bloque de carpeta modificado: %v", err)
     }
+    if err := superBloqueParticion.Codificar(archivo, int64(particionMontada.Part_start)); err != nil {
+        return fmt.Errorf("error al guardar el superbloque: %v", err)
+    }
 
     fmt.Fprintf(bufferSalida, "Nombre cambiado exitosamente de '%s' a '%s'\n", nombreAntiguo, comandoRename.nombre)
     fmt.Fprint(bufferSalida, "=====================================================\n")
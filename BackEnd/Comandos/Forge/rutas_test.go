@@ -0,0 +1,100 @@
+package Forge
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	Pruebas "backend/Comandos/Pruebas"
+	Estructuras "backend/Estructuras"
+	Global "backend/Global"
+)
+
+func TestResolverRuta(t *testing.T) {
+	id := Pruebas.MontarParticionFormateada(t, "")
+	Pruebas.Ejecutar(t, ParserMkdir, "-p -path=/home/docs")
+	Pruebas.Ejecutar(t, ParserMkdir, "-path=/home/privada")
+	Pruebas.Ejecutar(t, ParserMkfile, "-path=/home/docs/a.txt -size=10")
+	Pruebas.Ejecutar(t, ParserMkfile, "-path=/home/privada/b.txt -size=10")
+	Pruebas.Ejecutar(t, ParserChmod, "-path=/home/privada -ugo=700")
+	Pruebas.Ejecutar(t, ParserLn, "-s -path=/home/docs/a.txt -dest=/home/atajo")
+	Pruebas.Ejecutar(t, ParserLn, "-s -path=docs -dest=/home/relativo")
+	Pruebas.Ejecutar(t, ParserLn, "-s -path=/home/bucle -dest=/home/bucle")
+	Pruebas.Ejecutar(t, ParserLn, "-s -path=../../home/docs/a.txt -dest=/home/docs/arriba")
+
+	sb, _, ruta, err := Global.ObtenerSuperblockParticionMontada(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	archivo, err := os.OpenFile(ruta, os.O_RDWR, 0666)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer archivo.Close()
+
+	resolver := func(ruta string) (Estructuras.RutaResuelta, error) {
+		return sb.ResolverRuta(archivo, ruta, Estructuras.CredencialesRoot)
+	}
+	inodoDe := func(ruta string) int32 {
+		t.Helper()
+		resuelta, err := resolver(ruta)
+		if err != nil {
+			t.Fatalf("%s: %v", ruta, err)
+		}
+		return resuelta.Inodo
+	}
+	home, docs, archivoA := inodoDe("/home"), inodoDe("/home/docs"), inodoDe("/home/docs/a.txt")
+
+	casos := []struct {
+		ruta     string
+		inodo    int32
+		esperado error
+	}{
+		{"/", 0, nil},
+		{"/..", 0, nil},
+		{"//home///docs/./a.txt", archivoA, nil},
+		{"/home/docs/../docs/a.txt", archivoA, nil},
+		{"/home/docs/..", home, nil},
+		{"/home/atajo", archivoA, nil},
+		{"/home/relativo", docs, nil},
+		{"/home/relativo/a.txt", archivoA, nil},
+		{"/home/relativo/..", home, nil}, // '..' es la entrada guardada en docs, no un recorte del texto
+		{"/home/docs/arriba", archivoA, nil},
+		{"/home/docs/a.txt/", 0, Estructuras.ErrNoEsDirectorio},
+		{"/home/docs/a.txt/x", 0, Estructuras.ErrNoEsDirectorio},
+		{"/home/noexiste", 0, Estructuras.ErrRutaNoEncontrada},
+		{"/home/bucle", 0, Estructuras.ErrBucleEnlaces},
+	}
+	for _, caso := range casos {
+		resuelta, err := resolver(caso.ruta)
+		if caso.esperado != nil {
+			if !errors.Is(err, caso.esperado) {
+				t.Errorf("%s: error %v, se esperaba %v", caso.ruta, err, caso.esperado)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", caso.ruta, err)
+		} else if resuelta.Inodo != caso.inodo {
+			t.Errorf("%s: inodo %d, se esperaba %d", caso.ruta, resuelta.Inodo, caso.inodo)
+		}
+	}
+
+	// Sin seguir el ultimo componente se obtiene el enlace, salvo con '/' final
+	enlace, err := sb.ResolverRutaSinSeguir(archivo, "/home/relativo", Estructuras.CredencialesRoot)
+	if err != nil || enlace.Inodo == docs || enlace.Nombre != "relativo" || enlace.Padre != home {
+		t.Errorf("ResolverRutaSinSeguir(/home/relativo) = %+v, %v", enlace, err)
+	}
+	if carpeta, err := sb.ResolverRutaSinSeguir(archivo, "/home/relativo/", Estructuras.CredencialesRoot); err != nil || carpeta.Inodo != docs {
+		t.Errorf("ResolverRutaSinSeguir(/home/relativo/) = %+v, %v", carpeta, err)
+	}
+
+	// Un usuario sin permiso de ejecucion en la carpeta no puede buscar nombres en ella
+	otro := Estructuras.Credenciales{Uid: 50, Gid: 50}
+	if _, err := sb.ResolverRuta(archivo, "/home/privada/b.txt", otro); !errors.Is(err, Estructuras.ErrPermisoDenegado) {
+		t.Errorf("/home/privada/b.txt sin permisos: %v", err)
+	}
+	if _, err := sb.ResolverRuta(archivo, "/home/docs/a.txt", otro); err != nil {
+		t.Errorf("/home/docs/a.txt: %v", err)
+	}
+}
@@ -0,0 +1,140 @@
+package Forge
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	Estructuras "backend/Estructuras"
+	Global "backend/Global"
+)
+
+// formatoFechaStat muestra las marcas de tiempo con sus nanosegundos
+const formatoFechaStat = "2006-01-02 15:04:05.000000000 -0700"
+
+// STAT estructura del comando stat con sus parametros
+type STAT struct {
+	ruta string // Ruta del elemento a inspeccionar
+}
+
+// ParserStat parsea el comando stat y muestra los campos del inodo
+func ParserStat(tokens []string) (string, error) {
+	cmd := &STAT{}
+	var bufferSalida bytes.Buffer
+
+	// Expresion regular para capturar el parametro -path
+	re := regexp.MustCompile(`-path="[^"]+"|-path=[^\s]+`)
+	matches := re.FindAllString(strings.Join(tokens, " "), -1)
+	if len(matches) != len(tokens) {
+		for _, token := range tokens {
+			if !re.MatchString(token) {
+				return "", fmt.Errorf("parametro invalido: %s", token)
+			}
+		}
+	}
+
+	for _, match := range matches {
+		kv := strings.SplitN(match, "=", 2)
+		if strings.ToLower(kv[0]) == "-path" {
+			cmd.ruta = strings.Trim(kv[1], "\"")
+		}
+	}
+
+	if cmd.ruta == "" {
+		return "", errors.New("el parametro -path es obligatorio")
+	}
+
+	err := comandoStat(cmd, &bufferSalida)
+	if err != nil {
+		return "", err
+	}
+	return bufferSalida.String(), nil
+}
+
+// comandoStat imprime todos los campos del inodo de la ruta. Igual que stat en Linux, un
+// enlace simbolico se describe a si mismo y no a su destino
+func comandoStat(stat *STAT, bufferSalida *bytes.Buffer) error {
+	fmt.Fprint(bufferSalida, "======================== STAT ========================\n")
+
+	if !Global.VerificarSesionActiva() {
+		return fmt.Errorf("no hay un usuario logueado")
+	}
+
+	idParticion := Global.UsuarioActual.Id
+	superBloqueParticion, _, rutaParticion, err := Global.ObtenerSuperblockParticionMontada(idParticion)
+	if err != nil {
+		return fmt.Errorf("error al obtener la particion montada: %w", err)
+	}
+
+	archivo, err := os.OpenFile(rutaParticion, os.O_RDWR, 0666)
+	if err != nil {
+		return fmt.Errorf("error al abrir el archivo de particion: %w", err)
+	}
+	defer archivo.Close()
+
+	credenciales := Global.CredencialesActuales(archivo, superBloqueParticion)
+	resuelta, err := superBloqueParticion.ResolverRutaSinSeguir(archivo, stat.ruta, credenciales)
+	if err != nil {
+		return fmt.Errorf("error al resolver '%s': %w", stat.ruta, err)
+	}
+
+	inodo := &Estructuras.INodo{}
+	if err := inodo.Decodificar(archivo, superBloqueParticion.CalcularDesplazamientoInodo(resuelta.Inodo)); err != nil {
+		return fmt.Errorf("error al deserializar el inodo %d: %w", resuelta.Inodo, err)
+	}
+
+	tipo := "archivo"
+	switch inodo.I_type[0] {
+	case Estructuras.TipoCarpeta:
+		tipo = "carpeta"
+	case Estructuras.TipoEnlaceSimbolico:
+		destino, err := superBloqueParticion.LeerDestinoEnlace(archivo, inodo)
+		if err != nil {
+			return err
+		}
+		tipo = fmt.Sprintf("enlace simbolico -> %s", destino)
+	}
+
+	bloques, err := inodo.ObtenerTodosLosIndicesDeBloques(archivo, superBloqueParticion)
+	if err != nil {
+		return fmt.Errorf("error al contar los bloques del inodo %d: %w", resuelta.Inodo, err)
+	}
+
+	permisos := string(inodo.I_perm[:])
+	usuario, grupo := Global.NombresPropietario(archivo, superBloqueParticion, inodo.I_uid, inodo.I_gid)
+
+	fmt.Fprintf(bufferSalida, "Ruta:     %s\n", stat.ruta)
+	fmt.Fprintf(bufferSalida, "Inodo:    %d\n", resuelta.Inodo)
+	fmt.Fprintf(bufferSalida, "Tipo:     %s\n", tipo)
+	fmt.Fprintf(bufferSalida, "Tamaño:   %d bytes\n", inodo.I_size)
+	fmt.Fprintf(bufferSalida, "Bloques:  %d %v\n", len(bloques), bloques)
+	fmt.Fprintf(bufferSalida, "Enlaces:  %d\n", max(inodo.I_links, 1))
+	fmt.Fprintf(bufferSalida, "Permisos: %s (%s)\n", permisos, formatoPermisosStat(permisos))
+	fmt.Fprintf(bufferSalida, "Usuario:  %d (%s)\n", inodo.I_uid, usuario)
+	fmt.Fprintf(bufferSalida, "Grupo:    %d (%s)\n", inodo.I_gid, grupo)
+	fmt.Fprintf(bufferSalida, "Acceso:        %s\n", Estructuras.FechaDeMarca(inodo.I_atime).Format(formatoFechaStat))
+	fmt.Fprintf(bufferSalida, "Modificacion:  %s\n", Estructuras.FechaDeMarca(inodo.I_mtime).Format(formatoFechaStat))
+	fmt.Fprintf(bufferSalida, "Cambio:        %s\n", Estructuras.FechaDeMarca(inodo.I_ctime).Format(formatoFechaStat))
+
+	fmt.Fprint(bufferSalida, "======================================================\n")
+	return nil
+}
+
+// formatoPermisosStat convierte los tres digitos UGO a la forma rwxrwxrwx
+func formatoPermisosStat(permisos string) string {
+	var resultado strings.Builder
+	for _, digito := range permisos {
+		valor := byte(digito - '0')
+		for i, letra := range "rwx" {
+			if valor&(4>>i) != 0 {
+				resultado.WriteRune(letra)
+			} else {
+				resultado.WriteByte('-')
+			}
+		}
+	}
+	return resultado.String()
+}
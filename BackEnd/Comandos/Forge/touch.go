@@ -0,0 +1,166 @@
+package Forge
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	Estructuras "backend/Estructuras"
+	Global "backend/Global"
+	Utils "backend/Utils"
+)
+
+// formatosFechaTouch son los formatos aceptados por -date, interpretados en hora local
+var formatosFechaTouch = []string{
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	time.RFC3339Nano,
+}
+
+// TOUCH estructura del comando touch con sus parametros
+type TOUCH struct {
+	ruta  string // Archivo a crear o cuyas fechas se actualizan
+	fecha string // Fecha a asignar; vacia usa la hora actual
+}
+
+// ParserTouch parsea el comando touch y ejecuta la actualizacion
+func ParserTouch(tokens []string) (string, error) {
+	cmd := &TOUCH{}
+	var bufferSalida bytes.Buffer
+
+	// Expresion regular para capturar los parametros -path y -date
+	re := regexp.MustCompile(`-path="[^"]+"|-path=[^\s]+|-date="[^"]+"|-date=[^\s]+`)
+	matches := re.FindAllString(strings.Join(tokens, " "), -1)
+	for _, token := range tokens {
+		// Una fecha entre comillas con espacios llega partida en varios tokens
+		if strings.HasPrefix(token, "-") && !re.MatchString(token) {
+			return "", fmt.Errorf("parametro invalido: %s", token)
+		}
+	}
+
+	for _, match := range matches {
+		kv := strings.SplitN(match, "=", 2)
+		value := strings.Trim(kv[1], "\"")
+		switch strings.ToLower(kv[0]) {
+		case "-path":
+			cmd.ruta = value
+		case "-date":
+			cmd.fecha = value
+		}
+	}
+
+	if cmd.ruta == "" {
+		return "", errors.New("el parametro -path es obligatorio")
+	}
+
+	err := comandoTouch(cmd, &bufferSalida)
+	if err != nil {
+		return "", err
+	}
+	return bufferSalida.String(), nil
+}
+
+// interpretarFechaTouch convierte -date a marca de tiempo en nanosegundos
+func interpretarFechaTouch(fecha string) (int64, error) {
+	if fecha == "" {
+		return Estructuras.MarcaTiempoActual(), nil
+	}
+	for _, formato := range formatosFechaTouch {
+		if t, err := time.ParseInLocation(formato, fecha, time.Local); err == nil {
+			return t.UnixNano(), nil
+		}
+	}
+	return 0, fmt.Errorf("fecha invalida '%s': use AAAA-MM-DD, 'AAAA-MM-DD hh:mm:ss' o RFC3339", fecha)
+}
+
+// comandoTouch crea un archivo vacio si la ruta no existe; si existe asigna la fecha a su
+// acceso y modificacion. Los enlaces simbolicos se siguen hasta su destino
+func comandoTouch(touch *TOUCH, bufferSalida *bytes.Buffer) error {
+	fmt.Fprint(bufferSalida, "======================== TOUCH =======================\n")
+
+	if !Global.VerificarSesionActiva() {
+		return fmt.Errorf("no hay un usuario logueado")
+	}
+
+	marca, err := interpretarFechaTouch(touch.fecha)
+	if err != nil {
+		return err
+	}
+
+	idParticion := Global.UsuarioActual.Id
+	superBloqueParticion, particionMontada, rutaParticion, err := Global.ObtenerSuperblockParticionMontada(idParticion)
+	if err != nil {
+		return fmt.Errorf("error al obtener la particion montada: %w", err)
+	}
+
+	archivo, err := os.OpenFile(rutaParticion, os.O_RDWR, 0666)
+	if err != nil {
+		return fmt.Errorf("error al abrir el archivo de particion: %w", err)
+	}
+	defer archivo.Close()
+
+	credenciales := Global.CredencialesActuales(archivo, superBloqueParticion)
+	resuelta, err := superBloqueParticion.ResolverRuta(archivo, touch.ruta, credenciales)
+	if errors.Is(err, Estructuras.ErrRutaNoEncontrada) {
+		// Solo falta el ultimo componente: se crea vacio en su carpeta
+		indiceCarpeta, nombre, err := superBloqueParticion.ResolverPadre(archivo, touch.ruta, credenciales)
+		if err != nil {
+			return fmt.Errorf("error al resolver la carpeta de '%s': %w", touch.ruta, err)
+		}
+		if !verificarPermisosEscritura(archivo, superBloqueParticion, indiceCarpeta) {
+			return fmt.Errorf("no tiene permisos de escritura sobre la carpeta de '%s'", touch.ruta)
+		}
+
+		directoriosPadre, _ := Utils.ObtenerDirectoriosPadre(touch.ruta)
+		if err := superBloqueParticion.CrearArchivo(archivo, directoriosPadre, nombre, 0, nil, false); err != nil {
+			return fmt.Errorf("error al crear el archivo '%s': %w", touch.ruta, err)
+		}
+		if touch.fecha != "" {
+			creado, err := superBloqueParticion.ResolverRuta(archivo, touch.ruta, credenciales)
+			if err != nil {
+				return fmt.Errorf("error al resolver '%s': %w", touch.ruta, err)
+			}
+			if err := asignarFechasTouch(archivo, superBloqueParticion, creado.Inodo, marca); err != nil {
+				return err
+			}
+		}
+		if err := superBloqueParticion.Codificar(archivo, int64(particionMontada.Part_start)); err != nil {
+			return fmt.Errorf("error al guardar el superbloque: %w", err)
+		}
+		fmt.Fprintf(bufferSalida, "Archivo vacio '%s' creado\n", touch.ruta)
+	} else if err != nil {
+		return fmt.Errorf("error al resolver '%s': %w", touch.ruta, err)
+	} else {
+		if !verificarPermisosEscritura(archivo, superBloqueParticion, resuelta.Inodo) {
+			return fmt.Errorf("no tiene permisos de escritura sobre '%s'", touch.ruta)
+		}
+		if err := asignarFechasTouch(archivo, superBloqueParticion, resuelta.Inodo, marca); err != nil {
+			return err
+		}
+		fmt.Fprintf(bufferSalida, "Fechas de '%s' actualizadas a %s\n", touch.ruta, Estructuras.FechaDeMarca(marca).Format(formatoFechaStat))
+	}
+
+	fmt.Fprint(bufferSalida, "======================================================\n")
+	return nil
+}
+
+// asignarFechasTouch fija acceso y modificacion del inodo; el cambio de metadatos siempre
+// registra la hora actual
+func asignarFechasTouch(archivo *os.File, sb *Estructuras.SuperBlock, indiceInodo int32, marca int64) error {
+	desplazamiento := sb.CalcularDesplazamientoInodo(indiceInodo)
+	inodo := &Estructuras.INodo{}
+	if err := inodo.Decodificar(archivo, desplazamiento); err != nil {
+		return fmt.Errorf("error al deserializar el inodo %d: %w", indiceInodo, err)
+	}
+	inodo.I_atime = marca
+	inodo.I_mtime = marca
+	inodo.ActualizarTiempoPermisos()
+	if err := inodo.Codificar(archivo, desplazamiento); err != nil {
+		return fmt.Errorf("error al guardar el inodo %d: %w", indiceInodo, err)
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package Forge
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	Pruebas "backend/Comandos/Pruebas"
+)
+
+func TestInterpretarFechaTouch(t *testing.T) {
+	casos := []struct {
+		fecha    string
+		esperada time.Time
+	}{
+		{"2024-02-29 13:45:10", time.Date(2024, 2, 29, 13, 45, 10, 0, time.Local)},
+		{"2024-02-29", time.Date(2024, 2, 29, 0, 0, 0, 0, time.Local)},
+		{"2024-02-29T13:45:10.123456789Z", time.Date(2024, 2, 29, 13, 45, 10, 123456789, time.UTC)},
+		{"2024-02-29T13:45:10-06:00", time.Date(2024, 2, 29, 19, 45, 10, 0, time.UTC)},
+	}
+	for _, caso := range casos {
+		marca, err := interpretarFechaTouch(caso.fecha)
+		if err != nil {
+			t.Errorf("%q: %v", caso.fecha, err)
+			continue
+		}
+		if marca != caso.esperada.UnixNano() {
+			t.Errorf("%q: marca %v, se esperaba %v", caso.fecha, time.Unix(0, marca), caso.esperada)
+		}
+	}
+
+	// Sin -date se usa la hora actual
+	antes := time.Now().UnixNano()
+	marca, err := interpretarFechaTouch("")
+	if err != nil || marca < antes || marca > time.Now().UnixNano() {
+		t.Errorf("sin fecha: %v, %v", time.Unix(0, marca), err)
+	}
+
+	for _, fecha := range []string{"2023-02-29", "2024-13-01", "29/02/2024", "2024-02-29 25:00:00", "ayer"} {
+		if _, err := interpretarFechaTouch(fecha); err == nil || !strings.Contains(err.Error(), "fecha invalida") {
+			t.Errorf("%q: error %v, se esperaba fecha invalida", fecha, err)
+		}
+	}
+}
+
+// marcaStat retorna la linea 'campo' de la salida de stat sin el nombre del campo
+func marcaStat(t *testing.T, salida, campo string) string {
+	t.Helper()
+	for _, linea := range strings.Split(salida, "\n") {
+		if strings.HasPrefix(linea, campo+":") {
+			return strings.TrimSpace(strings.TrimPrefix(linea, campo+":"))
+		}
+	}
+	t.Fatalf("stat no muestra %s:\n%s", campo, salida)
+	return ""
+}
+
+func TestTouchAsignaFechas(t *testing.T) {
+	Pruebas.MontarParticionFormateada(t, "")
+
+	// Un archivo nuevo se crea vacio con la fecha de -date en acceso y modificacion
+	Pruebas.Ejecutar(t, ParserTouch, `-path=/notas.txt -date="2024-02-29 13:45:10"`)
+	salida := Pruebas.Ejecutar(t, ParserStat, "-path=/notas.txt")
+	esperada := time.Date(2024, 2, 29, 13, 45, 10, 0, time.Local).Format(formatoFechaStat)
+	for _, campo := range []string{"Acceso", "Modificacion"} {
+		if marca := marcaStat(t, salida, campo); marca != esperada {
+			t.Errorf("%s: %q, se esperaba %q", campo, marca, esperada)
+		}
+	}
+	if !strings.Contains(salida, "Tamaño:   0 bytes") {
+		t.Errorf("touch no creo un archivo vacio:\n%s", salida)
+	}
+	// El cambio de metadatos registra la hora actual, no la de -date
+	if marca := marcaStat(t, salida, "Cambio"); strings.HasPrefix(marca, "2024-02-29") {
+		t.Errorf("Cambio tomo la fecha de -date: %s", marca)
+	}
+
+	// En un archivo existente la fecha conserva los nanosegundos
+	Pruebas.Ejecutar(t, ParserTouch, "-path=/notas.txt -date=2023-05-06T07:08:09.123456789Z")
+	salida = Pruebas.Ejecutar(t, ParserStat, "-path=/notas.txt")
+	esperada = time.Date(2023, 5, 6, 7, 8, 9, 123456789, time.UTC).Local().Format(formatoFechaStat)
+	if marca := marcaStat(t, salida, "Modificacion"); marca != esperada {
+		t.Errorf("Modificacion: %q, se esperaba %q", marca, esperada)
+	}
+
+	// Una fecha invalida se rechaza antes de crear el archivo
+	if _, err := ParserTouch([]string{"-path=/otro.txt", "-date=2024-02-30"}); err == nil || !strings.Contains(err.Error(), "fecha invalida") {
+		t.Errorf("touch con fecha invalida: %v", err)
+	}
+	if _, err := ParserStat([]string{"-path=/otro.txt"}); err == nil {
+		t.Error("touch con fecha invalida creo el archivo")
+	}
+	if _, err := ParserTouch([]string{"-path=/otro.txt", "-fecha=2024-02-29"}); err == nil {
+		t.Error("touch acepto un parametro desconocido")
+	}
+}
+
+func TestStatMuestraNanosegundos(t *testing.T) {
+	Pruebas.MontarParticionFormateada(t, "")
+	Pruebas.Ejecutar(t, ParserMkfile, "-path=/datos.txt -size=10")
+
+	salida := Pruebas.Ejecutar(t, ParserStat, "-path=/datos.txt")
+	formato := regexp.MustCompile(`^\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2}\.\d{9} [+-]\d{4}$`)
+	for _, campo := range []string{"Acceso", "Modificacion", "Cambio"} {
+		marca := marcaStat(t, salida, campo)
+		if !formato.MatchString(marca) {
+			t.Errorf("%s: %q no tiene el formato con nanosegundos", campo, marca)
+			continue
+		}
+		fecha, err := time.Parse(formatoFechaStat, marca)
+		if err != nil || time.Since(fecha) < 0 || time.Since(fecha) > time.Minute {
+			t.Errorf("%s: %q no es la hora de creacion (%v)", campo, marca, err)
+		}
+	}
+}
@@ -7,13 +7,14 @@ import (
 
     Estructuras "backend/Estructuras"
     Global "backend/Global"
-    Utils "backend/Utils"
 )
 
 type DirectoryTree struct {
     Name     string           `json:"name"`
     Children []*DirectoryTree `json:"children,omitempty"`
     IsDir    bool             `json:"isDir"`
+    IsLink   bool             `json:"isLink,omitempty"`
+    Target   string           `json:"target,omitempty"`
 }
 
 // DirectoryTreeService maneja la construcción y obtención del árbol de directorios.
@@ -54,20 +55,13 @@ func (dts *DirectoryTreeService) Close() {
 }
 
 func (dts *DirectoryTreeService) GetDirectoryTree(path string) (*DirectoryTree, error) {
-    var rootInodeIndex int32
-    var err error
-
-    if path == "/" {
-        rootInodeIndex = 0
-    } else {
-        parentDirs, dirName := Utils.ObtenerDirectoriosPadre(path)
-        rootInodeIndex, err = buscarInodoArchivo(dts.file, dts.partitionSuperblock, parentDirs, dirName)
-        if err != nil {
-            return nil, fmt.Errorf("error al encontrar el directorio inicial '%s': %w", path, err)
-        }
+    credentials := Global.CredencialesActuales(dts.file, dts.partitionSuperblock)
+    root, err := dts.partitionSuperblock.ResolverRuta(dts.file, path, credentials)
+    if err != nil {
+        return nil, fmt.Errorf("error al encontrar el directorio inicial '%s': %w", path, err)
     }
 
-    tree, err := dts.buildDirectoryTree(rootInodeIndex, path)
+    tree, err := dts.buildDirectoryTree(root.Inodo, path)
     if err != nil {
         return nil, fmt.Errorf("error al construir el árbol de directorios para '%s': %w", path, err)
     }
@@ -78,7 +72,7 @@ func (dts *DirectoryTreeService) GetDirectoryTree(path string) (*DirectoryTree,
 // buildDirectoryTree construye recursivamente el árbol de directorios a partir del inodo indicado y el path actual.
 func (dts *DirectoryTreeService) buildDirectoryTree(inodeIndex int32, currentPath string) (*DirectoryTree, error) {
     inodo := &Estructuras.INodo{}
-    offset := int64(dts.partitionSuperblock.S_inode_start) + int64(inodeIndex*dts.partitionSuperblock.S_inode_size)
+    offset := dts.partitionSuperblock.CalcularDesplazamientoInodo(inodeIndex)
     if err := inodo.Decodificar(dts.file, offset); err != nil {
         return nil, fmt.Errorf("error al deserializar el inodo %d (offset %d) para '%s': %w", inodeIndex, offset, currentPath, err)
     }
@@ -92,47 +86,47 @@ func (dts *DirectoryTreeService) buildDirectoryTree(inodeIndex int32, currentPat
     }
 
     tree := &DirectoryTree{
-        Name:  currentName,
-        IsDir: inodo.I_type[0] == '0',
+        Name:   currentName,
+        IsDir:  inodo.I_type[0] == '0',
+        IsLink: inodo.EsEnlaceSimbolico(),
+    }
+
+    // Los enlaces simbólicos se muestran con su destino pero no se recorren
+    if tree.IsLink {
+        target, err := dts.partitionSuperblock.LeerDestinoEnlace(dts.file, inodo)
+        if err != nil {
+            return nil, fmt.Errorf("error al leer el destino del enlace '%s': %w", currentPath, err)
+        }
+        tree.Target = target
     }
 
     if !tree.IsDir {
         return tree, nil
     }
 
-    for _, blockIndex := range inodo.I_block {
-        if blockIndex == -1 {
-            break
-        }
+    directorio, err := dts.partitionSuperblock.LeerDirectorio(dts.file, inodeIndex)
+    if err != nil {
+        return nil, fmt.Errorf("error al leer las entradas del directorio '%s': %w", currentPath, err)
+    }
 
-        bloque := &Estructuras.FolderBlock{}
-        blockOffset := int64(dts.partitionSuperblock.S_block_start) + int64(blockIndex*dts.partitionSuperblock.S_block_size)
-        if err := bloque.Decodificar(dts.file, blockOffset); err != nil {
-            return nil, fmt.Errorf("error al deserializar el bloque %d (offset %d): %w", blockIndex, blockOffset, err)
+    for _, contenido := range directorio.Entradas() {
+        if contenido.EsEspecial() || directorio.EsOculta(contenido) {
+            continue
         }
+        nombre := contenido.Nombre
 
-        for _, contenido := range bloque.B_cont {
-            if contenido.B_inodo == -1 {
-                continue
-            }
-            nombre := strings.Trim(string(contenido.B_name[:]), "\x00 ")
-            if nombre == "." || nombre == ".." {
-                continue
-            }
-
-            var childPath string
-            if currentPath == "/" {
-                childPath = "/" + nombre
-            } else {
-                childPath = currentPath + "/" + nombre
-            }
+        var childPath string
+        if currentPath == "/" {
+            childPath = "/" + nombre
+        } else {
+            childPath = currentPath + "/" + nombre
+        }
 
-            childNode, err := dts.buildDirectoryTree(contenido.B_inodo, childPath)
-            if err != nil {
-                return nil, fmt.Errorf("error al construir el árbol para '%s': %w", childPath, err)
-            }
-            tree.Children = append(tree.Children, childNode)
+        childNode, err := dts.buildDirectoryTree(contenido.Inodo, childPath)
+        if err != nil {
+            return nil, fmt.Errorf("error al construir el árbol para '%s': %w", childPath, err)
         }
+        tree.Children = append(tree.Children, childNode)
     }
 
     return tree, nil
@@ -171,6 +165,10 @@ func (dts *DirectoryTreeService) GenerateDotGraph() (string, error) {
             fill = "#4285F4"
             font = "#FFFFFF"
             border = "#2B579A"
+        } else if node.IsLink {
+            fill = "#FBBC05"
+            font = "#000000"
+            border = "#B8860B"
         } else {
             fill = "#34A853"
             font = "#FFFFFF"
@@ -184,6 +182,9 @@ func (dts *DirectoryTreeService) GenerateDotGraph() (string, error) {
             if label == "/" {
                 label = "ROOT"
             }
+        } else if node.IsLink {
+            shape = "cds"
+            label = node.Name + " -> " + node.Target
         }
 
         lines = append(lines, fmt.Sprintf(
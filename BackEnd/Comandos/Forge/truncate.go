@@ -0,0 +1,117 @@
+package Forge
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	Estructuras "backend/Estructuras"
+	Global "backend/Global"
+)
+
+// TRUNCATE estructura del comando truncate con sus parametros
+type TRUNCATE struct {
+	ruta   string // Archivo a recortar o extender
+	tamano int64  // Nuevo tamaño en bytes
+}
+
+// ParserTruncate parsea el comando truncate y ejecuta el cambio de tamaño
+func ParserTruncate(tokens []string) (string, error) {
+	cmd := &TRUNCATE{tamano: -1}
+	var bufferSalida bytes.Buffer
+
+	// Expresion regular para capturar los parametros -path y -size
+	re := regexp.MustCompile(`-path="[^"]+"|-path=[^\s]+|-size=[^\s]+`)
+	matches := re.FindAllString(strings.Join(tokens, " "), -1)
+	if len(matches) != len(tokens) {
+		for _, token := range tokens {
+			if !re.MatchString(token) {
+				return "", fmt.Errorf("parametro invalido: %s", token)
+			}
+		}
+	}
+
+	for _, match := range matches {
+		kv := strings.SplitN(match, "=", 2)
+		value := strings.Trim(kv[1], "\"")
+		switch strings.ToLower(kv[0]) {
+		case "-path":
+			cmd.ruta = value
+		case "-size":
+			tamano, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || tamano < 0 {
+				return "", fmt.Errorf("el parametro -size debe ser un entero no negativo: %s", value)
+			}
+			cmd.tamano = tamano
+		}
+	}
+
+	if cmd.ruta == "" || cmd.tamano < 0 {
+		return "", errors.New("los parametros -path y -size son obligatorios")
+	}
+
+	err := comandoTruncate(cmd, &bufferSalida)
+	if err != nil {
+		return "", err
+	}
+	return bufferSalida.String(), nil
+}
+
+// comandoTruncate cambia el tamaño del archivo liberando o asignando solo los bloques del
+// final
+func comandoTruncate(truncate *TRUNCATE, bufferSalida *bytes.Buffer) error {
+	fmt.Fprint(bufferSalida, "====================== TRUNCATE ======================\n")
+
+	if !Global.VerificarSesionActiva() {
+		return fmt.Errorf("no hay un usuario logueado")
+	}
+
+	idParticion := Global.UsuarioActual.Id
+	superBloqueParticion, particionMontada, rutaParticion, err := Global.ObtenerSuperblockParticionMontada(idParticion)
+	if err != nil {
+		return fmt.Errorf("error al obtener la particion montada: %w", err)
+	}
+
+	archivo, err := os.OpenFile(rutaParticion, os.O_RDWR, 0666)
+	if err != nil {
+		return fmt.Errorf("error al abrir el archivo de particion: %w", err)
+	}
+	defer archivo.Close()
+
+	credenciales := Global.CredencialesActuales(archivo, superBloqueParticion)
+	resuelta, err := superBloqueParticion.ResolverRuta(archivo, truncate.ruta, credenciales)
+	if err != nil {
+		return fmt.Errorf("error al resolver '%s': %w", truncate.ruta, err)
+	}
+	if !verificarPermisosEscritura(archivo, superBloqueParticion, resuelta.Inodo) {
+		return fmt.Errorf("no tiene permisos de escritura sobre '%s'", truncate.ruta)
+	}
+
+	desplazamiento := superBloqueParticion.CalcularDesplazamientoInodo(resuelta.Inodo)
+	inodo := &Estructuras.INodo{}
+	if err := inodo.Decodificar(archivo, desplazamiento); err != nil {
+		return fmt.Errorf("error al deserializar el inodo %d: %w", resuelta.Inodo, err)
+	}
+	if inodo.I_type[0] != Estructuras.TipoArchivo {
+		return fmt.Errorf("'%s' no es un archivo", truncate.ruta)
+	}
+
+	tamanoAnterior := inodo.I_size
+	if err := inodo.Truncate(archivo, superBloqueParticion, truncate.tamano); err != nil {
+		return fmt.Errorf("error al cambiar el tamaño de '%s': %w", truncate.ruta, err)
+	}
+	if err := inodo.Codificar(archivo, desplazamiento); err != nil {
+		return fmt.Errorf("error al guardar el inodo %d: %w", resuelta.Inodo, err)
+	}
+	if err := superBloqueParticion.Codificar(archivo, int64(particionMontada.Part_start)); err != nil {
+		return fmt.Errorf("error al guardar el superbloque: %w", err)
+	}
+
+	fmt.Fprintf(bufferSalida, "Tamaño de '%s': %d -> %d bytes\n", truncate.ruta, tamanoAnterior, inodo.I_size)
+	fmt.Fprint(bufferSalida, "======================================================\n")
+	return nil
+}
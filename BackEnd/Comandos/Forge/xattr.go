@@ -0,0 +1,207 @@
+package Forge
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	Estructuras "backend/Estructuras"
+	Global "backend/Global"
+)
+
+// XATTR estructura compartida por setxattr, getxattr, listxattr y rmxattr
+type XATTR struct {
+	ruta   string // Archivo, carpeta o enlace (se sigue hasta su destino)
+	nombre string // Nombre del atributo
+	valor  string // Valor a guardar con setxattr
+}
+
+// parsearXattr lee los parametros indicados; un valor entre comillas con espacios llega
+// partido en varios tokens
+func parsearXattr(tokens []string, parametros ...string) (*XATTR, error) {
+	var alternativas []string
+	for _, parametro := range parametros {
+		alternativas = append(alternativas, parametro+`="[^"]*"`, parametro+`=[^\s]+`)
+	}
+	re := regexp.MustCompile(strings.Join(alternativas, "|"))
+	matches := re.FindAllString(strings.Join(tokens, " "), -1)
+	for _, token := range tokens {
+		if strings.HasPrefix(token, "-") && !re.MatchString(token) {
+			return nil, fmt.Errorf("parametro invalido: %s", token)
+		}
+	}
+
+	cmd := &XATTR{}
+	for _, match := range matches {
+		kv := strings.SplitN(match, "=", 2)
+		value := strings.Trim(kv[1], "\"")
+		switch strings.ToLower(kv[0]) {
+		case "-path":
+			cmd.ruta = value
+		case "-name":
+			cmd.nombre = value
+		case "-value":
+			cmd.valor = value
+		}
+	}
+
+	if cmd.ruta == "" {
+		return nil, errors.New("el parametro -path es obligatorio")
+	}
+	for _, parametro := range parametros {
+		if parametro == "-name" && cmd.nombre == "" {
+			return nil, errors.New("el parametro -name es obligatorio")
+		}
+	}
+	return cmd, nil
+}
+
+// ParserSetxattr crea o reemplaza un atributo extendido
+func ParserSetxattr(tokens []string) (string, error) {
+	cmd, err := parsearXattr(tokens, "-path", "-name", "-value")
+	if err != nil {
+		return "", err
+	}
+	var bufferSalida bytes.Buffer
+	fmt.Fprint(&bufferSalida, "===================== SETXATTR =====================\n")
+	err = comandoXattr(cmd, true, func(archivo *os.File, sb *Estructuras.SuperBlock, inodo *Estructuras.INodo) (bool, error) {
+		if err := sb.EstablecerAtributo(archivo, inodo, cmd.nombre, []byte(cmd.valor)); err != nil {
+			return false, err
+		}
+		fmt.Fprintf(&bufferSalida, "Atributo '%s' establecido en '%s' (%d bytes)\n", cmd.nombre, cmd.ruta, len(cmd.valor))
+		return true, nil
+	})
+	if err != nil {
+		return "", err
+	}
+	fmt.Fprint(&bufferSalida, "====================================================\n")
+	return bufferSalida.String(), nil
+}
+
+// ParserGetxattr muestra el valor de un atributo extendido
+func ParserGetxattr(tokens []string) (string, error) {
+	cmd, err := parsearXattr(tokens, "-path", "-name")
+	if err != nil {
+		return "", err
+	}
+	var bufferSalida bytes.Buffer
+	fmt.Fprint(&bufferSalida, "===================== GETXATTR =====================\n")
+	err = comandoXattr(cmd, false, func(archivo *os.File, sb *Estructuras.SuperBlock, inodo *Estructuras.INodo) (bool, error) {
+		valor, err := sb.LeerAtributo(archivo, inodo, cmd.nombre)
+		if err != nil {
+			return false, err
+		}
+		fmt.Fprintf(&bufferSalida, "%s=%q\n", cmd.nombre, valor)
+		return false, nil
+	})
+	if err != nil {
+		return "", err
+	}
+	fmt.Fprint(&bufferSalida, "====================================================\n")
+	return bufferSalida.String(), nil
+}
+
+// ParserListxattr lista los atributos extendidos con sus valores
+func ParserListxattr(tokens []string) (string, error) {
+	cmd, err := parsearXattr(tokens, "-path")
+	if err != nil {
+		return "", err
+	}
+	var bufferSalida bytes.Buffer
+	fmt.Fprint(&bufferSalida, "==================== LISTXATTR =====================\n")
+	err = comandoXattr(cmd, false, func(archivo *os.File, sb *Estructuras.SuperBlock, inodo *Estructuras.INodo) (bool, error) {
+		atributos, err := sb.LeerAtributos(archivo, inodo)
+		if err != nil {
+			return false, err
+		}
+		fmt.Fprintf(&bufferSalida, "Atributos de '%s': %d\n", cmd.ruta, len(atributos))
+		for _, atributo := range atributos {
+			fmt.Fprintf(&bufferSalida, "%s=%q\n", atributo.Nombre, atributo.Valor)
+		}
+		return false, nil
+	})
+	if err != nil {
+		return "", err
+	}
+	fmt.Fprint(&bufferSalida, "====================================================\n")
+	return bufferSalida.String(), nil
+}
+
+// ParserRmxattr elimina un atributo extendido
+func ParserRmxattr(tokens []string) (string, error) {
+	cmd, err := parsearXattr(tokens, "-path", "-name")
+	if err != nil {
+		return "", err
+	}
+	var bufferSalida bytes.Buffer
+	fmt.Fprint(&bufferSalida, "===================== RMXATTR ======================\n")
+	err = comandoXattr(cmd, true, func(archivo *os.File, sb *Estructuras.SuperBlock, inodo *Estructuras.INodo) (bool, error) {
+		if err := sb.EliminarAtributo(archivo, inodo, cmd.nombre); err != nil {
+			return false, err
+		}
+		fmt.Fprintf(&bufferSalida, "Atributo '%s' eliminado de '%s'\n", cmd.nombre, cmd.ruta)
+		return true, nil
+	})
+	if err != nil {
+		return "", err
+	}
+	fmt.Fprint(&bufferSalida, "====================================================\n")
+	return bufferSalida.String(), nil
+}
+
+// comandoXattr resuelve la ruta, revisa el permiso de escritura (o de lectura si no
+// modifica) y ejecuta la operacion sobre el inodo. Si la operacion indica que lo modifico,
+// guarda el inodo y el superbloque
+func comandoXattr(cmd *XATTR, escritura bool, operacion func(*os.File, *Estructuras.SuperBlock, *Estructuras.INodo) (bool, error)) error {
+	if !Global.VerificarSesionActiva() {
+		return fmt.Errorf("no hay un usuario logueado")
+	}
+
+	idParticion := Global.UsuarioActual.Id
+	superBloqueParticion, particionMontada, rutaParticion, err := Global.ObtenerSuperblockParticionMontada(idParticion)
+	if err != nil {
+		return fmt.Errorf("error al obtener la particion montada: %w", err)
+	}
+
+	archivo, err := os.OpenFile(rutaParticion, os.O_RDWR, 0666)
+	if err != nil {
+		return fmt.Errorf("error al abrir el archivo de particion: %w", err)
+	}
+	defer archivo.Close()
+
+	credenciales := Global.CredencialesActuales(archivo, superBloqueParticion)
+	resuelta, err := superBloqueParticion.ResolverRuta(archivo, cmd.ruta, credenciales)
+	if err != nil {
+		return fmt.Errorf("error al resolver '%s': %w", cmd.ruta, err)
+	}
+	if escritura && !verificarPermisosEscritura(archivo, superBloqueParticion, resuelta.Inodo) {
+		return fmt.Errorf("no tiene permisos de escritura sobre '%s'", cmd.ruta)
+	}
+	if !escritura && !verificarPermisosLectura(archivo, superBloqueParticion, resuelta.Inodo) {
+		return fmt.Errorf("no tiene permisos de lectura sobre '%s'", cmd.ruta)
+	}
+
+	desplazamiento := superBloqueParticion.CalcularDesplazamientoInodo(resuelta.Inodo)
+	inodo := &Estructuras.INodo{}
+	if err := inodo.Decodificar(archivo, desplazamiento); err != nil {
+		return fmt.Errorf("error al deserializar el inodo %d: %w", resuelta.Inodo, err)
+	}
+
+	modificado, err := operacion(archivo, superBloqueParticion, inodo)
+	if err != nil {
+		return err
+	}
+	if !modificado {
+		return nil
+	}
+	if err := inodo.Codificar(archivo, desplazamiento); err != nil {
+		return fmt.Errorf("error al guardar el inodo %d: %w", resuelta.Inodo, err)
+	}
+	if err := superBloqueParticion.Codificar(archivo, int64(particionMontada.Part_start)); err != nil {
+		return fmt.Errorf("error al guardar el superbloque: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package Forge
+
+import (
+	"strings"
+	"testing"
+
+	Pruebas "backend/Comandos/Pruebas"
+	Global "backend/Global"
+)
+
+func TestAtributosExtendidos(t *testing.T) {
+	id := Pruebas.MontarParticionFormateada(t, "")
+	bloquesUsados := func() int32 {
+		t.Helper()
+		sb, _, _, err := Global.ObtenerSuperblockParticionMontada(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sb.S_blocks_count
+	}
+	Pruebas.Ejecutar(t, ParserMkdir, "-path=/docs")
+	Pruebas.Ejecutar(t, ParserMkdir, "-path=/copias")
+	Pruebas.Ejecutar(t, ParserMkfile, "-path=/docs/a.txt -size=10")
+	usadosSinAtributos := bloquesUsados()
+
+	Pruebas.Ejecutar(t, ParserSetxattr, `-path=/docs/a.txt -name=user.autor -value="ana lopez"`)
+	Pruebas.Ejecutar(t, ParserSetxattr, "-path=/docs/a.txt -name=user.version -value=1")
+	Pruebas.Ejecutar(t, ParserSetxattr, "-path=/docs/a.txt -name=user.version -value=2")
+	if usados := bloquesUsados(); usados != usadosSinAtributos+1 {
+		t.Errorf("los atributos ocupan %d bloques, se esperaba uno", usados-usadosSinAtributos)
+	}
+	Pruebas.Ejecutar(t, ParserCopy, "-path=/docs/a.txt -destino=/copias")
+
+	casos := []struct {
+		ruta   string
+		nombre string
+		valor  string // vacio si el atributo no debe existir
+	}{
+		{"/docs/a.txt", "user.autor", `"ana lopez"`},
+		{"/docs/a.txt", "user.version", `"2"`},
+		{"/docs/a.txt", "user.otro", ""},
+		{"/copias/a.txt", "user.autor", `"ana lopez"`}, // copy conserva los atributos
+		{"/docs", "user.autor", ""},
+	}
+	for _, caso := range casos {
+		salida, err := ParserGetxattr(strings.Fields("-path=" + caso.ruta + " -name=" + caso.nombre))
+		if caso.valor == "" {
+			if err == nil {
+				t.Errorf("getxattr %s %s encontro un atributo que no existe:\n%s", caso.ruta, caso.nombre, salida)
+			}
+			continue
+		}
+		if err != nil || !strings.Contains(salida, caso.nombre+"="+caso.valor) {
+			t.Errorf("getxattr %s %s: %v\n%s", caso.ruta, caso.nombre, err, salida)
+		}
+	}
+
+	// Quitar el ultimo atributo libera el bloque, y remove libera el de la copia
+	Pruebas.Ejecutar(t, ParserRmxattr, "-path=/docs/a.txt -name=user.autor")
+	Pruebas.Ejecutar(t, ParserRmxattr, "-path=/docs/a.txt -name=user.version")
+	Pruebas.Ejecutar(t, ParserRemove, "-path=/copias/a.txt")
+	if usados := bloquesUsados(); usados != usadosSinAtributos {
+		t.Errorf("quedaron %d bloques usados, se esperaban %d", usados, usadosSinAtributos)
+	}
+}
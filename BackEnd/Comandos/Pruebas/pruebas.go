@@ -0,0 +1,89 @@
+// Package Pruebas reune la preparacion que comparten las pruebas de los comandos: crear
+// el disco, montar y formatear la particion e iniciar la sesion de root
+package Pruebas
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	Disk "backend/Comandos/Disk"
+	User "backend/Comandos/User"
+	Global "backend/Global"
+)
+
+// Ejecutar corre un comando con los tokens separados como lo hace el analizador y
+// detiene la prueba si falla
+func Ejecutar(t testing.TB, parser func([]string) (string, error), linea string) string {
+	t.Helper()
+	salida, err := parser(strings.Fields(linea))
+	if err != nil {
+		t.Fatalf("%s: %v", linea, err)
+	}
+	return salida
+}
+
+// SinCache corre un comando que escribe el disco directamente con las caches suspendidas,
+// como lo hace el analizador
+func SinCache(parser func([]string) (string, error), linea string) error {
+	err := Global.SuspenderCaches()
+	defer Global.ReanudarCaches()
+	if err != nil {
+		return err
+	}
+	_, err = parser(strings.Fields(linea))
+	return err
+}
+
+// Disco crea en un directorio temporal un disco de 3 MB con una particion Part1 de 1 MB
+// y retorna su ruta. parametrosMkdisk se agrega a mkdisk, por ejemplo "-table=GPT"
+func Disco(t testing.TB, parametrosMkdisk string) string {
+	t.Helper()
+	disco := filepath.Join(t.TempDir(), "disco.mia")
+	Ejecutar(t, Disk.ParserMkdisk, "-size=3 -unit=M -path="+disco+" "+parametrosMkdisk)
+	Ejecutar(t, Disk.ParserFdisk, "-size=1 -unit=M -path="+disco+" -name=Part1")
+	return disco
+}
+
+// Montar monta la particion del disco y retorna su ID; se desmonta al terminar la prueba
+func Montar(t testing.TB, disco, nombre string) string {
+	t.Helper()
+	antes := make(map[string]bool)
+	for id := range Global.ParticionesMontadas {
+		antes[id] = true
+	}
+	Ejecutar(t, Disk.ParserMount, "-path="+disco+" -name="+nombre)
+	var id string
+	for montada := range Global.ParticionesMontadas {
+		if !antes[montada] {
+			id = montada
+		}
+	}
+	if id == "" {
+		t.Fatalf("mount no registro la particion %s de %s", nombre, disco)
+	}
+	t.Cleanup(func() {
+		Global.Logout()
+		Disk.ParserUnmount([]string{"-id=" + id})
+	})
+	return id
+}
+
+// Formatear da formato a la particion montada y deja la sesion de root iniciada en ella
+func Formatear(t testing.TB, id, parametrosMkfs string) {
+	t.Helper()
+	if err := SinCache(Disk.ParserMkfs, "-id="+id+" -type=full "+parametrosMkfs); err != nil {
+		t.Fatal(err)
+	}
+	Global.Logout()
+	Ejecutar(t, User.ParserLogin, "-user=root -pass=123 -id="+id)
+}
+
+// MontarParticionFormateada crea un disco con Disco, monta y formatea su particion con
+// parametrosMkfs y deja la sesion de root iniciada en ella. Retorna el ID de montaje
+func MontarParticionFormateada(t testing.TB, parametrosMkfs string) string {
+	t.Helper()
+	id := Montar(t, Disco(t, ""), "Part1")
+	Formatear(t, id, parametrosMkfs)
+	return id
+}
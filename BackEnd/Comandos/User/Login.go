@@ -2,7 +2,6 @@ package User
 
 import (
 	"bytes"
-	"encoding/binary"
 	"fmt"
 	"os"
 	"regexp"
@@ -92,6 +91,9 @@ func comandoLogin(login *LOGIN, bufferSalida *bytes.Buffer) error {
 	if err != nil {
 		return fmt.Errorf("no se pudo cargar el SuperBlock: %v", err)
 	}
+	if err := sb.ValidarFormatoInodos(); err != nil {
+		return err
+	}
 	fmt.Fprintln(bufferSalida, "SuperBlock cargado correctamente")
 
 	// Acceder al inodo del archivo users.txt (inodo 1)
@@ -103,7 +105,7 @@ func comandoLogin(login *LOGIN, bufferSalida *bytes.Buffer) error {
 
 	// Leer el inodo 1 (que contiene el archivo users.txt)
 	var inodoUsuarios Estructuras.INodo
-	desplazamientoInodo := int64(sb.S_inode_start + int32(binary.Size(inodoUsuarios)))
+	desplazamientoInodo := sb.CalcularDesplazamientoInodo(1)
 
 	err = inodoUsuarios.Decodificar(archivo, desplazamientoInodo)
 	if err != nil {
@@ -113,22 +115,11 @@ func comandoLogin(login *LOGIN, bufferSalida *bytes.Buffer) error {
 	inodoUsuarios.ActualizarTiempoAcceso()
 
 	// Leer el contenido de los bloques asociados al archivo users.txt
-	var contenido string
-	for _, indiceBloques := range inodoUsuarios.I_block {
-		if indiceBloques == -1 {
-			continue
-		}
-
-		desplazamientoBloque := int64(sb.S_block_start + indiceBloques*int32(binary.Size(Estructuras.FileBlock{})))
-
-		var bloqueArchivo Estructuras.FileBlock
-		err = bloqueArchivo.Decodificar(archivo, desplazamientoBloque)
-		if err != nil {
-			return fmt.Errorf("error leyendo bloque de users.txt: %v", err)
-		}
-
-		contenido += string(bloqueArchivo.B_cont[:])
+	datosUsuarios, err := inodoUsuarios.LeerDatos(archivo, sb)
+	if err != nil {
+		return fmt.Errorf("error leyendo bloques de users.txt: %v", err)
 	}
+	contenido := string(datosUsuarios)
 
 	// Validar el usuario y contrasena
 	encontrado := false
@@ -149,6 +140,7 @@ func comandoLogin(login *LOGIN, bufferSalida *bytes.Buffer) error {
 				Global.UsuarioActual = usuario
 				Global.UsuarioActual.Estado = true
 				Global.UsuarioActual.Id = login.ID
+				Estructuras.ReiniciarCuotas() // lo calculado para la sesion anterior ya no aplica
 				fmt.Fprintf(bufferSalida, "Bienvenido %s, inicio de sesion exitoso.\n", usuario.Nombre)
 				break
 			}
@@ -38,6 +38,7 @@ func comandoLogout(bufferSalida *bytes.Buffer) error {
 
 	// Reiniciar la estructura del usuario actual
 	Global.UsuarioActual = &Estructuras.Usuario{}
+	Estructuras.ReiniciarCuotas()
 
 	fmt.Fprintln(bufferSalida, "Sesion cerrada correctamente.")
 
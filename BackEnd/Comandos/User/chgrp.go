@@ -1,7 +1,6 @@
 package User
 
 import (
-	"encoding/binary"
 	"fmt"
 	"os"
 	"regexp"
@@ -78,7 +77,7 @@ func comandoChgrp(chgrp *CHGRP, bufferSalida *strings.Builder) error {
 	}
 
 	var inodoUsuarios Estructuras.INodo
-	desplazamientoInodo := int64(sb.S_inode_start + int32(binary.Size(inodoUsuarios))) //ubicacion de los bloques de users.txt
+	desplazamientoInodo := sb.CalcularDesplazamientoInodo(1) //ubicacion de los bloques de users.txt
 	err = inodoUsuarios.Decodificar(archivo, desplazamientoInodo)
 	if err != nil {
 		return fmt.Errorf("error leyendo el inodo de users.txt: %v", err)
@@ -177,22 +176,9 @@ func CambiarGrupoUsuario(archivo *os.File, sb *Estructuras.SuperBlock, inodoUsua
 	}
 
 	// Limpiar los bloques asignados antes de escribir el nuevo contenido
-	for _, indiceBloques := range inodoUsuarios.I_block {
-		if indiceBloques == -1 {
-			break
-		}
-
-		desplazamientoBloque := int64(sb.S_block_start + indiceBloques*sb.S_block_size)
-		var bloqueArchivo Estructuras.FileBlock
-
-		// Limpiar el contenido del bloque
-		bloqueArchivo.LimpiarContenido()
-
-		// Escribir el bloque vacio de nuevo
-		err = bloqueArchivo.Codificar(archivo, desplazamientoBloque)
-		if err != nil {
-			return fmt.Errorf("error escribiendo bloque limpio %d: %w", indiceBloques, err)
-		}
+	err = Global.LimpiarBloquesArchivo(archivo, sb, inodoUsuarios)
+	if err != nil {
+		return err
 	}
 
 	err = EscribirContenidoEnBloques(archivo, sb, inodoUsuarios, nuevoContenido)
@@ -200,13 +186,11 @@ func CambiarGrupoUsuario(archivo *os.File, sb *Estructuras.SuperBlock, inodoUsua
 		return fmt.Errorf("error guardando los cambios en users.txt: %v", err)
 	}
 
-	inodoUsuarios.I_size = int32(len(strings.Join(nuevoContenido, "\n")))
-
 	inodoUsuarios.ActualizarTiempoModificacion()
 	inodoUsuarios.ActualizarTiempoPermisos()
 
 	// Guardar el inodo actualizado en el archivo
-	desplazamientoInodo := int64(sb.S_inode_start + int32(binary.Size(*inodoUsuarios)))
+	desplazamientoInodo := sb.CalcularDesplazamientoInodo(1)
 	err = inodoUsuarios.Codificar(archivo, desplazamientoInodo)
 	if err != nil {
 		return fmt.Errorf("error actualizando inodo de users.txt: %w", err)
@@ -215,39 +199,18 @@ func CambiarGrupoUsuario(archivo *os.File, sb *Estructuras.SuperBlock, inodoUsua
 	return nil
 }
 
-// Escribe el contenido de users.txt dividiendolo
+// Escribe el contenido de users.txt dividiendolo y registra su tamaño, incluido el salto
+// de linea final
 func EscribirContenidoEnBloques(archivo *os.File, sb *Estructuras.SuperBlock, inodoUsuarios *Estructuras.INodo, contenido []string) error {
 	// Convertir el contenido en una cadena
 	contenidoFinal := strings.Join(contenido, "\n") + "\n"
-	datos := []byte(contenidoFinal)
-
-	// Dimension maxima del bloque
-	dimensionBloque := int(sb.S_block_size)
 
-	// Escribir el contenido por bloques
-	for i, indiceBloques := range inodoUsuarios.I_block {
-		if indiceBloques == -1 {
-			break // No hay mas bloques asignados
-		}
-
-		// Dividir los datos en bloques de dimension maxima
-		inicio := i * dimensionBloque
-		fin := inicio + dimensionBloque
-		if fin > len(datos) {
-			fin = len(datos)
-		}
-
-		// Crear un bloque con el contenido correspondiente
-		var bloqueArchivo Estructuras.FileBlock
-		copy(bloqueArchivo.B_cont[:], datos[inicio:fin])
-
-		desplazamientoBloque := int64(sb.S_block_start + indiceBloques*sb.S_block_size)
-
-		err := bloqueArchivo.Codificar(archivo, desplazamientoBloque)
-		if err != nil {
-			return fmt.Errorf("error escribiendo bloque %d: %w", indiceBloques, err)
-		}
+	// Escribir el contenido por bloques, siguiendo los apuntadores indirectos
+	err := inodoUsuarios.SobrescribirBloquesDatos(archivo, sb, []byte(contenidoFinal))
+	if err != nil {
+		return fmt.Errorf("error escribiendo bloques de users.txt: %w", err)
 	}
+	inodoUsuarios.I_size = int32(len(contenidoFinal))
 
 	return nil
 }
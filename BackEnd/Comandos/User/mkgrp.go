@@ -2,7 +2,6 @@ package User
 
 import (
 	"bytes"
-	"encoding/binary"
 	"fmt"
 	"os"
 	"regexp"
@@ -73,21 +72,15 @@ func comandoMkgrp(mkgrp *MKGRP, bufferSalida *bytes.Buffer) error {
 	defer archivo.Close()
 
 	// Cargar el SuperBlock y la particion
-	mbr, sb, _, err := Global.ObtenerParticionMontadaReporte(Global.UsuarioActual.Id)
+	sb, particion, _, err := Global.ObtenerSuperblockParticionMontada(Global.UsuarioActual.Id)
 	if err != nil {
 		return fmt.Errorf("no se pudo cargar el SuperBlock: %v", err)
 	}
 
-	// Obtener la particion asociada al id
-	particion, err := mbr.ObtenerParticionPorID(Global.UsuarioActual.Id)
-	if err != nil {
-		return fmt.Errorf("no se pudo obtener la particion: %v", err)
-	}
-
 	// Leer el inodo de users.txt
 	var inodoUsuarios Estructuras.INodo
 	// Calcular el offset del inodo de users.txt, esta en el inodo 1
-	desplazamientoInodo := int64(sb.S_inode_start + int32(binary.Size(inodoUsuarios)))
+	desplazamientoInodo := sb.CalcularDesplazamientoInodo(1)
 	// Decodificar el inodo de users.txt
 	err = inodoUsuarios.Decodificar(archivo, desplazamientoInodo)
 	inodoUsuarios.ActualizarTiempoAcceso()
@@ -136,6 +129,14 @@ func comandoMkgrp(mkgrp *MKGRP, bufferSalida *bytes.Buffer) error {
 
 // Calcula el siguiente ID disponible para un grupo o usuario en users.txt
 func calcularSiguienteID(archivo *os.File, sb *Estructuras.SuperBlock, inodo *Estructuras.INodo) (int, error) {
+	return calcularSiguienteIDDeTipo(archivo, sb, inodo, "")
+}
+
+// Calcula el siguiente ID contando solo las lineas del tipo indicado ("U" o "G"); con
+// tipo vacio cuenta todas. Con un tipo, las lineas eliminadas (ID 0) tambien cuentan:
+// cada una tuvo un ID que no se vuelve a usar, asi un usuario nuevo no hereda los
+// archivos ni las cuotas de uno eliminado
+func calcularSiguienteIDDeTipo(archivo *os.File, sb *Estructuras.SuperBlock, inodo *Estructuras.INodo, tipo string) (int, error) {
 	// Leer el contenido de users.txt
 	contenido, err := Global.LeerBloquesArchivo(archivo, sb, inodo)
 	if err != nil {
@@ -143,7 +144,7 @@ func calcularSiguienteID(archivo *os.File, sb *Estructuras.SuperBlock, inodo *Es
 	}
 
 	lineas := strings.Split(contenido, "\n")
-	maxID := 0
+	maxID, cantidad := 0, 0
 	for _, linea := range lineas {
 		if linea == "" {
 			continue
@@ -154,6 +155,10 @@ func calcularSiguienteID(archivo *os.File, sb *Estructuras.SuperBlock, inodo *Es
 			// Ignorar lineas mal formadas
 			continue
 		}
+		if tipo != "" && campos[1] != tipo {
+			continue
+		}
+		cantidad++
 
 		// Convertir el primer campo (ID) a entero
 		id, err := strconv.Atoi(campos[0])
@@ -169,5 +174,8 @@ func calcularSiguienteID(archivo *os.File, sb *Estructuras.SuperBlock, inodo *Es
 	}
 
 	// Devolver el siguiente ID disponible
+	if tipo != "" && cantidad > maxID {
+		maxID = cantidad
+	}
 	return maxID + 1, nil
 }
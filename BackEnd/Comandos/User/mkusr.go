@@ -2,7 +2,6 @@ package User
 
 import (
     "bytes"
-    "encoding/binary"
     "fmt"
     "os"
     "regexp"
@@ -102,19 +101,13 @@ func comandoMkusr(mkusr *MKUSR, bufferSalida *bytes.Buffer) error {
     defer archivo.Close()
 
     // Cargar SuperBlock y particion utilizando la funcion ObtenerParticionMontadaRep
-    mbr, sb, _, err := Global.ObtenerParticionMontadaReporte(Global.UsuarioActual.Id)
+    sb, particion, _, err := Global.ObtenerSuperblockParticionMontada(Global.UsuarioActual.Id)
     if err != nil {
         return fmt.Errorf("no se pudo cargar el SuperBlock: %v", err)
     }
 
-    // Obtener la particion montada
-    particion, err := mbr.ObtenerParticionPorID(Global.UsuarioActual.Id)
-    if err != nil {
-        return fmt.Errorf("no se pudo obtener la particion: %v", err)
-    }
-
     var inodoUsuarios Estructuras.INodo
-    desplazamientoInodo := int64(sb.S_inode_start + int32(binary.Size(inodoUsuarios))) //ubicacion de los bloques de users.txt
+    desplazamientoInodo := sb.CalcularDesplazamientoInodo(1) //ubicacion de los bloques de users.txt
     err = inodoUsuarios.Decodificar(archivo, desplazamientoInodo)
     if err != nil {
         return fmt.Errorf("error leyendo el inodo de users.txt: %v", err)
@@ -130,7 +123,13 @@ func comandoMkusr(mkusr *MKUSR, bufferSalida *bytes.Buffer) error {
         return fmt.Errorf("el usuario '%s' ya existe", mkusr.Usuario)
     }
 
-    usuario := Estructuras.NuevoUsuario(fmt.Sprintf("%d", sb.S_inodes_count+1), mkusr.Grupo, mkusr.Usuario, mkusr.Contrasena)
+    // Cada usuario necesita un UID propio: los permisos y las cuotas se asignan por UID
+    siguienteUID, err := calcularSiguienteIDDeTipo(archivo, sb, &inodoUsuarios, "U")
+    if err != nil {
+        return fmt.Errorf("error calculando el siguiente UID: %v", err)
+    }
+
+    usuario := Estructuras.NuevoUsuario(fmt.Sprintf("%d", siguienteUID), mkusr.Grupo, mkusr.Usuario, mkusr.Contrasena)
 
     // Insertar la nueva entrada en el archivo users.txt
     err = Global.InsertarEnArchivoUsuarios(archivo, sb, &inodoUsuarios, usuario.ToString())
@@ -0,0 +1,96 @@
+package User
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"slices"
+
+	Estructuras "backend/Estructuras"
+	Global "backend/Global"
+)
+
+// ParserRepquota muestra el uso de bloques e inodos de cada usuario y grupo contra sus
+// limites; no recibe parametros
+func ParserRepquota(tokens []string) (string, error) {
+	if len(tokens) > 0 {
+		return "", fmt.Errorf("parametro invalido: %s", tokens[0])
+	}
+	var bufferSalida bytes.Buffer
+	err := comandoRepquota(&bufferSalida)
+	if err != nil {
+		return "", err
+	}
+	return bufferSalida.String(), nil
+}
+
+func comandoRepquota(bufferSalida *bytes.Buffer) error {
+	fmt.Fprintln(bufferSalida, "===================== REPQUOTA =====================")
+
+	if !Global.VerificarSesionActiva() {
+		return fmt.Errorf("no hay ninguna sesion activa")
+	}
+	if Global.UsuarioActual.Nombre != "root" {
+		return fmt.Errorf("solo el usuario root puede ejecutar este comando")
+	}
+
+	sb, _, ruta, err := Global.ObtenerSuperblockParticionMontada(Global.UsuarioActual.Id)
+	if err != nil {
+		return fmt.Errorf("error al obtener la particion montada: %w", err)
+	}
+	archivo, err := os.Open(ruta)
+	if err != nil {
+		return fmt.Errorf("no se puede abrir el archivo de la particion: %v", err)
+	}
+	defer archivo.Close()
+
+	tabla, err := sb.LeerCuotas(archivo)
+	if err != nil {
+		return err
+	}
+	usoUsuarios, usoGrupos, err := sb.CalcularUsoCuotas(archivo)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(bufferSalida, "Particion %s\n", Global.UsuarioActual.Id)
+	reportarCuotas(bufferSalida, "Usuario", tabla.Usuarios, usoUsuarios, func(id int32) string {
+		nombre, _ := Global.NombresPropietario(archivo, sb, id, id)
+		return nombre
+	})
+	reportarCuotas(bufferSalida, "Grupo", tabla.Grupos, usoGrupos, func(id int32) string {
+		_, nombre := Global.NombresPropietario(archivo, sb, id, id)
+		return nombre
+	})
+	fmt.Fprintln(bufferSalida, "====================================================")
+	return nil
+}
+
+// reportarCuotas escribe una fila por cada id con uso o con limites. Las marcas indican
+// con '+' si los bloques (primera) o los inodos (segunda) pasan su limite suave
+func reportarCuotas(bufferSalida *bytes.Buffer, titulo string, limites map[int32]Estructuras.LimiteCuota, usos map[int32]Estructuras.UsoCuota, nombre func(int32) string) {
+	var ids []int32
+	for id := range usos {
+		ids = append(ids, id)
+	}
+	for id := range limites {
+		if _, existe := usos[id]; !existe {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+
+	fmt.Fprintf(bufferSalida, "\n%-12s %-2s | %8s %8s %8s | %8s %8s %8s\n", titulo, "", "Bloques", "Suave", "Duro", "Inodos", "Suave", "Duro")
+	for _, id := range ids {
+		uso, limite := usos[id], limites[id]
+		marcas := []byte("--")
+		if limite.BloquesSuave > 0 && uso.Bloques > limite.BloquesSuave {
+			marcas[0] = '+'
+		}
+		if limite.InodosSuave > 0 && uso.Inodos > limite.InodosSuave {
+			marcas[1] = '+'
+		}
+		fmt.Fprintf(bufferSalida, "%-12s %-2s | %8d %8d %8d | %8d %8d %8d\n", nombre(id), marcas,
+			uso.Bloques, limite.BloquesSuave, limite.BloquesDuro, uso.Inodos, limite.InodosSuave, limite.InodosDuro)
+	}
+}
@@ -2,7 +2,6 @@ package User
 
 import (
 	"bytes"
-	"encoding/binary"
 	"fmt"
 	"os"
 	"regexp"
@@ -73,20 +72,14 @@ func comandoRmgrp(rmgrp *RMGRP, bufferSalida *bytes.Buffer) error {
 	defer archivo.Close()
 
 	// Cargar el SuperBlock y la particion
-	mbr, sb, _, err := Global.ObtenerParticionMontadaReporte(Global.UsuarioActual.Id)
+	sb, particion, _, err := Global.ObtenerSuperblockParticionMontada(Global.UsuarioActual.Id)
 	if err != nil {
 		return fmt.Errorf("no se pudo cargar el SuperBlock: %v", err)
 	}
 
-	// Obtener la particion montada
-	particion, err := mbr.ObtenerParticionPorID(Global.UsuarioActual.Id)
-	if err != nil {
-		return fmt.Errorf("no se pudo obtener la particion: %v", err)
-	}
-
 	// Leer el inodo de users.txt
 	var inodoUsuarios Estructuras.INodo
-	desplazamientoInodo := int64(sb.S_inode_start + int32(binary.Size(inodoUsuarios))) //posicion del inodo de users.txt
+	desplazamientoInodo := sb.CalcularDesplazamientoInodo(1) //posicion del inodo de users.txt
 	err = inodoUsuarios.Decodificar(archivo, desplazamientoInodo)
 	if err != nil {
 		return fmt.Errorf("error leyendo el inodo de users.txt: %v", err)
@@ -184,23 +177,10 @@ func ActualizarEstadoEntidadOEliminarUsuarios(archivo *os.File, sb *Estructuras.
 	if modificado {
 		contenidoActualizado := strings.Join(lineas, "\n")
 
-		for _, indiceBloques := range inodoUsuarios.I_block {
-			if indiceBloques == -1 {
-				// No hay mas bloques asignados
-				break
-			}
-
-			desplazamientoBloque := int64(sb.S_block_start + indiceBloques*sb.S_block_size)
-			var bloqueArchivo Estructuras.FileBlock
-
-			// Limpiar el contenido del bloque
-			bloqueArchivo.LimpiarContenido()
-
-			// Escribir el bloque vacio de nuevo
-			err = bloqueArchivo.Codificar(archivo, desplazamientoBloque)
-			if err != nil {
-				return fmt.Errorf("error escribiendo bloque limpio %d: %w", indiceBloques, err)
-			}
+		// Limpiar los bloques asignados, incluidos los indirectos
+		err = Global.LimpiarBloquesArchivo(archivo, sb, inodoUsuarios)
+		if err != nil {
+			return err
 		}
 
 		// Reescribir todo el contenido en los bloques despues de limpiar
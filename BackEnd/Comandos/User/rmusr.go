@@ -2,7 +2,6 @@ package User
 
 import (
 	"bytes"
-	"encoding/binary"
 	"fmt"
 	"os"
 	"regexp"
@@ -71,19 +70,13 @@ func comandoRmusr(rmusr *RMUSR, bufferSalida *bytes.Buffer) error {
 	}
 	defer archivo.Close()
 
-	mbr, sb, _, err := Global.ObtenerParticionMontadaReporte(Global.UsuarioActual.Id)
+	sb, particion, _, err := Global.ObtenerSuperblockParticionMontada(Global.UsuarioActual.Id)
 	if err != nil {
 		return fmt.Errorf("no se pudo cargar el SuperBlock: %v", err)
 	}
 
-	// Obtener la particion montada
-	particion, err := mbr.ObtenerParticionPorID(Global.UsuarioActual.Id)
-	if err != nil {
-		return fmt.Errorf("no se pudo obtener la particion: %v", err)
-	}
-
 	var inodoUsuarios Estructuras.INodo
-	desplazamientoInodo := int64(sb.S_inode_start + int32(binary.Size(inodoUsuarios))) // Posicion de los bloques de users.txt
+	desplazamientoInodo := sb.CalcularDesplazamientoInodo(1) // Posicion de los bloques de users.txt
 	err = inodoUsuarios.Decodificar(archivo, desplazamientoInodo)
 	if err != nil {
 		return fmt.Errorf("error leyendo el inodo de users.txt: %v", err)
@@ -178,23 +171,12 @@ func limpiarYActualizarContenido(lineas []string) string {
 
 // Limpia los bloques y escribe el contenido actualizado en el archivo
 func escribirCambiosEnArchivo(archivo *os.File, sb *Estructuras.SuperBlock, inodoUsuarios *Estructuras.INodo, contenido string) error {
-	for _, indiceBloques := range inodoUsuarios.I_block {
-		if indiceBloques == -1 {
-			break
-		}
-
-		desplazamientoBloque := int64(sb.S_block_start + indiceBloques*sb.S_block_size)
-		var bloqueArchivo Estructuras.FileBlock
-
-		bloqueArchivo.LimpiarContenido()
-
-		err := bloqueArchivo.Codificar(archivo, desplazamientoBloque)
-		if err != nil {
-			return fmt.Errorf("error escribiendo bloque limpio %d: %w", indiceBloques, err)
-		}
+	err := Global.LimpiarBloquesArchivo(archivo, sb, inodoUsuarios)
+	if err != nil {
+		return err
 	}
 
-	err := Global.EscribirBloquesUsuarios(archivo, sb, inodoUsuarios, contenido)
+	err = Global.EscribirBloquesUsuarios(archivo, sb, inodoUsuarios, contenido)
 	if err != nil {
 		return fmt.Errorf("error guardando los cambios en users.txt: %v", err)
 	}
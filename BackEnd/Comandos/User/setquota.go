@@ -0,0 +1,170 @@
+package User
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	Estructuras "backend/Estructuras"
+	Global "backend/Global"
+)
+
+// SETQUOTA estructura del comando setquota; los limites en nil conservan su valor actual
+type SETQUOTA struct {
+	Usuario      string
+	Grupo        string
+	BloquesSuave *int32
+	BloquesDuro  *int32
+	InodosSuave  *int32
+	InodosDuro   *int32
+}
+
+// ParserSetquota parsea el comando setquota y guarda los limites
+func ParserSetquota(tokens []string) (string, error) {
+	var bufferSalida bytes.Buffer
+	cmd := &SETQUOTA{}
+
+	re := regexp.MustCompile(`(?i)^-(user|grp|bsoft|bhard|isoft|ihard)=[^\s]+$`)
+	for _, token := range tokens {
+		if !re.MatchString(token) {
+			return "", fmt.Errorf("parametro invalido: %s", token)
+		}
+		kv := strings.SplitN(token, "=", 2)
+		clave, valor := strings.ToLower(kv[0]), strings.Trim(kv[1], "\"")
+		switch clave {
+		case "-user":
+			cmd.Usuario = valor
+		case "-grp":
+			cmd.Grupo = valor
+		default:
+			numero, err := strconv.ParseInt(valor, 10, 32)
+			if err != nil || numero < 0 {
+				return "", fmt.Errorf("el parametro %s debe ser un entero no negativo: %s", clave, valor)
+			}
+			limite := int32(numero)
+			switch clave {
+			case "-bsoft":
+				cmd.BloquesSuave = &limite
+			case "-bhard":
+				cmd.BloquesDuro = &limite
+			case "-isoft":
+				cmd.InodosSuave = &limite
+			case "-ihard":
+				cmd.InodosDuro = &limite
+			}
+		}
+	}
+
+	if (cmd.Usuario == "") == (cmd.Grupo == "") {
+		return "", errors.New("debe indicar -user o -grp (solo uno)")
+	}
+	if cmd.BloquesSuave == nil && cmd.BloquesDuro == nil && cmd.InodosSuave == nil && cmd.InodosDuro == nil {
+		return "", errors.New("debe indicar al menos un limite: -bsoft, -bhard, -isoft o -ihard")
+	}
+
+	err := comandoSetquota(cmd, &bufferSalida)
+	if err != nil {
+		return "", err
+	}
+	return bufferSalida.String(), nil
+}
+
+// comandoSetquota actualiza los limites del usuario o grupo en el archivo de cuotas. Con
+// todos los limites en 0 la entrada se elimina
+func comandoSetquota(setquota *SETQUOTA, bufferSalida *bytes.Buffer) error {
+	fmt.Fprintln(bufferSalida, "===================== SETQUOTA =====================")
+
+	if !Global.VerificarSesionActiva() {
+		return fmt.Errorf("no hay ninguna sesion activa")
+	}
+	if Global.UsuarioActual.Nombre != "root" {
+		return fmt.Errorf("solo el usuario root puede ejecutar este comando")
+	}
+
+	sb, particion, ruta, err := Global.ObtenerSuperblockParticionMontada(Global.UsuarioActual.Id)
+	if err != nil {
+		return fmt.Errorf("error al obtener la particion montada: %w", err)
+	}
+	archivo, err := os.OpenFile(ruta, os.O_RDWR, 0666)
+	if err != nil {
+		return fmt.Errorf("no se puede abrir el archivo de la particion: %v", err)
+	}
+	defer archivo.Close()
+
+	tipo, nombre := "U", setquota.Usuario
+	if setquota.Grupo != "" {
+		tipo, nombre = "G", setquota.Grupo
+	}
+	id, err := identificadorEnUsuarios(archivo, sb, nombre, tipo)
+	if err != nil {
+		return err
+	}
+
+	tabla, err := sb.LeerCuotas(archivo)
+	if err != nil {
+		return err
+	}
+	limites := tabla.Usuarios
+	if tipo == "G" {
+		limites = tabla.Grupos
+	}
+
+	limite := limites[id]
+	asignar := func(destino *int32, valor *int32) {
+		if valor != nil {
+			*destino = *valor
+		}
+	}
+	asignar(&limite.BloquesSuave, setquota.BloquesSuave)
+	asignar(&limite.BloquesDuro, setquota.BloquesDuro)
+	asignar(&limite.InodosSuave, setquota.InodosSuave)
+	asignar(&limite.InodosDuro, setquota.InodosDuro)
+	if limite.BloquesDuro > 0 && limite.BloquesSuave > limite.BloquesDuro {
+		return fmt.Errorf("el limite suave de bloques (%d) supera al duro (%d)", limite.BloquesSuave, limite.BloquesDuro)
+	}
+	if limite.InodosDuro > 0 && limite.InodosSuave > limite.InodosDuro {
+		return fmt.Errorf("el limite suave de inodos (%d) supera al duro (%d)", limite.InodosSuave, limite.InodosDuro)
+	}
+
+	if limite.SinLimites() {
+		delete(limites, id)
+	} else {
+		limites[id] = limite
+	}
+	if err := sb.GuardarCuotas(archivo, tabla); err != nil {
+		return err
+	}
+	if err := sb.Codificar(archivo, int64(particion.Part_start)); err != nil {
+		return fmt.Errorf("error guardando el SuperBlock: %v", err)
+	}
+
+	entidad := "Usuario"
+	if tipo == "G" {
+		entidad = "Grupo"
+	}
+	fmt.Fprintf(bufferSalida, "%s '%s' (id %d): bloques suave %d, duro %d | inodos suave %d, duro %d\n",
+		entidad, nombre, id, limite.BloquesSuave, limite.BloquesDuro, limite.InodosSuave, limite.InodosDuro)
+	fmt.Fprintln(bufferSalida, "====================================================")
+	return nil
+}
+
+// identificadorEnUsuarios retorna el id del usuario o grupo activo con ese nombre
+func identificadorEnUsuarios(archivo *os.File, sb *Estructuras.SuperBlock, nombre, tipo string) (int32, error) {
+	inodoUsuarios := &Estructuras.INodo{}
+	if err := inodoUsuarios.Decodificar(archivo, int64(sb.S_inode_start+sb.S_inode_size)); err != nil {
+		return -1, fmt.Errorf("error leyendo el inodo de users.txt: %v", err)
+	}
+	linea, err := Global.BuscarEnArchivoUsuarios(archivo, sb, inodoUsuarios, nombre, tipo)
+	if err != nil {
+		return -1, err
+	}
+	id, err := strconv.Atoi(strings.Split(linea, ",")[0])
+	if err != nil || id == 0 {
+		return -1, fmt.Errorf("%s '%s' fue eliminado", tipo, nombre)
+	}
+	return int32(id), nil
+}
@@ -0,0 +1,69 @@
+package User_test
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+
+	Pruebas "backend/Comandos/Pruebas"
+	User "backend/Comandos/User"
+	Global "backend/Global"
+)
+
+func TestComandosDeUsuariosEnTablasMBRyGPT(t *testing.T) {
+	for _, tabla := range []string{"MBR", "GPT"} {
+		t.Run(tabla, func(t *testing.T) {
+			id := Pruebas.Montar(t, Pruebas.Disco(t, "-table="+tabla), "Part1")
+			Pruebas.Formatear(t, id, "")
+
+			Pruebas.Ejecutar(t, User.ParserMkgrp, "-name=ventas")
+			Pruebas.Ejecutar(t, User.ParserMkusr, "-user=ana -pass=abc -grp=ventas")
+			if _, err := User.ParserMkusr(strings.Fields("-user=ana -pass=abc -grp=ventas")); err == nil {
+				t.Fatalf("mkusr acepto un usuario repetido")
+			}
+			Pruebas.Ejecutar(t, User.ParserRmusr, "-user=ana")
+			Pruebas.Ejecutar(t, User.ParserRmgrp, "-name=ventas")
+			if _, err := User.ParserMkusr(strings.Fields("-user=ana -pass=abc -grp=ventas")); err == nil {
+				t.Fatalf("mkusr acepto un grupo eliminado")
+			}
+		})
+	}
+}
+
+func TestMkusrNoReusaUIDDeUsuarioEliminado(t *testing.T) {
+	id := Pruebas.MontarParticionFormateada(t, "")
+	Pruebas.Ejecutar(t, User.ParserMkgrp, "-name=ventas")
+	Pruebas.Ejecutar(t, User.ParserMkusr, "-user=ana -pass=abc -grp=ventas")
+	uidAna := uidDe(t, id, "ana")
+
+	// ana tenia el UID mas alto; el siguiente usuario no debe recibirlo de nuevo
+	Pruebas.Ejecutar(t, User.ParserRmusr, "-user=ana")
+	Pruebas.Ejecutar(t, User.ParserMkusr, "-user=beto -pass=abc -grp=ventas")
+	if uidBeto := uidDe(t, id, "beto"); uidBeto == uidAna {
+		t.Errorf("beto recibio el UID %s de ana, que fue eliminada", uidBeto)
+	}
+}
+
+// uidDe retorna el campo ID de la linea del usuario en users.txt
+func uidDe(t *testing.T, id, usuario string) string {
+	t.Helper()
+	if _, err := Global.SincronizarParticion(id); err != nil {
+		t.Fatal(err)
+	}
+	sistema, err := Global.SistemaArchivosMontado(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sistema.Close()
+	contenido, err := fs.ReadFile(sistema, "users.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, linea := range strings.Split(string(contenido), "\n") {
+		if campos := strings.Split(linea, ","); len(campos) == 5 && campos[1] == "U" && campos[3] == usuario {
+			return campos[0]
+		}
+	}
+	t.Fatalf("%s no aparece en users.txt:\n%s", usuario, contenido)
+	return ""
+}
@@ -0,0 +1,240 @@
+package Estructuras
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+)
+
+// ErrBloqueNoAsignado indica que el bloque logico pedido esta despues del ultimo bloque
+// de datos del inodo
+var ErrBloqueNoAsignado = errors.New("no está asignado")
+
+// ubicarIndireccion retorna el nivel de indireccion (1 simple, 2 doble, 3 triple) que
+// contiene el bloque logico n >= 12, su posicion dentro de ese nivel y cuantos bloques de
+// datos cubre el nivel completo
+func ubicarIndireccion(sb *SuperBlock, n int32) (nivel int32, restante int32, capacidad int32, err error) {
+	porBloque := sb.S_block_size / DimensionApuntador
+	restante = n - 12
+	nivel, capacidad = 1, porBloque
+	for nivel <= 3 && restante >= capacidad {
+		restante -= capacidad
+		nivel++
+		capacidad *= porBloque
+	}
+	if nivel > 3 {
+		return 0, 0, 0, fmt.Errorf("el bloque lógico %d supera la capacidad del inodo", n)
+	}
+	return nivel, restante, capacidad, nil
+}
+
+// ReadAt lee len(p) bytes del archivo a partir del desplazamiento, leyendo solo los
+// bloques que cubren ese rango. Igual que io.ReaderAt retorna io.EOF si el rango pasa del
+// final del archivo. No actualiza la fecha de acceso; eso queda a cargo de quien lee
+func (inodo *INodo) ReadAt(archivo *os.File, sb *SuperBlock, p []byte, desplazamiento int64) (int, error) {
+	if desplazamiento < 0 {
+		return 0, fmt.Errorf("desplazamiento negativo: %d", desplazamiento)
+	}
+	tamano := int64(inodo.I_size)
+	if desplazamiento >= tamano {
+		if len(p) == 0 {
+			return 0, nil
+		}
+		return 0, io.EOF
+	}
+
+	fin := min(desplazamiento+int64(len(p)), tamano)
+	dimension := int64(sb.S_block_size)
+	bloque := NuevoFileBlockVacio(sb.S_block_size)
+	leidos := 0
+	for posicion := desplazamiento; posicion < fin; {
+		fisico, err := inodo.BloqueDatos(archivo, sb, int32(posicion/dimension))
+		if err != nil {
+			return leidos, err
+		}
+		if err := bloque.Decodificar(archivo, int64(sb.S_block_start+fisico*sb.S_block_size)); err != nil {
+			return leidos, fmt.Errorf("error leyendo bloque %d: %w", fisico, err)
+		}
+		n := copy(p[leidos:fin-desplazamiento], bloque.B_cont[posicion%dimension:])
+		leidos += n
+		posicion += int64(n)
+	}
+
+	if leidos < len(p) {
+		return leidos, io.EOF
+	}
+	return leidos, nil
+}
+
+// WriteAt escribe los datos a partir del desplazamiento modificando solo los bloques que
+// cubren ese rango; los que falten se asignan con AgregarBloque (directos o por
+// indireccion). Si el desplazamiento esta despues del final, el espacio intermedio se
+// llena con ceros porque el inodo no admite bloques sin asignar en medio del archivo.
+// Actualiza I_size y las fechas en memoria; el inodo y el superbloque los guarda quien llama
+func (inodo *INodo) WriteAt(archivo *os.File, sb *SuperBlock, datos []byte, desplazamiento int64) (int, error) {
+	if desplazamiento < 0 {
+		return 0, fmt.Errorf("desplazamiento negativo: %d", desplazamiento)
+	}
+	fin := desplazamiento + int64(len(datos))
+	if fin > math.MaxInt32 {
+		return 0, fmt.Errorf("el archivo no puede superar %d bytes", math.MaxInt32)
+	}
+
+	tamano := int64(inodo.I_size)
+	escritura := datos
+	inicio := desplazamiento
+	if desplazamiento > tamano {
+		escritura = append(make([]byte, desplazamiento-tamano), datos...)
+		inicio = tamano
+	}
+
+	dimension := int64(sb.S_block_size)
+	for posicion := inicio; posicion < fin; {
+		fisico, nuevo, err := inodo.bloqueDatosOAsignar(archivo, sb, int32(posicion/dimension))
+		if err != nil {
+			return int(max(posicion-desplazamiento, 0)), err
+		}
+
+		// Un bloque recien asignado puede tener restos de otro archivo: se parte de ceros
+		desplazamientoBloque := int64(sb.S_block_start + fisico*sb.S_block_size)
+		bloque := NuevoFileBlockVacio(sb.S_block_size)
+		enBloque := posicion % dimension
+		n := min(dimension-enBloque, fin-posicion)
+		if !nuevo && n < dimension {
+			if err := bloque.Decodificar(archivo, desplazamientoBloque); err != nil {
+				return int(max(posicion-desplazamiento, 0)), fmt.Errorf("error leyendo bloque %d: %w", fisico, err)
+			}
+		}
+		copy(bloque.B_cont[enBloque:enBloque+n], escritura[posicion-inicio:])
+		if err := bloque.Codificar(archivo, desplazamientoBloque); err != nil {
+			return int(max(posicion-desplazamiento, 0)), fmt.Errorf("error escribiendo bloque %d: %w", fisico, err)
+		}
+		posicion += n
+	}
+
+	if fin > tamano {
+		inodo.I_size = int32(fin)
+	}
+	inodo.ActualizarTiempoModificacion()
+	inodo.ActualizarTiempoPermisos()
+	return len(datos), nil
+}
+
+// Truncate cambia el tamaño del archivo. Al crecer agrega ceros al final; al reducirse
+// libera los bloques de datos que quedan fuera (y los de apuntadores que quedan vacios) y
+// limpia el resto del ultimo bloque conservado
+func (inodo *INodo) Truncate(archivo *os.File, sb *SuperBlock, tamano int64) error {
+	if tamano < 0 || tamano > math.MaxInt32 {
+		return fmt.Errorf("tamaño inválido: %d", tamano)
+	}
+	actual := int64(inodo.I_size)
+	if tamano > actual {
+		_, err := inodo.WriteAt(archivo, sb, make([]byte, tamano-actual), actual)
+		return err
+	}
+
+	dimension := int64(sb.S_block_size)
+	conservar := int32((tamano + dimension - 1) / dimension)
+	asignados, err := inodo.ObtenerIndicesBloquesDatos(archivo, sb)
+	if err != nil {
+		return fmt.Errorf("error obteniendo bloques de datos: %w", err)
+	}
+	for logico := int32(len(asignados)) - 1; logico >= conservar; logico-- {
+		if err := inodo.liberarBloqueLogico(archivo, sb, logico); err != nil {
+			return err
+		}
+	}
+	if int32(len(asignados)) > conservar {
+		if err := inodo.VerificarYLiberarBloquesIndirectosVacios(archivo, sb); err != nil {
+			return err
+		}
+	}
+
+	// Los bytes despues del nuevo final no deben reaparecer si el archivo vuelve a crecer
+	if resto := tamano % dimension; resto != 0 {
+		fisico, err := inodo.BloqueDatos(archivo, sb, conservar-1)
+		if err != nil {
+			return err
+		}
+		desplazamientoBloque := int64(sb.S_block_start + fisico*sb.S_block_size)
+		bloque := NuevoFileBlockVacio(sb.S_block_size)
+		if err := bloque.Decodificar(archivo, desplazamientoBloque); err != nil {
+			return fmt.Errorf("error leyendo bloque %d: %w", fisico, err)
+		}
+		clear(bloque.B_cont[resto:])
+		if err := bloque.Codificar(archivo, desplazamientoBloque); err != nil {
+			return fmt.Errorf("error escribiendo bloque %d: %w", fisico, err)
+		}
+	}
+
+	inodo.I_size = int32(tamano)
+	inodo.ActualizarTiempoModificacion()
+	inodo.ActualizarTiempoPermisos()
+	return nil
+}
+
+// bloqueDatosOAsignar retorna el bloque fisico del bloque logico indicado; si es el
+// siguiente al ultimo asignado lo agrega e indica que es nuevo
+func (inodo *INodo) bloqueDatosOAsignar(archivo *os.File, sb *SuperBlock, logico int32) (int32, bool, error) {
+	fisico, err := inodo.BloqueDatos(archivo, sb, logico)
+	if err == nil {
+		return fisico, false, nil
+	}
+	if !errors.Is(err, ErrBloqueNoAsignado) {
+		return -1, false, err
+	}
+
+	nuevo, err := inodo.AgregarBloque(archivo, sb)
+	if err != nil {
+		return -1, false, fmt.Errorf("error asignando el bloque lógico %d: %w", logico, err)
+	}
+	// AgregarBloque ocupa la primera posicion libre; debe coincidir con el bloque pedido
+	if fisico, err := inodo.BloqueDatos(archivo, sb, logico); err != nil || fisico != nuevo {
+		return -1, false, fmt.Errorf("el bloque lógico %d no sigue al último bloque del archivo", logico)
+	}
+	return nuevo, true, nil
+}
+
+// liberarBloqueLogico libera el bloque de datos logico n y limpia el apuntador que lo
+// referencia. Los bloques de apuntadores vacios se liberan despues con
+// VerificarYLiberarBloquesIndirectosVacios
+func (inodo *INodo) liberarBloqueLogico(archivo *os.File, sb *SuperBlock, n int32) error {
+	if n < 12 {
+		fisico := inodo.I_block[n]
+		inodo.I_block[n] = -1
+		return inodo.LiberarBloque(archivo, sb, fisico)
+	}
+
+	porBloque := sb.S_block_size / DimensionApuntador
+	nivel, restante, capacidad, err := ubicarIndireccion(sb, n)
+	if err != nil {
+		return err
+	}
+	actual := inodo.I_block[11+nivel]
+	for ; nivel > 0; nivel-- {
+		if actual == -1 {
+			return fmt.Errorf("el bloque lógico %d: %w", n, ErrBloqueNoAsignado)
+		}
+		capacidad /= porBloque
+		desplazamientoBA := int64(sb.S_block_start + actual*sb.S_block_size)
+		ba := NuevoPointerBlock(sb.S_block_size)
+		if err := ba.Decodificar(archivo, desplazamientoBA); err != nil {
+			return fmt.Errorf("error leyendo bloque de apuntadores %d: %w", actual, err)
+		}
+		posicion := restante / capacidad
+		actual = int32(ba.B_apuntadores[posicion])
+		restante %= capacidad
+		if nivel == 1 {
+			ba.B_apuntadores[posicion] = -1
+			if err := ba.Codificar(archivo, desplazamientoBA); err != nil {
+				return fmt.Errorf("error actualizando bloque de apuntadores: %w", err)
+			}
+		}
+	}
+	if actual == -1 {
+		return fmt.Errorf("el bloque lógico %d: %w", n, ErrBloqueNoAsignado)
+	}
+	return inodo.LiberarBloque(archivo, sb, actual)
+}
@@ -0,0 +1,243 @@
+package Estructuras
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// MaximoNombreAtributo limita la longitud del nombre de un atributo extendido
+const MaximoNombreAtributo = 255
+
+// ErrAtributoNoEncontrado indica que el inodo no tiene el atributo pedido
+var ErrAtributoNoEncontrado = errors.New("el atributo no existe")
+
+// AtributoExtendido es un par nombre/valor guardado fuera del contenido del archivo
+type AtributoExtendido struct {
+	Nombre string
+	Valor  []byte
+}
+
+// XattrBlock guarda los atributos extendidos de un inodo. Cada atributo ocupa 1 byte con
+// la longitud del nombre, 2 bytes con la del valor, el nombre y el valor; una longitud de
+// nombre 0 marca el final. Todos los atributos de un inodo deben caber en un bloque
+type XattrBlock struct {
+	B_cont []byte
+	// Total: S_block_size bytes
+}
+
+// NuevoXattrBlock crea un bloque de atributos vacio
+func NuevoXattrBlock(tamanoBloque int32) *XattrBlock {
+	return &XattrBlock{B_cont: make([]byte, tamanoBloque)}
+}
+
+// Codificar escribe el bloque; igual que el contenido de los archivos no pasa por la cache
+func (xb *XattrBlock) Codificar(archivo *os.File, desplazamiento int64) error {
+	return (&FileBlock{B_cont: xb.B_cont}).Codificar(archivo, desplazamiento)
+}
+
+// Decodificar lee el bloque; debe crearse con NuevoXattrBlock para saber cuantos bytes leer
+func (xb *XattrBlock) Decodificar(archivo *os.File, desplazamiento int64) error {
+	if len(xb.B_cont) == 0 {
+		return fmt.Errorf("el XattrBlock no tiene dimension, use NuevoXattrBlock")
+	}
+	return (&FileBlock{B_cont: xb.B_cont}).Decodificar(archivo, desplazamiento)
+}
+
+// Atributos retorna los atributos guardados en el bloque
+func (xb *XattrBlock) Atributos() ([]AtributoExtendido, error) {
+	var atributos []AtributoExtendido
+	for posicion := 0; posicion+3 <= len(xb.B_cont); {
+		largoNombre := int(xb.B_cont[posicion])
+		if largoNombre == 0 {
+			break
+		}
+		largoValor := int(binary.LittleEndian.Uint16(xb.B_cont[posicion+1:]))
+		inicio := posicion + 3
+		if inicio+largoNombre+largoValor > len(xb.B_cont) {
+			return nil, fmt.Errorf("bloque de atributos corrupto en el byte %d", posicion)
+		}
+		atributos = append(atributos, AtributoExtendido{
+			Nombre: string(xb.B_cont[inicio : inicio+largoNombre]),
+			Valor:  append([]byte(nil), xb.B_cont[inicio+largoNombre:inicio+largoNombre+largoValor]...),
+		})
+		posicion = inicio + largoNombre + largoValor
+	}
+	return atributos, nil
+}
+
+// EstablecerAtributos reemplaza el contenido del bloque por los atributos indicados
+func (xb *XattrBlock) EstablecerAtributos(atributos []AtributoExtendido) error {
+	necesario := 0
+	for _, atributo := range atributos {
+		necesario += 3 + len(atributo.Nombre) + len(atributo.Valor)
+	}
+	// Si sobra espacio se deja al menos un byte en cero para marcar el final
+	if necesario > len(xb.B_cont) {
+		return fmt.Errorf("los atributos ocupan %d bytes y no caben en un bloque de %d bytes", necesario, len(xb.B_cont))
+	}
+
+	clear(xb.B_cont)
+	posicion := 0
+	for _, atributo := range atributos {
+		xb.B_cont[posicion] = byte(len(atributo.Nombre))
+		binary.LittleEndian.PutUint16(xb.B_cont[posicion+1:], uint16(len(atributo.Valor)))
+		posicion += 3
+		posicion += copy(xb.B_cont[posicion:], atributo.Nombre)
+		posicion += copy(xb.B_cont[posicion:], atributo.Valor)
+	}
+	return nil
+}
+
+// ValidarNombreAtributo revisa que el nombre se pueda guardar y escribir en los comandos
+func ValidarNombreAtributo(nombre string) error {
+	if nombre == "" {
+		return fmt.Errorf("el nombre del atributo no puede estar vacio")
+	}
+	if len(nombre) > MaximoNombreAtributo {
+		return fmt.Errorf("el nombre del atributo supera los %d bytes", MaximoNombreAtributo)
+	}
+	if strings.ContainsAny(nombre, "\x00 \t\n=") {
+		return fmt.Errorf("el nombre del atributo '%s' no puede tener espacios, '=' ni caracteres nulos", nombre)
+	}
+	return nil
+}
+
+// LeerAtributos retorna los atributos extendidos del inodo ordenados por nombre
+func (sb *SuperBlock) LeerAtributos(archivo *os.File, inodo *INodo) ([]AtributoExtendido, error) {
+	if inodo.I_xattr == -1 {
+		return nil, nil
+	}
+	xb := NuevoXattrBlock(sb.S_block_size)
+	if err := xb.Decodificar(archivo, int64(sb.S_block_start+inodo.I_xattr*sb.S_block_size)); err != nil {
+		return nil, fmt.Errorf("error leyendo el bloque de atributos %d: %w", inodo.I_xattr, err)
+	}
+	return xb.Atributos()
+}
+
+// LeerAtributo retorna el valor de un atributo o ErrAtributoNoEncontrado
+func (sb *SuperBlock) LeerAtributo(archivo *os.File, inodo *INodo, nombre string) ([]byte, error) {
+	atributos, err := sb.LeerAtributos(archivo, inodo)
+	if err != nil {
+		return nil, err
+	}
+	for _, atributo := range atributos {
+		if atributo.Nombre == nombre {
+			return atributo.Valor, nil
+		}
+	}
+	return nil, fmt.Errorf("'%s': %w", nombre, ErrAtributoNoEncontrado)
+}
+
+// EstablecerAtributo crea o reemplaza un atributo del inodo
+func (sb *SuperBlock) EstablecerAtributo(archivo *os.File, inodo *INodo, nombre string, valor []byte) error {
+	if err := ValidarNombreAtributo(nombre); err != nil {
+		return err
+	}
+	atributos, err := sb.LeerAtributos(archivo, inodo)
+	if err != nil {
+		return err
+	}
+	atributos = sinAtributo(atributos, nombre)
+	atributos = append(atributos, AtributoExtendido{Nombre: nombre, Valor: valor})
+	return sb.GuardarAtributos(archivo, inodo, atributos)
+}
+
+// EliminarAtributo quita un atributo del inodo o retorna ErrAtributoNoEncontrado
+func (sb *SuperBlock) EliminarAtributo(archivo *os.File, inodo *INodo, nombre string) error {
+	atributos, err := sb.LeerAtributos(archivo, inodo)
+	if err != nil {
+		return err
+	}
+	restantes := sinAtributo(atributos, nombre)
+	if len(restantes) == len(atributos) {
+		return fmt.Errorf("'%s': %w", nombre, ErrAtributoNoEncontrado)
+	}
+	return sb.GuardarAtributos(archivo, inodo, restantes)
+}
+
+// sinAtributo retorna los atributos sin el del nombre indicado
+func sinAtributo(atributos []AtributoExtendido, nombre string) []AtributoExtendido {
+	var resultado []AtributoExtendido
+	for _, atributo := range atributos {
+		if atributo.Nombre != nombre {
+			resultado = append(resultado, atributo)
+		}
+	}
+	return resultado
+}
+
+// GuardarAtributos escribe los atributos en el bloque del inodo, asignandolo si no tenia
+// uno y liberandolo si la lista queda vacia. Actualiza I_xattr e I_ctime en memoria; el
+// inodo y el superbloque los guarda quien llama
+func (sb *SuperBlock) GuardarAtributos(archivo *os.File, inodo *INodo, atributos []AtributoExtendido) error {
+	if len(atributos) == 0 {
+		if err := sb.LiberarAtributos(archivo, inodo); err != nil {
+			return err
+		}
+		inodo.ActualizarTiempoPermisos()
+		return nil
+	}
+
+	if !sb.AdmiteAtributosExtendidos() {
+		return fmt.Errorf("los inodos de %d bytes no guardan atributos extendidos; vuelva a formatear con mkfs para usarlos", sb.S_inode_size)
+	}
+
+	sort.Slice(atributos, func(i, j int) bool { return atributos[i].Nombre < atributos[j].Nombre })
+	xb := NuevoXattrBlock(sb.S_block_size)
+	if err := xb.EstablecerAtributos(atributos); err != nil {
+		return err
+	}
+
+	if inodo.I_xattr == -1 {
+		sb.PreferirBloquesCercaDe(archivo, inodo.UltimoBloqueReferenciado())
+		nuevo, err := sb.BuscarSiguienteBloqueLibre(archivo)
+		if err != nil {
+			return fmt.Errorf("error asignando el bloque de atributos: %w", err)
+		}
+		sb.ActualizarSuperblockDespuesAsignacionBloque()
+		inodo.I_xattr = nuevo
+	}
+	if err := xb.Codificar(archivo, int64(sb.S_block_start+inodo.I_xattr*sb.S_block_size)); err != nil {
+		return fmt.Errorf("error escribiendo el bloque de atributos %d: %w", inodo.I_xattr, err)
+	}
+	inodo.ActualizarTiempoPermisos()
+	return nil
+}
+
+// LiberarAtributos libera el bloque de atributos del inodo, si tiene uno
+func (sb *SuperBlock) LiberarAtributos(archivo *os.File, inodo *INodo) error {
+	if inodo.I_xattr == -1 {
+		return nil
+	}
+	if err := inodo.LiberarBloque(archivo, sb, inodo.I_xattr); err != nil {
+		return fmt.Errorf("error liberando el bloque de atributos: %w", err)
+	}
+	inodo.I_xattr = -1
+	return nil
+}
+
+// CopiarAtributos da al inodo destino los mismos atributos que el origen y lo guarda
+func (sb *SuperBlock) CopiarAtributos(archivo *os.File, indiceOrigen int32, indiceDestino int32) error {
+	origen := &INodo{}
+	if err := origen.Decodificar(archivo, sb.CalcularDesplazamientoInodo(indiceOrigen)); err != nil {
+		return fmt.Errorf("error al deserializar el inodo %d: %w", indiceOrigen, err)
+	}
+	atributos, err := sb.LeerAtributos(archivo, origen)
+	if err != nil || len(atributos) == 0 {
+		return err
+	}
+
+	desplazamiento := sb.CalcularDesplazamientoInodo(indiceDestino)
+	destino := &INodo{}
+	if err := destino.Decodificar(archivo, desplazamiento); err != nil {
+		return fmt.Errorf("error al deserializar el inodo %d: %w", indiceDestino, err)
+	}
+	if err := sb.GuardarAtributos(archivo, destino, atributos); err != nil {
+		return err
+	}
+	return destino.Codificar(archivo, desplazamiento)
+}
@@ -0,0 +1,76 @@
+package Estructuras
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestXattrBlockIdaYVuelta(t *testing.T) {
+	casos := []struct {
+		nombre    string
+		atributos []AtributoExtendido
+		seRechaza bool
+	}{
+		{"sin atributos", nil, false},
+		{"uno", []AtributoExtendido{{"user.autor", []byte("ana")}}, false},
+		{"valores binarios y vacios", []AtributoExtendido{{"a", []byte{0, 1, 0}}, {"b", nil}, {"c", []byte("\x00")}}, false},
+		{"llena el bloque exacto", []AtributoExtendido{{"x", bytes.Repeat([]byte{'v'}, 60)}}, false},
+		{"un byte de mas", []AtributoExtendido{{"x", bytes.Repeat([]byte{'v'}, 61)}}, true},
+		{"nombre maximo", []AtributoExtendido{{strings.Repeat("n", MaximoNombreAtributo), []byte("v")}}, true},
+	}
+	for _, caso := range casos {
+		bloque := NuevoXattrBlock(64)
+		copy(bloque.B_cont, bytes.Repeat([]byte{0xFF}, 64)) // restos de un uso anterior
+		err := bloque.EstablecerAtributos(caso.atributos)
+		if (err != nil) != caso.seRechaza {
+			t.Errorf("%s: EstablecerAtributos = %v", caso.nombre, err)
+			continue
+		}
+		if caso.seRechaza {
+			continue
+		}
+		leidos, err := bloque.Atributos()
+		if err != nil {
+			t.Errorf("%s: %v", caso.nombre, err)
+			continue
+		}
+		if len(leidos) != len(caso.atributos) {
+			t.Errorf("%s: se leyeron %d atributos, se esperaban %d", caso.nombre, len(leidos), len(caso.atributos))
+			continue
+		}
+		for i, atributo := range caso.atributos {
+			if leidos[i].Nombre != atributo.Nombre || !bytes.Equal(leidos[i].Valor, atributo.Valor) {
+				t.Errorf("%s: atributo %d = %q=%q, se esperaba %q=%q", caso.nombre, i, leidos[i].Nombre, leidos[i].Valor, atributo.Nombre, atributo.Valor)
+			}
+		}
+	}
+}
+
+func TestXattrBlockCorrupto(t *testing.T) {
+	bloque := NuevoXattrBlock(64)
+	bloque.B_cont[0], bloque.B_cont[1] = 4, 200 // el valor sale del bloque
+	if _, err := bloque.Atributos(); err == nil {
+		t.Errorf("se aceptaron atributos que salen del bloque")
+	}
+}
+
+func TestValidarNombreAtributo(t *testing.T) {
+	casos := []struct {
+		nombre string
+		valido bool
+	}{
+		{"user.comentario", true},
+		{strings.Repeat("n", MaximoNombreAtributo), true},
+		{strings.Repeat("n", MaximoNombreAtributo+1), false},
+		{"", false},
+		{"con espacio", false},
+		{"a=b", false},
+		{"nulo\x00", false},
+	}
+	for _, caso := range casos {
+		if err := ValidarNombreAtributo(caso.nombre); (err == nil) != caso.valido {
+			t.Errorf("ValidarNombreAtributo(%.20q) = %v", caso.nombre, err)
+		}
+	}
+}
@@ -13,12 +13,12 @@ const (
 
 // Genera los Bitmaps de inodos y bloques en el archivo especificado
 func (sb *SuperBlock) CrearBitMaps(archivo *os.File) error {
-	err := sb.crearBitmap(archivo, sb.S_bm_inode_start, sb.S_inodes_count+sb.S_free_inodes_count, false)
+	err := sb.crearBitmap(archivo, sb.S_bm_inode_start, false)
 	if err != nil {
 		return fmt.Errorf("error generando bitmap de inodos: %w", err)
 	}
 
-	err = sb.crearBitmap(archivo, sb.S_bm_block_start, sb.S_blocks_count+sb.S_free_blocks_count, false)
+	err = sb.crearBitmap(archivo, sb.S_bm_block_start, false)
 	if err != nil {
 		return fmt.Errorf("error generando bitmap de bloques: %w", err)
 	}
@@ -26,29 +26,30 @@ func (sb *SuperBlock) CrearBitMaps(archivo *os.File) error {
 	return nil
 }
 
-func (sb *SuperBlock) crearBitmap(archivo *os.File, inicio int32, cantidad int32, ocupado bool) error {
-	_, err := archivo.Seek(int64(inicio), 0)
-	if err != nil {
-		return fmt.Errorf("error buscando el inicio del bitmap: %w", err)
-	}
-
-	cantidadBytes := (cantidad + 7) / 8
-
+func (sb *SuperBlock) crearBitmap(archivo *os.File, inicio int32, ocupado bool) error {
 	// Crear el buffer de bytes con todos los bits en 0 (libres) o 1 (ocupados)
 	byteRelleno := byte(0x00) // 00000000 (todos los bloques libres)
 	if ocupado {
 		byteRelleno = 0xFF // 11111111 (todos los bloques ocupados)
 	}
 
-	buffer := make([]byte, cantidadBytes)
-	for i := range buffer {
-		buffer[i] = byteRelleno
-	}
-
-	// Escribir el buffer en el archivo
-	err = binary.Write(archivo, binary.LittleEndian, buffer)
-	if err != nil {
-		return fmt.Errorf("error escribiendo el bitmap: %w", err)
+	// Con grupos cada grupo tiene su propio tramo del bitmap
+	for _, tramo := range sb.ubicacionBitmap(inicio).tramos {
+		_, err := archivo.Seek(tramo.inicio, 0)
+		if err != nil {
+			return fmt.Errorf("error buscando el inicio del bitmap: %w", err)
+		}
+
+		buffer := make([]byte, tramo.bytes)
+		for i := range buffer {
+			buffer[i] = byteRelleno
+		}
+
+		// Escribir el buffer en el archivo
+		err = binary.Write(archivo, binary.LittleEndian, buffer)
+		if err != nil {
+			return fmt.Errorf("error escribiendo el bitmap: %w", err)
+		}
 	}
 
 	return nil
@@ -66,12 +67,20 @@ func (sb *SuperBlock) ActualizarBitmapBloque(archivo *os.File, posicion int32, o
 
 // Funcion auxiliar que actualiza un bit en un bitmap
 func (sb *SuperBlock) actualizarBitmap(archivo *os.File, inicio int32, posicion int32, ocupado bool) error {
-	// Calcular el byte y el bit dentro de ese byte
-	indiceByte := posicion / 8
+	// Con la particion montada solo cambia la copia en memoria; se escribe al sincronizar
+	if cache := cacheDe(archivo, int64(inicio)); cache != nil {
+		return cache.actualizarBitmap(sb.ubicacionBitmap(inicio), posicion, ocupado)
+	}
+
+	// Calcular el byte (con grupos puede estar en el bitmap de otro grupo) y el bit dentro de ese byte
+	posicionByte := sb.ubicacionBitmap(inicio).desplazamiento(posicion / 8)
 	desplazamientoBit := posicion % 8
+	if posicionByte == -1 {
+		return fmt.Errorf("posicion %d fuera del bitmap", posicion)
+	}
 
 	// Mover el puntero al byte correspondiente
-	_, err := archivo.Seek(int64(inicio)+int64(indiceByte), 0)
+	_, err := archivo.Seek(posicionByte, 0)
 	if err != nil {
 		return fmt.Errorf("error buscando la posicion en el bitmap: %w", err)
 	}
@@ -90,7 +99,7 @@ func (sb *SuperBlock) actualizarBitmap(archivo *os.File, inicio int32, posicion
 		valorByte &= ^(1 << desplazamientoBit) // Poner el bit a 0 (libre)
 	}
 
-	_, err = archivo.Seek(int64(inicio)+int64(indiceByte), 0)
+	_, err = archivo.Seek(posicionByte, 0)
 	if err != nil {
 		return fmt.Errorf("error buscando la posicion en el bitmap para escribir: %w", err)
 	}
@@ -104,43 +113,88 @@ func (sb *SuperBlock) actualizarBitmap(archivo *os.File, inicio int32, posicion
 	return nil
 }
 
-// Verifica si un bloque en el bitmap esta libre
-func (sb *SuperBlock) verificarBloqueLibre(archivo *os.File, inicio int32, posicion int32) (bool, error) {
-	// Calcular el byte y el bit dentro del byte
-	indiceByte := posicion / 8
-	desplazamientoBit := posicion % 8
-
-	_, err := archivo.Seek(int64(inicio)+int64(indiceByte), 0)
-	if err != nil {
-		return false, fmt.Errorf("error buscando la posicion en el bitmap: %w", err)
+// dimensionBitmap devuelve cuantas posiciones tiene el bitmap que empieza en 'inicio'
+func (sb *SuperBlock) dimensionBitmap(inicio int32) int32 {
+	if inicio == sb.S_bm_inode_start {
+		return sb.S_inodes_count + sb.S_free_inodes_count
 	}
+	return sb.S_blocks_count + sb.S_free_blocks_count
+}
 
-	var valorByte byte
-	err = binary.Read(archivo, binary.LittleEndian, &valorByte)
-	if err != nil {
-		return false, fmt.Errorf("error leyendo el byte del bitmap: %w", err)
+// ubicacionBitmap devuelve los tramos del bitmap que empieza en 'inicio': uno solo sin
+// grupos, o el bitmap de cada grupo en orden
+func (sb *SuperBlock) ubicacionBitmap(inicio int32) ubicacionBitmap {
+	cantidad := sb.dimensionBitmap(inicio)
+	if sb.S_groups_count == 0 {
+		return ubicacionBitmap{tramos: []tramoBitmap{{inicio: int64(inicio), bytes: (cantidad + 7) / 8}}, cantidad: cantidad}
 	}
 
-	return (valorByte & (1 << desplazamientoBit)) == 0, nil
+	tramos := make([]tramoBitmap, sb.S_groups_count)
+	for grupo := range tramos {
+		disposicion := sb.DisposicionGrupo(int32(grupo))
+		if inicio == sb.S_bm_inode_start {
+			tramos[grupo] = tramoBitmap{inicio: sb.desplazamientoBloque(disposicion.BitmapInodos), bytes: sb.S_inodes_per_group / 8}
+		} else {
+			tramos[grupo] = tramoBitmap{inicio: sb.desplazamientoBloque(disposicion.BitmapBloques), bytes: disposicion.Bloques / 8}
+		}
+	}
+	return ubicacionBitmap{tramos: tramos, cantidad: cantidad}
 }
 
-// Verifica si un inodo en el bitmap esta libre
-func (sb *SuperBlock) verificarInodoLibre(archivo *os.File, inicio int32, posicion int32) (bool, error) {
-	indiceByte := posicion / 8        // Calcular el byte dentro del bitmap
-	desplazamientoBit := posicion % 8 // Calcular el bit dentro del byte
+// asignarEnBitmap marca como ocupada la primera posicion libre desde 'desde' (-1 sigue
+// desde la ultima asignacion) y la devuelve, o -1 si el bitmap esta lleno. Con la
+// particion montada se usa el bitmap en memoria; si no, se lee completo una sola vez
+func (sb *SuperBlock) asignarEnBitmap(archivo *os.File, inicio int32, desde int32) (int32, error) {
+	ubicacion := sb.ubicacionBitmap(inicio)
+	if cache := cacheDe(archivo, int64(inicio)); cache != nil {
+		return cache.asignarEnBitmap(ubicacion, desde)
+	}
 
-	_, err := archivo.Seek(int64(inicio)+int64(indiceByte), 0)
+	bitmap, err := cargarBitmap(archivo, ubicacion)
 	if err != nil {
-		return false, fmt.Errorf("error buscando el byte en el bitmap de inodos: %w", err)
+		return -1, err
 	}
+	posicion := bitmap.buscarLibre(desde)
+	if posicion == -1 {
+		return -1, nil
+	}
+	return posicion, sb.actualizarBitmap(archivo, inicio, posicion, true)
+}
 
-	var valorByte byte
-	err = binary.Read(archivo, binary.LittleEndian, &valorByte)
-	if err != nil {
-		return false, fmt.Errorf("error leyendo el byte del bitmap de inodos: %w", err)
+// PreferirBloquesCercaDe hace que la siguiente asignacion de bloques busque justo despues
+// del bloque indicado, para que los bloques de un archivo queden contiguos y cerca de los
+// de su carpeta. Solo aplica a particiones montadas, que guardan el cursor en memoria
+func (sb *SuperBlock) PreferirBloquesCercaDe(archivo *os.File, bloque int32) {
+	if bloque < 0 {
+		return
+	}
+	if cache := cacheDe(archivo, int64(sb.S_bm_block_start)); cache != nil {
+		cache.moverCursorBitmap(sb.ubicacionBitmap(sb.S_bm_block_start), bloque+1)
 	}
+}
+
+// LeerBitmapInodos devuelve el bitmap de inodos con el formato del disco, incluidos los
+// cambios que todavia estan en memoria
+func (sb *SuperBlock) LeerBitmapInodos(archivo *os.File) ([]byte, error) {
+	return sb.leerBitmap(archivo, sb.S_bm_inode_start)
+}
+
+// LeerBitmapBloques devuelve el bitmap de bloques con el formato del disco, incluidos los
+// cambios que todavia estan en memoria
+func (sb *SuperBlock) LeerBitmapBloques(archivo *os.File) ([]byte, error) {
+	return sb.leerBitmap(archivo, sb.S_bm_block_start)
+}
 
-	return (valorByte & (1 << desplazamientoBit)) == 0, nil
+func (sb *SuperBlock) leerBitmap(archivo *os.File, inicio int32) ([]byte, error) {
+	ubicacion := sb.ubicacionBitmap(inicio)
+	if cache := cacheDe(archivo, int64(inicio)); cache != nil {
+		return cache.copiaBitmap(ubicacion)
+	}
+	bitmap, err := cargarBitmap(archivo, ubicacion)
+	if err != nil {
+		return nil, err
+	}
+	return bitmap.bytes(), nil
 }
 
 // LiberarBloque libera un bloque específico, lo marca como disponible en el bitmap y borra su contenido
@@ -156,6 +210,7 @@ func (sb *SuperBlock) LiberarBloque(archivo *os.File, indiceBloque int32) error
     if err != nil {
         return fmt.Errorf("error al borrar el contenido del bloque %d: %w", indiceBloque, err)
     }
+    descartarEnCache(archivo, offsetBloque)
 
     // Marcar el bloque como disponible en el bitmap correspondiente
     err = sb.ActualizarBitmapBloque(archivo, indiceBloque, false)
@@ -175,7 +230,7 @@ func (sb *SuperBlock) LiberarInodo(archivo *os.File, indiceInodo int32) error {
     inodo := &INodo{}
     
     // Calcular ubicación del inodo en el archivo
-    offsetInodo := int64(sb.S_inode_start + indiceInodo*sb.S_inode_size)
+    offsetInodo := sb.CalcularDesplazamientoInodo(indiceInodo)
     
     // Cargar el inodo desde el disco para su limpieza
     err := inodo.Decodificar(archivo, offsetInodo)
@@ -186,8 +241,10 @@ func (sb *SuperBlock) LiberarInodo(archivo *os.File, indiceInodo int32) error {
     // Reiniciar todos los metadatos del inodo a valores por defecto
     inodo.I_size = 0                    // Tamaño del archivo a cero
     inodo.I_type[0] = '0'              // Restablecer tipo de inodo
+    inodo.I_links = 0                  // Ninguna entrada lo nombra
     // Inicializar todos los bloques como no asignados
     inodo.I_block = [15]int32{-1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1}
+    inodo.I_xattr = -1
 
     // Escribir el inodo limpio de vuelta al disco
     err = inodo.Codificar(archivo, offsetInodo)
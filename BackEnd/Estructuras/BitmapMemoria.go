@@ -0,0 +1,196 @@
+package Estructuras
+
+import (
+	"fmt"
+	"math/bits"
+	"os"
+	"sort"
+)
+
+// tramoBitmap es una parte contigua de un bitmap en el disco
+type tramoBitmap struct {
+	inicio int64
+	bytes  int32
+}
+
+// ubicacionBitmap describe donde esta un bitmap en el disco. Sin grupos es un solo tramo;
+// con grupos hay un tramo por grupo y juntos forman un bitmap continuo, de modo que el
+// byte i del bitmap es el byte i contando los tramos en orden
+type ubicacionBitmap struct {
+	tramos   []tramoBitmap
+	cantidad int32 // posiciones validas
+}
+
+// clave identifica al bitmap por el inicio de su primer tramo
+func (u ubicacionBitmap) clave() int32 {
+	return int32(u.tramos[0].inicio)
+}
+
+// desplazamiento devuelve la posicion en el disco del byte 'indice' del bitmap
+func (u ubicacionBitmap) desplazamiento(indice int32) int64 {
+	for _, tramo := range u.tramos {
+		if indice < tramo.bytes {
+			return tramo.inicio + int64(indice)
+		}
+		indice -= tramo.bytes
+	}
+	return -1
+}
+
+// bitmapMemoria es una copia en memoria de un bitmap del disco agrupada en palabras de
+// 64 bits. El bit i de la palabra p corresponde a la posicion 64*p+i, igual que el bit
+// i%8 del byte i/8 en el disco
+type bitmapMemoria struct {
+	tramos   []tramoBitmap
+	cantidad int32 // posiciones validas
+	palabras []uint64
+	sucias   map[int]bool // palabras modificadas pendientes de escribir
+	cursor   int32        // posicion desde la que busca la siguiente asignacion
+}
+
+// cargarBitmap lee el bitmap completo con una lectura por tramo
+func cargarBitmap(archivo *os.File, ubicacion ubicacionBitmap) (*bitmapMemoria, error) {
+	datos := make([]byte, (ubicacion.cantidad+7)/8)
+	leidos := datos
+	for _, tramo := range ubicacion.tramos {
+		if int(tramo.bytes) > len(leidos) {
+			return nil, fmt.Errorf("el bitmap en %d tiene mas bytes de los esperados", tramo.inicio)
+		}
+		if _, err := archivo.ReadAt(leidos[:tramo.bytes], tramo.inicio); err != nil {
+			return nil, fmt.Errorf("error leyendo el bitmap en %d: %w", tramo.inicio, err)
+		}
+		leidos = leidos[tramo.bytes:]
+	}
+
+	bitmap := &bitmapMemoria{
+		tramos:   ubicacion.tramos,
+		cantidad: ubicacion.cantidad,
+		palabras: make([]uint64, (ubicacion.cantidad+63)/64),
+		sucias:   make(map[int]bool),
+	}
+	for i, valor := range datos {
+		bitmap.palabras[i/8] |= uint64(valor) << (8 * (i % 8))
+	}
+	return bitmap, nil
+}
+
+func (b *bitmapMemoria) ocupado(posicion int32) bool {
+	return b.palabras[posicion/64]&(1<<(posicion%64)) != 0
+}
+
+func (b *bitmapMemoria) fijar(posicion int32, ocupado bool) error {
+	if posicion < 0 || posicion >= b.cantidad {
+		return fmt.Errorf("posicion %d fuera del bitmap (%d posiciones)", posicion, b.cantidad)
+	}
+	palabra := int(posicion / 64)
+	if ocupado {
+		b.palabras[palabra] |= 1 << (posicion % 64)
+	} else {
+		b.palabras[palabra] &^= 1 << (posicion % 64)
+	}
+	b.sucias[palabra] = true
+	return nil
+}
+
+// buscarLibre devuelve la primera posicion libre desde 'desde', dando la vuelta al final;
+// revisa una palabra a la vez y salta las que estan llenas. Devuelve -1 si no hay
+func (b *bitmapMemoria) buscarLibre(desde int32) int32 {
+	if b.cantidad == 0 {
+		return -1
+	}
+	if desde < 0 || desde >= b.cantidad {
+		desde = 0
+	}
+
+	total := len(b.palabras)
+	primera := int(desde / 64)
+	for i := 0; i <= total; i++ {
+		palabra := (primera + i) % total
+		libres := ^b.palabras[palabra]
+		switch i {
+		case 0:
+			libres &= ^uint64(0) << (desde % 64) // solo desde la posicion pedida
+		case total:
+			libres &= (1 << (desde % 64)) - 1 // la vuelta completa termina antes de 'desde'
+		}
+		if libres == 0 {
+			continue
+		}
+		posicion := int32(palabra*64 + bits.TrailingZeros64(libres))
+		if posicion < b.cantidad {
+			return posicion
+		}
+	}
+	return -1
+}
+
+// asignar busca una posicion libre desde 'desde', la marca ocupada y avanza el cursor
+func (b *bitmapMemoria) asignar(desde int32) (int32, error) {
+	posicion := b.buscarLibre(desde)
+	if posicion == -1 {
+		return -1, nil
+	}
+	if err := b.fijar(posicion, true); err != nil {
+		return -1, err
+	}
+	b.cursor = posicion + 1
+	return posicion, nil
+}
+
+// bytes devuelve el bitmap con el mismo formato que tiene en el disco
+func (b *bitmapMemoria) bytes() []byte {
+	datos := make([]byte, (b.cantidad+7)/8)
+	for i := range datos {
+		datos[i] = byte(b.palabras[i/8] >> (8 * (i % 8)))
+	}
+	return datos
+}
+
+// escribir lleva al disco las palabras modificadas; las consecutivas van en una sola
+// escritura por tramo
+func (b *bitmapMemoria) escribir(archivo *os.File) (bool, error) {
+	if len(b.sucias) == 0 {
+		return false, nil
+	}
+	var palabras []int
+	for palabra := range b.sucias {
+		palabras = append(palabras, palabra)
+	}
+	sort.Ints(palabras)
+
+	datos := b.bytes()
+	for i := 0; i < len(palabras); {
+		j := i
+		for j+1 < len(palabras) && palabras[j+1] == palabras[j]+1 {
+			j++
+		}
+		desde := palabras[i] * 8
+		hasta := (palabras[j] + 1) * 8
+		if hasta > len(datos) {
+			hasta = len(datos)
+		}
+		if err := b.escribirRango(archivo, datos, desde, hasta); err != nil {
+			return false, err
+		}
+		i = j + 1
+	}
+	b.sucias = make(map[int]bool)
+	return true, nil
+}
+
+// escribirRango escribe los bytes [desde, hasta) del bitmap repartidos en sus tramos
+func (b *bitmapMemoria) escribirRango(archivo *os.File, datos []byte, desde, hasta int) error {
+	base := 0
+	for _, tramo := range b.tramos {
+		fin := base + int(tramo.bytes)
+		inicio, termino := max(desde, base), min(hasta, fin)
+		if inicio < termino {
+			posicion := tramo.inicio + int64(inicio-base)
+			if _, err := archivo.WriteAt(datos[inicio:termino], posicion); err != nil {
+				return fmt.Errorf("error escribiendo el bitmap en %d: %w", posicion, err)
+			}
+		}
+		base = fin
+	}
+	return nil
+}
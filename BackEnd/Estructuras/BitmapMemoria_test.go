@@ -0,0 +1,133 @@
+package Estructuras
+
+import (
+	"bytes"
+	"testing"
+)
+
+// bitmapConOcupadas arma un bitmap en memoria de 'cantidad' posiciones con las indicadas ocupadas
+func bitmapConOcupadas(t *testing.T, cantidad int32, ocupadas ...int32) *bitmapMemoria {
+	t.Helper()
+	bitmap := &bitmapMemoria{
+		cantidad: cantidad,
+		palabras: make([]uint64, (cantidad+63)/64),
+		sucias:   make(map[int]bool),
+	}
+	for _, posicion := range ocupadas {
+		if err := bitmap.fijar(posicion, true); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return bitmap
+}
+
+func TestBuscarLibre(t *testing.T) {
+	rango := func(desde, hasta int32) []int32 {
+		var posiciones []int32
+		for i := desde; i < hasta; i++ {
+			posiciones = append(posiciones, i)
+		}
+		return posiciones
+	}
+
+	casos := []struct {
+		nombre   string
+		cantidad int32
+		ocupadas []int32
+		desde    int32
+		esperado int32
+	}{
+		{"vacio", 100, nil, 0, 0},
+		{"desde el medio", 100, nil, 70, 70},
+		{"salta las ocupadas", 100, []int32{5, 6, 7}, 5, 8},
+		{"salta palabras llenas", 200, rango(0, 130), 0, 130},
+		{"da la vuelta al final", 100, rango(40, 100), 50, 0},
+		{"la vuelta llega hasta antes de desde", 100, append(rango(0, 49), rango(50, 100)...), 50, 49},
+		{"ignora el relleno de la ultima palabra", 70, rango(3, 70), 10, 0},
+		{"lleno", 70, rango(0, 70), 30, -1},
+		{"desde fuera del bitmap empieza en cero", 70, []int32{0}, 500, 1},
+		{"sin posiciones", 0, nil, 0, -1},
+	}
+	for _, caso := range casos {
+		bitmap := bitmapConOcupadas(t, caso.cantidad, caso.ocupadas...)
+		if posicion := bitmap.buscarLibre(caso.desde); posicion != caso.esperado {
+			t.Errorf("%s: buscarLibre(%d) = %d, se esperaba %d", caso.nombre, caso.desde, posicion, caso.esperado)
+		}
+	}
+}
+
+func TestAsignarAvanzaElCursor(t *testing.T) {
+	bitmap := bitmapConOcupadas(t, 10, 0, 1, 2, 8)
+	var asignadas []int32
+	for {
+		posicion, err := bitmap.asignar(bitmap.cursor)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if posicion == -1 {
+			break
+		}
+		asignadas = append(asignadas, posicion)
+	}
+	esperadas := []int32{3, 4, 5, 6, 7, 9}
+	if len(asignadas) != len(esperadas) {
+		t.Fatalf("asignadas %v, se esperaba %v", asignadas, esperadas)
+	}
+	for i := range esperadas {
+		if asignadas[i] != esperadas[i] {
+			t.Fatalf("asignadas %v, se esperaba %v", asignadas, esperadas)
+		}
+	}
+
+	// Al liberar una posicion anterior al cursor la siguiente asignacion da la vuelta
+	if err := bitmap.fijar(1, false); err != nil {
+		t.Fatal(err)
+	}
+	if posicion, _ := bitmap.asignar(bitmap.cursor); posicion != 1 || bitmap.cursor != 2 {
+		t.Errorf("asignar despues de liberar: posicion %d, cursor %d", posicion, bitmap.cursor)
+	}
+}
+
+func TestBitmapMemoriaEnTramos(t *testing.T) {
+	archivo := discoTemporal(t, 512)
+	// Dos grupos: 2 bytes en 100 y 1 byte en 300, 20 posiciones en total
+	ubicacion := ubicacionBitmap{tramos: []tramoBitmap{{100, 2}, {300, 1}}, cantidad: 20}
+	escribirBytes(t, archivo, 100, []byte{0x01, 0x80})
+	escribirBytes(t, archivo, 300, []byte{0x02})
+
+	bitmap, err := cargarBitmap(archivo, ubicacion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, posicion := range []int32{0, 15, 17} {
+		if !bitmap.ocupado(posicion) {
+			t.Errorf("la posicion %d deberia estar ocupada", posicion)
+		}
+	}
+
+	for _, posicion := range []int32{9, 19} {
+		if err := bitmap.fijar(posicion, true); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := bitmap.fijar(20, true); err == nil {
+		t.Errorf("fijar acepto una posicion fuera del bitmap")
+	}
+	if _, err := bitmap.escribir(archivo); err != nil {
+		t.Fatal(err)
+	}
+
+	leidos := make([]byte, 512)
+	if _, err := archivo.ReadAt(leidos, 0); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(leidos[100:102], []byte{0x01, 0x82}) || leidos[300] != 0x0A {
+		t.Errorf("tramos escritos: % x y % x", leidos[100:102], leidos[300:301])
+	}
+	if leidos[102] != 0 || leidos[299] != 0 || leidos[301] != 0 {
+		t.Errorf("se escribio fuera de los tramos")
+	}
+	if escrito, _ := bitmap.escribir(archivo); escrito {
+		t.Errorf("escribir sin cambios no deberia tocar el disco")
+	}
+}
@@ -0,0 +1,510 @@
+package Estructuras
+
+import (
+	"container/list"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	Utils "backend/Utils"
+)
+
+// CapacidadCache es la cantidad maxima de estructuras decodificadas por particion
+const CapacidadCache = 4096
+
+type tipoEntradaCache int
+
+const (
+	entradaInodo tipoEntradaCache = iota
+	entradaCarpeta
+	entradaApuntadores
+)
+
+// entradaCache guarda una estructura decodificada junto a su posicion en el disco
+type entradaCache struct {
+	desplazamiento int64
+	tipo           tipoEntradaCache
+	inodo          INodo
+	carpeta        []FolderContent
+	apuntadores    []int64
+	sucia          bool // modificada en memoria y pendiente de escribir
+}
+
+// CacheParticion mantiene abierto el disco de una particion montada, un LRU de sus
+// inodos, bloques de carpeta y bloques de apuntadores y sus bitmaps completos. Las
+// escrituras quedan en memoria hasta Sincronizar (sync, unmount o cierre del servidor)
+// o hasta que la entrada sale del LRU
+type CacheParticion struct {
+	Id        string
+	Ruta      string
+	Particion Particion
+	Aciertos  int64
+	Fallos    int64
+
+	mutex          sync.Mutex
+	archivo        *os.File
+	lru            *list.List // el frente es la entrada usada mas recientemente
+	entradas       map[int64]*list.Element
+	bitmaps        map[int32]*bitmapMemoria // por desplazamiento de inicio del bitmap
+	dimensionInodo int32                    // S_inode_size del superbloque, 0 si aun no se lee
+}
+
+var (
+	mutexCaches       sync.Mutex
+	cachesPorDisco    = make(map[string][]*CacheParticion)
+	cachesSuspendidas bool
+)
+
+// claveDisco normaliza la ruta del disco para que distintos descriptores coincidan
+func claveDisco(ruta string) string {
+	if absoluta, err := filepath.Abs(ruta); err == nil {
+		return absoluta
+	}
+	return filepath.Clean(ruta)
+}
+
+// AbrirCacheParticion abre el disco de la particion montada con el id indicado y registra
+// su cache; desde ese momento los inodos y bloques de la particion se leen a traves de ella
+func AbrirCacheParticion(id string, ruta string) (*CacheParticion, error) {
+	archivo, err := os.OpenFile(ruta, os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("error abriendo el disco %s: %w", ruta, err)
+	}
+	particion, err := BuscarParticionPorID(archivo, id)
+	if particion == nil {
+		archivo.Close()
+		return nil, fmt.Errorf("error ubicando la partición %s: %v", id, err)
+	}
+
+	cache := &CacheParticion{
+		Id:        id,
+		Ruta:      ruta,
+		Particion: *particion,
+		archivo:   archivo,
+		lru:       list.New(),
+		entradas:  make(map[int64]*list.Element),
+		bitmaps:   make(map[int32]*bitmapMemoria),
+	}
+
+	mutexCaches.Lock()
+	clave := claveDisco(ruta)
+	cachesPorDisco[clave] = append(cachesPorDisco[clave], cache)
+	mutexCaches.Unlock()
+	return cache, nil
+}
+
+// cacheDe devuelve la cache de la particion que contiene el desplazamiento, o nil si no
+// esta montada o las caches estan suspendidas
+func cacheDe(archivo *os.File, desplazamiento int64) *CacheParticion {
+	mutexCaches.Lock()
+	defer mutexCaches.Unlock()
+	if cachesSuspendidas || len(cachesPorDisco) == 0 {
+		return nil
+	}
+	for _, cache := range cachesPorDisco[claveDisco(archivo.Name())] {
+		inicio := int64(cache.Particion.Part_start)
+		if desplazamiento >= inicio && desplazamiento < inicio+int64(cache.Particion.Part_size) {
+			return cache
+		}
+	}
+	return nil
+}
+
+// descartarEnCache olvida la estructura guardada en el desplazamiento; lo usan las
+// escrituras directas de bloques completos, que reemplazan lo que hubiera en memoria
+func descartarEnCache(archivo *os.File, desplazamiento int64) {
+	if cache := cacheDe(archivo, desplazamiento); cache != nil {
+		cache.mutex.Lock()
+		cache.quitar(desplazamiento)
+		cache.mutex.Unlock()
+	}
+}
+
+// escribirPendienteEnCache lleva al disco la estructura sucia del desplazamiento antes
+// de que se lea el bloque sin decodificar (por ejemplo como FileBlock)
+func escribirPendienteEnCache(archivo *os.File, desplazamiento int64) error {
+	cache := cacheDe(archivo, desplazamiento)
+	if cache == nil {
+		return nil
+	}
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	if elemento, existe := cache.entradas[desplazamiento]; existe {
+		return cache.escribir(elemento.Value.(*entradaCache))
+	}
+	return nil
+}
+
+// buscar devuelve la entrada del desplazamiento si es del tipo pedido; una entrada de otro
+// tipo (bloque reutilizado) se escribe si esta sucia y se descarta
+func (c *CacheParticion) buscar(desplazamiento int64, tipo tipoEntradaCache) (*entradaCache, error) {
+	elemento, existe := c.entradas[desplazamiento]
+	if !existe {
+		c.Fallos++
+		return nil, nil
+	}
+	entrada := elemento.Value.(*entradaCache)
+	if entrada.tipo != tipo {
+		c.Fallos++
+		if err := c.escribir(entrada); err != nil {
+			return nil, err
+		}
+		c.quitar(desplazamiento)
+		return nil, nil
+	}
+	c.Aciertos++
+	c.lru.MoveToFront(elemento)
+	return entrada, nil
+}
+
+// guardar inserta o reemplaza la entrada y expulsa las menos usadas si se excede la capacidad
+func (c *CacheParticion) guardar(entrada *entradaCache) error {
+	if elemento, existe := c.entradas[entrada.desplazamiento]; existe {
+		elemento.Value = entrada
+		c.lru.MoveToFront(elemento)
+		return nil
+	}
+	c.entradas[entrada.desplazamiento] = c.lru.PushFront(entrada)
+
+	for c.lru.Len() > CapacidadCache {
+		ultima := c.lru.Back().Value.(*entradaCache)
+		if err := c.escribir(ultima); err != nil {
+			return err
+		}
+		c.quitar(ultima.desplazamiento)
+	}
+	return nil
+}
+
+func (c *CacheParticion) quitar(desplazamiento int64) {
+	if elemento, existe := c.entradas[desplazamiento]; existe {
+		c.lru.Remove(elemento)
+		delete(c.entradas, desplazamiento)
+	}
+}
+
+// escribir serializa una entrada sucia con el descriptor propio de la cache
+func (c *CacheParticion) escribir(entrada *entradaCache) error {
+	if !entrada.sucia {
+		return nil
+	}
+	var err error
+	switch entrada.tipo {
+	case entradaInodo:
+		err = entrada.inodo.codificarDirecto(c.archivo, entrada.desplazamiento, c.tamanoInodo())
+	case entradaCarpeta:
+		err = Utils.EscribirAArchivo(c.archivo, entrada.desplazamiento, entrada.carpeta)
+	case entradaApuntadores:
+		err = (&PointerBlock{B_apuntadores: entrada.apuntadores}).codificarDirecto(c.archivo, entrada.desplazamiento)
+	}
+	if err != nil {
+		return fmt.Errorf("error escribiendo la cache de %s en %d: %w", c.Id, entrada.desplazamiento, err)
+	}
+	entrada.sucia = false
+	return nil
+}
+
+// tamanoInodo devuelve el tamaño de inodo del superbloque de la particion, leyendolo la
+// primera vez. Se llama con el mutex tomado
+func (c *CacheParticion) tamanoInodo() int32 {
+	if c.dimensionInodo == 0 {
+		c.dimensionInodo = tamanoInodoDe(c.archivo, int64(c.Particion.Part_start))
+	}
+	return c.dimensionInodo
+}
+
+func (c *CacheParticion) leerInodo(desplazamiento int64, inodo *INodo) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	entrada, err := c.buscar(desplazamiento, entradaInodo)
+	if err != nil {
+		return err
+	}
+	if entrada != nil {
+		*inodo = entrada.inodo
+		return nil
+	}
+	if err := inodo.decodificarDirecto(c.archivo, desplazamiento, c.tamanoInodo()); err != nil {
+		return err
+	}
+	return c.guardar(&entradaCache{desplazamiento: desplazamiento, tipo: entradaInodo, inodo: *inodo})
+}
+
+func (c *CacheParticion) escribirInodo(desplazamiento int64, inodo *INodo) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.guardar(&entradaCache{desplazamiento: desplazamiento, tipo: entradaInodo, inodo: *inodo, sucia: true})
+}
+
+func (c *CacheParticion) leerCarpeta(desplazamiento int64, contenido []FolderContent) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	entrada, err := c.buscar(desplazamiento, entradaCarpeta)
+	if err != nil {
+		return err
+	}
+	if entrada != nil && len(entrada.carpeta) == len(contenido) {
+		copy(contenido, entrada.carpeta)
+		return nil
+	}
+	if err := Utils.LeerDeArchivo(c.archivo, desplazamiento, contenido); err != nil {
+		return err
+	}
+	copia := append([]FolderContent(nil), contenido...)
+	return c.guardar(&entradaCache{desplazamiento: desplazamiento, tipo: entradaCarpeta, carpeta: copia})
+}
+
+func (c *CacheParticion) escribirCarpeta(desplazamiento int64, contenido []FolderContent) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	copia := append([]FolderContent(nil), contenido...)
+	return c.guardar(&entradaCache{desplazamiento: desplazamiento, tipo: entradaCarpeta, carpeta: copia, sucia: true})
+}
+
+func (c *CacheParticion) leerApuntadores(desplazamiento int64, apuntadores []int64) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	entrada, err := c.buscar(desplazamiento, entradaApuntadores)
+	if err != nil {
+		return err
+	}
+	if entrada != nil && len(entrada.apuntadores) == len(apuntadores) {
+		copy(apuntadores, entrada.apuntadores)
+		return nil
+	}
+	if err := (&PointerBlock{B_apuntadores: apuntadores}).decodificarDirecto(c.archivo, desplazamiento); err != nil {
+		return err
+	}
+	copia := append([]int64(nil), apuntadores...)
+	return c.guardar(&entradaCache{desplazamiento: desplazamiento, tipo: entradaApuntadores, apuntadores: copia})
+}
+
+func (c *CacheParticion) escribirApuntadores(desplazamiento int64, apuntadores []int64) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	copia := append([]int64(nil), apuntadores...)
+	return c.guardar(&entradaCache{desplazamiento: desplazamiento, tipo: entradaApuntadores, apuntadores: copia, sucia: true})
+}
+
+// LeerSuperBloque deserializa el superbloque de la particion con el descriptor abierto
+func (c *CacheParticion) LeerSuperBloque() (*SuperBlock, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	var sb SuperBlock
+	if err := sb.Decodificar(c.archivo, int64(c.Particion.Part_start)); err != nil {
+		return nil, err
+	}
+	return &sb, nil
+}
+
+// bitmap devuelve el bitmap de la ubicacion indicada, leyendolo completo la primera vez
+func (c *CacheParticion) bitmap(ubicacion ubicacionBitmap) (*bitmapMemoria, error) {
+	bitmap, existe := c.bitmaps[ubicacion.clave()]
+	if existe && bitmap.cantidad == ubicacion.cantidad {
+		return bitmap, nil
+	}
+	if existe {
+		if _, err := bitmap.escribir(c.archivo); err != nil {
+			return nil, err
+		}
+	}
+	bitmap, err := cargarBitmap(c.archivo, ubicacion)
+	if err != nil {
+		return nil, err
+	}
+	c.bitmaps[ubicacion.clave()] = bitmap
+	return bitmap, nil
+}
+
+// CargarBitmaps lee en memoria los bitmaps de inodos y bloques de la particion
+func (c *CacheParticion) CargarBitmaps(sb *SuperBlock) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for _, inicio := range []int32{sb.S_bm_inode_start, sb.S_bm_block_start} {
+		if _, err := c.bitmap(sb.ubicacionBitmap(inicio)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *CacheParticion) actualizarBitmap(ubicacion ubicacionBitmap, posicion int32, ocupado bool) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	bitmap, err := c.bitmap(ubicacion)
+	if err != nil {
+		return err
+	}
+	return bitmap.fijar(posicion, ocupado)
+}
+
+// asignarEnBitmap ocupa la primera posicion libre desde 'desde'; -1 continua desde el cursor
+func (c *CacheParticion) asignarEnBitmap(ubicacion ubicacionBitmap, desde int32) (int32, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	bitmap, err := c.bitmap(ubicacion)
+	if err != nil {
+		return -1, err
+	}
+	if desde == -1 {
+		desde = bitmap.cursor
+	}
+	return bitmap.asignar(desde)
+}
+
+func (c *CacheParticion) moverCursorBitmap(ubicacion ubicacionBitmap, posicion int32) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	bitmap, err := c.bitmap(ubicacion)
+	if err != nil {
+		return err
+	}
+	bitmap.cursor = posicion
+	return nil
+}
+
+func (c *CacheParticion) copiaBitmap(ubicacion ubicacionBitmap) ([]byte, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	bitmap, err := c.bitmap(ubicacion)
+	if err != nil {
+		return nil, err
+	}
+	return bitmap.bytes(), nil
+}
+
+// Sincronizar escribe las entradas sucias en orden de desplazamiento y los bitmaps
+// modificados, y devuelve cuantas estructuras fueron
+func (c *CacheParticion) Sincronizar() (int, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	var sucias []*entradaCache
+	for _, elemento := range c.entradas {
+		if entrada := elemento.Value.(*entradaCache); entrada.sucia {
+			sucias = append(sucias, entrada)
+		}
+	}
+	sort.Slice(sucias, func(i, j int) bool { return sucias[i].desplazamiento < sucias[j].desplazamiento })
+
+	for _, entrada := range sucias {
+		if err := c.escribir(entrada); err != nil {
+			return 0, err
+		}
+	}
+	escritas := len(sucias)
+	bitmapsEscritos := false
+	for _, bitmap := range c.bitmaps {
+		escrito, err := bitmap.escribir(c.archivo)
+		if err != nil {
+			return 0, err
+		}
+		if escrito {
+			escritas++
+			bitmapsEscritos = true
+		}
+	}
+	if bitmapsEscritos {
+		if err := c.actualizarDescriptores(); err != nil {
+			return 0, err
+		}
+	}
+	if escritas > 0 {
+		if err := c.archivo.Sync(); err != nil {
+			return 0, fmt.Errorf("error sincronizando el disco %s: %w", c.Ruta, err)
+		}
+	}
+	return escritas, nil
+}
+
+// Vaciar sincroniza y olvida todas las entradas
+func (c *CacheParticion) Vaciar() error {
+	if _, err := c.Sincronizar(); err != nil {
+		return err
+	}
+	c.mutex.Lock()
+	c.lru.Init()
+	c.entradas = make(map[int64]*list.Element)
+	c.bitmaps = make(map[int32]*bitmapMemoria)
+	c.dimensionInodo = 0
+	c.mutex.Unlock()
+	return nil
+}
+
+// RecargarParticion vuelve a leer la ubicacion de la particion desde la tabla del disco
+func (c *CacheParticion) RecargarParticion() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	particion, err := BuscarParticionPorID(c.archivo, c.Id)
+	if particion == nil {
+		return fmt.Errorf("error ubicando la partición %s: %v", c.Id, err)
+	}
+	c.Particion = *particion
+	c.dimensionInodo = 0
+	return nil
+}
+
+// Cerrar sincroniza la cache, la quita del registro y libera el descriptor del disco
+func (c *CacheParticion) Cerrar() error {
+	_, errSincronizar := c.Sincronizar()
+
+	mutexCaches.Lock()
+	clave := claveDisco(c.Ruta)
+	restantes := cachesPorDisco[clave][:0]
+	for _, cache := range cachesPorDisco[clave] {
+		if cache != c {
+			restantes = append(restantes, cache)
+		}
+	}
+	if len(restantes) == 0 {
+		delete(cachesPorDisco, clave)
+	} else {
+		cachesPorDisco[clave] = restantes
+	}
+	mutexCaches.Unlock()
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	errCerrar := c.archivo.Close()
+	if errSincronizar != nil {
+		return errSincronizar
+	}
+	return errCerrar
+}
+
+// SuspenderCaches vacia todas las caches y desactiva su uso hasta ReanudarCaches; se usa
+// alrededor de los comandos que leen o escriben el disco sin decodificar las estructuras.
+// Si una cache no se puede vaciar las caches quedan activas y se retorna el error
+func SuspenderCaches() error {
+	mutexCaches.Lock()
+	var caches []*CacheParticion
+	for _, lista := range cachesPorDisco {
+		caches = append(caches, lista...)
+	}
+	cachesSuspendidas = true
+	mutexCaches.Unlock()
+
+	for _, cache := range caches {
+		if err := cache.Vaciar(); err != nil {
+			ReanudarCaches()
+			return err
+		}
+	}
+	return nil
+}
+
+// ReanudarCaches vuelve a usar las caches despues de SuspenderCaches
+func ReanudarCaches() {
+	mutexCaches.Lock()
+	cachesSuspendidas = false
+	mutexCaches.Unlock()
+}
+
+// CachesSuspendidas indica si las estructuras se estan leyendo directamente del disco
+func CachesSuspendidas() bool {
+	mutexCaches.Lock()
+	defer mutexCaches.Unlock()
+	return cachesSuspendidas
+}
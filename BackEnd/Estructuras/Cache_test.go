@@ -0,0 +1,46 @@
+package Estructuras
+
+import (
+	"container/list"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSuspenderCachesConErrorLasDejaActivas(t *testing.T) {
+	ruta := filepath.Join(t.TempDir(), "disco.mia")
+	archivo, err := os.Create(ruta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Con el descriptor cerrado la escritura de la entrada sucia falla al vaciar
+	archivo.Close()
+
+	cache := &CacheParticion{
+		Id:       "891A",
+		Ruta:     ruta,
+		archivo:  archivo,
+		lru:      list.New(),
+		entradas: make(map[int64]*list.Element),
+		bitmaps:  make(map[int32]*bitmapMemoria),
+	}
+	if err := cache.escribirInodo(0, &INodo{}); err != nil {
+		t.Fatal(err)
+	}
+	mutexCaches.Lock()
+	clave := claveDisco(ruta)
+	cachesPorDisco[clave] = append(cachesPorDisco[clave], cache)
+	mutexCaches.Unlock()
+	defer func() {
+		mutexCaches.Lock()
+		delete(cachesPorDisco, clave)
+		mutexCaches.Unlock()
+	}()
+
+	if err := SuspenderCaches(); err == nil {
+		t.Fatalf("SuspenderCaches no reporto el error de escritura")
+	}
+	if CachesSuspendidas() {
+		t.Errorf("las caches quedaron suspendidas despues de un vaciado fallido")
+	}
+}
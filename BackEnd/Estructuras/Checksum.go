@@ -0,0 +1,81 @@
+package Estructuras
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"os"
+)
+
+// ErrSinFormato indica que en la posicion leida solo hay ceros (estructura nunca escrita)
+var ErrSinFormato = errors.New("la region no contiene ninguna estructura (solo ceros)")
+
+// ErrSinChecksum indica una estructura escrita antes de que existieran los checksums. Al
+// leerla se acepta sin checksum, pero su contenido no se puede verificar
+var ErrSinChecksum = errors.New("formato anterior sin checksum, no se puede verificar")
+
+// ErrorCorrupcion indica que una estructura leida del disco no coincide con su checksum
+type ErrorCorrupcion struct {
+	Estructura string // MBR, EBR o SuperBlock
+	Posicion   int64  // Byte del disco donde se leyo
+	Esperado   uint32 // Checksum almacenado en la estructura
+	Calculado  uint32 // Checksum calculado sobre los datos leidos
+}
+
+func (e *ErrorCorrupcion) Error() string {
+	return fmt.Sprintf("%s corrupto en la posicion %d: checksum almacenado %08x, calculado %08x",
+		e.Estructura, e.Posicion, e.Esperado, e.Calculado)
+}
+
+// EsCorrupcion indica si el error (o alguno que envuelva) es un ErrorCorrupcion
+func EsCorrupcion(err error) bool {
+	var corrupcion *ErrorCorrupcion
+	return errors.As(err, &corrupcion)
+}
+
+// calcularChecksum obtiene el CRC32 de la estructura serializada, excluyendo
+// los ultimos 4 bytes donde cada estructura guarda su propio checksum
+func calcularChecksum(estructura interface{}) (uint32, bool) {
+	var buffer bytes.Buffer
+	binary.Write(&buffer, binary.LittleEndian, estructura)
+	datos := buffer.Bytes()
+	return crc32.ChecksumIEEE(datos[:len(datos)-4]), bytes.Count(datos, []byte{0}) == len(datos)
+}
+
+// verificarChecksum compara el checksum almacenado con el de los datos leidos
+func verificarChecksum(nombre string, posicion int64, estructura interface{}, almacenado uint32) error {
+	calculado, vacia := calcularChecksum(estructura)
+	if vacia {
+		return fmt.Errorf("%s en la posicion %d: %w", nombre, posicion, ErrSinFormato)
+	}
+	if calculado != almacenado {
+		return &ErrorCorrupcion{Estructura: nombre, Posicion: posicion, Esperado: almacenado, Calculado: calculado}
+	}
+	return nil
+}
+
+// verificarChecksumAnterior es verificarChecksum para el MBR, el EBR y el SuperBlock, que
+// existian antes de los checksums. Si los campos nuevos caen sobre los datos que siguen a
+// la estructura es el formato anterior y no corrupcion. Fuera de esa disposicion un
+// checksum en cero tambien es corrupcion: puede ser una estructura cuyos ultimos bytes se
+// borraron
+func verificarChecksumAnterior(nombre string, posicion int64, estructura interface{}, almacenado uint32, formatoAnterior bool) error {
+	err := verificarChecksum(nombre, posicion, estructura, almacenado)
+	if EsCorrupcion(err) && formatoAnterior {
+		return ErrSinChecksum
+	}
+	return err
+}
+
+// escribirFormatoAnterior escribe solo los primeros 'tamano' bytes de la estructura, los
+// que tenia antes de los campos nuevos, para no pisar los datos que la siguen en el disco
+func escribirFormatoAnterior(archivo *os.File, posicion int64, estructura interface{}, tamano int) error {
+	var buffer bytes.Buffer
+	if err := binary.Write(&buffer, binary.LittleEndian, estructura); err != nil {
+		return err
+	}
+	_, err := archivo.WriteAt(buffer.Bytes()[:tamano], posicion)
+	return err
+}
@@ -0,0 +1,343 @@
+package Estructuras
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// discoTemporal crea un archivo de 'tamano' bytes en cero
+func discoTemporal(t *testing.T, tamano int64) *os.File {
+	t.Helper()
+	archivo, err := os.Create(filepath.Join(t.TempDir(), "disco.mia"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { archivo.Close() })
+	if err := archivo.Truncate(tamano); err != nil {
+		t.Fatal(err)
+	}
+	return archivo
+}
+
+// escribirBytes escribe bytes crudos en el disco
+func escribirBytes(t *testing.T, archivo *os.File, posicion int64, datos []byte) {
+	t.Helper()
+	if _, err := archivo.WriteAt(datos, posicion); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// prefijo serializa la estructura y se queda con los primeros 'tamano' bytes, como la
+// escribia la version anterior
+func prefijo(t *testing.T, estructura interface{}, tamano int) []byte {
+	t.Helper()
+	var buffer bytes.Buffer
+	if err := binary.Write(&buffer, binary.LittleEndian, estructura); err != nil {
+		t.Fatal(err)
+	}
+	return buffer.Bytes()[:tamano]
+}
+
+func mbrDePrueba(inicioParticion int32) MBR {
+	mbr := MBR{MbrSize: 4096, MbrDiskSignature: 77, MbrDiskFit: [1]byte{'F'}}
+	for i := range mbr.MbrPartitions {
+		mbr.MbrPartitions[i] = Particion{Part_start: -1, Part_size: -1, Part_correlative: -1}
+	}
+	mbr.MbrPartitions[0] = Particion{Part_status: [1]byte{'0'}, Part_type: [1]byte{'P'}, Part_fit: [1]byte{'F'},
+		Part_start: inicioParticion, Part_size: 1024, Part_correlative: -1}
+	copy(mbr.MbrPartitions[0].Part_name[:], "Part1")
+	return mbr
+}
+
+func TestDecodificarMBRChecksum(t *testing.T) {
+	tamano := int64(binary.Size(MBR{}))
+	casos := []struct {
+		nombre       string
+		preparar     func(t *testing.T, archivo *os.File)
+		error        func(error) bool
+		sinChecksum  bool
+		inicioPrimer int32
+	}{
+		{
+			nombre:   "solo ceros",
+			preparar: func(t *testing.T, archivo *os.File) {},
+			error:    func(err error) bool { return errors.Is(err, ErrSinFormato) },
+		},
+		{
+			nombre: "checksum correcto",
+			preparar: func(t *testing.T, archivo *os.File) {
+				mbr := mbrDePrueba(int32(tamano))
+				if err := mbr.Codificar(archivo); err != nil {
+					t.Fatal(err)
+				}
+			},
+			error:        func(err error) bool { return err == nil },
+			inicioPrimer: int32(tamano),
+		},
+		{
+			nombre: "un byte alterado",
+			preparar: func(t *testing.T, archivo *os.File) {
+				mbr := mbrDePrueba(int32(tamano))
+				if err := mbr.Codificar(archivo); err != nil {
+					t.Fatal(err)
+				}
+				escribirBytes(t, archivo, 0, []byte{0x01})
+			},
+			error: EsCorrupcion,
+		},
+		{
+			nombre: "checksum borrado",
+			preparar: func(t *testing.T, archivo *os.File) {
+				mbr := mbrDePrueba(int32(tamano))
+				if err := mbr.Codificar(archivo); err != nil {
+					t.Fatal(err)
+				}
+				escribirBytes(t, archivo, tamano-4, make([]byte, 4))
+			},
+			error: EsCorrupcion,
+		},
+		{
+			// Sin una particion justo despues no se distingue de un checksum borrado
+			nombre: "formato anterior sin particiones",
+			preparar: func(t *testing.T, archivo *os.File) {
+				mbr := mbrDePrueba(-1)
+				escribirBytes(t, archivo, 0, prefijo(t, &mbr, tamanoMBRAnterior))
+			},
+			error: EsCorrupcion,
+		},
+		{
+			nombre: "formato anterior con la particion formateada justo despues",
+			preparar: func(t *testing.T, archivo *os.File) {
+				mbr := mbrDePrueba(int32(tamanoMBRAnterior))
+				escribirBytes(t, archivo, 0, prefijo(t, &mbr, tamanoMBRAnterior))
+				escribirBytes(t, archivo, int64(tamanoMBRAnterior), []byte{2, 0, 0, 0, 9, 0, 0, 0})
+			},
+			error:        func(err error) bool { return err == nil },
+			sinChecksum:  true,
+			inicioPrimer: int32(tamanoMBRAnterior),
+		},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			archivo := discoTemporal(t, 4096)
+			caso.preparar(t, archivo)
+
+			var mbr MBR
+			err := mbr.Decodificar(archivo)
+			if !caso.error(err) {
+				t.Fatalf("Decodificar retorno %v", err)
+			}
+			if err != nil {
+				return
+			}
+			if mbr.SinChecksum() != caso.sinChecksum {
+				t.Errorf("SinChecksum() = %v, se esperaba %v", mbr.SinChecksum(), caso.sinChecksum)
+			}
+			if mbr.EsCompatible() {
+				t.Errorf("el MBR quedo marcado como compatible")
+			}
+			if mbr.MbrPartitions[0].Part_start != caso.inicioPrimer {
+				t.Errorf("la primera particion empieza en %d, se esperaba %d", mbr.MbrPartitions[0].Part_start, caso.inicioPrimer)
+			}
+		})
+	}
+}
+
+func TestCodificarMBRAnteriorNoPisaLaParticion(t *testing.T) {
+	archivo := discoTemporal(t, 4096)
+	mbr := mbrDePrueba(int32(tamanoMBRAnterior))
+	escribirBytes(t, archivo, 0, prefijo(t, &mbr, tamanoMBRAnterior))
+	datos := []byte{2, 0, 0, 0, 9, 0, 0, 0}
+	escribirBytes(t, archivo, int64(tamanoMBRAnterior), datos)
+
+	var leido MBR
+	if err := leido.Decodificar(archivo); err != nil {
+		t.Fatal(err)
+	}
+	leido.MbrPartitions[1] = Particion{Part_status: [1]byte{'0'}, Part_type: [1]byte{'P'}, Part_start: 2048, Part_size: 512, Part_correlative: -1}
+	if err := leido.Codificar(archivo); err != nil {
+		t.Fatal(err)
+	}
+
+	despues := make([]byte, len(datos))
+	if _, err := archivo.ReadAt(despues, int64(tamanoMBRAnterior)); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(despues, datos) {
+		t.Errorf("Codificar piso el inicio de la particion: %v, antes %v", despues, datos)
+	}
+	var releido MBR
+	if err := releido.Decodificar(archivo); err != nil {
+		t.Fatal(err)
+	}
+	if releido.MbrPartitions[1].Part_start != 2048 {
+		t.Errorf("no se guardo la particion nueva: %+v", releido.MbrPartitions[1])
+	}
+}
+
+func TestDecodificarSuperBlockChecksum(t *testing.T) {
+	const inicio = 1024
+	nuevo := func() SuperBlock {
+		return SuperBlock{S_filesystem_type: 2, S_magic: 0xEF53, S_inode_size: int32(TamanoInodo), S_block_size: 64,
+			S_bm_inode_start: inicio + int32(binary.Size(SuperBlock{})), S_bm_block_start: 3000, S_inode_start: 3100, S_block_start: 3500}
+	}
+	anterior := func(tipo int32) SuperBlock {
+		sb := nuevo()
+		sb.S_filesystem_type = tipo
+		sb.S_bm_inode_start = inicio + int32(tamanoSuperBlockAnterior)
+		if tipo == 3 {
+			sb.S_bm_inode_start += int32(ENTRADAS_JOURNAL * binary.Size(Journal{}))
+		}
+		return sb
+	}
+
+	casos := []struct {
+		nombre      string
+		preparar    func(t *testing.T, archivo *os.File)
+		error       func(error) bool
+		sinChecksum bool
+	}{
+		{
+			nombre:   "solo ceros",
+			preparar: func(t *testing.T, archivo *os.File) {},
+			error:    func(err error) bool { return errors.Is(err, ErrSinFormato) },
+		},
+		{
+			nombre: "checksum correcto",
+			preparar: func(t *testing.T, archivo *os.File) {
+				sb := nuevo()
+				if err := sb.Codificar(archivo, inicio); err != nil {
+					t.Fatal(err)
+				}
+			},
+			error: func(err error) bool { return err == nil },
+		},
+		{
+			nombre: "contador alterado",
+			preparar: func(t *testing.T, archivo *os.File) {
+				sb := nuevo()
+				if err := sb.Codificar(archivo, inicio); err != nil {
+					t.Fatal(err)
+				}
+				escribirBytes(t, archivo, inicio+4, []byte{0x7F})
+			},
+			error: EsCorrupcion,
+		},
+		{
+			nombre: "checksum borrado",
+			preparar: func(t *testing.T, archivo *os.File) {
+				sb := nuevo()
+				if err := sb.Codificar(archivo, inicio); err != nil {
+					t.Fatal(err)
+				}
+				escribirBytes(t, archivo, inicio+int64(binary.Size(SuperBlock{}))-4, make([]byte, 4))
+			},
+			error: EsCorrupcion,
+		},
+		{
+			nombre: "2fs anterior con el bitmap de inodos justo despues",
+			preparar: func(t *testing.T, archivo *os.File) {
+				sb := anterior(2)
+				escribirBytes(t, archivo, inicio, prefijo(t, &sb, tamanoSuperBlockAnterior))
+				escribirBytes(t, archivo, int64(sb.S_bm_inode_start), bytes.Repeat([]byte{0xFF}, 20))
+			},
+			error:       func(err error) bool { return err == nil },
+			sinChecksum: true,
+		},
+		{
+			nombre: "3fs anterior con el journal justo despues",
+			preparar: func(t *testing.T, archivo *os.File) {
+				sb := anterior(3)
+				escribirBytes(t, archivo, inicio, prefijo(t, &sb, tamanoSuperBlockAnterior))
+				escribirBytes(t, archivo, inicio+int64(tamanoSuperBlockAnterior), bytes.Repeat([]byte{0x5A}, 20))
+			},
+			error:       func(err error) bool { return err == nil },
+			sinChecksum: true,
+		},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			archivo := discoTemporal(t, 8192)
+			caso.preparar(t, archivo)
+
+			var sb SuperBlock
+			err := sb.Decodificar(archivo, inicio)
+			if !caso.error(err) {
+				t.Fatalf("Decodificar retorno %v", err)
+			}
+			if err != nil {
+				return
+			}
+			if sb.SinChecksum() != caso.sinChecksum {
+				t.Errorf("SinChecksum() = %v, se esperaba %v", sb.SinChecksum(), caso.sinChecksum)
+			}
+			if sb.S_groups_count != 0 || sb.S_gdt_start != 0 {
+				t.Errorf("el superbloque quedo con grupos: %d desde %d", sb.S_groups_count, sb.S_gdt_start)
+			}
+			if sb.S_magic != 0xEF53 {
+				t.Errorf("numero magico 0x%X", sb.S_magic)
+			}
+
+			// Guardarlo de nuevo no debe pisar lo que sigue al superbloque
+			antes := make([]byte, 20)
+			archivo.ReadAt(antes, inicio+int64(tamanoSuperBlockAnterior))
+			sb.S_mnt_count++
+			if err := sb.Codificar(archivo, inicio); err != nil {
+				t.Fatal(err)
+			}
+			despues := make([]byte, 20)
+			archivo.ReadAt(despues, inicio+int64(tamanoSuperBlockAnterior))
+			if caso.sinChecksum && !bytes.Equal(antes, despues) {
+				t.Errorf("Codificar piso los datos que siguen al superbloque")
+			}
+			var releido SuperBlock
+			if err := releido.Decodificar(archivo, inicio); err != nil || releido.S_mnt_count != sb.S_mnt_count {
+				t.Errorf("al releer: %v, montajes %d", err, releido.S_mnt_count)
+			}
+		})
+	}
+}
+
+func TestDecodificarEBRAnterior(t *testing.T) {
+	archivo := discoTemporal(t, 4096)
+	ebr := EBR{Ebr_mount: [1]byte{'0'}, Ebr_fit: [1]byte{'F'}, Ebr_start: 512, Ebr_size: 1024, Ebr_next: -1}
+	copy(ebr.Ebr_name[:], "Logica1")
+	escribirBytes(t, archivo, 512, prefijo(t, &ebr, binary.Size(EBR{})-4))
+
+	// En un disco con el MBR actual un checksum en cero es un EBR con los ultimos bytes borrados
+	mbr := mbrDePrueba(int32(binary.Size(MBR{})))
+	if err := mbr.Codificar(archivo); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LeerEBR(512, archivo); !EsCorrupcion(err) {
+		t.Fatalf("un EBR sin checksum en un disco actual retorno %v", err)
+	}
+
+	// En un disco del formato anterior se acepta sin verificar
+	mbr = mbrDePrueba(int32(tamanoMBRAnterior))
+	escribirBytes(t, archivo, 0, prefijo(t, &mbr, tamanoMBRAnterior))
+	leido, err := LeerEBR(512, archivo)
+	if err != nil {
+		t.Fatalf("un EBR sin checksum en un disco anterior no debe ser corrupto: %v", err)
+	}
+	if !leido.SinChecksum() || leido.Ebr_size != 1024 {
+		t.Errorf("EBR leido: %+v", leido)
+	}
+
+	if err := leido.Codificar(archivo, 512); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LeerEBR(512, archivo); err != nil {
+		t.Fatalf("el EBR reescrito no se pudo leer: %v", err)
+	}
+	escribirBytes(t, archivo, 512+6, []byte{0x01}) // Ebr_size
+	if _, err := LeerEBR(512, archivo); !EsCorrupcion(err) {
+		t.Errorf("un EBR con checksum alterado retorno %v", err)
+	}
+}
@@ -0,0 +1,206 @@
+package Estructuras
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// CompactarIndices deja todos los inodos ocupados por debajo de 'inodos' y todos los
+// bloques ocupados por debajo de 'bloques', moviendo los que sobran a las posiciones libres
+// mas bajas. Despues reescribe cada referencia a ellos: los apuntadores de los inodos y de
+// sus bloques de apuntadores, el bloque de atributos y las entradas de las carpetas,
+// incluida la marca del indice hash. Lee y escribe directo en el disco, asi que la
+// particion no debe tener la cache activa (resizefs corre con las caches suspendidas)
+func (sb *SuperBlock) CompactarIndices(archivo *os.File, inodos, bloques int32) error {
+	if cacheDe(archivo, int64(sb.S_inode_start)) != nil {
+		return errors.New("no se puede compactar una particion con la cache activa")
+	}
+
+	bitmapInodos, err := cargarBitmap(archivo, sb.ubicacionBitmap(sb.S_bm_inode_start))
+	if err != nil {
+		return fmt.Errorf("error leyendo el bitmap de inodos: %w", err)
+	}
+	bitmapBloques, err := cargarBitmap(archivo, sb.ubicacionBitmap(sb.S_bm_block_start))
+	if err != nil {
+		return fmt.Errorf("error leyendo el bitmap de bloques: %w", err)
+	}
+
+	nuevosInodos, err := reubicacionesBitmap(bitmapInodos, inodos)
+	if err != nil {
+		return fmt.Errorf("no se pueden compactar los inodos: %w", err)
+	}
+	nuevosBloques, err := reubicacionesBitmap(bitmapBloques, bloques)
+	if err != nil {
+		return fmt.Errorf("no se pueden compactar los bloques: %w", err)
+	}
+	if len(nuevosInodos) == 0 && len(nuevosBloques) == 0 {
+		return nil
+	}
+
+	// Copiar el contenido a su nueva posicion y limpiar la anterior
+	for anterior, nuevo := range nuevosInodos {
+		if err := moverRegion(archivo, sb.CalcularDesplazamientoInodo(anterior), sb.CalcularDesplazamientoInodo(nuevo), sb.S_inode_size); err != nil {
+			return fmt.Errorf("error moviendo el inodo %d al %d: %w", anterior, nuevo, err)
+		}
+	}
+	for anterior, nuevo := range nuevosBloques {
+		if err := moverRegion(archivo, sb.desplazamientoBloque(anterior), sb.desplazamientoBloque(nuevo), sb.S_block_size); err != nil {
+			return fmt.Errorf("error moviendo el bloque %d al %d: %w", anterior, nuevo, err)
+		}
+	}
+	if _, err := bitmapInodos.escribir(archivo); err != nil {
+		return err
+	}
+	if _, err := bitmapBloques.escribir(archivo); err != nil {
+		return err
+	}
+
+	// Reescribir las referencias de todos los inodos que quedaron ocupados
+	for indice := int32(0); indice < inodos; indice++ {
+		if !bitmapInodos.ocupado(indice) {
+			continue
+		}
+		if err := sb.reescribirReferencias(archivo, indice, nuevosInodos, nuevosBloques); err != nil {
+			return fmt.Errorf("error actualizando las referencias del inodo %d: %w", indice, err)
+		}
+	}
+	return nil
+}
+
+// reubicacionesBitmap asigna a cada posicion ocupada desde 'limite' la primera libre antes
+// de el y deja el bitmap con el cambio. Falla si no hay suficientes posiciones libres
+func reubicacionesBitmap(bitmap *bitmapMemoria, limite int32) (map[int32]int32, error) {
+	reubicaciones := make(map[int32]int32)
+	libre := int32(0)
+	for posicion := limite; posicion < bitmap.cantidad; posicion++ {
+		if !bitmap.ocupado(posicion) {
+			continue
+		}
+		for libre < limite && bitmap.ocupado(libre) {
+			libre++
+		}
+		if libre >= limite {
+			return nil, fmt.Errorf("hay mas posiciones ocupadas que las %d disponibles", limite)
+		}
+		if err := bitmap.fijar(libre, true); err != nil {
+			return nil, err
+		}
+		if err := bitmap.fijar(posicion, false); err != nil {
+			return nil, err
+		}
+		reubicaciones[posicion] = libre
+	}
+	return reubicaciones, nil
+}
+
+// moverRegion copia 'tamano' bytes de 'origen' a 'destino' y llena el origen con ceros
+func moverRegion(archivo *os.File, origen, destino int64, tamano int32) error {
+	datos := make([]byte, tamano)
+	if _, err := archivo.ReadAt(datos, origen); err != nil {
+		return err
+	}
+	if _, err := archivo.WriteAt(datos, destino); err != nil {
+		return err
+	}
+	_, err := archivo.WriteAt(make([]byte, tamano), origen)
+	return err
+}
+
+// reubicado retorna la nueva posicion de 'indice' o el mismo indice si no se movio
+func reubicado(reubicaciones map[int32]int32, indice int32) int32 {
+	if nuevo, ok := reubicaciones[indice]; ok {
+		return nuevo
+	}
+	return indice
+}
+
+// reescribirReferencias actualiza los apuntadores del inodo y, si es carpeta, los inodos
+// a los que apuntan sus entradas
+func (sb *SuperBlock) reescribirReferencias(archivo *os.File, indice int32, nuevosInodos, nuevosBloques map[int32]int32) error {
+	desplazamiento := sb.CalcularDesplazamientoInodo(indice)
+	inodo := &INodo{}
+	if err := inodo.decodificarDirecto(archivo, desplazamiento, sb.S_inode_size); err != nil {
+		return err
+	}
+
+	// Los bloques directos son de datos; los indirectos simple, doble y triple tienen uno,
+	// dos y tres niveles de bloques de apuntadores
+	var datos []int32
+	for i, bloque := range inodo.I_block {
+		if bloque == -1 {
+			continue
+		}
+		inodo.I_block[i] = reubicado(nuevosBloques, bloque)
+		if i < 12 {
+			datos = append(datos, inodo.I_block[i])
+			continue
+		}
+		if err := sb.reescribirApuntadores(archivo, inodo.I_block[i], i-11, nuevosBloques, &datos); err != nil {
+			return err
+		}
+	}
+	if inodo.I_xattr != -1 {
+		inodo.I_xattr = reubicado(nuevosBloques, inodo.I_xattr)
+	}
+	if err := inodo.codificarDirecto(archivo, desplazamiento, sb.S_inode_size); err != nil {
+		return err
+	}
+
+	if inodo.I_type[0] != '0' || len(nuevosInodos) == 0 {
+		return nil
+	}
+	for _, bloque := range datos {
+		carpeta := NuevoFolderBlock(sb.S_block_size)
+		if err := carpeta.Decodificar(archivo, sb.desplazamientoBloque(bloque)); err != nil {
+			return err
+		}
+		cambio := false
+		for i, contenido := range carpeta.B_cont {
+			if indiceMarca, esMarca := InodoDeMarca(contenido); esMarca {
+				if nuevo, ok := nuevosInodos[indiceMarca]; ok {
+					carpeta.B_cont[i] = contenidoMarcaIndice(nuevo)
+					cambio = true
+				}
+			} else if nuevo, ok := nuevosInodos[contenido.B_inodo]; ok && contenido.B_inodo >= 0 {
+				carpeta.B_cont[i].B_inodo = nuevo
+				cambio = true
+			}
+		}
+		if cambio {
+			if err := carpeta.Codificar(archivo, sb.desplazamientoBloque(bloque)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// reescribirApuntadores actualiza un bloque de apuntadores de 'nivel' niveles y los que
+// cuelgan de el; agrega a 'datos' los bloques de datos que encuentra
+func (sb *SuperBlock) reescribirApuntadores(archivo *os.File, bloque int32, nivel int, nuevosBloques map[int32]int32, datos *[]int32) error {
+	apuntadores := NuevoPointerBlock(sb.S_block_size)
+	if err := apuntadores.decodificarDirecto(archivo, sb.desplazamientoBloque(bloque)); err != nil {
+		return err
+	}
+	cambio := false
+	for i, apuntador := range apuntadores.B_apuntadores {
+		if apuntador == -1 {
+			continue
+		}
+		nuevo := reubicado(nuevosBloques, int32(apuntador))
+		if int64(nuevo) != apuntador {
+			apuntadores.B_apuntadores[i] = int64(nuevo)
+			cambio = true
+		}
+		if nivel == 1 {
+			*datos = append(*datos, nuevo)
+		} else if err := sb.reescribirApuntadores(archivo, nuevo, nivel-1, nuevosBloques, datos); err != nil {
+			return err
+		}
+	}
+	if !cambio {
+		return nil
+	}
+	return apuntadores.codificarDirecto(archivo, sb.desplazamientoBloque(bloque))
+}
@@ -0,0 +1,164 @@
+package Estructuras
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+
+	Utils "backend/Utils"
+)
+
+// Constantes del formato MBR estandar (el que leen fdisk -l o sfdisk en el host)
+const (
+	TamanoSector        = 512   // Los discos compatibles trabajan en sectores de 512 bytes
+	TipoCompatLinux     = 0x83  // Particion de datos Linux (primarias y logicas)
+	TipoCompatExtendida = 0x05  // Particion extendida
+	posicionFirmaDisco  = 0x1B8 // Firma del disco dentro del MBR estandar
+	posicionTablaCompat = 0x1BE // Primera de las 4 entradas de particion
+	marcaCompatible     = '1'   // Valor de MbrCompatible en discos compatibles
+)
+
+// CHS fuera de rango (cilindro 1023, cabeza 254, sector 63): las herramientas usan LBA
+var chsSoloLBA = [3]byte{0xFE, 0xFF, 0xFF}
+
+// Firma 0x55AA al final de cada sector con tabla de particiones
+var firmaCompat = [2]byte{0x55, 0xAA}
+
+// Entrada de 16 bytes de la tabla de particiones estandar
+type entradaCompat struct {
+	Estado    byte    // 0x80 si es de arranque
+	ChsInicio [3]byte // Direccion CHS del primer sector
+	Tipo      byte    // Codigo del tipo de particion
+	ChsFin    [3]byte // Direccion CHS del ultimo sector
+	LbaInicio uint32  // Primer sector (relativo segun el contexto)
+	Sectores  uint32  // Cantidad de sectores
+}
+
+// Tabla de particiones estandar con su firma 0x55AA, ocupa los bytes 0x1BE-0x1FF del sector
+type tablaCompat struct {
+	Entradas [4]entradaCompat
+	Firma    [2]byte
+}
+
+// EsCompatible indica si el disco fue creado con mkdisk -compat
+func (mbr *MBR) EsCompatible() bool {
+	return mbr.MbrCompatible[0] == marcaCompatible
+}
+
+// MarcarCompatible activa el formato compatible en el MBR
+func (mbr *MBR) MarcarCompatible() {
+	mbr.MbrCompatible[0] = marcaCompatible
+}
+
+// InicioUsable retorna el primer byte donde puede empezar una particion. En discos
+// compatibles el sector 0 queda reservado para el MBR estandar
+func (mbr *MBR) InicioUsable() int32 {
+	if mbr.EsCompatible() {
+		return TamanoSector
+	}
+	return int32(binary.Size(mbr))
+}
+
+// InicioDatos retorna el primer byte de la particion logica que describe el EBR. En discos
+// compatibles el sector completo del EBR queda reservado, porque su tabla estandar ocupa
+// los bytes 0x1BE-0x1FF
+func (e *EBR) InicioDatos(compatible bool) int32 {
+	if compatible {
+		return e.Ebr_start + TamanoSector
+	}
+	return e.Ebr_start + int32(binary.Size(e))
+}
+
+// nuevaEntradaCompat construye una entrada a partir de posiciones en bytes
+func nuevaEntradaCompat(tipo byte, inicio int64, tamano int64) entradaCompat {
+	return entradaCompat{
+		ChsInicio: chsSoloLBA,
+		Tipo:      tipo,
+		ChsFin:    chsSoloLBA,
+		LbaInicio: uint32(inicio / TamanoSector),
+		Sectores:  uint32(tamano / TamanoSector),
+	}
+}
+
+// SincronizarTablaCompatible reescribe la tabla estandar del MBR y de cada EBR a partir
+// de nuestras estructuras. Nuestro MBR y nuestros EBR viven en el area de codigo de
+// arranque de cada sector, que las herramientas del host ignoran
+func SincronizarTablaCompatible(archivo *os.File, mbr *MBR) error {
+	if !mbr.EsCompatible() {
+		return nil
+	}
+
+	var tabla tablaCompat
+	tabla.Firma = firmaCompat
+	var extendida *Particion
+	for i := range mbr.MbrPartitions {
+		p := &mbr.MbrPartitions[i]
+		if p.Part_start == -1 || p.Part_size <= 0 {
+			continue
+		}
+		tipo := byte(TipoCompatLinux)
+		if p.Part_type[0] == 'E' {
+			tipo = TipoCompatExtendida
+			extendida = p
+		}
+		tabla.Entradas[i] = nuevaEntradaCompat(tipo, int64(p.Part_start), int64(p.Part_size))
+	}
+
+	if err := Utils.EscribirAArchivo(archivo, posicionFirmaDisco, uint32(mbr.MbrDiskSignature)); err != nil {
+		return fmt.Errorf("error al escribir la firma del disco compatible: %v", err)
+	}
+	if err := Utils.EscribirAArchivo(archivo, posicionTablaCompat, &tabla); err != nil {
+		return fmt.Errorf("error al escribir la tabla compatible del MBR: %v", err)
+	}
+
+	if extendida == nil {
+		return nil
+	}
+	return sincronizarEBRCompatibles(archivo, extendida)
+}
+
+// Cada EBR estandar describe su particion logica (relativa al EBR) y el siguiente
+// EBR (relativo al inicio de la extendida). Los datos de la logica empiezan despues
+// del sector del EBR (ver EBR.InicioDatos), por eso la tabla no pisa la particion
+func sincronizarEBRCompatibles(archivo *os.File, extendida *Particion) error {
+	visitados := make(map[int32]bool)
+	inicioEBR := extendida.Part_start
+	for inicioEBR != -1 && !visitados[inicioEBR] {
+		visitados[inicioEBR] = true
+
+		var ebr EBR
+		if err := Utils.LeerDeArchivo(archivo, int64(inicioEBR), &ebr); err != nil {
+			return nil // La cadena aun no esta completa, se sincroniza en la siguiente escritura
+		}
+		if err := verificarChecksum("EBR", int64(inicioEBR), &ebr, ebr.Ebr_checksum); err != nil {
+			return nil
+		}
+
+		var tabla tablaCompat
+		tabla.Firma = firmaCompat
+		if ebr.Ebr_size > TamanoSector {
+			tabla.Entradas[0] = nuevaEntradaCompat(TipoCompatLinux, TamanoSector, int64(ebr.Ebr_size)-TamanoSector)
+		}
+		if ebr.Ebr_next != -1 {
+			var siguiente EBR
+			if err := Utils.LeerDeArchivo(archivo, int64(ebr.Ebr_next), &siguiente); err == nil {
+				tabla.Entradas[1] = nuevaEntradaCompat(TipoCompatExtendida,
+					int64(ebr.Ebr_next-extendida.Part_start), int64(siguiente.Ebr_size))
+			}
+		}
+		if err := Utils.EscribirAArchivo(archivo, int64(inicioEBR)+posicionTablaCompat, &tabla); err != nil {
+			return fmt.Errorf("error al escribir la tabla compatible del EBR en %d: %v", inicioEBR, err)
+		}
+		inicioEBR = ebr.Ebr_next
+	}
+	return nil
+}
+
+// sincronizarDesdeDisco lee el MBR del disco y, si es compatible, actualiza la tabla estandar
+func sincronizarDesdeDisco(archivo *os.File) error {
+	var mbr MBR
+	if err := mbr.Decodificar(archivo); err != nil {
+		return nil
+	}
+	return SincronizarTablaCompatible(archivo, &mbr)
+}
@@ -0,0 +1,114 @@
+package Estructuras
+
+import (
+	"os"
+	"testing"
+
+	Utils "backend/Utils"
+)
+
+// leerTablaCompat lee la tabla estandar del sector que empieza en 'sector'
+func leerTablaCompat(t *testing.T, archivo *os.File, sector int64) tablaCompat {
+	t.Helper()
+	var tabla tablaCompat
+	if err := Utils.LeerDeArchivo(archivo, sector+posicionTablaCompat, &tabla); err != nil {
+		t.Fatal(err)
+	}
+	return tabla
+}
+
+func TestSincronizarTablaCompatible(t *testing.T) {
+	archivo := discoTemporal(t, 8192)
+
+	// Primaria en el sector 1 y extendida en los sectores 5-12 con dos logicas
+	mbr := mbrDePrueba(TamanoSector)
+	mbr.MbrSize = 8192
+	mbr.MbrPartitions[0].Part_size = 4 * TamanoSector
+	mbr.MbrPartitions[1] = Particion{Part_status: [1]byte{'0'}, Part_type: [1]byte{'E'}, Part_fit: [1]byte{'F'},
+		Part_start: 5 * TamanoSector, Part_size: 8 * TamanoSector, Part_correlative: -1}
+	mbr.MarcarCompatible()
+	if err := mbr.Codificar(archivo); err != nil {
+		t.Fatal(err)
+	}
+	logicas := []EBR{
+		{Ebr_start: 5 * TamanoSector, Ebr_size: 3 * TamanoSector, Ebr_next: 8 * TamanoSector},
+		{Ebr_start: 8 * TamanoSector, Ebr_size: 5 * TamanoSector, Ebr_next: -1},
+	}
+	for i := range logicas {
+		if err := logicas[i].Codificar(archivo, int64(logicas[i].Ebr_start)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Datos de cada logica justo donde empieza segun su EBR
+	datos := []byte("datos de la logica")
+	for _, logica := range logicas {
+		if inicio := logica.InicioDatos(true); inicio != logica.Ebr_start+TamanoSector {
+			t.Fatalf("la logica del EBR %d empieza en %d, dentro del sector del EBR", logica.Ebr_start, inicio)
+		}
+		escribirBytes(t, archivo, int64(logica.InicioDatos(true)), datos)
+	}
+	if err := SincronizarTablaCompatible(archivo, &mbr); err != nil {
+		t.Fatal(err)
+	}
+	for _, logica := range logicas {
+		leidos := make([]byte, len(datos))
+		archivo.ReadAt(leidos, int64(logica.InicioDatos(true)))
+		if string(leidos) != string(datos) {
+			t.Errorf("la tabla del EBR %d piso los datos de su logica: %q", logica.Ebr_start, leidos)
+		}
+	}
+
+	entrada := func(tipo byte, lba, sectores uint32) entradaCompat {
+		return entradaCompat{ChsInicio: chsSoloLBA, Tipo: tipo, ChsFin: chsSoloLBA, LbaInicio: lba, Sectores: sectores}
+	}
+	casos := []struct {
+		nombre   string
+		sector   int64
+		entradas [4]entradaCompat
+	}{
+		{"MBR", 0, [4]entradaCompat{entrada(TipoCompatLinux, 1, 4), entrada(TipoCompatExtendida, 5, 8)}},
+		// Cada logica es relativa a su EBR y el siguiente EBR relativo a la extendida
+		{"primer EBR", 5 * TamanoSector, [4]entradaCompat{entrada(TipoCompatLinux, 1, 2), entrada(TipoCompatExtendida, 3, 5)}},
+		{"ultimo EBR", 8 * TamanoSector, [4]entradaCompat{entrada(TipoCompatLinux, 1, 4)}},
+	}
+	for _, caso := range casos {
+		tabla := leerTablaCompat(t, archivo, caso.sector)
+		if tabla.Firma != firmaCompat {
+			t.Errorf("%s: firma % x", caso.nombre, tabla.Firma)
+		}
+		if tabla.Entradas != caso.entradas {
+			t.Errorf("%s: entradas %+v, se esperaba %+v", caso.nombre, tabla.Entradas, caso.entradas)
+		}
+	}
+
+	var firma uint32
+	if err := Utils.LeerDeArchivo(archivo, posicionFirmaDisco, &firma); err != nil {
+		t.Fatal(err)
+	}
+	if firma != uint32(mbr.MbrDiskSignature) {
+		t.Errorf("firma del disco %d, se esperaba %d", firma, mbr.MbrDiskSignature)
+	}
+
+	// Nuestro MBR y nuestros EBR siguen legibles despues de escribir las tablas
+	var leido MBR
+	if err := leido.Decodificar(archivo); err != nil {
+		t.Fatal(err)
+	}
+	for _, logica := range logicas {
+		var ebr EBR
+		if err := ebr.Decodificar(archivo, int64(logica.Ebr_start)); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestSincronizarTablaNoCompatible(t *testing.T) {
+	archivo := discoTemporal(t, 4096)
+	mbr := mbrDePrueba(TamanoSector)
+	if err := SincronizarTablaCompatible(archivo, &mbr); err != nil {
+		t.Fatal(err)
+	}
+	if tabla := leerTablaCompat(t, archivo, 0); tabla != (tablaCompat{}) {
+		t.Errorf("un disco sin -compat no debe tener tabla estandar: %+v", tabla)
+	}
+}
@@ -0,0 +1,356 @@
+package Estructuras
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// RutaArchivoCuotas es el archivo oculto, junto a users.txt, con los limites de cada
+// usuario y grupo. Cada linea es "U|G,id,bloques suave,bloques duro,inodos suave,inodos duro".
+// Solo root lo resuelve por su ruta y no aparece en los listados
+const RutaArchivoCuotas = "/.quota"
+
+var nombreArchivoCuotas = strings.TrimPrefix(RutaArchivoCuotas, "/")
+
+// esArchivoCuotas indica si la entrada 'nombre' de la carpeta 'indiceCarpeta' es el archivo
+// de cuotas
+func esArchivoCuotas(indiceCarpeta int32, nombre string) bool {
+	return indiceCarpeta == 0 && nombre == nombreArchivoCuotas
+}
+
+// EsOculta indica si la entrada no se muestra en los listados (ls, tree, find, fs.FS)
+func (d *Directorio) EsOculta(entrada EntradaDirectorio) bool {
+	return esArchivoCuotas(d.IndiceInodo, entrada.Nombre)
+}
+
+// ErrCuotaExcedida indica que la asignacion pasaria un limite duro
+var ErrCuotaExcedida = errors.New("cuota excedida")
+
+// CredencialesAsignacion retorna quien ejecuta el comando en curso. La registra Global para
+// que las asignaciones se cobren al usuario con sesion; sin ella no se aplican cuotas. El
+// error indica una sesion cuyo usuario no se pudo identificar, que no puede asignar nada
+var CredencialesAsignacion func(archivo *os.File, sb *SuperBlock) (Credenciales, error)
+
+// LimiteCuota son los limites de bloques e inodos; 0 significa sin limite
+type LimiteCuota struct {
+	BloquesSuave int32
+	BloquesDuro  int32
+	InodosSuave  int32
+	InodosDuro   int32
+}
+
+// SinLimites indica que ningun limite esta definido
+func (l LimiteCuota) SinLimites() bool {
+	return l == LimiteCuota{}
+}
+
+// UsoCuota es lo que ocupan los inodos de un usuario o grupo
+type UsoCuota struct {
+	Bloques int32
+	Inodos  int32
+}
+
+// TablaCuotas son los limites guardados en el archivo de cuotas
+type TablaCuotas struct {
+	Usuarios map[int32]LimiteCuota
+	Grupos   map[int32]LimiteCuota
+}
+
+// NuevaTablaCuotas crea una tabla sin limites
+func NuevaTablaCuotas() *TablaCuotas {
+	return &TablaCuotas{Usuarios: map[int32]LimiteCuota{}, Grupos: map[int32]LimiteCuota{}}
+}
+
+// interpretarCuotas lee el contenido del archivo de cuotas
+func interpretarCuotas(contenido string) (*TablaCuotas, error) {
+	tabla := NuevaTablaCuotas()
+	for numero, linea := range strings.Split(contenido, "\n") {
+		linea = strings.TrimSpace(linea)
+		if linea == "" {
+			continue
+		}
+		campos := strings.Split(linea, ",")
+		if len(campos) != 6 || (campos[0] != "U" && campos[0] != "G") {
+			return nil, fmt.Errorf("linea %d del archivo de cuotas invalida: %s", numero+1, linea)
+		}
+		var valores [5]int32
+		for i, campo := range campos[1:] {
+			valor, err := strconv.ParseInt(campo, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("linea %d del archivo de cuotas invalida: %s", numero+1, linea)
+			}
+			valores[i] = int32(valor)
+		}
+		limite := LimiteCuota{BloquesSuave: valores[1], BloquesDuro: valores[2], InodosSuave: valores[3], InodosDuro: valores[4]}
+		if campos[0] == "U" {
+			tabla.Usuarios[valores[0]] = limite
+		} else {
+			tabla.Grupos[valores[0]] = limite
+		}
+	}
+	return tabla, nil
+}
+
+// Contenido serializa la tabla, ordenada por tipo e identificador
+func (t *TablaCuotas) Contenido() string {
+	var salida strings.Builder
+	escribir := func(tipo string, limites map[int32]LimiteCuota) {
+		ids := make([]int32, 0, len(limites))
+		for id := range limites {
+			ids = append(ids, id)
+		}
+		slices.Sort(ids)
+		for _, id := range ids {
+			l := limites[id]
+			fmt.Fprintf(&salida, "%s,%d,%d,%d,%d,%d\n", tipo, id, l.BloquesSuave, l.BloquesDuro, l.InodosSuave, l.InodosDuro)
+		}
+	}
+	escribir("U", t.Usuarios)
+	escribir("G", t.Grupos)
+	return salida.String()
+}
+
+// LeerCuotas retorna los limites guardados; sin archivo de cuotas la tabla esta vacia
+func (sb *SuperBlock) LeerCuotas(archivo *os.File) (*TablaCuotas, error) {
+	resuelta, err := sb.ResolverRuta(archivo, RutaArchivoCuotas, CredencialesRoot)
+	if errors.Is(err, ErrRutaNoEncontrada) {
+		return NuevaTablaCuotas(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error al buscar el archivo de cuotas: %w", err)
+	}
+	inodo := &INodo{}
+	if err := inodo.Decodificar(archivo, sb.CalcularDesplazamientoInodo(resuelta.Inodo)); err != nil {
+		return nil, fmt.Errorf("error al deserializar el inodo %d: %w", resuelta.Inodo, err)
+	}
+	datos, err := inodo.LeerDatos(archivo, sb)
+	if err != nil {
+		return nil, fmt.Errorf("error al leer el archivo de cuotas: %w", err)
+	}
+	return interpretarCuotas(string(datos))
+}
+
+// GuardarCuotas escribe la tabla en el archivo de cuotas, creandolo en la raiz si no existe.
+// El archivo pertenece a root y solo root lo puede leer o escribir
+func (sb *SuperBlock) GuardarCuotas(archivo *os.File, tabla *TablaCuotas) error {
+	contenido := []byte(tabla.Contenido())
+	resuelta, err := sb.ResolverRuta(archivo, RutaArchivoCuotas, CredencialesRoot)
+	creado := false
+	if errors.Is(err, ErrRutaNoEncontrada) {
+		if err := sb.crearArchivoEnInodo(archivo, 0, nombreArchivoCuotas, 0, contenido, false); err != nil {
+			return fmt.Errorf("error al crear el archivo de cuotas: %w", err)
+		}
+		creado = true
+		resuelta, err = sb.ResolverRuta(archivo, RutaArchivoCuotas, CredencialesRoot)
+	}
+	if err != nil {
+		return fmt.Errorf("error al buscar el archivo de cuotas: %w", err)
+	}
+
+	desplazamiento := sb.CalcularDesplazamientoInodo(resuelta.Inodo)
+	inodo := &INodo{}
+	if err := inodo.Decodificar(archivo, desplazamiento); err != nil {
+		return fmt.Errorf("error al deserializar el inodo %d: %w", resuelta.Inodo, err)
+	}
+	if !creado {
+		if err := inodo.EscribirDatos(archivo, sb, contenido); err != nil {
+			return fmt.Errorf("error al escribir el archivo de cuotas: %w", err)
+		}
+	}
+	inodo.I_uid, inodo.I_gid = 1, 1
+	inodo.I_perm = [3]byte{'6', '0', '0'}
+	return inodo.Codificar(archivo, desplazamiento)
+}
+
+// CalcularUsoCuotas recorre los inodos ocupados y suma a su usuario y a su grupo el inodo
+// y los bloques que usa (datos, apuntadores y atributos)
+func (sb *SuperBlock) CalcularUsoCuotas(archivo *os.File) (map[int32]UsoCuota, map[int32]UsoCuota, error) {
+	bitmap, err := sb.LeerBitmapInodos(archivo)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error al leer el bitmap de inodos: %w", err)
+	}
+
+	usuarios, grupos := map[int32]UsoCuota{}, map[int32]UsoCuota{}
+	for posicion, valor := range bitmap {
+		for valor != 0 {
+			bit := bits.TrailingZeros8(valor)
+			valor &= valor - 1
+			indice := int32(posicion*8 + bit)
+
+			inodo := &INodo{}
+			if err := inodo.Decodificar(archivo, sb.CalcularDesplazamientoInodo(indice)); err != nil {
+				return nil, nil, fmt.Errorf("error al deserializar el inodo %d: %w", indice, err)
+			}
+			bloques, err := inodo.ObtenerTodosLosIndicesDeBloques(archivo, sb)
+			if err != nil {
+				return nil, nil, fmt.Errorf("error al leer los bloques del inodo %d: %w", indice, err)
+			}
+			uso := UsoCuota{Bloques: int32(len(bloques)), Inodos: 1}
+			if inodo.I_xattr != -1 {
+				uso.Bloques++
+			}
+
+			u := usuarios[inodo.I_uid]
+			usuarios[inodo.I_uid] = UsoCuota{Bloques: u.Bloques + uso.Bloques, Inodos: u.Inodos + uso.Inodos}
+			g := grupos[inodo.I_gid]
+			grupos[inodo.I_gid] = UsoCuota{Bloques: g.Bloques + uso.Bloques, Inodos: g.Inodos + uso.Inodos}
+		}
+	}
+	return usuarios, grupos, nil
+}
+
+// controlCuotas guarda, durante un comando, a quien se cobran las asignaciones, sus limites
+// y su uso, para no leer users.txt ni recorrer los inodos en cada asignacion
+type controlCuotas struct {
+	particion     string
+	credenciales  Credenciales
+	err           error // quien tiene la sesion no se pudo identificar
+	limiteUsuario LimiteCuota
+	limiteGrupo   LimiteCuota
+	usoUsuario    UsoCuota
+	usoGrupo      UsoCuota
+}
+
+// cuotasEnCurso es el control del comando en curso; ReiniciarCuotas lo descarta
+var cuotasEnCurso *controlCuotas
+
+// advertenciasCuotas son los limites suaves superados durante el comando en curso
+var advertenciasCuotas []string
+
+// ReiniciarCuotas descarta quien asigna, los limites, el uso y las advertencias del comando
+// anterior. El analizador la llama antes de cada comando y login y logout al cambiar la sesion
+func ReiniciarCuotas() {
+	cuotasEnCurso = nil
+	advertenciasCuotas = nil
+}
+
+// AdvertenciasCuotas retorna los limites suaves que supero el comando en curso, para
+// mostrarlos junto a su salida
+func AdvertenciasCuotas() []string {
+	return advertenciasCuotas
+}
+
+// cuotasDe retorna el control de cuotas de la particion para el comando en curso,
+// calculandolo la primera vez que se asigna algo en ella
+func (sb *SuperBlock) cuotasDe(archivo *os.File) *controlCuotas {
+	if cuotasEnCurso == nil || cuotasEnCurso.particion != archivo.Name() {
+		cuotasEnCurso = sb.cargarControlCuotas(archivo)
+	}
+	return cuotasEnCurso
+}
+
+// controlDe retorna el control de cuotas de la particion, o nil si no hay limites que
+// aplicar ni una sesion sin identificar que rechazar
+func (sb *SuperBlock) controlDe(archivo *os.File) *controlCuotas {
+	if CredencialesAsignacion == nil {
+		return nil
+	}
+	control := sb.cuotasDe(archivo)
+	if control.err == nil && control.limiteUsuario.SinLimites() && control.limiteGrupo.SinLimites() {
+		return nil
+	}
+	return control
+}
+
+func (sb *SuperBlock) cargarControlCuotas(archivo *os.File) *controlCuotas {
+	credenciales, err := CredencialesAsignacion(archivo, sb)
+	control := &controlCuotas{particion: archivo.Name(), credenciales: credenciales, err: err}
+	// root no tiene cuotas y sin sesion (mkfs, recovery) no hay a quien cobrarlas
+	if err != nil || control.credenciales.Root || control.credenciales.Uid < 0 {
+		return control
+	}
+
+	tabla, err := sb.LeerCuotas(archivo)
+	if err != nil {
+		fmt.Printf("Advertencia: no se aplican cuotas: %v\n", err)
+		return control
+	}
+	control.limiteUsuario = tabla.Usuarios[control.credenciales.Uid]
+	control.limiteGrupo = tabla.Grupos[control.credenciales.Gid]
+	if control.limiteUsuario.SinLimites() && control.limiteGrupo.SinLimites() {
+		return control
+	}
+
+	usuarios, grupos, err := sb.CalcularUsoCuotas(archivo)
+	if err != nil {
+		fmt.Printf("Advertencia: no se aplican cuotas: %v\n", err)
+		return &controlCuotas{particion: control.particion, credenciales: control.credenciales}
+	}
+	control.usoUsuario = usuarios[control.credenciales.Uid]
+	control.usoGrupo = grupos[control.credenciales.Gid]
+	return control
+}
+
+// reservarCuota cobra bloques o inodos a quien ejecuta el comando antes de asignarlos. Pasar
+// un limite duro lo rechaza; pasar uno suave solo deja una advertencia
+func (sb *SuperBlock) reservarCuota(archivo *os.File, bloques, inodos int32) error {
+	control := sb.controlDe(archivo)
+	if control == nil {
+		return nil
+	}
+	if control.err != nil {
+		return fmt.Errorf("no se puede asignar: %w", control.err)
+	}
+	usuario := UsoCuota{Bloques: control.usoUsuario.Bloques + bloques, Inodos: control.usoUsuario.Inodos + inodos}
+	grupo := UsoCuota{Bloques: control.usoGrupo.Bloques + bloques, Inodos: control.usoGrupo.Inodos + inodos}
+	if err := control.limiteUsuario.revisar(usuario, control.usoUsuario, fmt.Sprintf("usuario %d", control.credenciales.Uid)); err != nil {
+		return err
+	}
+	if err := control.limiteGrupo.revisar(grupo, control.usoGrupo, fmt.Sprintf("grupo %d", control.credenciales.Gid)); err != nil {
+		return err
+	}
+	control.usoUsuario, control.usoGrupo = usuario, grupo
+	return nil
+}
+
+// revisar compara el uso que resultaria de la asignacion con los limites
+func (l LimiteCuota) revisar(nuevo, anterior UsoCuota, quien string) error {
+	if l.BloquesDuro > 0 && nuevo.Bloques > l.BloquesDuro && nuevo.Bloques > anterior.Bloques {
+		return fmt.Errorf("el %s llego a su limite de %d bloques: %w", quien, l.BloquesDuro, ErrCuotaExcedida)
+	}
+	if l.InodosDuro > 0 && nuevo.Inodos > l.InodosDuro && nuevo.Inodos > anterior.Inodos {
+		return fmt.Errorf("el %s llego a su limite de %d inodos: %w", quien, l.InodosDuro, ErrCuotaExcedida)
+	}
+	if l.BloquesSuave > 0 && nuevo.Bloques > l.BloquesSuave && anterior.Bloques <= l.BloquesSuave {
+		advertenciasCuotas = append(advertenciasCuotas, fmt.Sprintf("el %s supera su limite suave de %d bloques", quien, l.BloquesSuave))
+	}
+	if l.InodosSuave > 0 && nuevo.Inodos > l.InodosSuave && anterior.Inodos <= l.InodosSuave {
+		advertenciasCuotas = append(advertenciasCuotas, fmt.Sprintf("el %s supera su limite suave de %d inodos", quien, l.InodosSuave))
+	}
+	return nil
+}
+
+// propietarioNuevoInodo retorna el usuario y grupo al que pertenece un inodo creado en el
+// comando en curso, que es a quien se cobra. Sin sesion (mkfs, recovery) o con root es root
+func (sb *SuperBlock) propietarioNuevoInodo(archivo *os.File) (int32, int32) {
+	if CredencialesAsignacion == nil {
+		return 1, 1
+	}
+	credenciales := sb.cuotasDe(archivo).credenciales
+	if credenciales.Root || credenciales.Uid < 0 || credenciales.Gid < 0 {
+		return 1, 1
+	}
+	return credenciales.Uid, credenciales.Gid
+}
+
+// descontarCuota devuelve al usuario y grupo del inodo los bloques o inodos liberados
+// durante el comando, para que reescribir un archivo no cuente dos veces sus bloques
+func descontarCuota(archivo *os.File, inodo *INodo, bloques, inodos int32) {
+	control := cuotasEnCurso
+	if control == nil || control.particion != archivo.Name() {
+		return
+	}
+	if inodo.I_uid == control.credenciales.Uid {
+		control.usoUsuario.Bloques -= bloques
+		control.usoUsuario.Inodos -= inodos
+	}
+	if inodo.I_gid == control.credenciales.Gid {
+		control.usoGrupo.Bloques -= bloques
+		control.usoGrupo.Inodos -= inodos
+	}
+}
@@ -2,17 +2,19 @@ package Estructuras
 
 import (
 	Utils "backend/Utils"
+	"errors"
 	"fmt"
 	"os"
 )
 
 type EBR struct {
-	Ebr_mount [1]byte  // Estado de montaje de la partición
-	Ebr_fit   [1]byte  // Algoritmo de ajuste: BF, FF, WF
-	Ebr_start int32    // Posición inicial en bytes
-	Ebr_size  int32    // Dimensión total en bytes
-	Ebr_next  int32    // Puntero al próximo EBR (-1 si es el último)
-	Ebr_name  [16]byte // Identificador de la partición
+	Ebr_mount    [1]byte  // Estado de montaje de la partición
+	Ebr_fit      [1]byte  // Algoritmo de ajuste: BF, FF, WF
+	Ebr_start    int32    // Posición inicial en bytes
+	Ebr_size     int32    // Dimensión total en bytes
+	Ebr_next     int32    // Puntero al próximo EBR (-1 si es el último)
+	Ebr_name     [16]byte // Identificador de la partición
+	Ebr_checksum uint32   // CRC32 de los campos anteriores
 }
 
 func (e *EBR) EstablecerEBR(ajuste byte, capacidad int32, inicio int32, siguiente int32, nombre string) {
@@ -35,7 +37,11 @@ func (e *EBR) EstablecerEBR(ajuste byte, capacidad int32, inicio int32, siguient
 
 // Serialización del EBR hacia archivo en ubicación específica
 func (e *EBR) Codificar(archivo *os.File, posicion int64) error {
-	return Utils.EscribirAArchivo(archivo, posicion, e)
+	e.Ebr_checksum, _ = calcularChecksum(e)
+	if err := Utils.EscribirAArchivo(archivo, posicion, e); err != nil {
+		return err
+	}
+	return sincronizarDesdeDisco(archivo)
 }
 
 func (e *EBR) CalcularInicioSiguienteEBR(inicioParticionExtendida int32, capacidadParticionExtendida int32) (int32, error) {
@@ -94,11 +100,31 @@ func (ebr *EBR) Decodificar(archivo *os.File, posicion int64) error {
 	if err != nil {
 		return err
 	}
+	// Los EBR anteriores a los checksums tienen ceros donde ahora va Ebr_checksum
+	anterior := ebr.Ebr_checksum == 0 && formatoAnteriorEBR(archivo)
+	err = verificarChecksumAnterior("EBR", posicion, ebr, ebr.Ebr_checksum, anterior)
+	if errors.Is(err, ErrSinChecksum) {
+		ebr.Ebr_checksum = 0
+	} else if err != nil {
+		return err
+	}
 
 	fmt.Printf("EBR recuperado correctamente desde posición %d\n", posicion)
 	return nil
 }
 
+// formatoAnteriorEBR indica que los EBR del disco son anteriores a los checksums. Lo que
+// sigue a un EBR no permite reconocerlo, asi que se reconoce por el MBR del mismo disco
+func formatoAnteriorEBR(archivo *os.File) bool {
+	var mbr MBR
+	return Utils.LeerDeArchivo(archivo, 0, &mbr) == nil && mbr.formatoAnterior()
+}
+
+// SinChecksum indica que el EBR esta en el formato anterior y no se pudo verificar
+func (e *EBR) SinChecksum() bool {
+	return e.Ebr_checksum == 0
+}
+
 // Extrae un EBR específico desde una ubicación determinada del archivo
 func LeerEBR(inicio int32, archivo *os.File) (*EBR, error) {
 	fmt.Printf("Extrayendo EBR desde posición: %d\n", inicio)
@@ -0,0 +1,153 @@
+package Estructuras
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Tipos de inodo guardados en I_type
+const (
+	TipoCarpeta         byte = '0'
+	TipoArchivo         byte = '1'
+	TipoEnlaceSimbolico byte = '2'
+)
+
+// MaximoEnlacesSeguidos limita cuantos enlaces simbolicos se siguen al resolver una ruta;
+// pasar de ese limite se toma como un ciclo entre enlaces
+const MaximoEnlacesSeguidos = 8
+
+// EsEnlaceSimbolico indica si el inodo es un enlace simbolico
+func (inodo *INodo) EsEnlaceSimbolico() bool {
+	return inodo.I_type[0] == TipoEnlaceSimbolico
+}
+
+// LeerDestinoEnlace retorna la ruta guardada en los bloques de datos del enlace
+func (sb *SuperBlock) LeerDestinoEnlace(archivo *os.File, inodo *INodo) (string, error) {
+	if !inodo.EsEnlaceSimbolico() {
+		return "", fmt.Errorf("el inodo no es un enlace simbolico")
+	}
+	datos, err := inodo.LeerDatos(archivo, sb)
+	if err != nil {
+		return "", fmt.Errorf("error leyendo el destino del enlace: %w", err)
+	}
+	return string(datos), nil
+}
+
+// agregarEntradaEnCarpeta agrega el nombre a la carpeta indicada apuntando al inodo y
+// actualiza el tamaño y la fecha de la carpeta
+func (sb *SuperBlock) agregarEntradaEnCarpeta(archivo *os.File, indiceCarpeta int32, nombre string, indiceInodo int32) error {
+	directorio, err := sb.LeerDirectorio(archivo, indiceCarpeta)
+	if err != nil {
+		return err
+	}
+	if err := ValidarNombreEntrada(nombre); err != nil {
+		return err
+	}
+	if _, existe := directorio.Buscar(nombre); existe {
+		return fmt.Errorf("ya existe una entrada llamada '%s'", nombre)
+	}
+	if err := directorio.Agregar(archivo, sb, nombre, indiceInodo); err != nil {
+		return err
+	}
+
+	// Guardar escribe el inodo de la carpeta con el nuevo tamaño y sus fechas
+	directorio.Inodo.I_size++
+	return directorio.Guardar(archivo, sb)
+}
+
+// CrearEnlaceSimbolico crea en la carpeta un inodo de tipo enlace cuyo contenido es la
+// ruta destino. El destino no necesita existir; se resuelve cada vez que se sigue
+func (sb *SuperBlock) CrearEnlaceSimbolico(archivo *os.File, indiceCarpeta int32, nombre string, destino string, uid int32, gid int32) (int32, error) {
+	destino = strings.Trim(destino, "\x00")
+	if destino == "" {
+		return -1, fmt.Errorf("el destino del enlace no puede estar vacio")
+	}
+
+	indiceInodo, err := sb.BuscarInodoLibreCercaDe(archivo, indiceCarpeta)
+	if err != nil {
+		return -1, err
+	}
+	if err := sb.ActualizarBitmapInodo(archivo, indiceInodo, true); err != nil {
+		return -1, err
+	}
+
+	enlace := NuevoInodoVacio()
+	enlace.I_type[0] = TipoEnlaceSimbolico
+	enlace.I_perm = [3]byte{'7', '7', '7'}
+	enlace.I_uid, enlace.I_gid = uid, gid
+
+	carpeta := &INodo{}
+	if err := carpeta.Decodificar(archivo, sb.CalcularDesplazamientoInodo(indiceCarpeta)); err == nil {
+		sb.PreferirBloquesCercaDe(archivo, carpeta.UltimoBloqueReferenciado())
+	}
+	if err := enlace.EscribirDatos(archivo, sb, []byte(destino)); err != nil {
+		enlace.LiberarTodosLosBloques(archivo, sb)
+		sb.ActualizarBitmapInodo(archivo, indiceInodo, false)
+		return -1, err
+	}
+	enlace.I_size = int32(len(destino))
+
+	if err := enlace.Codificar(archivo, sb.CalcularDesplazamientoInodo(indiceInodo)); err != nil {
+		return -1, err
+	}
+	if err := sb.agregarEntradaEnCarpeta(archivo, indiceCarpeta, nombre, indiceInodo); err != nil {
+		enlace.LiberarTodosLosBloques(archivo, sb)
+		sb.ActualizarBitmapInodo(archivo, indiceInodo, false)
+		return -1, err
+	}
+	sb.ActualizarSuperblockDespuesAsignacionInodo()
+	return indiceInodo, nil
+}
+
+// CrearEnlaceDuro agrega a la carpeta otro nombre para un inodo existente e incrementa su
+// cantidad de enlaces. Las carpetas no admiten enlaces duros
+func (sb *SuperBlock) CrearEnlaceDuro(archivo *os.File, indiceCarpeta int32, nombre string, indiceInodo int32) error {
+	if !sb.AdmiteEnlacesDuros() {
+		return fmt.Errorf("los inodos de %d bytes no guardan el contador de enlaces; vuelva a formatear con mkfs para usar enlaces duros", sb.S_inode_size)
+	}
+	desplazamiento := sb.CalcularDesplazamientoInodo(indiceInodo)
+	inodo := &INodo{}
+	if err := inodo.Decodificar(archivo, desplazamiento); err != nil {
+		return fmt.Errorf("error al deserializar inodo %d: %w", indiceInodo, err)
+	}
+	if inodo.I_type[0] == TipoCarpeta {
+		return fmt.Errorf("no se permiten enlaces duros a carpetas")
+	}
+
+	if err := sb.agregarEntradaEnCarpeta(archivo, indiceCarpeta, nombre, indiceInodo); err != nil {
+		return err
+	}
+
+	inodo.I_links = max(inodo.I_links, 1) + 1
+	inodo.I_ctime = MarcaTiempoActual()
+	return inodo.Codificar(archivo, desplazamiento)
+}
+
+// DesvincularInodo descuenta una entrada del archivo o enlace. Mientras otros nombres lo
+// referencien solo se guarda el nuevo contador; al llegar a cero se liberan sus bloques,
+// sus atributos extendidos y el inodo. Retorna si el inodo fue liberado
+func (sb *SuperBlock) DesvincularInodo(archivo *os.File, indiceInodo int32, inodo *INodo) (bool, error) {
+	if inodo.I_links > 1 {
+		inodo.I_links--
+		inodo.I_ctime = MarcaTiempoActual()
+		if err := inodo.Codificar(archivo, sb.CalcularDesplazamientoInodo(indiceInodo)); err != nil {
+			return false, fmt.Errorf("error actualizando los enlaces del inodo %d: %w", indiceInodo, err)
+		}
+		fmt.Printf("Inodo %d conserva %d enlace(s)\n", indiceInodo, inodo.I_links)
+		return false, nil
+	}
+
+	if err := inodo.LiberarTodosLosBloques(archivo, sb); err != nil {
+		return false, fmt.Errorf("error liberando bloques del inodo %d: %w", indiceInodo, err)
+	}
+	if err := sb.LiberarAtributos(archivo, inodo); err != nil {
+		return false, fmt.Errorf("error liberando atributos del inodo %d: %w", indiceInodo, err)
+	}
+	if err := sb.ActualizarBitmapInodo(archivo, indiceInodo, false); err != nil {
+		return false, fmt.Errorf("error liberando inodo %d: %w", indiceInodo, err)
+	}
+	sb.ActualizarSuperblockDespuesDesasignacionInodo()
+	descontarCuota(archivo, inodo, 0, 1)
+	return true, nil
+}
@@ -0,0 +1,304 @@
+package Estructuras
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Nombres largos: un nombre que no cabe en los 12 bytes de B_name se reparte en
+// contenidos de continuacion (B_inodo = InodoContinuacion) seguidos del contenido
+// principal, que guarda el ultimo fragmento y el inodo real. Los nombres de hasta
+// 12 bytes ocupan un solo contenido, igual que en el formato original
+const (
+	LongitudMaximaNombre           = 255
+	DimensionNombreContenido       = 12
+	InodoContinuacion        int32 = -2
+)
+
+// Entrada logica de un directorio: el nombre completo y el inodo al que apunta
+type EntradaDirectorio struct {
+	Nombre   string
+	Inodo    int32
+	Bloque   int32 // Bloque donde esta el contenido principal
+	inicio   int   // Posicion del primer contenido dentro del directorio
+	cantidad int   // Contenidos que ocupa (continuaciones y principal)
+}
+
+// EsEspecial indica si la entrada es "." o ".."
+func (e EntradaDirectorio) EsEspecial() bool {
+	return e.Nombre == "." || e.Nombre == ".."
+}
+
+// Directorio mantiene en memoria los bloques de carpeta de un inodo para leer y
+// modificar sus entradas sin importar en que bloque quedo cada fragmento del nombre
+type Directorio struct {
+	IndiceInodo int32
+	Inodo       *INodo
+	indices     []int32
+	bloques     []*FolderBlock
+	modificados map[int]bool
+	inodoNuevo  bool              // Se agregaron bloques y el inodo debe reescribirse
+	indice      *IndiceDirectorio // Indice hash, nil si el directorio es lineal
+}
+
+// ValidarNombreEntrada verifica que el nombre pueda guardarse en un directorio
+func ValidarNombreEntrada(nombre string) error {
+	switch {
+	case nombre == "":
+		return errors.New("el nombre no puede estar vacio")
+	case nombre == "." || nombre == "..":
+		return fmt.Errorf("el nombre '%s' esta reservado", nombre)
+	case strings.Contains(nombre, "/"):
+		return fmt.Errorf("el nombre '%s' no puede contener '/'", nombre)
+	case len(nombre) > LongitudMaximaNombre:
+		return fmt.Errorf("el nombre '%s' ocupa %d bytes, el maximo es %d", nombre, len(nombre), LongitudMaximaNombre)
+	}
+	return nil
+}
+
+// FragmentarNombre reparte el nombre en los contenidos que lo representan, el ultimo
+// es el principal y apunta al inodo
+func FragmentarNombre(nombre string, inodo int32) []FolderContent {
+	cantidad := (len(nombre) + DimensionNombreContenido - 1) / DimensionNombreContenido
+	if cantidad == 0 {
+		cantidad = 1
+	}
+	contenidos := make([]FolderContent, cantidad)
+	for i := range contenidos {
+		inicio := i * DimensionNombreContenido
+		fin := inicio + DimensionNombreContenido
+		if fin > len(nombre) {
+			fin = len(nombre)
+		}
+		copy(contenidos[i].B_name[:], nombre[inicio:fin])
+		contenidos[i].B_inodo = InodoContinuacion
+	}
+	contenidos[cantidad-1].B_inodo = inodo
+	return contenidos
+}
+
+// NombreContenido retorna el texto guardado en un contenido sin relleno. En un
+// contenido de continuacion es solo un fragmento del nombre
+func NombreContenido(contenido FolderContent) string {
+	return strings.Trim(string(contenido.B_name[:]), "\x00 ")
+}
+
+// LeerDirectorio carga todos los bloques de carpeta del inodo indicado
+func (sb *SuperBlock) LeerDirectorio(archivo *os.File, indiceInodo int32) (*Directorio, error) {
+	inodo := &INodo{}
+	if err := inodo.Decodificar(archivo, sb.CalcularDesplazamientoInodo(indiceInodo)); err != nil {
+		return nil, fmt.Errorf("error al deserializar inodo %d: %w", indiceInodo, err)
+	}
+	if inodo.I_type[0] != '0' {
+		return nil, fmt.Errorf("el inodo %d no es una carpeta", indiceInodo)
+	}
+
+	indices, err := inodo.ObtenerIndicesBloquesDatos(archivo, sb)
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo bloques del directorio %d: %w", indiceInodo, err)
+	}
+
+	d := &Directorio{IndiceInodo: indiceInodo, Inodo: inodo, indices: indices, modificados: make(map[int]bool)}
+	for _, indice := range indices {
+		bloque := NuevoFolderBlock(sb.S_block_size)
+		if err := bloque.Decodificar(archivo, int64(sb.S_block_start+indice*sb.S_block_size)); err != nil {
+			return nil, fmt.Errorf("error deserializando bloque %d: %w", indice, err)
+		}
+		d.bloques = append(d.bloques, bloque)
+	}
+
+	if indiceInodoIndice, ok := d.marcaIndice(); ok {
+		if err := d.cargarIndice(archivo, sb, indiceInodoIndice); err != nil {
+			return nil, err
+		}
+	}
+	return d, nil
+}
+
+// Cantidad total de contenidos entre todos los bloques del directorio
+func (d *Directorio) totalContenidos() int {
+	if len(d.bloques) == 0 {
+		return 0
+	}
+	return len(d.bloques) * len(d.bloques[0].B_cont)
+}
+
+// Bloque e indice dentro del bloque de una posicion del directorio
+func (d *Directorio) ubicar(posicion int) (int, int) {
+	porBloque := len(d.bloques[0].B_cont)
+	return posicion / porBloque, posicion % porBloque
+}
+
+func (d *Directorio) contenido(posicion int) FolderContent {
+	bloque, indice := d.ubicar(posicion)
+	return d.bloques[bloque].B_cont[indice]
+}
+
+func (d *Directorio) escribirContenido(posicion int, contenido FolderContent) {
+	bloque, indice := d.ubicar(posicion)
+	d.bloques[bloque].B_cont[indice] = contenido
+	d.modificados[bloque] = true
+}
+
+// Entradas retorna las entradas del directorio en orden, incluidas "." y "..". Las
+// continuaciones sin contenido principal (restos de una escritura incompleta) se ignoran
+func (d *Directorio) Entradas() []EntradaDirectorio {
+	var entradas []EntradaDirectorio
+	var fragmentos []byte
+	inicio := -1
+
+	for posicion := 0; posicion < d.totalContenidos(); posicion++ {
+		contenido := d.contenido(posicion)
+		switch {
+		case contenido.B_inodo == InodoContinuacion:
+			if inicio == -1 {
+				inicio = posicion
+			}
+			fragmentos = append(fragmentos, contenido.B_name[:]...)
+		case contenido.B_inodo < 0:
+			fragmentos, inicio = nil, -1
+		default:
+			entrada := EntradaDirectorio{Nombre: nombreEntrada(fragmentos, contenido), Inodo: contenido.B_inodo, inicio: inicio}
+			if inicio == -1 {
+				entrada.inicio = posicion
+			}
+			entrada.cantidad = posicion - entrada.inicio + 1
+			bloque, _ := d.ubicar(posicion)
+			entrada.Bloque = d.indices[bloque]
+			entradas = append(entradas, entrada)
+			fragmentos, inicio = nil, -1
+		}
+	}
+	return entradas
+}
+
+// Buscar localiza una entrada por nombre sin distinguir mayusculas
+func (d *Directorio) Buscar(nombre string) (EntradaDirectorio, bool) {
+	nombre = strings.Trim(nombre, "\x00 ")
+	for _, entrada := range d.Entradas() {
+		if strings.EqualFold(entrada.Nombre, nombre) {
+			return entrada, true
+		}
+	}
+	return EntradaDirectorio{}, false
+}
+
+// Agregar guarda una nueva entrada en el primer hueco con espacio para todos sus
+// contenidos. Si no hay hueco se agregan bloques al directorio
+func (d *Directorio) Agregar(archivo *os.File, sb *SuperBlock, nombre string, inodo int32) error {
+	if err := ValidarNombreEntrada(nombre); err != nil {
+		return err
+	}
+	if _, existe := d.Buscar(nombre); existe {
+		return fmt.Errorf("ya existe una entrada llamada '%s'", nombre)
+	}
+
+	contenidos := FragmentarNombre(nombre, inodo)
+	posicion, libres := -1, 0
+	for p := 0; p < d.totalContenidos() && posicion == -1; p++ {
+		if d.contenido(p).B_inodo != -1 {
+			libres = 0
+			continue
+		}
+		libres++
+		if libres == len(contenidos) {
+			posicion = p - libres + 1
+		}
+	}
+
+	// Los huecos al final del ultimo bloque se aprovechan y se completan con bloques nuevos
+	if posicion == -1 {
+		posicion = d.totalContenidos() - libres
+		for d.totalContenidos()-posicion < len(contenidos) {
+			indice, err := d.Inodo.AgregarBloque(archivo, sb)
+			if err != nil {
+				return fmt.Errorf("error añadiendo bloque al directorio %d: %w", d.IndiceInodo, err)
+			}
+			d.indices = append(d.indices, indice)
+			d.bloques = append(d.bloques, NuevoFolderBlock(sb.S_block_size))
+			d.modificados[len(d.bloques)-1] = true
+			d.inodoNuevo = true
+		}
+	}
+
+	for i, contenido := range contenidos {
+		d.escribirContenido(posicion+i, contenido)
+	}
+	return d.indexar(archivo, sb, nombre, posicion)
+}
+
+// Eliminar libera todos los contenidos de la entrada
+func (d *Directorio) Eliminar(entrada EntradaDirectorio) {
+	for i := 0; i < entrada.cantidad; i++ {
+		d.escribirContenido(entrada.inicio+i, FolderContent{B_name: [12]byte{'-'}, B_inodo: -1})
+	}
+	if d.indice != nil {
+		d.indice.quitar(HashNombre(entrada.Nombre), int32(entrada.inicio))
+	}
+}
+
+// Renombrar cambia el nombre de una entrada conservando su inodo. El nuevo nombre
+// puede necesitar otra cantidad de contenidos, por eso se vuelve a ubicar
+func (d *Directorio) Renombrar(archivo *os.File, sb *SuperBlock, entrada EntradaDirectorio, nuevoNombre string) error {
+	if err := ValidarNombreEntrada(nuevoNombre); err != nil {
+		return err
+	}
+	if existente, existe := d.Buscar(nuevoNombre); existe && existente.inicio != entrada.inicio {
+		return fmt.Errorf("ya existe una entrada llamada '%s'", nuevoNombre)
+	}
+
+	d.Eliminar(entrada)
+	if err := d.Agregar(archivo, sb, nuevoNombre, entrada.Inodo); err != nil {
+		// Restaurar el nombre original en su posicion
+		for i, contenido := range FragmentarNombre(entrada.Nombre, entrada.Inodo) {
+			d.escribirContenido(entrada.inicio+i, contenido)
+		}
+		if errIndice := d.indexar(archivo, sb, entrada.Nombre, entrada.inicio); errIndice != nil {
+			return errIndice
+		}
+		return err
+	}
+	return nil
+}
+
+// ActualizarInodo cambia el inodo al que apunta una entrada (por ejemplo ".." al mover)
+func (d *Directorio) ActualizarInodo(entrada EntradaDirectorio, inodo int32) {
+	posicion := entrada.inicio + entrada.cantidad - 1
+	contenido := d.contenido(posicion)
+	contenido.B_inodo = inodo
+	d.escribirContenido(posicion, contenido)
+}
+
+// Guardar escribe los bloques modificados y, si crecio o cambio su contenido, el inodo
+// del directorio con sus fechas de modificacion actualizadas
+func (d *Directorio) Guardar(archivo *os.File, sb *SuperBlock) error {
+	// Agregar o quitar entradas cambia el contenido de la carpeta
+	contenidoModificado := len(d.modificados) > 0
+	for bloque := range d.modificados {
+		desplazamiento := int64(sb.S_block_start + d.indices[bloque]*sb.S_block_size)
+		if err := d.bloques[bloque].Codificar(archivo, desplazamiento); err != nil {
+			return fmt.Errorf("error escribiendo bloque %d del directorio: %w", d.indices[bloque], err)
+		}
+	}
+	d.modificados = make(map[int]bool)
+
+	if d.indice != nil {
+		if err := d.indice.guardar(archivo, sb); err != nil {
+			return err
+		}
+	}
+
+	if contenidoModificado {
+		d.Inodo.ActualizarTiempoModificacion()
+		d.Inodo.ActualizarTiempoPermisos()
+	}
+	if d.inodoNuevo || contenidoModificado {
+		if err := d.Inodo.Codificar(archivo, sb.CalcularDesplazamientoInodo(d.IndiceInodo)); err != nil {
+			return fmt.Errorf("error actualizando inodo %d: %w", d.IndiceInodo, err)
+		}
+		d.inodoNuevo = false
+	}
+	return nil
+}
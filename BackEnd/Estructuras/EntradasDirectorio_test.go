@@ -0,0 +1,118 @@
+package Estructuras
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFragmentarNombre(t *testing.T) {
+	casos := []struct {
+		nombre     string
+		contenidos int
+	}{
+		{"a", 1},
+		{"doce_bytes12", 1},
+		{"trece_bytes13", 2},
+		{strings.Repeat("x", 24), 2},
+		{strings.Repeat("x", 25), 3},
+		{strings.Repeat("n", LongitudMaximaNombre), 22},
+	}
+	for _, caso := range casos {
+		contenidos := FragmentarNombre(caso.nombre, 7)
+		if len(contenidos) != caso.contenidos {
+			t.Errorf("%q: %d contenidos, se esperaban %d", caso.nombre, len(contenidos), caso.contenidos)
+			continue
+		}
+		for i, contenido := range contenidos[:len(contenidos)-1] {
+			if contenido.B_inodo != InodoContinuacion {
+				t.Errorf("%q: el contenido %d apunta a %d, se esperaba una continuacion", caso.nombre, i, contenido.B_inodo)
+			}
+		}
+		principal := contenidos[len(contenidos)-1]
+		if principal.B_inodo != 7 {
+			t.Errorf("%q: el contenido principal apunta a %d", caso.nombre, principal.B_inodo)
+		}
+		var fragmentos []byte
+		for _, contenido := range contenidos[:len(contenidos)-1] {
+			fragmentos = append(fragmentos, contenido.B_name[:]...)
+		}
+		if nombre := nombreEntrada(fragmentos, principal); nombre != caso.nombre {
+			t.Errorf("%q: se reconstruyo %q", caso.nombre, nombre)
+		}
+	}
+}
+
+func TestValidarNombreEntrada(t *testing.T) {
+	casos := []struct {
+		nombre string
+		valido bool
+	}{
+		{"archivo.txt", true},
+		{strings.Repeat("n", LongitudMaximaNombre), true},
+		{strings.Repeat("n", LongitudMaximaNombre+1), false},
+		{"", false},
+		{".", false},
+		{"..", false},
+		{"a/b", false},
+	}
+	for _, caso := range casos {
+		if err := ValidarNombreEntrada(caso.nombre); (err == nil) != caso.valido {
+			t.Errorf("ValidarNombreEntrada(%.20q) = %v", caso.nombre, err)
+		}
+	}
+}
+
+func TestEntradasConNombresLargos(t *testing.T) {
+	// Dos bloques de 4 contenidos para que un nombre largo cruce de uno al otro
+	d := &Directorio{
+		indices:     []int32{10, 11},
+		bloques:     []*FolderBlock{NuevoFolderBlock(64), NuevoFolderBlock(64)},
+		modificados: make(map[int]bool),
+	}
+	largo := "un_nombre_que_ocupa_tres_contenidos"
+	contenidos := append([]FolderContent{{B_name: [12]byte{'.'}, B_inodo: 0}, {B_name: [12]byte{'.', '.'}, B_inodo: 0}},
+		FragmentarNombre(largo, 5)...)
+	// Una continuacion huerfana seguida de un contenido libre no es una entrada
+	contenidos = append(contenidos, FolderContent{B_name: [12]byte{'r', 'e', 's', 't', 'o'}, B_inodo: InodoContinuacion},
+		FolderContent{B_name: [12]byte{'-'}, B_inodo: -1}, FragmentarNombre("corto", 6)[0])
+	for posicion, contenido := range contenidos {
+		d.escribirContenido(posicion, contenido)
+	}
+
+	esperadas := []struct {
+		nombre string
+		inodo  int32
+		bloque int32
+	}{
+		{".", 0, 10},
+		{"..", 0, 10},
+		{largo, 5, 11},
+		{"corto", 6, 11},
+	}
+	entradas := d.Entradas()
+	if len(entradas) != len(esperadas) {
+		t.Fatalf("Entradas() = %+v", entradas)
+	}
+	for i, esperada := range esperadas {
+		if entradas[i].Nombre != esperada.nombre || entradas[i].Inodo != esperada.inodo || entradas[i].Bloque != esperada.bloque {
+			t.Errorf("entrada %d = %+v, se esperaba %+v", i, entradas[i], esperada)
+		}
+	}
+
+	entrada, existe := d.Buscar(strings.ToUpper(largo))
+	if !existe || entrada.cantidad != 3 {
+		t.Fatalf("Buscar(%q) = %+v, %v", largo, entrada, existe)
+	}
+	d.Eliminar(entrada)
+	if _, existe := d.Buscar(largo); existe {
+		t.Errorf("%q sigue en el directorio despues de eliminarla", largo)
+	}
+	for posicion := 2; posicion < 5; posicion++ {
+		if contenido := d.contenido(posicion); contenido.B_inodo != -1 {
+			t.Errorf("el contenido %d quedo apuntando a %d", posicion, contenido.B_inodo)
+		}
+	}
+	if !d.modificados[0] || !d.modificados[1] {
+		t.Errorf("bloques modificados: %v", d.modificados)
+	}
+}
@@ -0,0 +1,135 @@
+package Estructuras
+
+import (
+	"fmt"
+	"math/bits"
+	"os"
+)
+
+// UsoEspacio es el total, lo ocupado y lo libre de inodos o de bloques
+type UsoEspacio struct {
+	Total  int32
+	Usados int32
+	Libres int32
+}
+
+// Porcentaje retorna el porcentaje ocupado
+func (u UsoEspacio) Porcentaje() float64 {
+	if u.Total == 0 {
+		return 0
+	}
+	return float64(u.Usados) * 100 / float64(u.Total)
+}
+
+// RevisionEspacio compara lo que dicen los contadores del superbloque con lo que marcan
+// los bitmaps, que son los que usan las asignaciones
+type RevisionEspacio struct {
+	Inodos          UsoEspacio // segun el bitmap de inodos
+	Bloques         UsoEspacio // segun el bitmap de bloques
+	InodosSuper     UsoEspacio // segun S_inodes_count y S_free_inodes_count
+	BloquesSuper    UsoEspacio // segun S_blocks_count y S_free_blocks_count
+	GruposDistintos []int32    // grupos cuyo descriptor no coincide con los bitmaps
+}
+
+// Consistente indica que el superbloque y los descriptores coinciden con los bitmaps
+func (r *RevisionEspacio) Consistente() bool {
+	return r.Inodos == r.InodosSuper && r.Bloques == r.BloquesSuper && len(r.GruposDistintos) == 0
+}
+
+// Capacidad calcula cuantos inodos y bloques tiene el sistema de archivos sin confiar en
+// los contadores. Sin grupos los bitmaps usan un bit por posicion, pero mkfs reserva para
+// cada uno un byte por inodo o por bloque, asi que la distancia entre el inicio de los
+// bitmaps y la tabla de inodos es la cantidad de inodos y de bloques. Con grupos los inodos son
+// los de cada grupo; los bloques se toman del primer superbloque de respaldo, que guarda
+// los contadores de cuando se formateo, o de los contadores si no hay respaldo valido
+func (sb *SuperBlock) Capacidad(archivo *os.File) (int32, int32, error) {
+	if sb.S_groups_count == 0 {
+		return sb.S_bm_block_start - sb.S_bm_inode_start, sb.S_inode_start - sb.S_bm_block_start, nil
+	}
+
+	bloques := sb.S_blocks_count + sb.S_free_blocks_count
+	for _, grupo := range sb.GruposConRespaldo() {
+		if respaldo, err := sb.LeerRespaldoGrupo(archivo, grupo); err == nil && respaldo.S_magic == 0xEF53 {
+			bloques = respaldo.S_blocks_count + respaldo.S_free_blocks_count
+			break
+		}
+	}
+	// El ultimo grupo puede ser mas corto, pero no vacio
+	if bloques <= (sb.S_groups_count-1)*sb.S_blocks_per_group || bloques > sb.S_groups_count*sb.S_blocks_per_group {
+		return 0, 0, fmt.Errorf("los contadores suman %d bloques y no caben en %d grupos de %d bloques",
+			bloques, sb.S_groups_count, sb.S_blocks_per_group)
+	}
+	return sb.S_groups_count * sb.S_inodes_per_group, bloques, nil
+}
+
+// RevisarEspacio cuenta los bits ocupados de cada bitmap y los compara con el superbloque
+// y, con grupos, con la tabla de descriptores
+func (sb *SuperBlock) RevisarEspacio(archivo *os.File) (*RevisionEspacio, error) {
+	totalInodos, totalBloques, err := sb.Capacidad(archivo)
+	if err != nil {
+		return nil, err
+	}
+
+	// Los bitmaps se leen con la capacidad real aunque los contadores se hayan desviado
+	geometria := *sb
+	geometria.S_free_inodes_count = totalInodos - geometria.S_inodes_count
+	geometria.S_free_blocks_count = totalBloques - geometria.S_blocks_count
+	bitmapInodos, err := geometria.LeerBitmapInodos(archivo)
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo el bitmap de inodos: %w", err)
+	}
+	bitmapBloques, err := geometria.LeerBitmapBloques(archivo)
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo el bitmap de bloques: %w", err)
+	}
+
+	revision := &RevisionEspacio{
+		Inodos:       usoDesdeBitmap(bitmapInodos, totalInodos),
+		Bloques:      usoDesdeBitmap(bitmapBloques, totalBloques),
+		InodosSuper:  UsoEspacio{Total: sb.S_inodes_count + sb.S_free_inodes_count, Usados: sb.S_inodes_count, Libres: sb.S_free_inodes_count},
+		BloquesSuper: UsoEspacio{Total: sb.S_blocks_count + sb.S_free_blocks_count, Usados: sb.S_blocks_count, Libres: sb.S_free_blocks_count},
+	}
+
+	if sb.S_groups_count > 0 {
+		guardados, err := sb.LeerDescriptoresGrupo(archivo)
+		if err != nil {
+			return nil, err
+		}
+		for grupo, esperado := range geometria.descriptoresDesdeBitmaps(bitmapInodos, bitmapBloques) {
+			if guardados[grupo].G_free_inodes_count != esperado.G_free_inodes_count ||
+				guardados[grupo].G_free_blocks_count != esperado.G_free_blocks_count {
+				revision.GruposDistintos = append(revision.GruposDistintos, int32(grupo))
+			}
+		}
+	}
+	return revision, nil
+}
+
+// usoDesdeBitmap cuenta las posiciones ocupadas entre las primeras 'total' del bitmap
+func usoDesdeBitmap(bitmap []byte, total int32) UsoEspacio {
+	var usados int32
+	for posicion, valor := range bitmap {
+		restantes := total - int32(posicion)*8
+		if restantes <= 0 {
+			break
+		}
+		if restantes < 8 {
+			valor &= byte(1<<restantes - 1)
+		}
+		usados += int32(bits.OnesCount8(valor))
+	}
+	return UsoEspacio{Total: total, Usados: usados, Libres: total - usados}
+}
+
+// CorregirEspacio deja los contadores del superbloque y los descriptores de grupo como
+// indican los bitmaps. Quien llama guarda el superbloque
+func (sb *SuperBlock) CorregirEspacio(archivo *os.File, revision *RevisionEspacio) error {
+	sb.S_inodes_count, sb.S_free_inodes_count = revision.Inodos.Usados, revision.Inodos.Libres
+	sb.S_blocks_count, sb.S_free_blocks_count = revision.Bloques.Usados, revision.Bloques.Libres
+	if len(revision.GruposDistintos) > 0 {
+		if err := sb.EscribirDescriptoresGrupo(archivo); err != nil {
+			return err
+		}
+	}
+	return nil
+}
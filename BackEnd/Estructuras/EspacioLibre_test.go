@@ -0,0 +1,26 @@
+package Estructuras
+
+import "testing"
+
+func TestUsoDesdeBitmap(t *testing.T) {
+	casos := []struct {
+		nombre string
+		bitmap []byte
+		total  int32
+		usados int32
+	}{
+		{"vacio", []byte{0x00, 0x00}, 16, 0},
+		{"lleno", []byte{0xFF, 0xFF}, 16, 16},
+		{"un bit por posicion", []byte{0x05, 0x80}, 16, 3},
+		{"ignora el relleno del ultimo byte", []byte{0xFF, 0xFF}, 11, 11},
+		{"ignora los bytes despues del total", []byte{0x01, 0xFF, 0xFF}, 8, 1},
+		{"total que no llena el primer byte", []byte{0xF0}, 4, 0},
+	}
+	for _, caso := range casos {
+		uso := usoDesdeBitmap(caso.bitmap, caso.total)
+		esperado := UsoEspacio{Total: caso.total, Usados: caso.usados, Libres: caso.total - caso.usados}
+		if uso != esperado {
+			t.Errorf("%s: %+v, se esperaba %+v", caso.nombre, uso, esperado)
+		}
+	}
+}
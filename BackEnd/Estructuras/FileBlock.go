@@ -8,11 +8,17 @@ import (
 	Utils "backend/Utils"
 )
 
+// Dimension de bloque por defecto; mkfs -bs permite 64, 128, 256 o 512 bytes
 const DimensionBloque = 64
 
 type FileBlock struct {
-	B_cont [DimensionBloque]byte
-	// Total: 64 bytes
+	B_cont []byte
+	// Total: S_block_size bytes
+}
+
+// NuevoFileBlockVacio crea un bloque de archivo lleno de ceros
+func NuevoFileBlockVacio(tamanoBloque int32) *FileBlock {
+	return &FileBlock{B_cont: make([]byte, tamanoBloque)}
 }
 
 // Serializa la estructura FileBlock en un archivo binario en la posicion especificada
@@ -21,12 +27,22 @@ func (fb *FileBlock) Codificar(archivo *os.File, desplazamiento int64) error {
 	if err != nil {
 		return fmt.Errorf("error escribiendo FileBlock al archivo: %w", err)
 	}
+	// El contenido de los archivos no se guarda en la cache; se olvida lo que hubiera en
+	// ese bloque cuando pertenecia a una carpeta o a un bloque de apuntadores
+	descartarEnCache(archivo, desplazamiento)
 	return nil
 }
 
-// Deserializa la estructura FileBlock desde un archivo binario en posicion especificada
+// Deserializa la estructura FileBlock desde un archivo binario en posicion especificada.
+// El bloque debe crearse con NuevoFileBlockVacio para saber cuantos bytes leer
 func (fb *FileBlock) Decodificar(archivo *os.File, desplazamiento int64) error {
-	err := Utils.LeerDeArchivo(archivo, desplazamiento, &fb.B_cont)
+	if len(fb.B_cont) == 0 {
+		return fmt.Errorf("el FileBlock no tiene dimension, use NuevoFileBlockVacio")
+	}
+	if err := escribirPendienteEnCache(archivo, desplazamiento); err != nil {
+		return err
+	}
+	err := Utils.LeerDeArchivo(archivo, desplazamiento, fb.B_cont)
 	if err != nil {
 		return fmt.Errorf("error leyendo FileBlock desde archivo: %w", err)
 	}
@@ -49,8 +65,8 @@ func (fb *FileBlock) ObtenerContenido() string {
 
 // Copia una cadena en B_cont, asegurando que no exceda la dimension maxima
 func (fb *FileBlock) EstablecerContenido(contenido string) error {
-	if len(contenido) > DimensionBloque {
-		return fmt.Errorf("la dimension del contenido excede la dimension del bloque de %d bytes", DimensionBloque)
+	if len(contenido) > len(fb.B_cont) {
+		return fmt.Errorf("la dimension del contenido excede la dimension del bloque de %d bytes", len(fb.B_cont))
 	}
 
 	fb.LimpiarContenido()
@@ -61,7 +77,7 @@ func (fb *FileBlock) EstablecerContenido(contenido string) error {
 
 // Retorna la cantidad de bytes disponibles en el bloque
 func (fb *FileBlock) EspacioDisponible() int {
-	return DimensionBloque - fb.EspacioUsado()
+	return len(fb.B_cont) - fb.EspacioUsado()
 }
 
 // Verifica si aun queda espacio en el bloque
@@ -88,7 +104,7 @@ func (fb *FileBlock) AgregarContenido(contenido string) error {
     copy(fb.B_cont[espacioUsado:], contenido)
 
     // IMPORTANTE: Limpiar el resto del bloque con bytes nulos
-    for i := espacioUsado + len(contenido); i < DimensionBloque; i++ {
+    for i := espacioUsado + len(contenido); i < len(fb.B_cont); i++ {
         fb.B_cont[i] = 0
     }
 
@@ -103,8 +119,8 @@ func (fb *FileBlock) LimpiarContenido() {
 }
 
 // Crea un nuevo FileBlock con contenido opcional
-func NuevoFileBlock(contenido string) (*FileBlock, error) {
-	fb := &FileBlock{}
+func NuevoFileBlock(contenido string, tamanoBloque int32) (*FileBlock, error) {
+	fb := NuevoFileBlockVacio(tamanoBloque)
 	err := fb.EstablecerContenido(contenido)
 	if err != nil {
 		return nil, err
@@ -113,14 +129,14 @@ func NuevoFileBlock(contenido string) (*FileBlock, error) {
 }
 
 // Divide una cadena en bloques de dimension y retorna un slice de FileBlocks
-func DividirContenido(contenido string) ([]*FileBlock, error) {
+func DividirContenido(contenido string, tamanoBloque int32) ([]*FileBlock, error) {
 	var bloques []*FileBlock
 	for len(contenido) > 0 {
-		final := DimensionBloque
-		if len(contenido) < DimensionBloque {
+		final := int(tamanoBloque)
+		if len(contenido) < final {
 			final = len(contenido)
 		}
-		ba, err := NuevoFileBlock(contenido[:final])
+		ba, err := NuevoFileBlock(contenido[:final], tamanoBloque)
 		if err != nil {
 			return nil, err
 		}
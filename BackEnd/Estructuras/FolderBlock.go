@@ -7,10 +7,13 @@ import (
 	Utils "backend/Utils"
 )
 
-// Representa un bloque de carpeta de 4 contenidos
+// Dimension de cada contenido de carpeta: nombre de 12 bytes e inodo
+const DimensionContenidoCarpeta = 16
+
+// Representa un bloque de carpeta, la cantidad de contenidos depende de S_block_size
 type FolderBlock struct {
-	/*  Total: 64 bytes  */
-	B_cont [4]FolderContent
+	/*  Total: S_block_size bytes (4 contenidos con bloques de 64 bytes)  */
+	B_cont []FolderContent
 }
 
 // Representa el contenido dentro de un bloque de carpeta 
@@ -20,18 +23,37 @@ type FolderContent struct {
 	B_inodo int32
 }
 
+// NuevoFolderBlock crea un bloque de carpeta con todos sus contenidos vacios
+func NuevoFolderBlock(tamanoBloque int32) *FolderBlock {
+	bc := &FolderBlock{B_cont: make([]FolderContent, tamanoBloque/DimensionContenidoCarpeta)}
+	for i := range bc.B_cont {
+		bc.B_cont[i] = FolderContent{B_name: [12]byte{'-'}, B_inodo: -1}
+	}
+	return bc
+}
+
 // Serializa la estructura FolderBlock en un archivo binario en la posicion especificada
 func (bc *FolderBlock) Codificar(archivo *os.File, desplazamiento int64) error {
-	err := Utils.EscribirAArchivo(archivo, desplazamiento, bc)
+	if cache := cacheDe(archivo, desplazamiento); cache != nil {
+		return cache.escribirCarpeta(desplazamiento, bc.B_cont)
+	}
+	err := Utils.EscribirAArchivo(archivo, desplazamiento, bc.B_cont)
 	if err != nil {
 		return fmt.Errorf("error escribiendo FolderBlock al archivo: %w", err)
 	}
 	return nil
 }
 
-// Deserializa la estructura FolderBlock desde un archivo binario en la posicion especificada
+// Deserializa la estructura FolderBlock desde un archivo binario en la posicion especificada.
+// El bloque debe crearse con NuevoFolderBlock para saber cuantos contenidos leer
 func (bc *FolderBlock) Decodificar(archivo *os.File, desplazamiento int64) error {
-	err := Utils.LeerDeArchivo(archivo, desplazamiento, bc)
+	if len(bc.B_cont) == 0 {
+		return fmt.Errorf("el FolderBlock no tiene dimension, use NuevoFolderBlock")
+	}
+	if cache := cacheDe(archivo, desplazamiento); cache != nil {
+		return cache.leerCarpeta(desplazamiento, bc.B_cont)
+	}
+	err := Utils.LeerDeArchivo(archivo, desplazamiento, bc.B_cont)
 	if err != nil {
 		return fmt.Errorf("error leyendo FolderBlock desde archivo: %w", err)
 	}
@@ -48,12 +70,11 @@ func (bc *FolderBlock) Imprimir() {
 }
 
 // NuevoBloqueDirectorio crea un bloque de carpeta inicial con entradas dadas
-func NuevoBloqueDirectorio(selfInodo int32, parentInodo int32, entradas map[string]int32) *FolderBlock {
-	fb := &FolderBlock{}
+func NuevoBloqueDirectorio(tamanoBloque int32, selfInodo int32, parentInodo int32, entradas map[string]int32) *FolderBlock {
+	fb := NuevoFolderBlock(tamanoBloque)
 	// Inicializar con entradas vacías
 	for i := range fb.B_cont {
 		fb.B_cont[i].B_name = [12]byte{}
-		fb.B_cont[i].B_inodo = -1
 	}
 
 	// '.'
@@ -64,15 +85,14 @@ func NuevoBloqueDirectorio(selfInodo int32, parentInodo int32, entradas map[stri
 	copy(fb.B_cont[1].B_name[:], "..")
 	fb.B_cont[1].B_inodo = parentInodo
 
-	// Rellenar con entradas adicionales hasta 2 más
+	// Rellenar con entradas adicionales mientras quepan (con sus continuaciones)
 	idx := 2
 	for nombre, inodo := range entradas {
-		if idx >= len(fb.B_cont) {
+		contenidos := FragmentarNombre(nombre, inodo)
+		if idx+len(contenidos) > len(fb.B_cont) {
 			break
 		}
-		copy(fb.B_cont[idx].B_name[:], nombre)
-		fb.B_cont[idx].B_inodo = inodo
-		idx++
+		idx += copy(fb.B_cont[idx:], contenidos)
 	}
 
 	return fb
@@ -4,301 +4,166 @@ import (
 	"fmt"
 	"os"
 	"strings"
-	"time"
-
-	Utils "backend/Utils"
 )
 
 // Acá | diferente a createFolderInInode pero se suponen hacen lo mismo
-// crearCarpetaEnInodo crea una carpeta en un inodo específico utilizando las funciones avanzadas
+// crearCarpetaEnInodo crea una carpeta dentro del directorio del inodo indicado; directoriosPadre
+// es la ruta de ese directorio y solo se usa para el journal
 func (sb *SuperBlock) crearCarpetaEnInodo(archivo *os.File, indiceInodo int32, directoriosPadre []string, directorioDestino string, registrarJournal bool) error {
-    // Crear un nuevo inodo
-    inodo := &INodo{}
     fmt.Printf("Deserializando inodo %d\n", indiceInodo) // Depuración
 
-    // Deserializar el inodo
-    err := inodo.Decodificar(archivo, int64(sb.S_inode_start+(indiceInodo*sb.S_inode_size)))
+    // Estamos en el directorio destino, cargar sus entradas (el inodo debe ser de tipo carpeta)
+    directorio, err := sb.LeerDirectorio(archivo, indiceInodo)
     if err != nil {
-        return fmt.Errorf("error al deserializar inodo %d: %v", indiceInodo, err)
+        return err
     }
-    fmt.Printf("Inodo %d deserializado. Tipo: %c\n", indiceInodo, inodo.I_type[0]) // Depuración
 
-    // Verificar si el inodo es de tipo carpeta
-    if inodo.I_type[0] != '0' {
-        fmt.Printf("Inodo %d no es una carpeta, es de tipo: %c\n", indiceInodo, inodo.I_type[0]) // Depuración
-        return nil
+    // Validar el nombre antes de reservar recursos
+    if err := ValidarNombreEntrada(directorioDestino); err != nil {
+        return err
     }
-
-    // Iterar sobre cada bloque del inodo (apuntadores)
-    indicesBloques, err := inodo.ObtenerIndicesBloquesDatos(archivo, sb)
-    if err != nil {
-        return fmt.Errorf("error obteniendo bloques de datos: %v", err)
+    if _, existe := directorio.Buscar(directorioDestino); existe {
+        return fmt.Errorf("ya existe una entrada llamada '%s'", directorioDestino)
     }
 
-    // Si no hay bloques, verificar si podemos agregar uno
-    if len(indicesBloques) == 0 {
-        fmt.Printf("El inodo %d no tiene bloques asignados, añadiendo uno nuevo\n", indiceInodo)
-        nuevoIndiceBloque, err := inodo.AgregarBloque(archivo, sb)
-        if err != nil {
-            return fmt.Errorf("error añadiendo nuevo bloque al inodo %d: %v", indiceInodo, err)
-        }
-        indicesBloques = []int32{nuevoIndiceBloque}
-
-        // Inicializar el nuevo bloque de carpeta con entradas . y ..
-        nuevoBloque := &FolderBlock{
-            B_cont: [4]FolderContent{
-                {B_name: [12]byte{'.'}, B_inodo: indiceInodo},
-                {B_name: [12]byte{'.', '.'}, B_inodo: indiceInodo}, // Por defecto apunta a sí mismo, se actualizará si es necesario
-                {B_name: [12]byte{'-'}, B_inodo: -1},
-                {B_name: [12]byte{'-'}, B_inodo: -1},
-            },
-        }
-        offsetBloque := int64(sb.S_block_start + nuevoIndiceBloque*sb.S_block_size)
-        if err := nuevoBloque.Codificar(archivo, offsetBloque); err != nil {
-            return fmt.Errorf("error inicializando nuevo bloque de carpeta: %v", err)
-        }
+    // 1. Crear un nuevo inodo para la carpeta
+    inodoCarpeta := &INodo{}
+
+    // Inicializar el inodo con valores predeterminados
+    inodoCarpeta.I_uid, inodoCarpeta.I_gid = sb.propietarioNuevoInodo(archivo)
+    inodoCarpeta.I_size = 0
+    inodoCarpeta.I_links = 1
+    ahora := MarcaTiempoActual()
+    inodoCarpeta.I_atime = ahora
+    inodoCarpeta.I_ctime = ahora
+    inodoCarpeta.I_mtime = ahora
+    inodoCarpeta.I_type = [1]byte{'0'} // Tipo carpeta
+    inodoCarpeta.I_perm = [3]byte{'7', '7', '5'} // ejecucion para poder recorrerla
+
+    // Inicializar todos los bloques a -1
+    for i := range inodoCarpeta.I_block {
+        inodoCarpeta.I_block[i] = -1
     }
+    inodoCarpeta.I_xattr = -1
 
-    // Iterar sobre los bloques existentes
-    for _, indiceBloque := range indicesBloques {
-        fmt.Printf("Procesando bloque %d del inodo %d\n", indiceBloque, indiceInodo) // Depuración
-        bloque := &FolderBlock{}
-
-        // Deserializar el bloque
-        offsetBloque := int64(sb.S_block_start + (indiceBloque * sb.S_block_size))
-        err := bloque.Decodificar(archivo, offsetBloque)
-        if err != nil {
-            return fmt.Errorf("error al deserializar bloque %d: %v", indiceBloque, err)
-        }
-
-        // Iterar sobre cada contenido del bloque, desde el índice 2 (evitamos . y ..)
-        for indiceContenido := 2; indiceContenido < len(bloque.B_cont); indiceContenido++ {
-            contenido := bloque.B_cont[indiceContenido]
-            fmt.Printf("Verificando contenido en índice %d del bloque %d\n", indiceContenido, indiceBloque)
-
-            // Si hay más carpetas padres en la ruta, buscar la siguiente carpeta
-            if len(directoriosPadre) != 0 {
-                // Si el contenido está vacío o no hay más entradas, salir
-                if contenido.B_inodo == -1 {
-                    fmt.Printf("No se encontró carpeta padre en inodo %d en la posición %d\n", indiceInodo, indiceContenido)
-                    break
-                }
-
-                directorioPadre, err := Utils.Primero(directoriosPadre)
-                if err != nil {
-                    return err
-                }
-
-                nombreContenido := strings.Trim(string(contenido.B_name[:]), "\x00 ")
-                nombreDirectorioPadre := strings.Trim(directorioPadre, "\x00 ")
-                fmt.Printf("Comparando '%s' con el nombre de la carpeta padre '%s'\n", nombreContenido, nombreDirectorioPadre)
-
-                // Si encontramos la carpeta padre, recursión
-                if strings.EqualFold(nombreContenido, nombreDirectorioPadre) {
-                    fmt.Printf("Carpeta padre '%s' encontrada en inodo %d\n", nombreDirectorioPadre, contenido.B_inodo)
-                    return sb.crearCarpetaEnInodo(archivo, contenido.B_inodo, Utils.EliminarElemento(directoriosPadre, 0), directorioDestino, registrarJournal)
-                }
-            } else {
-                // Estamos en el directorio destino, crear la carpeta
-
-                // Si esta posición ya está ocupada, probar la siguiente
-                if contenido.B_inodo != -1 {
-                    fmt.Printf("Posición %d ya ocupada, probando siguiente\n", indiceContenido)
-                    continue
-                }
-
-                fmt.Printf("Creando directorio '%s' en bloque %d posición %d\n", directorioDestino, indiceBloque, indiceContenido)
-
-                // 1. Crear un nuevo inodo para la carpeta
-                inodoCarpeta := &INodo{}
-
-                // Inicializar el inodo con valores predeterminados
-                inodoCarpeta.I_uid = 1
-                inodoCarpeta.I_gid = 1
-                inodoCarpeta.I_size = 0
-                inodoCarpeta.I_atime = float32(time.Now().Unix())
-                inodoCarpeta.I_ctime = float32(time.Now().Unix())
-                inodoCarpeta.I_mtime = float32(time.Now().Unix())
-                inodoCarpeta.I_type = [1]byte{'0'} // Tipo carpeta
-                inodoCarpeta.I_perm = [3]byte{'6', '6', '4'}
-
-                // Inicializar todos los bloques a -1
-                for i := range inodoCarpeta.I_block {
-                    inodoCarpeta.I_block[i] = -1
-                }
-
-                // 2. Asignar y marcar un nuevo inodo en el bitmap
-                nuevoIndiceInodo, err := sb.BuscarSiguienteInodoLibre(archivo)
-                if err != nil {
-                    return fmt.Errorf("error encontrando inodo libre: %v", err)
-                }
-
-                if err := sb.ActualizarBitmapInodo(archivo, nuevoIndiceInodo, true); err != nil {
-                    return fmt.Errorf("error marcando inodo como usado: %v", err)
-                }
-
-                // 3. Asignar un bloque para la carpeta utilizando AgregarBloque
-                nuevoIndiceBloque, err := inodoCarpeta.AgregarBloque(archivo, sb)
-                if err != nil {
-                    // Rollback: liberar el inodo
-                    sb.ActualizarBitmapInodo(archivo, nuevoIndiceInodo, false)
-                    return fmt.Errorf("error asignando bloque para la carpeta: %v", err)
-                }
-
-                // 4. Inicializar el contenido del nuevo bloque de carpeta
-                bloqueCarpeta := &FolderBlock{
-                    B_cont: [4]FolderContent{
-                        {B_name: [12]byte{'.'}, B_inodo: nuevoIndiceInodo},
-                        {B_name: [12]byte{'.', '.'}, B_inodo: indiceInodo}, // Apunta al directorio padre
-                        {B_name: [12]byte{'-'}, B_inodo: -1},
-                        {B_name: [12]byte{'-'}, B_inodo: -1},
-                    },
-                }
-
-                // 5. Escribir el bloque al disco
-                nuevoOffsetBloque := int64(sb.S_block_start + (nuevoIndiceBloque * sb.S_block_size))
-                if err := bloqueCarpeta.Codificar(archivo, nuevoOffsetBloque); err != nil {
-                    // Rollback: liberar bloque e inodo
-                    inodoCarpeta.LiberarBloque(archivo, sb, nuevoIndiceBloque)
-                    sb.ActualizarBitmapInodo(archivo, nuevoIndiceInodo, false)
-                    return fmt.Errorf("error escribiendo bloque de carpeta: %v", err)
-                }
-
-                // 6. Escribir el inodo al disco
-                offsetInodo := int64(sb.S_inode_start + (nuevoIndiceInodo * sb.S_inode_size))
-                if err := inodoCarpeta.Codificar(archivo, offsetInodo); err != nil {
-                    // Rollback: liberar recursos
-                    inodoCarpeta.LiberarBloque(archivo, sb, nuevoIndiceBloque)
-                    sb.ActualizarBitmapInodo(archivo, nuevoIndiceInodo, false)
-                    return fmt.Errorf("error escribiendo inodo de carpeta: %v", err)
-                }
-
-                // 7. Actualizar la entrada en el directorio padre
-                bytesNombre := [12]byte{}
-                copy(bytesNombre[:], directorioDestino)
-                bloque.B_cont[indiceContenido] = FolderContent{
-                    B_name:  bytesNombre,
-                    B_inodo: nuevoIndiceInodo,
-                }
+    // 2. Asignar y marcar un nuevo inodo en el bitmap (con grupos, en el grupo que le toque a la carpeta)
+    nuevoIndiceInodo, err := sb.BuscarInodoLibreParaCarpeta(archivo)
+    if err != nil {
+        return fmt.Errorf("error encontrando inodo libre: %v", err)
+    }
 
-                // 8. Guardar el bloque del directorio padre con la nueva entrada
-                if err := bloque.Codificar(archivo, offsetBloque); err != nil {
-                    // Rollback en caso de error (aunque es poco probable aquí)
-                    return fmt.Errorf("error actualizando bloque del directorio padre: %v", err)
-                }
+    if err := sb.ActualizarBitmapInodo(archivo, nuevoIndiceInodo, true); err != nil {
+        return fmt.Errorf("error marcando inodo como usado: %v", err)
+    }
 
-                // 9. Journaling si es necesario
-                if registrarJournal && sb.S_filesystem_type == 3 {
-                    inicioJournaling := int64(sb.InicioJournal())
-
-                    // Construir la ruta completa para el journal de forma más robusta
-                    var rutaCompleta string
-                    if len(directoriosPadre) > 0 {
-                        // Si hay directorios padres, incluirlos en la ruta
-                        rutaCompleta = "/" + strings.Join(directoriosPadre, "/")
-                        if len(directorioDestino) > 0 {
-                            rutaCompleta += "/" + directorioDestino
-                        }
-                    } else {
-                        // Si estamos en la raíz
-                        rutaCompleta = "/" + directorioDestino
-                    }
-
-                    // Usar AgregarEntradaJournal que maneja automáticamente índices y serialización
-                    if err := AgregarEntradaJournal(
-                        archivo,
-                        inicioJournaling,
-                        ENTRADAS_JOURNAL,
-                        "mkdir",
-                        rutaCompleta,
-                        "",
-                        sb,
-                    ); err != nil {
-                        // Solo mostrar advertencia pero continuar con la operación
-                        fmt.Printf("Advertencia: error registrando operación en journal: %v\n", err)
-                    } else {
-                        fmt.Printf("Operación 'mkdir %s' registrada en journal exitosamente\n", rutaCompleta)
-                    }
-                }
+    // 3. Asignar un bloque para la carpeta utilizando AgregarBloque, cerca de los del padre o en su grupo
+    sb.PreferirBloquesCercaDe(archivo, sb.BloqueReferenciaCarpeta(nuevoIndiceInodo, directorio.Inodo))
+    nuevoIndiceBloque, err := inodoCarpeta.AgregarBloque(archivo, sb)
+    if err != nil {
+        // Rollback: liberar el inodo
+        sb.ActualizarBitmapInodo(archivo, nuevoIndiceInodo, false)
+        return fmt.Errorf("error asignando bloque para la carpeta: %v", err)
+    }
 
-                fmt.Printf("Directorio '%s' creado exitosamente en inodo %d\n", directorioDestino, nuevoIndiceInodo)
-                sb.ActualizarSuperblockDespuesAsignacionInodo() // Actualizar contadores en el superbloque
+    // 4. Inicializar el contenido del nuevo bloque de carpeta
+    bloqueCarpeta := NuevoFolderBlock(sb.S_block_size)
+    bloqueCarpeta.B_cont[0] = FolderContent{B_name: [12]byte{'.'}, B_inodo: nuevoIndiceInodo}
+    bloqueCarpeta.B_cont[1] = FolderContent{B_name: [12]byte{'.', '.'}, B_inodo: indiceInodo} // Apunta al directorio padre
+
+    // 5. Escribir el bloque al disco
+    nuevoOffsetBloque := int64(sb.S_block_start + (nuevoIndiceBloque * sb.S_block_size))
+    if err := bloqueCarpeta.Codificar(archivo, nuevoOffsetBloque); err != nil {
+        // Rollback: liberar bloque e inodo
+        inodoCarpeta.LiberarBloque(archivo, sb, nuevoIndiceBloque)
+        sb.ActualizarBitmapInodo(archivo, nuevoIndiceInodo, false)
+        return fmt.Errorf("error escribiendo bloque de carpeta: %v", err)
+    }
 
-                return nil
-            }
-        }
+    // 6. Escribir el inodo al disco
+    offsetInodo := sb.CalcularDesplazamientoInodo(nuevoIndiceInodo)
+    if err := inodoCarpeta.Codificar(archivo, offsetInodo); err != nil {
+        // Rollback: liberar recursos
+        inodoCarpeta.LiberarBloque(archivo, sb, nuevoIndiceBloque)
+        sb.ActualizarBitmapInodo(archivo, nuevoIndiceInodo, false)
+        return fmt.Errorf("error escribiendo inodo de carpeta: %v", err)
     }
 
-    // Si llegamos aquí, no se encontró espacio en los bloques existentes
-    // Intentar agregar un nuevo bloque al directorio actual
-    if len(directoriosPadre) == 0 { // Solo si estamos buscando crear la carpeta en este nivel
-        nuevoIndiceBloque, err := inodo.AgregarBloque(archivo, sb)
-        if err != nil {
-            return fmt.Errorf("error añadiendo bloque adicional al directorio: %v", err)
-        }
+    // 7. Agregar la entrada en el directorio padre (puede ocupar varios contenidos si el nombre es largo)
+    if err := directorio.Agregar(archivo, sb, directorioDestino, nuevoIndiceInodo); err != nil {
+        inodoCarpeta.LiberarBloque(archivo, sb, nuevoIndiceBloque)
+        sb.ActualizarBitmapInodo(archivo, nuevoIndiceInodo, false)
+        return fmt.Errorf("error agregando '%s' al directorio padre: %v", directorioDestino, err)
+    }
 
-        // Inicializar el nuevo bloque
-        nuevoBloque := &FolderBlock{
-            B_cont: [4]FolderContent{
-                {B_name: [12]byte{'-'}, B_inodo: -1},
-                {B_name: [12]byte{'-'}, B_inodo: -1},
-                {B_name: [12]byte{'-'}, B_inodo: -1},
-                {B_name: [12]byte{'-'}, B_inodo: -1},
-            },
-        }
+    // 8. Guardar los bloques del directorio padre con la nueva entrada
+    if err := directorio.Guardar(archivo, sb); err != nil {
+        return fmt.Errorf("error actualizando bloque del directorio padre: %v", err)
+    }
 
-        offsetBloque := int64(sb.S_block_start + (nuevoIndiceBloque * sb.S_block_size))
-        if err := nuevoBloque.Codificar(archivo, offsetBloque); err != nil {
-            return fmt.Errorf("error escribiendo nuevo bloque en directorio: %v", err)
+    // 9. Journaling si es necesario
+    if registrarJournal && sb.S_filesystem_type == 3 {
+        inicioJournaling := int64(sb.InicioJournal())
+
+        // Construir la ruta completa para el journal de forma más robusta
+        var rutaCompleta string
+        if len(directoriosPadre) > 0 {
+            // Si hay directorios padres, incluirlos en la ruta
+            rutaCompleta = "/" + strings.Join(directoriosPadre, "/")
+            if len(directorioDestino) > 0 {
+                rutaCompleta += "/" + directorioDestino
+            }
+        } else {
+            // Si estamos en la raíz
+            rutaCompleta = "/" + directorioDestino
         }
 
-        // Actualizar el inodo del directorio con este nuevo bloque
-        if err := inodo.Codificar(archivo, int64(sb.S_inode_start+(indiceInodo*sb.S_inode_size))); err != nil {
-            return fmt.Errorf("error actualizando inodo %d: %v", indiceInodo, err)
+        // Usar AgregarEntradaJournal que maneja automáticamente índices y serialización
+        if err := AgregarEntradaJournal(
+            archivo,
+            inicioJournaling,
+            ENTRADAS_JOURNAL,
+            "mkdir",
+            rutaCompleta,
+            "",
+            sb,
+        ); err != nil {
+            // Solo mostrar advertencia pero continuar con la operación
+            fmt.Printf("Advertencia: error registrando operación en journal: %v\n", err)
+        } else {
+            fmt.Printf("Operación 'mkdir %s' registrada en journal exitosamente\n", rutaCompleta)
         }
-
-        // Recursión para volver a intentar la creación con el nuevo bloque disponible
-        fmt.Printf("Añadido nuevo bloque %d al directorio, reintentando creación\n", nuevoIndiceBloque)
-        return sb.crearCarpetaEnInodo(archivo, indiceInodo, directoriosPadre, directorioDestino, registrarJournal)
     }
 
-    return fmt.Errorf("no se encontró carpeta para '%s' y no se pudo crear", directorioDestino)
+    fmt.Printf("Directorio '%s' creado exitosamente en inodo %d\n", directorioDestino, nuevoIndiceInodo)
+    sb.ActualizarSuperblockDespuesAsignacionInodo() // Actualizar contadores en el superbloque
+
+    return nil
 }
 
-// CrearCarpeta genera una carpeta en el sistema de archivos
+// CrearCarpeta genera una carpeta en el sistema de archivos, descendiendo desde la raiz
 func (sb *SuperBlock) CrearCarpeta(archivo *os.File, directoriosPadre []string, directorioDestino string, log bool) error {
-	// Si directoriosPadre esta vacio trabajar solo con inodo raiz
-	if len(directoriosPadre) == 0 {
-		return sb.crearCarpetaEnInodo(archivo, 0, directoriosPadre, directorioDestino, log)
-	}
-
-	// Recorrer inodo para el inodo padre
-	for i := int32(0); i < sb.S_inodes_count; i++ {
-		// Desde inodo 0
-		err := sb.crearCarpetaEnInodo(archivo, i, directoriosPadre, directorioDestino, log)
-		if err != nil {
-			return err
-		}
+	indiceInodoPadre, err := sb.directorioDeComponentes(archivo, directoriosPadre)
+	if err != nil {
+		return err
 	}
-
-	return nil
+	return sb.crearCarpetaEnInodo(archivo, indiceInodoPadre, directoriosPadre, directorioDestino, log)
 }
 
 // CrearCarpetaRecursivamente crea carpetas recursivamente asegurando que cada directorio intermedio existe
 func (sb *SuperBlock) CrearCarpetaRecursivamente(archivo *os.File, ruta string, registrarJournal bool) error {
     // Fragmentar la ruta en directorios individuales
-    directorios := strings.Split(strings.Trim(ruta, "/"), "/")
+    directorios, _ := componentesRuta(ruta)
 
     if len(directorios) == 0 {
         return fmt.Errorf("ruta no válida: %s", ruta)
     }
 
     // Invocar la función recursiva iniciando desde el inodo raíz
-    return sb.crearCarpetaRecursivamenteEnInodo(archivo, 0, directorios, registrarJournal)
+    return sb.crearCarpetaRecursivamenteEnInodo(archivo, 0, nil, directorios, registrarJournal)
 }
 
 // crearCarpetaRecursivamenteEnInodo garantiza que cada carpeta en la lista exista o sea creada
-func (sb *SuperBlock) crearCarpetaRecursivamenteEnInodo(archivo *os.File, indiceInodo int32, directorios []string, registrarJournal bool) error {
+func (sb *SuperBlock) crearCarpetaRecursivamenteEnInodo(archivo *os.File, indiceInodo int32, recorridos []string, directorios []string, registrarJournal bool) error {
     if len(directorios) == 0 {
         return nil // No hay más directorios que procesar
     }
@@ -306,21 +171,35 @@ func (sb *SuperBlock) crearCarpetaRecursivamenteEnInodo(archivo *os.File, indice
     directorioActual := directorios[0]
     directoriosRestantes := directorios[1:]
 
-    // Utilizar la función `crearCarpetaEnInodo` para localizar o crear el directorio actual
-    err := sb.crearCarpetaEnInodo(archivo, indiceInodo, nil, directorioActual, registrarJournal)
+    // Si el directorio actual ya existe se desciende a el sin crearlo de nuevo
+    entrada, existe, err := sb.BuscarEntrada(archivo, indiceInodo, directorioActual)
     if err != nil {
         return fmt.Errorf("error procesando directorio '%s': %v", directorioActual, err)
     }
+    if !existe {
+        // Utilizar la función `crearCarpetaEnInodo` para crear el directorio actual
+        err = sb.crearCarpetaEnInodo(archivo, indiceInodo, recorridos, directorioActual, registrarJournal)
+        if err != nil {
+            return fmt.Errorf("error procesando directorio '%s': %v", directorioActual, err)
+        }
 
-    // Después de procesar el directorio actual, avanzar al siguiente nivel recursivamente
-    return sb.crearCarpetaRecursivamenteEnInodo(archivo, sb.S_inodes_count-1, directoriosRestantes, registrarJournal)
+        // El inodo asignado se obtiene de la entrada recien creada (crear el indice del
+        // directorio padre tambien puede asignar un inodo)
+        entrada, _, err = sb.BuscarEntrada(archivo, indiceInodo, directorioActual)
+        if err != nil {
+            return fmt.Errorf("error procesando directorio '%s': %v", directorioActual, err)
+        }
+    }
+
+    // Avanzar al siguiente nivel recursivamente
+    return sb.crearCarpetaRecursivamenteEnInodo(archivo, entrada.Inodo, append(recorridos, directorioActual), directoriosRestantes, registrarJournal)
 }
 
 // eliminarCarpetaEnInodo elimina recursivamente el contenido de una carpeta en un inodo específico
 func (sb *SuperBlock) eliminarCarpetaEnInodo(archivo *os.File, indiceInodo int32, rutaCarpeta ...string) error {
     // 1. Deserializar el inodo del directorio objetivo
     inodoDirectorio := &INodo{}
-    err := inodoDirectorio.Decodificar(archivo, int64(sb.S_inode_start+(indiceInodo*sb.S_inode_size)))
+    err := inodoDirectorio.Decodificar(archivo, sb.CalcularDesplazamientoInodo(indiceInodo))
     if err != nil {
         return fmt.Errorf("error al deserializar inodo %d: %w", indiceInodo, err)
     }
@@ -358,109 +237,94 @@ func (sb *SuperBlock) eliminarCarpetaEnInodo(archivo *os.File, indiceInodo int32
         }
     }
 
-    // 5. Obtener todos los bloques de datos del directorio
-    indicesBloques, err := inodoDirectorio.ObtenerIndicesBloquesDatos(archivo, sb)
+    // 5. Obtener todas las entradas del directorio
+    directorio, err := sb.LeerDirectorio(archivo, indiceInodo)
     if err != nil {
-        return fmt.Errorf("error obteniendo bloques de datos del directorio: %w", err)
+        return fmt.Errorf("error obteniendo entradas del directorio: %w", err)
     }
 
-    // 6. Procesar cada bloque del directorio
-    for _, indiceBloques := range indicesBloques {
-        // 6.1 Cargar el bloque de directorio
-        bloqueDir := &FolderBlock{}
-        offsetBloques := int64(sb.S_block_start + indiceBloques*sb.S_block_size)
-        if err := bloqueDir.Decodificar(archivo, offsetBloques); err != nil {
-            return fmt.Errorf("error deserializando bloque %d: %w", indiceBloques, err)
+    // 6. Procesar cada entrada del directorio
+    for _, contenido := range directorio.Entradas() {
+        // Saltar entradas especiales (. y ..)
+        if contenido.EsEspecial() {
+            continue
         }
 
-        // 6.2 Procesar cada entrada en el bloque
-        for _, contenido := range bloqueDir.B_cont {
-            // Saltar entradas vacías o especiales (. y ..)
-            if contenido.B_inodo == -1 ||
-                string(contenido.B_name[:1]) == "." ||
-                string(contenido.B_name[:2]) == ".." {
-                continue
-            }
-
-            // 6.3 Obtener el nombre del contenido y construir su ruta
-            nombreContenido := strings.Trim(string(contenido.B_name[:]), "\x00 ")
-            fmt.Printf("Eliminando contenido '%s' en inodo %d\n", nombreContenido, contenido.B_inodo)
+        // 6.3 Obtener el nombre del contenido y construir su ruta
+        nombreContenido := contenido.Nombre
+        fmt.Printf("Eliminando contenido '%s' en inodo %d\n", nombreContenido, contenido.Inodo)
 
-            rutaHijo := rutaCompleta
-            if !strings.HasSuffix(rutaHijo, "/") {
-                rutaHijo += "/"
-            }
-            rutaHijo += nombreContenido
+        rutaHijo := rutaCompleta
+        if !strings.HasSuffix(rutaHijo, "/") {
+            rutaHijo += "/"
+        }
+        rutaHijo += nombreContenido
 
-            // 6.4 Cargar el inodo del contenido
-            inodoHijo := &INodo{}
-            offsetInodoHijo := int64(sb.S_inode_start + (contenido.B_inodo * sb.S_inode_size))
-            if err := inodoHijo.Decodificar(archivo, offsetInodoHijo); err != nil {
-                return fmt.Errorf("error deserializando inodo hijo %d: %w", contenido.B_inodo, err)
-            }
+        // 6.4 Cargar el inodo del contenido
+        inodoHijo := &INodo{}
+        offsetInodoHijo := sb.CalcularDesplazamientoInodo(contenido.Inodo)
+        if err := inodoHijo.Decodificar(archivo, offsetInodoHijo); err != nil {
+            return fmt.Errorf("error deserializando inodo hijo %d: %w", contenido.Inodo, err)
+        }
 
-            // 6.5 Procesar según el tipo de contenido (directorio o archivo)
-            if inodoHijo.I_type[0] == '0' { // Es un directorio
-                // Registrar eliminación de carpeta en journal
-                if sb.S_filesystem_type == 3 {
-                    if err := AgregarEntradaJournal(
-                        archivo,
-                        inicioJournaling,
-                        ENTRADAS_JOURNAL,
-                        "rmdir",
-                        rutaHijo,
-                        "",
-                        sb,
-                    ); err != nil {
-                        fmt.Printf("Advertencia: error registrando eliminación de subcarpeta en journal: %v\n", err)
-                    }
+        // 6.5 Procesar según el tipo de contenido (directorio o archivo)
+        if inodoHijo.I_type[0] == '0' { // Es un directorio
+            // Registrar eliminación de carpeta en journal
+            if sb.S_filesystem_type == 3 {
+                if err := AgregarEntradaJournal(
+                    archivo,
+                    inicioJournaling,
+                    ENTRADAS_JOURNAL,
+                    "rmdir",
+                    rutaHijo,
+                    "",
+                    sb,
+                ); err != nil {
+                    fmt.Printf("Advertencia: error registrando eliminación de subcarpeta en journal: %v\n", err)
                 }
+            }
 
-                // Eliminar recursivamente la subcarpeta
-                if err := sb.eliminarCarpetaEnInodo(archivo, contenido.B_inodo, rutaHijo); err != nil {
-                    return fmt.Errorf("error eliminando subcarpeta '%s': %w", nombreContenido, err)
-                }
-            } else { // Es un archivo
-                // Registrar eliminación de archivo en journal
-                if sb.S_filesystem_type == 3 {
-                    // Obtener el contenido del archivo para el journal
-                    datosArchivo, err := inodoHijo.LeerDatos(archivo, sb)
-                    contenidoArchivo := ""
-                    if err == nil {
-                        contenidoArchivo = string(datosArchivo)
-                    }
-
-                    if err := AgregarEntradaJournal(
-                        archivo,
-                        inicioJournaling,
-                        ENTRADAS_JOURNAL,
-                        "rm",
-                        rutaHijo,
-                        contenidoArchivo,
-                        sb,
-                    ); err != nil {
-                        fmt.Printf("Advertencia: error registrando eliminación de archivo '%s' en journal: %v\n", nombreContenido, err)
-                    } else {
-                        fmt.Printf("Operación 'rm %s' registrada en journal exitosamente\n", rutaHijo)
-                    }
+            // Eliminar recursivamente la subcarpeta
+            if err := sb.eliminarCarpetaEnInodo(archivo, contenido.Inodo, rutaHijo); err != nil {
+                return fmt.Errorf("error eliminando subcarpeta '%s': %w", nombreContenido, err)
+            }
+        } else { // Es un archivo o un enlace simbolico
+            // Registrar eliminación de archivo en journal
+            if sb.S_filesystem_type == 3 {
+                // Obtener el contenido del archivo para el journal
+                datosArchivo, err := inodoHijo.LeerDatos(archivo, sb)
+                contenidoArchivo := ""
+                if err == nil {
+                    contenidoArchivo = string(datosArchivo)
                 }
 
-                // Liberar todos los bloques del archivo
-                if err := inodoHijo.LiberarTodosLosBloques(archivo, sb); err != nil {
-                    return fmt.Errorf("error liberando bloques del archivo '%s': %w", nombreContenido, err)
+                if err := AgregarEntradaJournal(
+                    archivo,
+                    inicioJournaling,
+                    ENTRADAS_JOURNAL,
+                    "rm",
+                    rutaHijo,
+                    contenidoArchivo,
+                    sb,
+                ); err != nil {
+                    fmt.Printf("Advertencia: error registrando eliminación de archivo '%s' en journal: %v\n", nombreContenido, err)
+                } else {
+                    fmt.Printf("Operación 'rm %s' registrada en journal exitosamente\n", rutaHijo)
                 }
+            }
 
-                // Liberar el inodo del archivo
-                if err := sb.ActualizarBitmapInodo(archivo, contenido.B_inodo, false); err != nil {
-                    return fmt.Errorf("error liberando inodo %d: %w", contenido.B_inodo, err)
-                }
-                sb.ActualizarSuperblockDespuesDesasignacionInodo()
-                fmt.Printf("Archivo '%s' eliminado exitosamente (inodo %d)\n", nombreContenido, contenido.B_inodo)
+            // Descontar el enlace; si otro nombre fuera de la carpeta lo usa, el archivo se conserva
+            if _, err := sb.DesvincularInodo(archivo, contenido.Inodo, inodoHijo); err != nil {
+                return fmt.Errorf("error liberando el archivo '%s': %w", nombreContenido, err)
             }
+            fmt.Printf("Archivo '%s' eliminado exitosamente (inodo %d)\n", nombreContenido, contenido.Inodo)
         }
     }
 
-    // 7. Liberar todos los bloques del directorio
+    // 7. Liberar el indice (si lo tiene) y todos los bloques del directorio
+    if err := directorio.LiberarIndice(archivo, sb); err != nil {
+        return fmt.Errorf("error liberando el indice del directorio: %w", err)
+    }
     if err := inodoDirectorio.LiberarTodosLosBloques(archivo, sb); err != nil {
         return fmt.Errorf("error liberando bloques del directorio: %w", err)
     }
@@ -470,11 +334,15 @@ func (sb *SuperBlock) eliminarCarpetaEnInodo(archivo *os.File, indiceInodo int32
         fmt.Printf("Advertencia: error al verificar bloques indirectos vacíos: %v\n", err)
     }
 
-    // 9. Liberar el inodo del directorio
+    // 9. Liberar los atributos extendidos y el inodo del directorio
+    if err := sb.LiberarAtributos(archivo, inodoDirectorio); err != nil {
+        return fmt.Errorf("error liberando atributos del directorio: %w", err)
+    }
     if err := sb.ActualizarBitmapInodo(archivo, indiceInodo, false); err != nil {
         return fmt.Errorf("error liberando inodo del directorio %d: %w", indiceInodo, err)
     }
     sb.ActualizarSuperblockDespuesDesasignacionInodo()
+    descontarCuota(archivo, inodoDirectorio, 0, 1)
 
     fmt.Printf("Carpeta en inodo %d eliminada exitosamente.\n", indiceInodo)
     return nil
@@ -493,72 +361,10 @@ func (sb *SuperBlock) EliminarCarpeta(archivo *os.File, directoriosPadre []strin
     }
     fmt.Printf("Ruta completa para eliminación: %s\n", rutaCompleta)
 
-    // Si no hay directorio padre, eliminar desde el directorio raíz
-    if len(directoriosPadre) == 0 {
-        return sb.eliminarCarpetaDelDirectorio(archivo, 0, nombreCarpeta, rutaCompleta)
-    }
-
-    // Navegar recursivamente por la estructura de directorios
-    indiceInodoActual := int32(0) // Comenzamos desde el directorio raíz
-
-    // Recorrer cada nivel de directorio padre
-    for _, nombreDir := range directoriosPadre {
-        encontrado := false
-
-        // Cargar el inodo del directorio actual
-        inodoActual := &INodo{}
-        if err := inodoActual.Decodificar(archivo, int64(sb.S_inode_start+indiceInodoActual*sb.S_inode_size)); err != nil {
-            return fmt.Errorf("error cargando directorio actual (inodo %d): %w", indiceInodoActual, err)
-        }
-
-        // Verificar que sea un directorio
-        if inodoActual.I_type[0] != '0' {
-            return fmt.Errorf("el inodo %d no corresponde a un directorio", indiceInodoActual)
-        }
-
-        // Obtener bloques de datos del directorio
-        indicesBloques, err := inodoActual.ObtenerIndicesBloquesDatos(archivo, sb)
-        if err != nil {
-            return fmt.Errorf("error obteniendo bloques de directorio: %w", err)
-        }
-
-        // Buscar el siguiente directorio en la ruta de navegación
-        for _, indiceBloques := range indicesBloques {
-            if encontrado {
-                break
-            }
-
-            bloque := &FolderBlock{}
-            if err := bloque.Decodificar(archivo, int64(sb.S_block_start+indiceBloques*sb.S_block_size)); err != nil {
-                return fmt.Errorf("error deserializando bloque %d: %w", indiceBloques, err)
-            }
-
-            // Buscar la carpeta en este bloque
-            for _, contenido := range bloque.B_cont {
-                nombreContenido := strings.Trim(string(contenido.B_name[:]), "\x00 ")
-
-                if contenido.B_inodo != -1 && strings.EqualFold(nombreContenido, nombreDir) {
-                    // Verificar que sea un directorio
-                    inodoSubDir := &INodo{}
-                    if err := inodoSubDir.Decodificar(archivo, int64(sb.S_inode_start+contenido.B_inodo*sb.S_inode_size)); err != nil {
-                        return fmt.Errorf("error cargando inodo %d: %w", contenido.B_inodo, err)
-                    }
-
-                    if inodoSubDir.I_type[0] != '0' {
-                        return fmt.Errorf("la entrada '%s' no es un directorio", nombreDir)
-                    }
-
-                    // Avanzar al siguiente directorio en la jerarquía
-                    indiceInodoActual = contenido.B_inodo
-                    encontrado = true
-                    break
-                }
-            }
-        }
-
-        if !encontrado {
-            return fmt.Errorf("no se encontró el directorio '%s' en la ruta especificada", nombreDir)
-        }
+    // Localizar el directorio que contiene la carpeta
+    indiceInodoActual, err := sb.directorioDeComponentes(archivo, directoriosPadre)
+    if err != nil {
+        return err
     }
 
     // Llegamos al directorio que debería contener la carpeta a eliminar
@@ -567,67 +373,37 @@ func (sb *SuperBlock) EliminarCarpeta(archivo *os.File, directoriosPadre []strin
 
 // eliminarCarpetaDelDirectorio método auxiliar para eliminar una carpeta de un directorio específico
 func (sb *SuperBlock) eliminarCarpetaDelDirectorio(archivo *os.File, indiceInodoPadre int32, nombreCarpeta string, rutaCompleta string) error {
-    // Cargar el inodo del directorio padre
-    inodoPadre := &INodo{}
-    if err := inodoPadre.Decodificar(archivo, int64(sb.S_inode_start+indiceInodoPadre*sb.S_inode_size)); err != nil {
-        return fmt.Errorf("error deserializando inodo del directorio padre %d: %w", indiceInodoPadre, err)
-    }
-
-    // Verificar que sea un directorio
-    if inodoPadre.I_type[0] != '0' {
-        return fmt.Errorf("el inodo %d no corresponde a un directorio", indiceInodoPadre)
-    }
-
-    // Obtener bloques de datos del directorio padre
-    indicesBloques, err := inodoPadre.ObtenerIndicesBloquesDatos(archivo, sb)
+    // Cargar las entradas del directorio padre
+    directorioPadre, err := sb.LeerDirectorio(archivo, indiceInodoPadre)
     if err != nil {
-        return fmt.Errorf("error obteniendo bloques de datos del directorio: %w", err)
+        return fmt.Errorf("error cargando el directorio padre %d: %w", indiceInodoPadre, err)
     }
 
     // Buscar la carpeta objetivo a eliminar
-    for _, indiceBloques := range indicesBloques {
-        bloque := &FolderBlock{}
-        offsetBloques := int64(sb.S_block_start + indiceBloques*sb.S_block_size)
-
-        if err := bloque.Decodificar(archivo, offsetBloques); err != nil {
-            return fmt.Errorf("error deserializando bloque %d: %w", indiceBloques, err)
+    if contenido, existe := directorioPadre.Buscar(nombreCarpeta); existe && !contenido.EsEspecial() {
+        // Verificar que la entrada corresponde a un directorio
+        inodoCarpeta := &INodo{}
+        if err := inodoCarpeta.Decodificar(archivo, sb.CalcularDesplazamientoInodo(contenido.Inodo)); err != nil {
+            return fmt.Errorf("error deserializando inodo %d: %w", contenido.Inodo, err)
         }
 
-        // Buscar la entrada específica en el directorio
-        for i, contenido := range bloque.B_cont {
-            nombreContenido := strings.Trim(string(contenido.B_name[:]), "\x00 ")
-
-            if contenido.B_inodo != -1 && strings.EqualFold(nombreContenido, nombreCarpeta) {
-                // Verificar que la entrada corresponde a un directorio
-                inodoCarpeta := &INodo{}
-                if err := inodoCarpeta.Decodificar(archivo, int64(sb.S_inode_start+contenido.B_inodo*sb.S_inode_size)); err != nil {
-                    return fmt.Errorf("error deserializando inodo %d: %w", contenido.B_inodo, err)
-                }
-
-                if inodoCarpeta.I_type[0] != '0' {
-                    return fmt.Errorf("'%s' no es un directorio válido", nombreCarpeta)
-                }
-
-                // Eliminar el directorio recursivamente usando la ruta completa
-                if err := sb.eliminarCarpetaEnInodo(archivo, contenido.B_inodo, rutaCompleta); err != nil {
-                    return fmt.Errorf("error eliminando carpeta '%s': %w", nombreCarpeta, err)
-                }
-
-                // Limpiar la entrada en el directorio padre
-                bloque.B_cont[i] = FolderContent{
-                    B_name:  [12]byte{'-'},
-                    B_inodo: -1,
-                }
+        if inodoCarpeta.I_type[0] != '0' {
+            return fmt.Errorf("'%s' no es un directorio válido", nombreCarpeta)
+        }
 
-                // Guardar el bloque actualizado con la entrada eliminada
-                if err := bloque.Codificar(archivo, offsetBloques); err != nil {
-                    return fmt.Errorf("error actualizando bloque de directorio padre: %w", err)
-                }
+        // Eliminar el directorio recursivamente usando la ruta completa
+        if err := sb.eliminarCarpetaEnInodo(archivo, contenido.Inodo, rutaCompleta); err != nil {
+            return fmt.Errorf("error eliminando carpeta '%s': %w", nombreCarpeta, err)
+        }
 
-                fmt.Printf("Carpeta '%s' eliminada exitosamente del sistema\n", nombreCarpeta)
-                return nil
-            }
+        // Limpiar la entrada (y sus continuaciones) en el directorio padre
+        directorioPadre.Eliminar(contenido)
+        if err := directorioPadre.Guardar(archivo, sb); err != nil {
+            return fmt.Errorf("error actualizando bloque de directorio padre: %w", err)
         }
+
+        fmt.Printf("Carpeta '%s' eliminada exitosamente del sistema\n", nombreCarpeta)
+        return nil
     }
 
     return fmt.Errorf("carpeta '%s' no encontrada en el directorio especificado", nombreCarpeta)
@@ -639,7 +415,7 @@ func (sb *SuperBlock) crearCarpetaEnInodo(archivo *os.File, indiceInodo int32, d
 	inodo := &INodo{}
 
 	// Deserializar el inodo
-	err := inodo.Decodificar(archivo, int64(sb.S_inode_start+(indiceInodo*sb.S_inode_size)))
+	err := inodo.Decodificar(archivo, sb.CalcularDesplazamientoInodo(indiceInodo))
 	if err != nil {
 		return fmt.Errorf("error al deserializar inodo %d: %v", indiceInodo, err)
 	}
@@ -656,7 +432,7 @@ func (sb *SuperBlock) crearCarpetaEnInodo(archivo *os.File, indiceInodo int32, d
 			break
 		}
 
-		bloque := &FolderBlock{}
+		bloque := NuevoFolderBlock(sb.S_block_size)
 
 		err := bloque.Decodificar(archivo, int64(sb.S_block_start+(indiceBloques*sb.S_block_size)))
 		if err != nil {
@@ -712,14 +488,16 @@ func (sb *SuperBlock) crearCarpetaEnInodo(archivo *os.File, indiceInodo int32, d
 				}
 
 				// Crear inodo de la nueva carpeta
+				propietario, grupo := sb.propietarioNuevoInodo(archivo)
 				inodoCarpeta := &INodo{
-					I_uid:   1,
-					I_gid:   1,
+					I_uid:   propietario,
+					I_gid:   grupo,
 					I_size:  0,
-					I_atime: float32(time.Now().Unix()),
-					I_ctime: float32(time.Now().Unix()),
-					I_mtime: float32(time.Now().Unix()),
+					I_atime: MarcaTiempoActual(),
+					I_ctime: MarcaTiempoActual(),
+					I_mtime: MarcaTiempoActual(),
 					I_block: [15]int32{sb.S_blocks_count, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1},
+					I_xattr: -1,
 					I_type:  [1]byte{'0'}, // Tipo carpeta
 					I_perm:  [3]byte{'6', '6', '4'},
 				}
@@ -740,14 +518,9 @@ func (sb *SuperBlock) crearCarpetaEnInodo(archivo *os.File, indiceInodo int32, d
 				sb.ActualizarSuperblockDespuesAsignacionInodo()
 
 				// Generar bloque para la nueva carpeta
-				bloqueCarpeta := &FolderBlock{
-					B_cont: [4]FolderContent{
-						{B_name: [12]byte{'.'}, B_inodo: contenido.B_inodo},
-						{B_name: [12]byte{'.', '.'}, B_inodo: indiceInodo},
-						{B_name: [12]byte{'-'}, B_inodo: -1},
-						{B_name: [12]byte{'-'}, B_inodo: -1},
-					},
-				}
+				bloqueCarpeta := NuevoFolderBlock(sb.S_block_size)
+				bloqueCarpeta.B_cont[0] = FolderContent{B_name: [12]byte{'.'}, B_inodo: contenido.B_inodo}
+				bloqueCarpeta.B_cont[1] = FolderContent{B_name: [12]byte{'.', '.'}, B_inodo: indiceInodo}
 
 				// Serializar bloque de la carpeta
 				err = bloqueCarpeta.Codificar(archivo, int64(sb.S_first_blo))
@@ -0,0 +1,161 @@
+package Estructuras
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os"
+
+	Utils "backend/Utils"
+)
+
+// Tamaño del formato original del inodo. Cada sistema de archivos guarda en S_inode_size
+// el tamaño con que se formateo y sus inodos se leen y escriben en ese formato
+const tamanoInodoOriginal = 88 // marcas de tiempo en segundos (float32), sin I_links ni I_xattr
+
+// inodoOriginal es el inodo de 88 bytes
+type inodoOriginal struct {
+	I_uid   int32
+	I_gid   int32
+	I_size  int32
+	I_atime float32
+	I_ctime float32
+	I_mtime float32
+	I_type  [1]byte
+	I_perm  [3]byte
+	I_block [15]int32
+}
+
+// FormatoInodoConocido indica si el inodo de 'tamano' bytes se puede leer
+func FormatoInodoConocido(tamano int32) bool {
+	switch tamano {
+	case TamanoInodo, tamanoInodoOriginal:
+		return true
+	}
+	return false
+}
+
+// AdmiteEnlacesDuros indica si los inodos del sistema de archivos guardan I_links
+func (sb *SuperBlock) AdmiteEnlacesDuros() bool {
+	return sb.S_inode_size != tamanoInodoOriginal
+}
+
+// AdmiteAtributosExtendidos indica si los inodos del sistema de archivos guardan I_xattr
+func (sb *SuperBlock) AdmiteAtributosExtendidos() bool {
+	return sb.S_inode_size == TamanoInodo
+}
+
+// segundosANano y nanoASegundos convierten entre las marcas float32 del formato anterior
+// y las marcas en nanosegundos
+func segundosANano(segundos float32) int64 { return int64(float64(segundos) * 1e9) }
+func nanoASegundos(nano int64) float32     { return float32(float64(nano) / 1e9) }
+
+// desdeFormato convierte los bytes de un inodo de 'tamano' bytes al inodo actual. Los
+// campos que el formato no tiene quedan como en un inodo nuevo
+func (inodo *INodo) desdeFormato(datos []byte, tamano int32) error {
+	lector := bytes.NewReader(datos)
+	switch tamano {
+	case TamanoInodo:
+		return binary.Read(lector, binary.LittleEndian, inodo)
+	case tamanoInodoOriginal:
+		var anterior inodoOriginal
+		if err := binary.Read(lector, binary.LittleEndian, &anterior); err != nil {
+			return err
+		}
+		*inodo = INodo{I_uid: anterior.I_uid, I_gid: anterior.I_gid, I_size: anterior.I_size, I_links: 1,
+			I_atime: segundosANano(anterior.I_atime), I_ctime: segundosANano(anterior.I_ctime), I_mtime: segundosANano(anterior.I_mtime),
+			I_type: anterior.I_type, I_perm: anterior.I_perm, I_block: anterior.I_block, I_xattr: -1}
+	default:
+		return fmt.Errorf("formato de inodo de %d bytes desconocido", tamano)
+	}
+	return nil
+}
+
+// aFormato serializa el inodo con el formato de 'tamano' bytes. Falla si el inodo usa un
+// campo que ese formato no tiene; las marcas de tiempo pierden precision sin reclamo
+func (inodo *INodo) aFormato(tamano int32) ([]byte, error) {
+	var estructura interface{}
+	switch tamano {
+	case TamanoInodo:
+		estructura = inodo
+	case tamanoInodoOriginal:
+		if inodo.I_xattr != -1 {
+			return nil, fmt.Errorf("el sistema de archivos usa inodos de %d bytes, sin atributos extendidos", tamano)
+		}
+		if inodo.I_links > 1 {
+			return nil, fmt.Errorf("el sistema de archivos usa inodos de %d bytes, sin contador de enlaces", tamano)
+		}
+		estructura = &inodoOriginal{I_uid: inodo.I_uid, I_gid: inodo.I_gid, I_size: inodo.I_size,
+			I_atime: nanoASegundos(inodo.I_atime), I_ctime: nanoASegundos(inodo.I_ctime), I_mtime: nanoASegundos(inodo.I_mtime),
+			I_type: inodo.I_type, I_perm: inodo.I_perm, I_block: inodo.I_block}
+	default:
+		return nil, fmt.Errorf("formato de inodo de %d bytes desconocido", tamano)
+	}
+
+	var buffer bytes.Buffer
+	if err := binary.Write(&buffer, binary.LittleEndian, estructura); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// tamanoInodoDe lee el superbloque que empieza en 'inicio' y devuelve su S_inode_size, o el
+// tamaño actual si ahi no hay un sistema de archivos con un formato conocido
+func tamanoInodoDe(archivo *os.File, inicio int64) int32 {
+	var sb SuperBlock
+	if err := Utils.LeerDeArchivo(archivo, inicio, &sb); err != nil {
+		return TamanoInodo
+	}
+	if sb.S_magic != 0xEF53 || !FormatoInodoConocido(sb.S_inode_size) {
+		return TamanoInodo
+	}
+	return sb.S_inode_size
+}
+
+// tamanoInodoEn devuelve el tamaño de inodo del sistema de archivos de la particion que
+// contiene el desplazamiento. Solo las primarias y las entradas GPT se pueden formatear,
+// y su superbloque esta al inicio de la particion
+func tamanoInodoEn(archivo *os.File, desplazamiento int64) int32 {
+	var mbr MBR
+	if err := Utils.LeerDeArchivo(archivo, 0, &mbr); err != nil {
+		return TamanoInodo
+	}
+	var particiones []Particion
+	if mbr.EsProtectorGPT() {
+		var gpt GPT
+		if err := gpt.Decodificar(archivo); err != nil {
+			return TamanoInodo
+		}
+		for i := range gpt.Entradas {
+			if gpt.Entradas[i].EnUso() {
+				particiones = append(particiones, *gpt.Entradas[i].ComoParticion())
+			}
+		}
+	} else {
+		for _, particion := range mbr.MbrPartitions {
+			if particion.Part_type[0] == 'P' {
+				particiones = append(particiones, particion)
+			}
+		}
+	}
+	for _, particion := range particiones {
+		inicio := int64(particion.Part_start)
+		if desplazamiento >= inicio && desplazamiento < inicio+int64(particion.Part_size) {
+			return tamanoInodoDe(archivo, inicio)
+		}
+	}
+	return TamanoInodo
+}
+
+// DecodificarEnDisco lee el inodo tal como esta en el disco, sin pasar por la cache, con
+// el formato de 'tamano' bytes (el S_inode_size del superbloque)
+func (inodo *INodo) DecodificarEnDisco(archivo *os.File, desplazamiento int64, tamano int32) error {
+	if !FormatoInodoConocido(tamano) {
+		return fmt.Errorf("formato de inodo de %d bytes desconocido", tamano)
+	}
+	datos := make([]byte, tamano)
+	if _, err := archivo.ReadAt(datos, desplazamiento); err != nil {
+		return fmt.Errorf("error leyendo INodo desde archivo: %w", err)
+	}
+	return inodo.desdeFormato(datos, tamano)
+}
@@ -0,0 +1,161 @@
+package Estructuras
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	Utils "backend/Utils"
+)
+
+func inodoDePrueba() INodo {
+	marca := time.Date(2025, 3, 14, 15, 9, 26, 535897932, time.UTC).UnixNano()
+	inodo := INodo{I_uid: 2, I_gid: 3, I_size: 130, I_links: 1, I_atime: marca, I_ctime: marca + 1e9, I_mtime: marca + 2e9,
+		I_type: [1]byte{'1'}, I_perm: [3]byte{'6', '6', '4'}, I_xattr: -1}
+	for i := range inodo.I_block {
+		inodo.I_block[i] = -1
+	}
+	inodo.I_block[0], inodo.I_block[1], inodo.I_block[12] = 7, 8, 9
+	return inodo
+}
+
+func TestFormatosDeInodo(t *testing.T) {
+	casos := []struct {
+		tamano    int32
+		precision time.Duration // lo que se pierde de las marcas de tiempo
+	}{
+		{TamanoInodo, 0},
+		{tamanoInodoOriginal, 256 * time.Second}, // float32 con marcas de ~1.7e9 segundos
+	}
+	for _, caso := range casos {
+		original := inodoDePrueba()
+		datos, err := original.aFormato(caso.tamano)
+		if err != nil {
+			t.Fatalf("%d bytes: %v", caso.tamano, err)
+		}
+		if int32(len(datos)) != caso.tamano {
+			t.Fatalf("%d bytes: se serializaron %d bytes", caso.tamano, len(datos))
+		}
+
+		var leido INodo
+		if err := leido.desdeFormato(datos, caso.tamano); err != nil {
+			t.Fatalf("%d bytes: %v", caso.tamano, err)
+		}
+		marcas := [][2]int64{{leido.I_atime, original.I_atime}, {leido.I_ctime, original.I_ctime}, {leido.I_mtime, original.I_mtime}}
+		for _, par := range marcas {
+			if diferencia := time.Duration(par[0] - par[1]); diferencia < -caso.precision || diferencia > caso.precision {
+				t.Errorf("%d bytes: la marca %d quedo en %d", caso.tamano, par[1], par[0])
+			}
+		}
+		leido.I_atime, leido.I_ctime, leido.I_mtime = original.I_atime, original.I_ctime, original.I_mtime
+		if leido != original {
+			t.Errorf("%d bytes: se leyo %+v, se esperaba %+v", caso.tamano, leido, original)
+		}
+	}
+}
+
+func TestFormatosAnterioresRechazanCamposNuevos(t *testing.T) {
+	conXattr := inodoDePrueba()
+	conXattr.I_xattr = 40
+	conEnlaces := inodoDePrueba()
+	conEnlaces.I_links = 2
+
+	casos := []struct {
+		nombre  string
+		inodo   INodo
+		tamano  int32
+		rechaza bool
+	}{
+		{"xattr en el formato actual", conXattr, TamanoInodo, false},
+		{"xattr en 88 bytes", conXattr, tamanoInodoOriginal, true},
+		{"enlaces duros en el formato actual", conEnlaces, TamanoInodo, false},
+		{"enlaces duros en 88 bytes", conEnlaces, tamanoInodoOriginal, true},
+		{"formato de 92 bytes", inodoDePrueba(), 92, true},
+		{"formato de 104 bytes", inodoDePrueba(), 104, true},
+	}
+	for _, caso := range casos {
+		_, err := caso.inodo.aFormato(caso.tamano)
+		if (err != nil) != caso.rechaza {
+			t.Errorf("%s: error %v", caso.nombre, err)
+		}
+	}
+}
+
+func TestDecodificarInodoOriginalDesdeDisco(t *testing.T) {
+	archivo := discoTemporal(t, 4096)
+	anterior := inodoOriginal{I_uid: 1, I_gid: 1, I_size: 27, I_atime: 1.7e9, I_ctime: 1.7e9, I_mtime: 1.7e9,
+		I_type: [1]byte{'1'}, I_perm: [3]byte{'7', '7', '7'}}
+	for i := range anterior.I_block {
+		anterior.I_block[i] = -1
+	}
+	anterior.I_block[0] = 1
+	var buffer bytes.Buffer
+	binary.Write(&buffer, binary.LittleEndian, &anterior)
+	if buffer.Len() != tamanoInodoOriginal {
+		t.Fatalf("el inodo original ocupa %d bytes", buffer.Len())
+	}
+	escribirBytes(t, archivo, 1000+tamanoInodoOriginal, buffer.Bytes())
+
+	var inodo INodo
+	if err := inodo.DecodificarEnDisco(archivo, 1000+tamanoInodoOriginal, tamanoInodoOriginal); err != nil {
+		t.Fatal(err)
+	}
+	if inodo.I_size != 27 || inodo.I_block[0] != 1 || inodo.I_block[1] != -1 || inodo.I_links != 1 || inodo.I_xattr != -1 {
+		t.Errorf("inodo leido: %+v", inodo)
+	}
+	if !FechaDeMarca(inodo.I_mtime).Equal(time.Unix(1.7e9, 0)) {
+		t.Errorf("mtime %v", FechaDeMarca(inodo.I_mtime))
+	}
+}
+
+func TestTamanoInodoDesdeElSuperbloque(t *testing.T) {
+	archivo := discoTemporal(t, 30000)
+	particion := func(tipo byte, inicio, tamano int32) Particion {
+		return Particion{Part_type: [1]byte{tipo}, Part_start: inicio, Part_size: tamano}
+	}
+	mbr := MBR{MbrPartitions: [4]Particion{
+		particion('P', 1000, 9000),
+		particion('P', 10000, 10000),
+		particion('P', 20000, 5000), // sin formato
+		particion('E', 25000, 5000),
+	}}
+	if err := Utils.EscribirAArchivo(archivo, 0, &mbr); err != nil {
+		t.Fatal(err)
+	}
+	// Cada particion guarda el tamaño de sus inodos en su propio superbloque
+	for _, sb := range []struct {
+		inicio int64
+		tamano int32
+	}{{1000, tamanoInodoOriginal}, {10000, TamanoInodo}, {25000, tamanoInodoOriginal}} {
+		if err := Utils.EscribirAArchivo(archivo, sb.inicio, &SuperBlock{S_magic: 0xEF53, S_inode_size: sb.tamano}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	casos := []struct {
+		desplazamiento int64
+		tamano         int32
+	}{
+		{500, TamanoInodo}, // fuera de las particiones
+		{1000, tamanoInodoOriginal},
+		{9999, tamanoInodoOriginal},
+		{10000, TamanoInodo},
+		{15000, TamanoInodo},
+		{22000, TamanoInodo},
+		{26000, TamanoInodo}, // las extendidas no se formatean
+	}
+	for _, caso := range casos {
+		if tamano := tamanoInodoEn(archivo, caso.desplazamiento); tamano != caso.tamano {
+			t.Errorf("en %d: %d bytes, se esperaba %d", caso.desplazamiento, tamano, caso.tamano)
+		}
+	}
+
+	// Un formato posterior de la particion cambia el tamaño que se lee
+	if err := Utils.EscribirAArchivo(archivo, 1000, &SuperBlock{S_magic: 0xEF53, S_inode_size: TamanoInodo}); err != nil {
+		t.Fatal(err)
+	}
+	if tamano := tamanoInodoEn(archivo, 5000); tamano != TamanoInodo {
+		t.Errorf("despues de formatear: %d bytes", tamano)
+	}
+}
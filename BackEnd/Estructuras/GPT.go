@@ -0,0 +1,386 @@
+package Estructuras
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	Utils "backend/Utils"
+
+	"github.com/google/uuid"
+)
+
+// Constantes de la tabla de particiones GPT
+const (
+	FirmaGPT         = "EFI PART" // Firma del encabezado GPT
+	EntradasGPT      = 128        // Cantidad de entradas en el arreglo de particiones
+	TipoProtectorGPT = 'G'        // Tipo de la entrada del MBR protector en discos GPT
+)
+
+// GUID de tipo para particiones de datos (Linux filesystem data)
+var TipoGuidDatos = uuid.MustParse("0FC63DAF-8483-4772-8E79-3D69D8477DE4")
+
+// Encabezado de la tabla GPT, se ubica justo despues del MBR protector
+type GPTHeader struct {
+	GptFirma            [8]byte  // "EFI PART"
+	GptTamanoDisco      int32    // Capacidad total del disco en bytes
+	GptCreacionDate     float32  // Timestamp de creación de la tabla
+	GptDiskGuid         [16]byte // Identificador único del disco
+	GptDiskFit          [1]byte  // Algoritmo de asignación: BF, FF, WF
+	GptInicioEntradas   int32    // Posición del arreglo de entradas
+	GptNumeroEntradas   int32    // Cantidad de entradas del arreglo
+	GptTamanoEntrada    int32    // Dimensión de cada entrada en bytes
+	GptPrimerByteUsable int32    // Primer byte disponible para particiones
+	GptUltimoByteUsable int32    // Último byte disponible para particiones
+}
+
+// Entrada del arreglo de particiones GPT
+type EntradaGPT struct {
+	Part_type_guid   [16]byte // Tipo de partición (ceros = entrada libre)
+	Part_guid        [16]byte // Identificador único de la partición
+	Part_status      [1]byte  // indica si la partición está activa (1) o inactiva (0)
+	Part_fit         [1]byte  // BF para Best Fit, FF para First Fit, WF para Worst Fit
+	Part_start       int32    // posición inicial de la partición en bytes
+	Part_size        int32    // tamaño de la partición en bytes
+	Part_name        [36]byte // nombre asignado a la partición
+	Part_correlative int32    // número correlativo, se asigna al montar la partición
+	Part_id          [8]byte  // identificador, se asigna al montar la partición
+}
+
+// Tabla GPT completa: encabezado y arreglo de entradas
+type GPT struct {
+	Encabezado GPTHeader
+	Entradas   [EntradasGPT]EntradaGPT
+}
+
+// Espacio libre contiguo dentro de un disco o partición extendida
+type EspacioLibre struct {
+	Inicio int32
+	Tamano int32
+}
+
+// Posición del encabezado GPT dentro del disco
+func InicioEncabezadoGPT() int64 {
+	return int64(binary.Size(MBR{}))
+}
+
+// NuevaGPT construye una tabla GPT vacia para un disco del tamaño indicado
+func NuevaGPT(tamanoDisco int32, ajuste byte) *GPT {
+	gpt := &GPT{}
+	copy(gpt.Encabezado.GptFirma[:], FirmaGPT)
+	gpt.Encabezado.GptTamanoDisco = tamanoDisco
+	gpt.Encabezado.GptCreacionDate = float32(time.Now().Unix())
+	gpt.Encabezado.GptDiskGuid = uuid.New()
+	gpt.Encabezado.GptDiskFit[0] = ajuste
+
+	inicioEntradas := InicioEncabezadoGPT() + int64(binary.Size(GPTHeader{}))
+	tamanoEntrada := int32(binary.Size(EntradaGPT{}))
+	gpt.Encabezado.GptInicioEntradas = int32(inicioEntradas)
+	gpt.Encabezado.GptNumeroEntradas = EntradasGPT
+	gpt.Encabezado.GptTamanoEntrada = tamanoEntrada
+	gpt.Encabezado.GptPrimerByteUsable = int32(inicioEntradas) + EntradasGPT*tamanoEntrada
+	gpt.Encabezado.GptUltimoByteUsable = tamanoDisco - 1
+
+	for i := range gpt.Entradas {
+		gpt.Entradas[i].Part_start = -1
+		gpt.Entradas[i].Part_size = -1
+		gpt.Entradas[i].Part_correlative = -1
+	}
+	return gpt
+}
+
+// NuevoMBRProtector crea el MBR que cubre todo el disco y marca la tabla como GPT
+func NuevoMBRProtector(tamanoDisco int32, firma int32, ajuste byte) *MBR {
+	mbr := &MBR{
+		MbrSize:          tamanoDisco,
+		MbrCreacionDate:  float32(time.Now().Unix()),
+		MbrDiskSignature: firma,
+		MbrDiskFit:       [1]byte{ajuste},
+	}
+	for i := range mbr.MbrPartitions {
+		mbr.MbrPartitions[i] = Particion{Part_status: [1]byte{'0'}, Part_type: [1]byte{'0'}, Part_fit: [1]byte{'0'}, Part_start: -1, Part_size: -1, Part_correlative: -1}
+	}
+
+	protectora := &mbr.MbrPartitions[0]
+	protectora.Part_status[0] = '1'
+	protectora.Part_type[0] = TipoProtectorGPT
+	protectora.Part_fit[0] = ajuste
+	protectora.Part_start = int32(binary.Size(MBR{}))
+	protectora.Part_size = tamanoDisco - protectora.Part_start
+	copy(protectora.Part_name[:], "GPT")
+	return mbr
+}
+
+// EsProtectorGPT indica si el MBR es el protector de un disco GPT
+func (mbr *MBR) EsProtectorGPT() bool {
+	return mbr.MbrPartitions[0].Part_type[0] == TipoProtectorGPT
+}
+
+// EsDiscoGPT lee el MBR del disco y determina si usa tabla GPT
+func EsDiscoGPT(archivo *os.File) (bool, error) {
+	var mbr MBR
+	if err := mbr.Decodificar(archivo); err != nil {
+		return false, err
+	}
+	return mbr.EsProtectorGPT(), nil
+}
+
+// Serializa el encabezado y el arreglo de entradas hacia el archivo
+func (gpt *GPT) Codificar(archivo *os.File) error {
+	if err := Utils.EscribirAArchivo(archivo, InicioEncabezadoGPT(), &gpt.Encabezado); err != nil {
+		return err
+	}
+	return Utils.EscribirAArchivo(archivo, int64(gpt.Encabezado.GptInicioEntradas), &gpt.Entradas)
+}
+
+// Reconstruye la tabla GPT desde el archivo validando su firma
+func (gpt *GPT) Decodificar(archivo *os.File) error {
+	if err := Utils.LeerDeArchivo(archivo, InicioEncabezadoGPT(), &gpt.Encabezado); err != nil {
+		return err
+	}
+	if string(gpt.Encabezado.GptFirma[:]) != FirmaGPT {
+		return errors.New("firma GPT inválida")
+	}
+	return Utils.LeerDeArchivo(archivo, int64(gpt.Encabezado.GptInicioEntradas), &gpt.Entradas)
+}
+
+// Indica si la entrada está ocupada por una partición
+func (e *EntradaGPT) EnUso() bool {
+	return e.Part_type_guid != [16]byte{} && e.Part_start != -1
+}
+
+// Nombre de la entrada sin caracteres nulos
+func (e *EntradaGPT) Nombre() string {
+	return strings.Trim(string(e.Part_name[:]), "\x00 ")
+}
+
+// Limpia la entrada dejandola disponible
+func (e *EntradaGPT) Limpiar() {
+	*e = EntradaGPT{Part_start: -1, Part_size: -1, Part_correlative: -1}
+}
+
+// Monta o desmonta la entrada asignando su correlativo e ID
+func (e *EntradaGPT) MontarParticion(correlativo int, id string) {
+	e.Part_correlative = int32(correlativo)
+	e.Part_id = [8]byte{}
+	copy(e.Part_id[:], id)
+}
+
+// ComoParticion convierte la entrada en una Particion para el resto del sistema. Part_name
+// solo guarda los primeros 16 bytes del nombre, asi que las busquedas por nombre deben
+// comparar contra Nombre() de la entrada y no contra la Particion resultante
+func (e *EntradaGPT) ComoParticion() *Particion {
+	p := &Particion{
+		Part_status:      e.Part_status,
+		Part_type:        [1]byte{'P'},
+		Part_fit:         e.Part_fit,
+		Part_start:       e.Part_start,
+		Part_size:        e.Part_size,
+		Part_correlative: e.Part_correlative,
+	}
+	copy(p.Part_name[:], e.Nombre())
+	copy(p.Part_id[:], strings.Trim(string(e.Part_id[:]), "\x00 "))
+	return p
+}
+
+// Busca una entrada por nombre
+func (gpt *GPT) ObtenerParticionPorNombre(nombre string) (*EntradaGPT, int) {
+	nombreEntrada := strings.Trim(nombre, "\x00 ")
+	for i := range gpt.Entradas {
+		if gpt.Entradas[i].EnUso() && strings.EqualFold(gpt.Entradas[i].Nombre(), nombreEntrada) {
+			return &gpt.Entradas[i], i
+		}
+	}
+	return nil, -1
+}
+
+// Busca una entrada por el ID asignado al montarla
+func (gpt *GPT) ObtenerParticionPorID(id string) (*EntradaGPT, int) {
+	idEntrada := strings.Trim(id, "\x00 ")
+	for i := range gpt.Entradas {
+		idParticion := strings.Trim(string(gpt.Entradas[i].Part_id[:]), "\x00 ")
+		if gpt.Entradas[i].EnUso() && idParticion != "" && strings.EqualFold(idParticion, idEntrada) {
+			return &gpt.Entradas[i], i
+		}
+	}
+	return nil, -1
+}
+
+// EspaciosLibres devuelve los huecos entre particiones, ordenados por posición
+func (gpt *GPT) EspaciosLibres() []EspacioLibre {
+	var ocupadas []EspacioLibre
+	for _, e := range gpt.Entradas {
+		if e.EnUso() {
+			ocupadas = append(ocupadas, EspacioLibre{Inicio: e.Part_start, Tamano: e.Part_size})
+		}
+	}
+	return CalcularEspaciosLibres(ocupadas, gpt.Encabezado.GptPrimerByteUsable, gpt.Encabezado.GptUltimoByteUsable+1)
+}
+
+// CalcularEspaciosLibres obtiene los huecos dentro de [inicio, fin) dados los rangos ocupados
+func CalcularEspaciosLibres(ocupadas []EspacioLibre, inicio, fin int32) []EspacioLibre {
+	sort.Slice(ocupadas, func(i, j int) bool { return ocupadas[i].Inicio < ocupadas[j].Inicio })
+
+	var libres []EspacioLibre
+	cursor := inicio
+	for _, o := range ocupadas {
+		if o.Inicio > cursor {
+			libres = append(libres, EspacioLibre{Inicio: cursor, Tamano: o.Inicio - cursor})
+		}
+		if o.Inicio+o.Tamano > cursor {
+			cursor = o.Inicio + o.Tamano
+		}
+	}
+	if fin > cursor {
+		libres = append(libres, EspacioLibre{Inicio: cursor, Tamano: fin - cursor})
+	}
+	return libres
+}
+
+// SeleccionarEspacio elige un hueco para el tamaño pedido según el ajuste (B, F, W)
+func SeleccionarEspacio(libres []EspacioLibre, tamano int32, ajuste byte) (EspacioLibre, bool) {
+	elegido := -1
+	for i, l := range libres {
+		if l.Tamano < tamano {
+			continue
+		}
+		switch ajuste {
+		case AjusteBF:
+			if elegido == -1 || l.Tamano < libres[elegido].Tamano {
+				elegido = i
+			}
+		case AjusteWF:
+			if elegido == -1 || l.Tamano > libres[elegido].Tamano {
+				elegido = i
+			}
+		default: // First Fit
+			if elegido == -1 {
+				elegido = i
+			}
+		}
+	}
+	if elegido == -1 {
+		return EspacioLibre{}, false
+	}
+	return libres[elegido], true
+}
+
+// CrearParticion ubica una nueva partición aplicando el ajuste del disco
+func (gpt *GPT) CrearParticion(tamano int32, nombre string) (*EntradaGPT, error) {
+	if len(nombre) > len(EntradaGPT{}.Part_name) {
+		return nil, fmt.Errorf("el nombre '%s' excede %d caracteres", nombre, len(EntradaGPT{}.Part_name))
+	}
+	if existente, _ := gpt.ObtenerParticionPorNombre(nombre); existente != nil {
+		return nil, fmt.Errorf("ya existe una partición con el nombre '%s'", nombre)
+	}
+
+	var libre *EntradaGPT
+	for i := range gpt.Entradas {
+		if !gpt.Entradas[i].EnUso() {
+			libre = &gpt.Entradas[i]
+			break
+		}
+	}
+	if libre == nil {
+		return nil, fmt.Errorf("la tabla GPT no tiene entradas disponibles (%d en uso)", EntradasGPT)
+	}
+
+	ajuste := gpt.Encabezado.GptDiskFit[0]
+	espacio, ok := SeleccionarEspacio(gpt.EspaciosLibres(), tamano, ajuste)
+	if !ok {
+		return nil, fmt.Errorf("no hay suficiente espacio contiguo en el disco para %d bytes", tamano)
+	}
+
+	libre.Part_type_guid = TipoGuidDatos
+	libre.Part_guid = uuid.New()
+	libre.Part_status[0] = '1'
+	libre.Part_fit[0] = ajuste
+	libre.Part_start = espacio.Inicio
+	libre.Part_size = tamano
+	libre.Part_name = [36]byte{}
+	copy(libre.Part_name[:], nombre)
+	libre.Part_correlative = -1
+	libre.Part_id = [8]byte{}
+
+	fmt.Printf("Partición GPT '%s' creada en %d con %d bytes (ajuste '%c').\n", nombre, espacio.Inicio, tamano, ajuste)
+	return libre, nil
+}
+
+// EspacioDisponibleParaParticion calcula los bytes libres a continuación de la entrada
+func (gpt *GPT) EspacioDisponibleParaParticion(entrada *EntradaGPT) int32 {
+	fin := entrada.Part_start + entrada.Part_size
+	limite := gpt.Encabezado.GptUltimoByteUsable + 1
+	for _, e := range gpt.Entradas {
+		if e.EnUso() && e.Part_start >= fin && e.Part_start < limite {
+			limite = e.Part_start
+		}
+	}
+	return limite - fin
+}
+
+// BuscarParticionPorID localiza una partición por ID en la tabla del disco (MBR o GPT)
+func BuscarParticionPorID(archivo *os.File, id string) (*Particion, error) {
+	var mbr MBR
+	if err := mbr.Decodificar(archivo); err != nil {
+		return nil, err
+	}
+	if !mbr.EsProtectorGPT() {
+		return mbr.ObtenerParticionPorID(id)
+	}
+
+	var gpt GPT
+	if err := gpt.Decodificar(archivo); err != nil {
+		return nil, err
+	}
+	entrada, _ := gpt.ObtenerParticionPorID(id)
+	if entrada == nil {
+		return nil, errors.New("partición con ID especificado no encontrada")
+	}
+	return entrada.ComoParticion(), nil
+}
+
+// BuscarParticionPorNombre localiza una partición por nombre en la tabla del disco (MBR o GPT)
+func BuscarParticionPorNombre(archivo *os.File, nombre string) (*Particion, error) {
+	var mbr MBR
+	if err := mbr.Decodificar(archivo); err != nil {
+		return nil, err
+	}
+	if !mbr.EsProtectorGPT() {
+		particion, _ := mbr.ObtenerParticionPorNombre(nombre)
+		if particion == nil {
+			return nil, fmt.Errorf("la partición '%s' no existe", nombre)
+		}
+		return particion, nil
+	}
+
+	var gpt GPT
+	if err := gpt.Decodificar(archivo); err != nil {
+		return nil, err
+	}
+	entrada, _ := gpt.ObtenerParticionPorNombre(nombre)
+	if entrada == nil {
+		return nil, fmt.Errorf("la partición '%s' no existe", nombre)
+	}
+	return entrada.ComoParticion(), nil
+}
+
+// Imprimir despliega el encabezado y las entradas en uso de la tabla GPT
+func (gpt *GPT) Imprimir() {
+	guidDisco, _ := uuid.FromBytes(gpt.Encabezado.GptDiskGuid[:])
+	fmt.Printf("═══ GUID PARTITION TABLE ═══\n")
+	fmt.Printf("Capacidad: %d bytes | GUID: %s | Ajuste: %c\n",
+		gpt.Encabezado.GptTamanoDisco, guidDisco, gpt.Encabezado.GptDiskFit[0])
+	for i, e := range gpt.Entradas {
+		if !e.EnUso() {
+			continue
+		}
+		guid, _ := uuid.FromBytes(e.Part_guid[:])
+		fmt.Printf("│ Entrada %d │ %s │ Inicio:%d │ Dimensión:%d │ GUID:%s │ ID:%s │\n",
+			i+1, e.Nombre(), e.Part_start, e.Part_size, guid, strings.Trim(string(e.Part_id[:]), "\x00"))
+	}
+	fmt.Printf("═══════════════════════════\n")
+}
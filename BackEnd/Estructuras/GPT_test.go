@@ -0,0 +1,71 @@
+package Estructuras
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// crearDiscoGPT deja en un archivo temporal un disco GPT con una particion por nombre
+func crearDiscoGPT(t *testing.T, tamano int32, nombres ...string) *os.File {
+	t.Helper()
+	archivo, err := os.Create(filepath.Join(t.TempDir(), "gpt.mia"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { archivo.Close() })
+	if err := archivo.Truncate(int64(tamano)); err != nil {
+		t.Fatal(err)
+	}
+
+	gpt := NuevaGPT(tamano, 'F')
+	for _, nombre := range nombres {
+		if _, err := gpt.CrearParticion(1024, nombre); err != nil {
+			t.Fatalf("CrearParticion(%q): %v", nombre, err)
+		}
+	}
+	if err := NuevoMBRProtector(tamano, 1, 'F').Codificar(archivo); err != nil {
+		t.Fatal(err)
+	}
+	if err := gpt.Codificar(archivo); err != nil {
+		t.Fatal(err)
+	}
+	return archivo
+}
+
+func TestBuscarParticionPorNombreGPTConNombresLargos(t *testing.T) {
+	// Los tres comparten los primeros 16 bytes, que es lo que cabe en Particion.Part_name
+	nombres := []string{
+		"particion_de_16b",
+		"particion_de_16b_y_mas",
+		strings.Repeat("x", 36),
+	}
+	archivo := crearDiscoGPT(t, 1024*1024, nombres...)
+
+	var gpt GPT
+	if err := gpt.Decodificar(archivo); err != nil {
+		t.Fatal(err)
+	}
+	for _, nombre := range nombres {
+		entrada, _ := gpt.ObtenerParticionPorNombre(nombre)
+		if entrada == nil {
+			t.Fatalf("la entrada %q no esta en la tabla", nombre)
+		}
+		particion, err := BuscarParticionPorNombre(archivo, nombre)
+		if err != nil {
+			t.Fatalf("BuscarParticionPorNombre(%q): %v", nombre, err)
+		}
+		if particion.Part_start != entrada.Part_start {
+			t.Errorf("BuscarParticionPorNombre(%q) retorno la particion en %d, se esperaba %d",
+				nombre, particion.Part_start, entrada.Part_start)
+		}
+	}
+
+	if _, err := BuscarParticionPorNombre(archivo, "particion_de_16b_y"); err == nil {
+		t.Errorf("un prefijo del nombre no debe encontrar la particion")
+	}
+	if _, err := gpt.CrearParticion(1024, strings.Repeat("x", 37)); err == nil {
+		t.Errorf("CrearParticion acepto un nombre de 37 bytes")
+	}
+}
@@ -0,0 +1,329 @@
+package Estructuras
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/bits"
+	"os"
+
+	Utils "backend/Utils"
+)
+
+// Grupos de bloques al estilo ext2 (mkfs -groups). Los bloques siguen numerados de forma
+// continua desde S_block_start, pero se reparten en grupos de S_blocks_per_group bloques
+// y los primeros bloques de cada grupo guardan sus metadatos: la copia de respaldo del
+// superbloque y de los descriptores (solo en algunos grupos), el bitmap de inodos, el
+// bitmap de bloques y la tabla de inodos del grupo. Esos bloques quedan ocupados en el
+// bitmap, asi que los indices de inodos y bloques siguen siendo globales y solo cambia
+// donde esta cada inodo y cada byte de los bitmaps
+
+// DescriptorGrupo es la entrada de un grupo en la tabla de descriptores, que va justo
+// despues del superbloque. Las posiciones son indices de bloque para que la tabla no
+// cambie si la particion se mueve dentro del disco
+type DescriptorGrupo struct {
+	G_block_bitmap      int32  /*  Bloque con el bitmap de bloques del grupo  */
+	G_inode_bitmap      int32  /*  Bloque con el bitmap de inodos del grupo  */
+	G_inode_table       int32  /*  Primer bloque de la tabla de inodos del grupo  */
+	G_free_blocks_count int32  /*  Bloques libres en el grupo  */
+	G_free_inodes_count int32  /*  Inodos libres en el grupo  */
+	G_checksum          uint32 /*  CRC32 de los campos anteriores  */
+}
+
+// DisposicionGrupo ubica los metadatos de un grupo, en indices de bloque
+type DisposicionGrupo struct {
+	PrimerBloque  int32
+	Bloques       int32 // bloques del grupo; el ultimo grupo puede ser mas corto
+	Respaldo      bool  // guarda una copia del superbloque y de los descriptores
+	BitmapInodos  int32
+	BitmapBloques int32
+	TablaInodos   int32
+	PrimerDato    int32 // primer bloque que no es de metadatos
+}
+
+// GrupoConRespaldo indica si el grupo guarda copia del superbloque: como en ext2 con
+// sparse_super, el grupo 1 y los que son potencia de 3, 5 o 7. El grupo 0 usa el principal
+func GrupoConRespaldo(grupo int32) bool {
+	if grupo <= 0 {
+		return false
+	}
+	return grupo == 1 || esPotenciaDe(grupo, 3) || esPotenciaDe(grupo, 5) || esPotenciaDe(grupo, 7)
+}
+
+func esPotenciaDe(numero, base int32) bool {
+	for numero > 1 && numero%base == 0 {
+		numero /= base
+	}
+	return numero == 1
+}
+
+// BloquesParaBytes devuelve cuantos bloques de tamanoBloque hacen falta para 'cantidad' bytes
+func BloquesParaBytes(cantidad, tamanoBloque int32) int32 {
+	return (cantidad + tamanoBloque - 1) / tamanoBloque
+}
+
+// TamanoDescriptores devuelve los bytes que ocupa la tabla de descriptores
+func (sb *SuperBlock) TamanoDescriptores() int32 {
+	return sb.S_groups_count * int32(binary.Size(DescriptorGrupo{}))
+}
+
+// DisposicionGrupo calcula donde estan los metadatos del grupo indicado. Solo depende de
+// la geometria del superbloque, igual que en ext2 con bloques de tamano fijo
+func (sb *SuperBlock) DisposicionGrupo(grupo int32) DisposicionGrupo {
+	disposicion := DisposicionGrupo{
+		PrimerBloque: grupo * sb.S_blocks_per_group,
+		Bloques:      sb.S_blocks_per_group,
+		Respaldo:     GrupoConRespaldo(grupo),
+	}
+	total := sb.S_blocks_count + sb.S_free_blocks_count
+	if resto := total - disposicion.PrimerBloque; resto < disposicion.Bloques {
+		disposicion.Bloques = resto
+	}
+
+	siguiente := disposicion.PrimerBloque
+	if disposicion.Respaldo {
+		siguiente += BloquesParaBytes(int32(binary.Size(SuperBlock{}))+sb.TamanoDescriptores(), sb.S_block_size)
+	}
+	disposicion.BitmapInodos = siguiente
+	siguiente += BloquesParaBytes(sb.S_inodes_per_group/8, sb.S_block_size)
+	disposicion.BitmapBloques = siguiente
+	siguiente += BloquesParaBytes(sb.S_blocks_per_group/8, sb.S_block_size)
+	disposicion.TablaInodos = siguiente
+	siguiente += BloquesParaBytes(sb.S_inodes_per_group*sb.S_inode_size, sb.S_block_size)
+	disposicion.PrimerDato = siguiente
+	return disposicion
+}
+
+// desplazamientoBloque devuelve el byte donde empieza el bloque indicado
+func (sb *SuperBlock) desplazamientoBloque(indiceBloque int32) int64 {
+	return int64(sb.S_block_start) + int64(indiceBloque)*int64(sb.S_block_size)
+}
+
+// GrupoDeInodo devuelve el grupo al que pertenece el inodo (0 sin grupos)
+func (sb *SuperBlock) GrupoDeInodo(indiceInodo int32) int32 {
+	if sb.S_groups_count == 0 {
+		return 0
+	}
+	return indiceInodo / sb.S_inodes_per_group
+}
+
+// GrupoDeBloque devuelve el grupo al que pertenece el bloque (0 sin grupos)
+func (sb *SuperBlock) GrupoDeBloque(indiceBloque int32) int32 {
+	if sb.S_groups_count == 0 {
+		return 0
+	}
+	return indiceBloque / sb.S_blocks_per_group
+}
+
+// ReservarMetadatosGrupos marca como ocupados los bloques de metadatos de cada grupo y
+// los descuenta de los bloques libres. Se usa al formatear, antes de crear la raiz
+func (sb *SuperBlock) ReservarMetadatosGrupos(archivo *os.File) error {
+	bitmap, err := cargarBitmap(archivo, sb.ubicacionBitmap(sb.S_bm_block_start))
+	if err != nil {
+		return err
+	}
+	for grupo := int32(0); grupo < sb.S_groups_count; grupo++ {
+		disposicion := sb.DisposicionGrupo(grupo)
+		for bloque := disposicion.PrimerBloque; bloque < disposicion.PrimerDato; bloque++ {
+			if err := bitmap.fijar(bloque, true); err != nil {
+				return fmt.Errorf("error reservando los metadatos del grupo %d: %w", grupo, err)
+			}
+			sb.S_blocks_count++
+			sb.S_free_blocks_count--
+		}
+	}
+	_, err = bitmap.escribir(archivo)
+	return err
+}
+
+// descriptoresDesdeBitmaps arma la tabla de descriptores contando los bits libres de
+// cada grupo en los bitmaps (con el formato del disco)
+func (sb *SuperBlock) descriptoresDesdeBitmaps(bitmapInodos, bitmapBloques []byte) []DescriptorGrupo {
+	descriptores := make([]DescriptorGrupo, sb.S_groups_count)
+	bytesInodos := sb.S_inodes_per_group / 8
+	for grupo := range descriptores {
+		disposicion := sb.DisposicionGrupo(int32(grupo))
+		inodos := bitmapInodos[int32(grupo)*bytesInodos : int32(grupo+1)*bytesInodos]
+		bloques := bitmapBloques[disposicion.PrimerBloque/8 : (disposicion.PrimerBloque+disposicion.Bloques)/8]
+		descriptores[grupo] = DescriptorGrupo{
+			G_block_bitmap:      disposicion.BitmapBloques,
+			G_inode_bitmap:      disposicion.BitmapInodos,
+			G_inode_table:       disposicion.TablaInodos,
+			G_free_blocks_count: bitsLibres(bloques),
+			G_free_inodes_count: bitsLibres(inodos),
+		}
+		descriptores[grupo].G_checksum, _ = calcularChecksum(&descriptores[grupo])
+	}
+	return descriptores
+}
+
+func bitsLibres(datos []byte) int32 {
+	libres := 0
+	for _, valor := range datos {
+		libres += 8 - bits.OnesCount8(valor)
+	}
+	return int32(libres)
+}
+
+// LeerDescriptoresGrupo lee la tabla de descriptores principal y valida cada entrada
+func (sb *SuperBlock) LeerDescriptoresGrupo(archivo *os.File) ([]DescriptorGrupo, error) {
+	descriptores := make([]DescriptorGrupo, sb.S_groups_count)
+	if err := Utils.LeerDeArchivo(archivo, int64(sb.S_gdt_start), descriptores); err != nil {
+		return nil, fmt.Errorf("error leyendo los descriptores de grupo: %w", err)
+	}
+	tamano := int64(binary.Size(DescriptorGrupo{}))
+	for grupo := range descriptores {
+		posicion := int64(sb.S_gdt_start) + int64(grupo)*tamano
+		if err := verificarChecksum("DescriptorGrupo", posicion, &descriptores[grupo], descriptores[grupo].G_checksum); err != nil {
+			return nil, err
+		}
+	}
+	return descriptores, nil
+}
+
+// EscribirDescriptoresGrupo recalcula los libres de cada grupo desde los bitmaps y
+// reescribe la tabla de descriptores principal
+func (sb *SuperBlock) EscribirDescriptoresGrupo(archivo *os.File) error {
+	descriptores, err := sb.calcularDescriptores(archivo)
+	if err != nil {
+		return err
+	}
+	if err := Utils.EscribirAArchivo(archivo, int64(sb.S_gdt_start), descriptores); err != nil {
+		return fmt.Errorf("error escribiendo los descriptores de grupo: %w", err)
+	}
+	return nil
+}
+
+// EscribirRespaldosGrupos copia el superbloque y la tabla de descriptores al inicio de
+// cada grupo con respaldo. Se hace al formatear; si el principal se daña las copias
+// conservan la geometria del sistema de archivos
+func (sb *SuperBlock) EscribirRespaldosGrupos(archivo *os.File) error {
+	descriptores, err := sb.calcularDescriptores(archivo)
+	if err != nil {
+		return err
+	}
+	copia := *sb
+	for grupo := int32(1); grupo < sb.S_groups_count; grupo++ {
+		if !GrupoConRespaldo(grupo) {
+			continue
+		}
+		posicion := sb.desplazamientoBloque(sb.DisposicionGrupo(grupo).PrimerBloque)
+		if err := copia.Codificar(archivo, posicion); err != nil {
+			return fmt.Errorf("error escribiendo el superbloque de respaldo del grupo %d: %w", grupo, err)
+		}
+		posicion += int64(binary.Size(SuperBlock{}))
+		if err := Utils.EscribirAArchivo(archivo, posicion, descriptores); err != nil {
+			return fmt.Errorf("error escribiendo los descriptores de respaldo del grupo %d: %w", grupo, err)
+		}
+	}
+	return nil
+}
+
+// GruposConRespaldo devuelve los grupos que guardan copia del superbloque
+func (sb *SuperBlock) GruposConRespaldo() []int32 {
+	var grupos []int32
+	for grupo := int32(1); grupo < sb.S_groups_count; grupo++ {
+		if GrupoConRespaldo(grupo) {
+			grupos = append(grupos, grupo)
+		}
+	}
+	return grupos
+}
+
+// LeerRespaldoGrupo lee el superbloque de respaldo guardado en el grupo indicado
+func (sb *SuperBlock) LeerRespaldoGrupo(archivo *os.File, grupo int32) (*SuperBlock, error) {
+	respaldo := &SuperBlock{}
+	posicion := sb.desplazamientoBloque(sb.DisposicionGrupo(grupo).PrimerBloque)
+	if err := respaldo.Decodificar(archivo, posicion); err != nil {
+		return nil, err
+	}
+	return respaldo, nil
+}
+
+func (sb *SuperBlock) calcularDescriptores(archivo *os.File) ([]DescriptorGrupo, error) {
+	bitmapInodos, err := sb.LeerBitmapInodos(archivo)
+	if err != nil {
+		return nil, err
+	}
+	bitmapBloques, err := sb.LeerBitmapBloques(archivo)
+	if err != nil {
+		return nil, err
+	}
+	return sb.descriptoresDesdeBitmaps(bitmapInodos, bitmapBloques), nil
+}
+
+// actualizarDescriptores reescribe la tabla de descriptores con los bitmaps en memoria
+// al sincronizar; se llama con el mutex tomado y solo si algun bitmap cambio
+func (c *CacheParticion) actualizarDescriptores() error {
+	var sb SuperBlock
+	if err := sb.Decodificar(c.archivo, int64(c.Particion.Part_start)); err != nil || sb.S_groups_count == 0 {
+		return nil
+	}
+	inodos, bloques := c.bitmaps[sb.S_bm_inode_start], c.bitmaps[sb.S_bm_block_start]
+	if inodos == nil || bloques == nil {
+		return nil
+	}
+	descriptores := sb.descriptoresDesdeBitmaps(inodos.bytes(), bloques.bytes())
+	if err := Utils.EscribirAArchivo(c.archivo, int64(sb.S_gdt_start), descriptores); err != nil {
+		return fmt.Errorf("error escribiendo los descriptores de grupo: %w", err)
+	}
+	return nil
+}
+
+// BuscarInodoLibreParaCarpeta ocupa un inodo para una carpeta nueva. Con grupos reparte
+// las carpetas como ext2: entre los grupos con al menos el promedio de inodos libres
+// elige el que tiene mas bloques libres, y busca el inodo desde el inicio de ese grupo
+func (sb *SuperBlock) BuscarInodoLibreParaCarpeta(archivo *os.File) (int32, error) {
+	if sb.S_groups_count == 0 {
+		return sb.BuscarSiguienteInodoLibre(archivo)
+	}
+	descriptores, err := sb.calcularDescriptores(archivo)
+	if err != nil {
+		return -1, fmt.Errorf("error calculando los libres por grupo: %w", err)
+	}
+
+	var totalInodosLibres int64
+	for _, descriptor := range descriptores {
+		totalInodosLibres += int64(descriptor.G_free_inodes_count)
+	}
+	promedio := totalInodosLibres / int64(len(descriptores))
+
+	elegido := int32(-1)
+	for grupo, descriptor := range descriptores {
+		if descriptor.G_free_inodes_count == 0 || int64(descriptor.G_free_inodes_count) < promedio {
+			continue
+		}
+		if elegido == -1 || descriptor.G_free_blocks_count > descriptores[elegido].G_free_blocks_count {
+			elegido = int32(grupo)
+		}
+	}
+	if elegido == -1 {
+		// Sin inodos libres la busqueda desde el grupo 0 devuelve el error de siempre
+		elegido = 0
+	}
+	return sb.buscarInodoLibreDesde(archivo, elegido*sb.S_inodes_per_group)
+}
+
+// BuscarInodoLibreCercaDe ocupa un inodo buscando desde el grupo del inodo indicado, para
+// que los archivos queden en el mismo grupo que su carpeta
+func (sb *SuperBlock) BuscarInodoLibreCercaDe(archivo *os.File, indiceInodo int32) (int32, error) {
+	if sb.S_groups_count == 0 {
+		return sb.BuscarSiguienteInodoLibre(archivo)
+	}
+	return sb.buscarInodoLibreDesde(archivo, sb.GrupoDeInodo(indiceInodo)*sb.S_inodes_per_group)
+}
+
+// BloqueReferenciaCarpeta indica despues de que bloque buscar el primer bloque de una
+// carpeta nueva: con grupos al inicio de los datos del grupo de su inodo, sin grupos
+// junto a los bloques de la carpeta padre
+func (sb *SuperBlock) BloqueReferenciaCarpeta(indiceInodo int32, padre *INodo) int32 {
+	if sb.S_groups_count == 0 {
+		return padre.UltimoBloqueReferenciado()
+	}
+	return sb.DisposicionGrupo(sb.GrupoDeInodo(indiceInodo)).PrimerDato - 1
+}
+
+// DesplazamientoEnBitmap devuelve el byte del disco que guarda la posicion indicada del
+// bitmap que empieza en 'inicio'; con grupos puede estar en el bitmap de otro grupo
+func (sb *SuperBlock) DesplazamientoEnBitmap(inicio int32, posicion int32) int64 {
+	return sb.ubicacionBitmap(inicio).desplazamiento(posicion / 8)
+}
@@ -0,0 +1,102 @@
+package Estructuras
+
+import (
+	"encoding/binary"
+	"reflect"
+	"testing"
+
+	Utils "backend/Utils"
+)
+
+// superBloqueConGrupos arma la geometria de un sistema de archivos de 4 grupos de 256
+// bloques de 64 bytes, con el ultimo grupo mas corto, como la deja mkfs -groups
+func superBloqueConGrupos() *SuperBlock {
+	sb := &SuperBlock{
+		S_filesystem_type:   2,
+		S_free_inodes_count: 4 * 64,
+		S_free_blocks_count: 900,
+		S_magic:             0xEF53,
+		S_inode_size:        TamanoInodo,
+		S_block_size:        64,
+		S_gdt_start:         1024 - 4*24,
+		S_block_start:       1024,
+		S_groups_count:      4,
+		S_blocks_per_group:  256,
+		S_inodes_per_group:  64,
+	}
+	grupo0 := sb.DisposicionGrupo(0)
+	sb.S_bm_inode_start = sb.S_block_start + grupo0.BitmapInodos*sb.S_block_size
+	sb.S_bm_block_start = sb.S_block_start + grupo0.BitmapBloques*sb.S_block_size
+	sb.S_inode_start = sb.S_block_start + grupo0.TablaInodos*sb.S_block_size
+	return sb
+}
+
+func TestGrupoConRespaldo(t *testing.T) {
+	var grupos []int32
+	for grupo := int32(-1); grupo <= 130; grupo++ {
+		if GrupoConRespaldo(grupo) {
+			grupos = append(grupos, grupo)
+		}
+	}
+	esperados := []int32{1, 3, 5, 7, 9, 25, 27, 49, 81, 125}
+	if !reflect.DeepEqual(grupos, esperados) {
+		t.Errorf("grupos con respaldo %v, se esperaba %v", grupos, esperados)
+	}
+}
+
+func TestDisposicionGrupo(t *testing.T) {
+	sb := superBloqueConGrupos()
+	// Respaldo: 96 bytes de superbloque y 4*24 de descriptores en 3 bloques; bitmaps de
+	// un bloque cada uno; tabla de 64 inodos de 108 bytes en 108 bloques
+	casos := []DisposicionGrupo{
+		{PrimerBloque: 0, Bloques: 256, Respaldo: false, BitmapInodos: 0, BitmapBloques: 1, TablaInodos: 2, PrimerDato: 110},
+		{PrimerBloque: 256, Bloques: 256, Respaldo: true, BitmapInodos: 259, BitmapBloques: 260, TablaInodos: 261, PrimerDato: 369},
+		{PrimerBloque: 512, Bloques: 256, Respaldo: false, BitmapInodos: 512, BitmapBloques: 513, TablaInodos: 514, PrimerDato: 622},
+		{PrimerBloque: 768, Bloques: 132, Respaldo: true, BitmapInodos: 771, BitmapBloques: 772, TablaInodos: 773, PrimerDato: 881},
+	}
+	for grupo, esperada := range casos {
+		if disposicion := sb.DisposicionGrupo(int32(grupo)); disposicion != esperada {
+			t.Errorf("grupo %d: %+v, se esperaba %+v", grupo, disposicion, esperada)
+		}
+	}
+	if grupos := sb.GruposConRespaldo(); !reflect.DeepEqual(grupos, []int32{1, 3}) {
+		t.Errorf("GruposConRespaldo() = %v", grupos)
+	}
+}
+
+func TestEscribirRespaldosGrupos(t *testing.T) {
+	sb := superBloqueConGrupos()
+	archivo := discoTemporal(t, sb.desplazamientoBloque(900))
+	if err := sb.EscribirRespaldosGrupos(archivo); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, grupo := range []int32{1, 3} {
+		respaldo, err := sb.LeerRespaldoGrupo(archivo, grupo)
+		if err != nil {
+			t.Fatalf("grupo %d: %v", grupo, err)
+		}
+		respaldo.S_checksum = sb.S_checksum
+		if *respaldo != *sb {
+			t.Errorf("grupo %d: respaldo %+v, se esperaba %+v", grupo, respaldo, sb)
+		}
+
+		// La copia de los descriptores va despues del superbloque de respaldo
+		descriptores := make([]DescriptorGrupo, sb.S_groups_count)
+		posicion := sb.desplazamientoBloque(sb.DisposicionGrupo(grupo).PrimerBloque) + int64(binary.Size(SuperBlock{}))
+		if err := Utils.LeerDeArchivo(archivo, posicion, descriptores); err != nil {
+			t.Fatal(err)
+		}
+		for i, descriptor := range descriptores {
+			disposicion := sb.DisposicionGrupo(int32(i))
+			if descriptor.G_inode_table != disposicion.TablaInodos || descriptor.G_free_inodes_count != sb.S_inodes_per_group {
+				t.Errorf("grupo %d: descriptor %d de respaldo %+v", grupo, i, descriptor)
+			}
+		}
+	}
+	for _, grupo := range []int32{2} {
+		if _, err := sb.LeerRespaldoGrupo(archivo, grupo); err == nil {
+			t.Errorf("el grupo %d no deberia tener respaldo", grupo)
+		}
+	}
+}
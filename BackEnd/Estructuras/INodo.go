@@ -1,6 +1,7 @@
 package Estructuras
 
 import (
+	"encoding/binary"
 	"fmt"
 	"os"
 	"time"
@@ -10,40 +11,84 @@ import (
 
 type INodo struct {
 
-	/* 88 bytes */
+	/* 108 bytes */
 
 	I_uid   int32     /* UID del usuario propietario del archivo */
 	I_gid   int32     /* GID del grupo propietario del archivo */
 	I_size  int32     /* Tamaño del archivo en bytes */
-	I_atime float32   /* Último acceso al archivo */
-	I_ctime float32   /* Último cambio de permisos */
-	I_mtime float32   /* Última modificación del archivo */
-	I_type  [1]byte   /* Indica si es archivo o carpeta 1=archivo, 0=carpeta */
+	I_links int32     /* Cantidad de entradas de directorio que nombran al inodo (sin contar . y ..) */
+	I_atime int64     /* Último acceso al archivo (nanosegundos Unix) */
+	I_ctime int64     /* Último cambio de permisos o metadatos (nanosegundos Unix) */
+	I_mtime int64     /* Última modificación del contenido (nanosegundos Unix) */
+	I_type  [1]byte   /* Indica el tipo: 0=carpeta, 1=archivo, 2=enlace simbolico */
 	I_perm  [3]byte   /* Guarda los permisos del archivo */
 	I_block [15]int32 /* 12 bloques directos, 1 indirecto simple, 1 indirecto doble, 1 indirecto triple */
+	I_xattr int32     /* Bloque con los atributos extendidos, -1 si no tiene */
 }
 
-func (inodo *INodo) Codificar(archivo *os.File, desplazamiento int64) error {
-	err := Utils.EscribirAArchivo(archivo, desplazamiento, inodo)
-	if err != nil {
-		return fmt.Errorf("error escribiendo INodo al archivo: %w", err)
+// TamanoInodo es la dimension serializada del inodo en el formato actual. Los sistemas
+// formateados antes con la version original guardan inodos de 88 bytes (ver FormatoInodo.go)
+var TamanoInodo = int32(binary.Size(INodo{}))
+
+// MarcaTiempoActual retorna el instante actual con la precision que guardan los inodos
+func MarcaTiempoActual() int64 {
+	return time.Now().UnixNano()
+}
+
+// FechaDeMarca convierte una marca de tiempo de un inodo a time.Time
+func FechaDeMarca(marca int64) time.Time {
+	return time.Unix(0, marca)
+}
+
+// ValidarFormatoInodos rechaza los sistemas de archivos cuyos inodos no tienen ninguno de
+// los formatos conocidos
+func (sb *SuperBlock) ValidarFormatoInodos() error {
+	if !FormatoInodoConocido(sb.S_inode_size) {
+		return fmt.Errorf("el sistema de archivos usa inodos de %d bytes, que no corresponden a ningun formato conocido", sb.S_inode_size)
 	}
 	return nil
 }
 
+// Codificar guarda el inodo; si la particion esta montada queda en su cache hasta sincronizar
+func (inodo *INodo) Codificar(archivo *os.File, desplazamiento int64) error {
+	if cache := cacheDe(archivo, desplazamiento); cache != nil {
+		return cache.escribirInodo(desplazamiento, inodo)
+	}
+	return inodo.codificarDirecto(archivo, desplazamiento, tamanoInodoEn(archivo, desplazamiento))
+}
+
+// Decodificar lee el inodo desde la cache de la particion montada o desde el disco
 func (inodo *INodo) Decodificar(archivo *os.File, desplazamiento int64) error {
-	err := Utils.LeerDeArchivo(archivo, desplazamiento, inodo)
+	if cache := cacheDe(archivo, desplazamiento); cache != nil {
+		return cache.leerInodo(desplazamiento, inodo)
+	}
+	return inodo.decodificarDirecto(archivo, desplazamiento, tamanoInodoEn(archivo, desplazamiento))
+}
+
+// codificarDirecto escribe el inodo con el formato de 'tamano' bytes del sistema de
+// archivos que lo contiene
+func (inodo *INodo) codificarDirecto(archivo *os.File, desplazamiento int64, tamano int32) error {
+	datos, err := inodo.aFormato(tamano)
 	if err != nil {
-		return fmt.Errorf("error leyendo INodo desde archivo: %w", err)
+		return fmt.Errorf("error escribiendo INodo al archivo: %w", err)
+	}
+	if err := Utils.EscribirAArchivo(archivo, desplazamiento, datos); err != nil {
+		return fmt.Errorf("error escribiendo INodo al archivo: %w", err)
 	}
 	return nil
 }
 
+// decodificarDirecto lee el inodo con el formato de 'tamano' bytes del sistema de archivos
+// que lo contiene
+func (inodo *INodo) decodificarDirecto(archivo *os.File, desplazamiento int64, tamano int32) error {
+	return inodo.DecodificarEnDisco(archivo, desplazamiento, tamano)
+}
+
 // CrearInodo - Crear y serializar un inodo, actualizando el bitmap de inodos
 func (inodo *INodo) CrearInodo(
     archivo *os.File,
     sb *SuperBlock,
-    tipoInodo byte, // Tipo de inodo (0 para directorio, 1 para archivo)
+    tipoInodo byte, // Tipo de inodo (0 para directorio, 1 para archivo, 2 para enlace)
     tamaño int32,
     bloques [15]int32,
     permisos [3]byte,
@@ -53,13 +98,15 @@ func (inodo *INodo) CrearInodo(
         return fmt.Errorf("error asignando nuevo inodo: %w", err)
     }
 
-    inodo.I_uid = 1
-    inodo.I_gid = 1
+    inodo.I_uid, inodo.I_gid = sb.propietarioNuevoInodo(archivo)
     inodo.I_size = tamaño
-    inodo.I_atime = float32(time.Now().Unix())
-    inodo.I_ctime = float32(time.Now().Unix())
-    inodo.I_mtime = float32(time.Now().Unix())
+    inodo.I_links = 1
+    ahora := MarcaTiempoActual()
+    inodo.I_atime = ahora
+    inodo.I_ctime = ahora
+    inodo.I_mtime = ahora
     inodo.I_block = bloques
+    inodo.I_xattr = -1
     inodo.I_type = [1]byte{tipoInodo}
     inodo.I_perm = permisos
 
@@ -68,7 +115,7 @@ func (inodo *INodo) CrearInodo(
         return fmt.Errorf("error actualizando el bitmap de inodos: %w", err)
     }
 
-    desplazamientoInodo := int64(sb.S_inode_start + (indiceInodo * sb.S_inode_size))
+    desplazamientoInodo := sb.CalcularDesplazamientoInodo(indiceInodo)
     err = inodo.Codificar(archivo, desplazamientoInodo)
     if err != nil {
         return fmt.Errorf("error serializando el inodo en la posición %d: %w", desplazamientoInodo, err)
@@ -78,29 +125,30 @@ func (inodo *INodo) CrearInodo(
 }
 
 func (inodo *INodo) ActualizarTiempoAcceso() {
-	inodo.I_atime = float32(time.Now().Unix())
+	inodo.I_atime = MarcaTiempoActual()
 }
 
 func (inodo *INodo) ActualizarTiempoModificacion() {
-	inodo.I_mtime = float32(time.Now().Unix())
+	inodo.I_mtime = MarcaTiempoActual()
 }
 
 func (inodo *INodo) ActualizarTiempoPermisos() {
-	inodo.I_ctime = float32(time.Now().Unix())
+	inodo.I_ctime = MarcaTiempoActual()
 }
 
 // Imprimir atributos del inodo
 func (inodo *INodo) Imprimir() {
-	tiempoAcceso := time.Unix(int64(inodo.I_atime), 0)
-	tiempoPermisos := time.Unix(int64(inodo.I_ctime), 0)
-	tiempoModificacion := time.Unix(int64(inodo.I_mtime), 0)
+	tiempoAcceso := FechaDeMarca(inodo.I_atime)
+	tiempoPermisos := FechaDeMarca(inodo.I_ctime)
+	tiempoModificacion := FechaDeMarca(inodo.I_mtime)
 
 	fmt.Printf("UID propietario: %d\n", inodo.I_uid)
 	fmt.Printf("GID grupo: %d\n", inodo.I_gid)
 	fmt.Printf("Dimension archivo: %d bytes\n", inodo.I_size)
-	fmt.Printf("Ultimo acceso: %s\n", tiempoAcceso.Format(time.RFC3339))
-	fmt.Printf("Ultimo cambio de permisos: %s\n", tiempoPermisos.Format(time.RFC3339))
-	fmt.Printf("Ultima modificacion: %s\n", tiempoModificacion.Format(time.RFC3339))
+	fmt.Printf("Enlaces: %d\n", inodo.I_links)
+	fmt.Printf("Ultimo acceso: %s\n", tiempoAcceso.Format(time.RFC3339Nano))
+	fmt.Printf("Ultimo cambio de permisos: %s\n", tiempoPermisos.Format(time.RFC3339Nano))
+	fmt.Printf("Ultima modificacion: %s\n", tiempoModificacion.Format(time.RFC3339Nano))
 	fmt.Printf("Bloques asignados: %v\n", inodo.I_block)
 	fmt.Printf("Tipo de elemento: %s\n", string(inodo.I_type[:]))
 	fmt.Printf("Permisos: %s\n", string(inodo.I_perm[:]))
@@ -123,7 +171,7 @@ func (inodo *INodo) ObtenerTodosLosIndicesDeBloques(archivo *os.File, sb *SuperB
         indicesBloques = append(indicesBloques, inodo.I_block[12])
 
         // Cargar el bloque de apuntadores
-        ba := &PointerBlock{}
+        ba := NuevoPointerBlock(sb.S_block_size)
         offsetBA := int64(sb.S_block_start + inodo.I_block[12]*sb.S_block_size)
         err := ba.Decodificar(archivo, offsetBA)
         if err != nil {
@@ -143,7 +191,7 @@ func (inodo *INodo) ObtenerTodosLosIndicesDeBloques(archivo *os.File, sb *SuperB
         indicesBloques = append(indicesBloques, inodo.I_block[13])
 
         // Cargar el bloque de apuntadores primario
-        baPrimario := &PointerBlock{}
+        baPrimario := NuevoPointerBlock(sb.S_block_size)
         offsetPrimario := int64(sb.S_block_start + inodo.I_block[13]*sb.S_block_size)
         err := baPrimario.Decodificar(archivo, offsetPrimario)
         if err != nil {
@@ -157,7 +205,7 @@ func (inodo *INodo) ObtenerTodosLosIndicesDeBloques(archivo *os.File, sb *SuperB
                 indicesBloques = append(indicesBloques, int32(apuntadorPrimario))
 
                 // Cargamos el bloque secundario
-                baSecundario := &PointerBlock{}
+                baSecundario := NuevoPointerBlock(sb.S_block_size)
                 offsetSecundario := int64(sb.S_block_start + int32(apuntadorPrimario)*sb.S_block_size)
                 err := baSecundario.Decodificar(archivo, offsetSecundario)
                 if err != nil {
@@ -177,7 +225,7 @@ func (inodo *INodo) ObtenerTodosLosIndicesDeBloques(archivo *os.File, sb *SuperB
     // 4. Procesar bloque indirecto triple (posición 14)
     if inodo.I_block[14] != -1 {
         indicesBloques = append(indicesBloques, inodo.I_block[14])
-        baPrimario := &PointerBlock{}
+        baPrimario := NuevoPointerBlock(sb.S_block_size)
         offsetPrimario := int64(sb.S_block_start + inodo.I_block[14]*sb.S_block_size)
         err := baPrimario.Decodificar(archivo, offsetPrimario)
         if err != nil {
@@ -191,7 +239,7 @@ func (inodo *INodo) ObtenerTodosLosIndicesDeBloques(archivo *os.File, sb *SuperB
                 indicesBloques = append(indicesBloques, int32(apuntadorPrimario))
 
                 // Cargamos el bloque secundario (nivel 2)
-                baSecundario := &PointerBlock{}
+                baSecundario := NuevoPointerBlock(sb.S_block_size)
                 offsetSecundario := int64(sb.S_block_start + int32(apuntadorPrimario)*sb.S_block_size)
                 err := baSecundario.Decodificar(archivo, offsetSecundario)
                 if err != nil {
@@ -202,7 +250,7 @@ func (inodo *INodo) ObtenerTodosLosIndicesDeBloques(archivo *os.File, sb *SuperB
                 for _, apuntadorSecundario := range baSecundario.B_apuntadores {
                     if apuntadorSecundario != -1 {
                         indicesBloques = append(indicesBloques, int32(apuntadorSecundario))
-                        baTerciario := &PointerBlock{}
+                        baTerciario := NuevoPointerBlock(sb.S_block_size)
                         offsetTerciario := int64(sb.S_block_start + int32(apuntadorSecundario)*sb.S_block_size)
                         err := baTerciario.Decodificar(archivo, offsetTerciario)
                         if err != nil {
@@ -224,8 +272,23 @@ func (inodo *INodo) ObtenerTodosLosIndicesDeBloques(archivo *os.File, sb *SuperB
     return indicesBloques, nil
 }
 
-// AgregarBloque asigna un nuevo bloque al inodo y devuelve su índice
+// UltimoBloqueReferenciado devuelve el último bloque de I_block en uso (directo o raíz
+// de indirección), o -1 si el inodo no tiene bloques
+func (inodo *INodo) UltimoBloqueReferenciado() int32 {
+    for i := len(inodo.I_block) - 1; i >= 0; i-- {
+        if inodo.I_block[i] != -1 {
+            return inodo.I_block[i]
+        }
+    }
+    return -1
+}
+
+// AgregarBloque asigna un nuevo bloque al inodo y devuelve su índice. El bloque se busca a
+// continuación del último bloque del inodo; un inodo sin bloques usa la preferencia
+// indicada por quien lo creó (ver PreferirBloquesCercaDe)
 func (inodo *INodo) AgregarBloque(archivo *os.File, sb *SuperBlock) (int32, error) {
+    sb.PreferirBloquesCercaDe(archivo, inodo.UltimoBloqueReferenciado())
+
     // 1. Intentar asignar en bloques directos (0-11)
     for i := 0; i < 12; i++ {
         if inodo.I_block[i] == -1 {
@@ -251,7 +314,7 @@ func (inodo *INodo) AgregarBloqueConIndirección(archivo *os.File, sb *SuperBloc
             return -1, fmt.Errorf("error al crear bloque de apuntadores simple: %w", err)
         }
 
-        ba := &PointerBlock{}
+        ba := NuevoPointerBlock(sb.S_block_size)
         for i := range ba.B_apuntadores {
             ba.B_apuntadores[i] = -1
         }
@@ -276,7 +339,7 @@ func (inodo *INodo) AgregarBloqueConIndirección(archivo *os.File, sb *SuperBloc
         }
 
         // Inicializar el bloque de apuntadores doble
-        baDoble := &PointerBlock{}
+        baDoble := NuevoPointerBlock(sb.S_block_size)
         for i := range baDoble.B_apuntadores {
             baDoble.B_apuntadores[i] = -1
         }
@@ -301,7 +364,7 @@ func (inodo *INodo) AgregarBloqueConIndirección(archivo *os.File, sb *SuperBloc
         }
 
         // Inicializar el bloque de apuntadores triple
-        baTriple := &PointerBlock{}
+        baTriple := NuevoPointerBlock(sb.S_block_size)
         for i := range baTriple.B_apuntadores {
             baTriple.B_apuntadores[i] = -1
         }
@@ -329,7 +392,7 @@ func (inodo *INodo) AgregarBloqueAIndireccionSimple(archivo *os.File, sb *SuperB
     }
 
     // Cargar el bloque de apuntadores
-    ba := &PointerBlock{}
+    ba := NuevoPointerBlock(sb.S_block_size)
     offsetBA := int64(sb.S_block_start + inodo.I_block[12]*sb.S_block_size)
     if err := ba.Decodificar(archivo, offsetBA); err != nil {
         return -1, fmt.Errorf("error al leer bloque de apuntadores: %w", err)
@@ -359,6 +422,7 @@ func (inodo *INodo) AgregarBloqueAIndireccionSimple(archivo *os.File, sb *SuperB
     if _, err := archivo.WriteAt(bufferCeros, offsetBloques); err != nil {
         return -1, fmt.Errorf("error inicializando bloque nuevo: %w", err)
     }
+    descartarEnCache(archivo, offsetBloques)
 
     // Actualizar el bloque de apuntadores
     ba.B_apuntadores[indiceLibre] = int64(nuevoIndiceBloques)
@@ -385,7 +449,7 @@ func (inodo *INodo) AgregarBloqueAIndireccionDoble(archivo *os.File, sb *SuperBl
     }
 
     // Cargar el bloque de apuntadores primario
-    baPrimario := &PointerBlock{}
+    baPrimario := NuevoPointerBlock(sb.S_block_size)
     offsetPrimario := int64(sb.S_block_start + inodo.I_block[13]*sb.S_block_size)
     if err := baPrimario.Decodificar(archivo, offsetPrimario); err != nil {
         return -1, fmt.Errorf("error al leer bloque de apuntadores primario: %w", err)
@@ -406,7 +470,7 @@ func (inodo *INodo) AgregarBloqueAIndireccionDoble(archivo *os.File, sb *SuperBl
             }
 
             // Inicializar el bloque de apuntadores secundario
-            baSecundario := &PointerBlock{}
+            baSecundario := NuevoPointerBlock(sb.S_block_size)
             for j := range baSecundario.B_apuntadores {
                 baSecundario.B_apuntadores[j] = -1
             }
@@ -448,7 +512,7 @@ func (inodo *INodo) AgregarBloqueAIndireccionDoble(archivo *os.File, sb *SuperBl
             return nuevoIndiceDatos, nil
         } else {
             // Usar un bloque secundario existente
-            baSecundario := &PointerBlock{}
+            baSecundario := NuevoPointerBlock(sb.S_block_size)
             offsetSecundario := int64(sb.S_block_start + int32(apuntadorPrimario)*sb.S_block_size)
             if err := baSecundario.Decodificar(archivo, offsetSecundario); err != nil {
                 return -1, fmt.Errorf("error leyendo bloque de apuntadores secundario: %w", err)
@@ -496,7 +560,7 @@ func (inodo *INodo) AgregarBloqueAIndireccionTriple(archivo *os.File, sb *SuperB
     }
 
     // Cargar el bloque de apuntadores primario (nivel 1)
-    baPrimario := &PointerBlock{}
+    baPrimario := NuevoPointerBlock(sb.S_block_size)
     offsetPrimario := int64(sb.S_block_start + inodo.I_block[14]*sb.S_block_size)
     if err := baPrimario.Decodificar(archivo, offsetPrimario); err != nil {
         return -1, fmt.Errorf("error al leer bloque de apuntadores primario: %w", err)
@@ -517,7 +581,7 @@ func (inodo *INodo) AgregarBloqueAIndireccionTriple(archivo *os.File, sb *SuperB
             }
 
             // Inicializar el bloque de apuntadores secundario
-            baSecundario := &PointerBlock{}
+            baSecundario := NuevoPointerBlock(sb.S_block_size)
             for j := range baSecundario.B_apuntadores {
                 baSecundario.B_apuntadores[j] = -1
             }
@@ -534,7 +598,7 @@ func (inodo *INodo) AgregarBloqueAIndireccionTriple(archivo *os.File, sb *SuperB
             }
 
             // Inicializar el bloque de apuntadores terciario
-            baTerciario := &PointerBlock{}
+            baTerciario := NuevoPointerBlock(sb.S_block_size)
             for j := range baTerciario.B_apuntadores {
                 baTerciario.B_apuntadores[j] = -1
             }
@@ -584,7 +648,7 @@ func (inodo *INodo) AgregarBloqueAIndireccionTriple(archivo *os.File, sb *SuperB
             return nuevoIndiceDatos, nil
         } else {
             // Bloque secundario ya existe, cargarlo
-            baSecundario := &PointerBlock{}
+            baSecundario := NuevoPointerBlock(sb.S_block_size)
             offsetSecundario := int64(sb.S_block_start + int32(apuntadorPrimario)*sb.S_block_size)
             if err := baSecundario.Decodificar(archivo, offsetSecundario); err != nil {
                 return -1, fmt.Errorf("error leyendo bloque de apuntadores secundario: %w", err)
@@ -605,7 +669,7 @@ func (inodo *INodo) AgregarBloqueAIndireccionTriple(archivo *os.File, sb *SuperB
                     }
 
                     // Inicializar el bloque de apuntadores terciario
-                    baTerciario := &PointerBlock{}
+                    baTerciario := NuevoPointerBlock(sb.S_block_size)
                     for j := range baTerciario.B_apuntadores {
                         baTerciario.B_apuntadores[j] = -1
                     }
@@ -645,7 +709,7 @@ func (inodo *INodo) AgregarBloqueAIndireccionTriple(archivo *os.File, sb *SuperB
                     return nuevoIndiceDatos, nil
                 } else {
                     // Bloque terciario ya existe, cargarlo
-                    baTerciario := &PointerBlock{}
+                    baTerciario := NuevoPointerBlock(sb.S_block_size)
                     offsetTerciario := int64(sb.S_block_start + int32(apuntadorSecundario)*sb.S_block_size)
                     if err := baTerciario.Decodificar(archivo, offsetTerciario); err != nil {
                         return -1, fmt.Errorf("error leyendo bloque de apuntadores terciario: %w", err)
@@ -700,6 +764,7 @@ func (inodo *INodo) LiberarBloque(archivo *os.File, sb *SuperBlock, indiceBloque
     
     // Actualizar los contadores del superbloque después de la desasignación
     sb.ActualizarSuperblockDespuesDesasignacionBloque()
+    descontarCuota(archivo, inodo, 1, 0)
     
     return nil
 }
@@ -736,7 +801,7 @@ func (inodo *INodo) LiberarTodosLosBloques(archivo *os.File, sb *SuperBlock) err
 func (inodo *INodo) VerificarYLiberarBloquesIndirectosVacios(archivo *os.File, sb *SuperBlock) error {
     // 1. Verificar bloque indirecto simple (posición 12)
     if inodo.I_block[12] != -1 {
-        ba := &PointerBlock{}
+        ba := NuevoPointerBlock(sb.S_block_size)
         offsetBA := int64(sb.S_block_start + inodo.I_block[12]*sb.S_block_size)
         if err := ba.Decodificar(archivo, offsetBA); err != nil {
             return fmt.Errorf("error leyendo bloque indirecto simple: %w", err)
@@ -764,7 +829,7 @@ func (inodo *INodo) VerificarYLiberarBloquesIndirectosVacios(archivo *os.File, s
 
     // 2. Verificar bloque indirecto doble (posición 13)
     if inodo.I_block[13] != -1 {
-        baPrimario := &PointerBlock{}
+        baPrimario := NuevoPointerBlock(sb.S_block_size)
         offsetPrimario := int64(sb.S_block_start + inodo.I_block[13]*sb.S_block_size)
         if err := baPrimario.Decodificar(archivo, offsetPrimario); err != nil {
             return fmt.Errorf("error leyendo bloque indirecto doble: %w", err)
@@ -777,7 +842,7 @@ func (inodo *INodo) VerificarYLiberarBloquesIndirectosVacios(archivo *os.File, s
         for i, apuntadorPrimario := range baPrimario.B_apuntadores {
             if apuntadorPrimario != -1 {
                 // Cargar el bloque secundario correspondiente
-                baSecundario := &PointerBlock{}
+                baSecundario := NuevoPointerBlock(sb.S_block_size)
                 offsetSecundario := int64(sb.S_block_start + int32(apuntadorPrimario)*sb.S_block_size)
                 if err := baSecundario.Decodificar(archivo, offsetSecundario); err != nil {
                     return fmt.Errorf("error leyendo bloque secundario: %w", err)
@@ -832,7 +897,7 @@ func (inodo *INodo) VerificarYLiberarBloquesIndirectosVacios(archivo *os.File, s
 
     // 3. Verificar bloque indirecto triple (posición 14)
     if inodo.I_block[14] != -1 {
-        baPrimario := &PointerBlock{}
+        baPrimario := NuevoPointerBlock(sb.S_block_size)
         offsetPrimario := int64(sb.S_block_start + inodo.I_block[14]*sb.S_block_size)
         if err := baPrimario.Decodificar(archivo, offsetPrimario); err != nil {
             return fmt.Errorf("error leyendo bloque indirecto triple: %w", err)
@@ -848,7 +913,7 @@ func (inodo *INodo) VerificarYLiberarBloquesIndirectosVacios(archivo *os.File, s
                 continue
             }
 
-            baSecundario := &PointerBlock{}
+            baSecundario := NuevoPointerBlock(sb.S_block_size)
             offsetSecundario := int64(sb.S_block_start + int32(apuntadorPrimario)*sb.S_block_size)
             if err := baSecundario.Decodificar(archivo, offsetSecundario); err != nil {
                 return fmt.Errorf("error leyendo bloque secundario en triple: %w", err)
@@ -864,7 +929,7 @@ func (inodo *INodo) VerificarYLiberarBloquesIndirectosVacios(archivo *os.File, s
                     continue
                 }
 
-                baTerciario := &PointerBlock{}
+                baTerciario := NuevoPointerBlock(sb.S_block_size)
                 offsetTerciario := int64(sb.S_block_start + int32(apuntadorSecundario)*sb.S_block_size)
                 if err := baTerciario.Decodificar(archivo, offsetTerciario); err != nil {
                     return fmt.Errorf("error leyendo bloque terciario: %w", err)
@@ -953,14 +1018,14 @@ func (inodo *INodo) LeerDatos(archivo *os.File, sb *SuperBlock) ([]byte, error)
         }
 
         // Leer el bloque como FileBlock
-        bloqueArchivo := &FileBlock{}
+        bloqueArchivo := NuevoFileBlockVacio(sb.S_block_size)
         offsetBloque := int64(sb.S_block_start + indiceBloque*sb.S_block_size)
         if err := bloqueArchivo.Decodificar(archivo, offsetBloque); err != nil {
             return nil, fmt.Errorf("error leyendo bloque %d: %w", indiceBloque, err)
         }
 
         // Determinar cuántos bytes leer de este bloque
-        bytesDeEsteBloque := DimensionBloque
+        bytesDeEsteBloque := int(sb.S_block_size)
         if bytesDeEsteBloque > bytesALeer {
             bytesDeEsteBloque = bytesALeer
         }
@@ -976,6 +1041,38 @@ func (inodo *INodo) LeerDatos(archivo *os.File, sb *SuperBlock) ([]byte, error)
     return resultado, nil
 }
 
+// SobrescribirBloquesDatos escribe los datos en orden sobre los bloques de datos del inodo.
+// Si no alcanzan se agregan con AgregarBloque (directos o por indirección) y los que
+// sobran quedan limpios. No modifica I_size
+func (inodo *INodo) SobrescribirBloquesDatos(archivo *os.File, sb *SuperBlock, datos []byte) error {
+    indicesBloques, err := inodo.ObtenerIndicesBloquesDatos(archivo, sb)
+    if err != nil {
+        return fmt.Errorf("error obteniendo bloques de datos: %w", err)
+    }
+
+    dimensionBloque := int(sb.S_block_size)
+    bloquesNecesarios := (len(datos) + dimensionBloque - 1) / dimensionBloque
+    for len(indicesBloques) < bloquesNecesarios {
+        nuevoBloque, err := inodo.AgregarBloque(archivo, sb)
+        if err != nil {
+            return fmt.Errorf("error asignando bloque %d: %w", len(indicesBloques), err)
+        }
+        indicesBloques = append(indicesBloques, nuevoBloque)
+    }
+
+    for i, indiceBloque := range indicesBloques {
+        bloqueArchivo := NuevoFileBlockVacio(sb.S_block_size)
+        if inicio := i * dimensionBloque; inicio < len(datos) {
+            copy(bloqueArchivo.B_cont, datos[inicio:])
+        }
+        offsetBloque := int64(sb.S_block_start + indiceBloque*sb.S_block_size)
+        if err := bloqueArchivo.Codificar(archivo, offsetBloque); err != nil {
+            return fmt.Errorf("error escribiendo bloque %d: %w", indiceBloque, err)
+        }
+    }
+    return nil
+}
+
 // EscribirDatos escribe datos en los bloques del inodo
 func (inodo *INodo) EscribirDatos(archivo *os.File, sb *SuperBlock, datos []byte) error {
     // Obtener el tamaño actual y el nuevo tamaño
@@ -989,9 +1086,12 @@ func (inodo *INodo) EscribirDatos(archivo *os.File, sb *SuperBlock, datos []byte
         // Si había bloques anteriores, liberarlos todos
         if tamañoAnterior > 0 {
             fmt.Printf("Liberando bloques existentes antes de redimensionar\n")
+            primerBloque := inodo.I_block[0]
             if err := inodo.LiberarTodosLosBloques(archivo, sb); err != nil {
                 return fmt.Errorf("error liberando bloques existentes: %w", err)
             }
+            // Reasignar desde donde estaba el contenido anterior
+            sb.PreferirBloquesCercaDe(archivo, primerBloque-1)
         }
 
         // Si el nuevo tamaño es 0, solo limpiar y salir
@@ -1061,6 +1161,7 @@ func (inodo *INodo) EscribirDatos(archivo *os.File, sb *SuperBlock, datos []byte
             return fmt.Errorf("error escribiendo datos al bloque %d (índice %d): %w", 
                 i, indiceBloque, err)
         }
+        descartarEnCache(archivo, offsetBloque)
 
         offsetDatos += bytesAEscribir
         
@@ -1097,7 +1198,7 @@ func (inodo *INodo) ObtenerIndicesBloquesDatos(archivo *os.File, sb *SuperBlock)
 
     // 2. Bloques en indirección simple (solo los apuntados, no el bloque 12)
     if inodo.I_block[12] != -1 {
-        ba := &PointerBlock{}
+        ba := NuevoPointerBlock(sb.S_block_size)
         offsetBA := int64(sb.S_block_start + inodo.I_block[12]*sb.S_block_size)
         if err := ba.Decodificar(archivo, offsetBA); err != nil {
             return nil, fmt.Errorf("error leyendo bloque indirecto simple: %w", err)
@@ -1112,7 +1213,7 @@ func (inodo *INodo) ObtenerIndicesBloquesDatos(archivo *os.File, sb *SuperBlock)
 
     // 3. Bloques en indirección doble (solo los bloques finales, no los apuntadores)
     if inodo.I_block[13] != -1 {
-        baPrimario := &PointerBlock{}
+        baPrimario := NuevoPointerBlock(sb.S_block_size)
         offsetPrimario := int64(sb.S_block_start + inodo.I_block[13]*sb.S_block_size)
         if err := baPrimario.Decodificar(archivo, offsetPrimario); err != nil {
             return nil, fmt.Errorf("error leyendo bloque indirecto doble: %w", err)
@@ -1120,7 +1221,7 @@ func (inodo *INodo) ObtenerIndicesBloquesDatos(archivo *os.File, sb *SuperBlock)
 
         for _, apuntadorPrimario := range baPrimario.B_apuntadores {
             if apuntadorPrimario != -1 {
-                baSecundario := &PointerBlock{}
+                baSecundario := NuevoPointerBlock(sb.S_block_size)
                 offsetSecundario := int64(sb.S_block_start + int32(apuntadorPrimario)*sb.S_block_size)
                 if err := baSecundario.Decodificar(archivo, offsetSecundario); err != nil {
                     return nil, fmt.Errorf("error leyendo bloque secundario: %w", err)
@@ -1137,7 +1238,7 @@ func (inodo *INodo) ObtenerIndicesBloquesDatos(archivo *os.File, sb *SuperBlock)
 
     // 4. Bloques en indirección triple (solo los bloques finales, no los apuntadores)
     if inodo.I_block[14] != -1 {
-        baPrimario := &PointerBlock{}
+        baPrimario := NuevoPointerBlock(sb.S_block_size)
         offsetPrimario := int64(sb.S_block_start + inodo.I_block[14]*sb.S_block_size)
         if err := baPrimario.Decodificar(archivo, offsetPrimario); err != nil {
             return nil, fmt.Errorf("error leyendo bloque indirecto triple: %w", err)
@@ -1145,7 +1246,7 @@ func (inodo *INodo) ObtenerIndicesBloquesDatos(archivo *os.File, sb *SuperBlock)
 
         for _, apuntadorPrimario := range baPrimario.B_apuntadores {
             if apuntadorPrimario != -1 {
-                baSecundario := &PointerBlock{}
+                baSecundario := NuevoPointerBlock(sb.S_block_size)
                 offsetSecundario := int64(sb.S_block_start + int32(apuntadorPrimario)*sb.S_block_size)
                 if err := baSecundario.Decodificar(archivo, offsetSecundario); err != nil {
                     return nil, fmt.Errorf("error leyendo bloque secundario en indirección triple: %w", err)
@@ -1153,7 +1254,7 @@ func (inodo *INodo) ObtenerIndicesBloquesDatos(archivo *os.File, sb *SuperBlock)
 
                 for _, apuntadorSecundario := range baSecundario.B_apuntadores {
                     if apuntadorSecundario != -1 {
-                        baTerciario := &PointerBlock{}
+                        baTerciario := NuevoPointerBlock(sb.S_block_size)
                         offsetTerciario := int64(sb.S_block_start + int32(apuntadorSecundario)*sb.S_block_size)
                         if err := baTerciario.Decodificar(archivo, offsetTerciario); err != nil {
                             return nil, fmt.Errorf("error leyendo bloque terciario: %w", err)
@@ -1173,6 +1274,46 @@ func (inodo *INodo) ObtenerIndicesBloquesDatos(archivo *os.File, sb *SuperBlock)
     return bloquesDatos, nil
 }
 
+// BloqueDatos devuelve el índice del bloque de datos lógico n del inodo, leyendo solo
+// los bloques de apuntadores de la rama que lo contiene
+func (inodo *INodo) BloqueDatos(archivo *os.File, sb *SuperBlock, n int32) (int32, error) {
+    if n < 0 {
+        return -1, fmt.Errorf("bloque lógico inválido: %d", n)
+    }
+    if n < 12 {
+        if inodo.I_block[n] == -1 {
+            return -1, fmt.Errorf("el bloque lógico %d: %w", n, ErrBloqueNoAsignado)
+        }
+        return inodo.I_block[n], nil
+    }
+
+    // Ubicar el nivel de indirección que contiene el bloque
+    porBloque := sb.S_block_size / DimensionApuntador
+    nivel, restante, capacidad, err := ubicarIndireccion(sb, n)
+    if err != nil {
+        return -1, err
+    }
+
+    // Descender por los bloques de apuntadores hasta el bloque de datos
+    actual := inodo.I_block[11+nivel]
+    for ; nivel > 0; nivel-- {
+        if actual == -1 {
+            return -1, fmt.Errorf("el bloque lógico %d: %w", n, ErrBloqueNoAsignado)
+        }
+        capacidad /= porBloque
+        ba := NuevoPointerBlock(sb.S_block_size)
+        if err := ba.Decodificar(archivo, int64(sb.S_block_start+actual*sb.S_block_size)); err != nil {
+            return -1, fmt.Errorf("error leyendo bloque de apuntadores %d: %w", actual, err)
+        }
+        actual = int32(ba.B_apuntadores[restante/capacidad])
+        restante %= capacidad
+    }
+    if actual == -1 {
+        return -1, fmt.Errorf("el bloque lógico %d: %w", n, ErrBloqueNoAsignado)
+    }
+    return actual, nil
+}
+
 // NuevoInodoVacio crea un inodo vacío con valores predeterminados
 func NuevoInodoVacio() *INodo {
     inodo := &INodo{}
@@ -1181,9 +1322,10 @@ func NuevoInodoVacio() *INodo {
     inodo.I_uid = 1
     inodo.I_gid = 1
     inodo.I_size = 0
+    inodo.I_links = 1
 
     // Inicializar todos los tiempos al momento actual
-    tiempoActual := float32(time.Now().Unix())
+    tiempoActual := MarcaTiempoActual()
     inodo.I_atime = tiempoActual
     inodo.I_ctime = tiempoActual
     inodo.I_mtime = tiempoActual
@@ -1198,6 +1340,7 @@ func NuevoInodoVacio() *INodo {
     for i := range inodo.I_block {
         inodo.I_block[i] = -1
     }
+    inodo.I_xattr = -1
 
     return inodo
 }
\ No newline at end of file
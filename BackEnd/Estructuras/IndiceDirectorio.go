@@ -0,0 +1,463 @@
+package Estructuras
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"strings"
+)
+
+// Indice hash de directorios: cuando un directorio llega a UmbralBloquesIndice bloques
+// se le asigna un inodo auxiliar cuyo contenido es una tabla hash (direccionamiento
+// abierto con sondeo lineal) que relaciona el hash del nombre con la posicion del
+// primer contenido de la entrada. El directorio guarda el numero de ese inodo en un
+// contenido marca (B_inodo = InodoIndice) en la posicion PosicionMarcaIndice de su
+// primer bloque. Los directorios sin marca se siguen recorriendo de forma lineal
+const (
+	InodoIndice         int32 = -3
+	PosicionMarcaIndice       = 2
+	UmbralBloquesIndice       = 8
+
+	firmaIndice           uint32 = 0x58444E49 // "INDX"
+	dimensionCabecera            = 16         // firma, capacidad, ocupadas y borradas
+	dimensionRanura              = 8          // hash y posicion
+	capacidadMinimaIndice        = 16
+	ranuraVacia           int32  = -1
+	ranuraBorrada         int32  = -2
+)
+
+// Ranura de la tabla hash, posicion es el primer contenido de la entrada en el directorio
+type ranuraIndice struct {
+	hash     uint32
+	posicion int32
+}
+
+// IndiceDirectorio es la tabla hash de un directorio cargada en memoria
+type IndiceDirectorio struct {
+	IndiceInodo  int32
+	inodo        *INodo
+	tamanoBloque int32
+	ranuras      []ranuraIndice
+	ocupadas     int32
+	borradas     int32
+	sucios       map[int32]bool // Bloques logicos del indice por escribir
+	reconstruido bool           // La tabla cambio de capacidad y se reescribe completa
+}
+
+// HashNombre calcula el hash de un nombre sin distinguir mayusculas, igual que Buscar
+func HashNombre(nombre string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(strings.ToLower(nombre)))
+	return h.Sum32()
+}
+
+// Contenido marca que apunta al inodo del indice
+func contenidoMarcaIndice(indiceInodo int32) FolderContent {
+	contenido := FolderContent{B_inodo: InodoIndice}
+	binary.LittleEndian.PutUint32(contenido.B_name[:4], uint32(indiceInodo))
+	return contenido
+}
+
+// InodoDeMarca retorna el inodo del indice si el contenido es una marca de indice
+func InodoDeMarca(contenido FolderContent) (int32, bool) {
+	if contenido.B_inodo != InodoIndice {
+		return -1, false
+	}
+	return int32(binary.LittleEndian.Uint32(contenido.B_name[:4])), true
+}
+
+// Nombre completo de una entrada a partir de sus continuaciones y su contenido principal
+func nombreEntrada(fragmentos []byte, principal FolderContent) string {
+	if len(fragmentos) == 0 {
+		return NombreContenido(principal)
+	}
+	return string(fragmentos) + strings.TrimRight(string(principal.B_name[:]), "\x00")
+}
+
+// Bloque logico del archivo del indice donde esta la ranura i
+func (ind *IndiceDirectorio) bloqueDeRanura(i int) int32 {
+	return int32(dimensionCabecera+i*dimensionRanura) / ind.tamanoBloque
+}
+
+// reconstruir vuelve a llenar la tabla con las entradas del directorio, con capacidad
+// suficiente para que quede a lo sumo a un cuarto de su carga
+func (ind *IndiceDirectorio) reconstruir(entradas []EntradaDirectorio) {
+	capacidad := capacidadMinimaIndice
+	for capacidad < 4*len(entradas) {
+		capacidad *= 2
+	}
+	ind.ranuras = make([]ranuraIndice, capacidad)
+	for i := range ind.ranuras {
+		ind.ranuras[i].posicion = ranuraVacia
+	}
+	ind.ocupadas, ind.borradas = 0, 0
+	for _, entrada := range entradas {
+		ind.colocar(HashNombre(entrada.Nombre), int32(entrada.inicio))
+	}
+	ind.reconstruido = true
+}
+
+// colocar inserta en la primera ranura libre o borrada de la secuencia de sondeo
+func (ind *IndiceDirectorio) colocar(hash uint32, posicion int32) int {
+	mascara := uint32(len(ind.ranuras) - 1)
+	for i := hash & mascara; ; i = (i + 1) & mascara {
+		ranura := &ind.ranuras[i]
+		if ranura.posicion != ranuraVacia && ranura.posicion != ranuraBorrada {
+			continue
+		}
+		if ranura.posicion == ranuraBorrada {
+			ind.borradas--
+		}
+		ranura.hash, ranura.posicion = hash, posicion
+		ind.ocupadas++
+		return int(i)
+	}
+}
+
+// insertar agrega una entrada; si la tabla supera la mitad de su carga se reconstruye
+// con las entradas actuales del directorio (que ya incluyen la nueva)
+func (ind *IndiceDirectorio) insertar(d *Directorio, hash uint32, posicion int32) {
+	if 2*(ind.ocupadas+ind.borradas+1) > int32(len(ind.ranuras)) {
+		ind.reconstruir(d.Entradas())
+		return
+	}
+	i := ind.colocar(hash, posicion)
+	ind.sucios[ind.bloqueDeRanura(i)] = true
+}
+
+// quitar marca como borrada la ranura de la entrada que empieza en la posicion indicada
+func (ind *IndiceDirectorio) quitar(hash uint32, posicion int32) {
+	mascara := uint32(len(ind.ranuras) - 1)
+	for i, sondeos := hash&mascara, 0; sondeos < len(ind.ranuras); i, sondeos = (i+1)&mascara, sondeos+1 {
+		ranura := &ind.ranuras[i]
+		if ranura.posicion == ranuraVacia {
+			return
+		}
+		if ranura.posicion == posicion && ranura.hash == hash {
+			ranura.posicion = ranuraBorrada
+			ind.ocupadas--
+			ind.borradas++
+			ind.sucios[ind.bloqueDeRanura(int(i))] = true
+			return
+		}
+	}
+}
+
+// Serializa la cabecera y las ranuras en el formato del archivo del indice
+func (ind *IndiceDirectorio) codificarDatos() []byte {
+	datos := make([]byte, dimensionCabecera+len(ind.ranuras)*dimensionRanura)
+	binary.LittleEndian.PutUint32(datos[0:], firmaIndice)
+	binary.LittleEndian.PutUint32(datos[4:], uint32(len(ind.ranuras)))
+	binary.LittleEndian.PutUint32(datos[8:], uint32(ind.ocupadas))
+	binary.LittleEndian.PutUint32(datos[12:], uint32(ind.borradas))
+	for i, ranura := range ind.ranuras {
+		desplazamiento := dimensionCabecera + i*dimensionRanura
+		binary.LittleEndian.PutUint32(datos[desplazamiento:], ranura.hash)
+		binary.LittleEndian.PutUint32(datos[desplazamiento+4:], uint32(ranura.posicion))
+	}
+	return datos
+}
+
+// Deserializa el contenido del archivo del indice
+func (ind *IndiceDirectorio) decodificarDatos(datos []byte) error {
+	if len(datos) < dimensionCabecera || binary.LittleEndian.Uint32(datos[0:]) != firmaIndice {
+		return errors.New("firma del indice invalida")
+	}
+	capacidad := int(binary.LittleEndian.Uint32(datos[4:]))
+	if capacidad < capacidadMinimaIndice || capacidad&(capacidad-1) != 0 || len(datos) < dimensionCabecera+capacidad*dimensionRanura {
+		return fmt.Errorf("capacidad del indice invalida: %d", capacidad)
+	}
+	ind.ocupadas = int32(binary.LittleEndian.Uint32(datos[8:]))
+	ind.borradas = int32(binary.LittleEndian.Uint32(datos[12:]))
+	ind.ranuras = make([]ranuraIndice, capacidad)
+	for i := range ind.ranuras {
+		desplazamiento := dimensionCabecera + i*dimensionRanura
+		ind.ranuras[i].hash = binary.LittleEndian.Uint32(datos[desplazamiento:])
+		ind.ranuras[i].posicion = int32(binary.LittleEndian.Uint32(datos[desplazamiento+4:]))
+	}
+	return nil
+}
+
+// guardar escribe los bloques modificados del indice, o el archivo completo si se reconstruyo
+func (ind *IndiceDirectorio) guardar(archivo *os.File, sb *SuperBlock) error {
+	if !ind.reconstruido && len(ind.sucios) == 0 {
+		return nil
+	}
+	datos := ind.codificarDatos()
+
+	if ind.reconstruido {
+		if err := ind.inodo.EscribirDatos(archivo, sb, datos); err != nil {
+			return fmt.Errorf("error escribiendo el indice del directorio: %w", err)
+		}
+		if err := ind.inodo.Codificar(archivo, sb.CalcularDesplazamientoInodo(ind.IndiceInodo)); err != nil {
+			return fmt.Errorf("error actualizando inodo del indice %d: %w", ind.IndiceInodo, err)
+		}
+	} else {
+		for logico := range ind.sucios {
+			fisico, err := ind.inodo.BloqueDatos(archivo, sb, logico)
+			if err != nil {
+				return fmt.Errorf("error ubicando bloque %d del indice: %w", logico, err)
+			}
+			inicio := logico * sb.S_block_size
+			fin := inicio + sb.S_block_size
+			if fin > int32(len(datos)) {
+				fin = int32(len(datos))
+			}
+			bloque := NuevoFileBlockVacio(sb.S_block_size)
+			copy(bloque.B_cont, datos[inicio:fin])
+			if err := bloque.Codificar(archivo, int64(sb.S_block_start+fisico*sb.S_block_size)); err != nil {
+				return fmt.Errorf("error escribiendo bloque %d del indice: %w", fisico, err)
+			}
+		}
+		// La cabecera lleva los contadores y siempre esta en el primer bloque
+		if !ind.sucios[0] {
+			fisico, err := ind.inodo.BloqueDatos(archivo, sb, 0)
+			if err != nil {
+				return fmt.Errorf("error ubicando la cabecera del indice: %w", err)
+			}
+			if _, err := archivo.WriteAt(datos[:dimensionCabecera], int64(sb.S_block_start+fisico*sb.S_block_size)); err != nil {
+				return fmt.Errorf("error escribiendo la cabecera del indice: %w", err)
+			}
+			descartarEnCache(archivo, int64(sb.S_block_start+fisico*sb.S_block_size))
+		}
+	}
+
+	ind.sucios = make(map[int32]bool)
+	ind.reconstruido = false
+	return nil
+}
+
+// TieneIndice indica si el directorio usa indice hash
+func (d *Directorio) TieneIndice() bool {
+	return d.indice != nil
+}
+
+// Inodo del indice segun la marca del primer bloque, si existe
+func (d *Directorio) marcaIndice() (int32, bool) {
+	if d.totalContenidos() <= PosicionMarcaIndice {
+		return -1, false
+	}
+	return InodoDeMarca(d.contenido(PosicionMarcaIndice))
+}
+
+// cargarIndice lee la tabla del indice; si esta dañada se reconstruye con las entradas
+func (d *Directorio) cargarIndice(archivo *os.File, sb *SuperBlock, indiceInodo int32) error {
+	inodo := &INodo{}
+	if err := inodo.Decodificar(archivo, sb.CalcularDesplazamientoInodo(indiceInodo)); err != nil {
+		return fmt.Errorf("error al deserializar el inodo del indice %d: %w", indiceInodo, err)
+	}
+	d.indice = &IndiceDirectorio{IndiceInodo: indiceInodo, inodo: inodo, tamanoBloque: sb.S_block_size, sucios: make(map[int32]bool)}
+
+	datos, err := inodo.LeerDatos(archivo, sb)
+	if err == nil {
+		err = d.indice.decodificarDatos(datos)
+	}
+	if err != nil {
+		fmt.Printf("Advertencia: indice del directorio %d dañado (%v), se reconstruye\n", d.IndiceInodo, err)
+		d.indice.reconstruir(d.Entradas())
+	}
+	return nil
+}
+
+// crearIndice asigna el inodo del indice, coloca la marca en el primer bloque y llena
+// la tabla. La entrada que ocupaba la posicion de la marca se vuelve a agregar
+func (d *Directorio) crearIndice(archivo *os.File, sb *SuperBlock) error {
+	indiceInodo, err := sb.AsignarNuevoInodo(archivo)
+	if err != nil {
+		// Sin indice el directorio sigue funcionando de forma lineal
+		fmt.Printf("Advertencia: no se pudo crear el indice del directorio %d: %v\n", d.IndiceInodo, err)
+		return nil
+	}
+	inodo := NuevoInodoVacio()
+	inodo.I_type[0] = '1'
+	inodo.I_uid, inodo.I_gid, inodo.I_perm = d.Inodo.I_uid, d.Inodo.I_gid, d.Inodo.I_perm
+
+	var desplazada *EntradaDirectorio
+	for _, entrada := range d.Entradas() {
+		if entrada.inicio <= PosicionMarcaIndice && PosicionMarcaIndice < entrada.inicio+entrada.cantidad {
+			copia := entrada
+			desplazada = &copia
+			break
+		}
+	}
+	if desplazada != nil {
+		d.Eliminar(*desplazada)
+	}
+
+	d.indice = &IndiceDirectorio{IndiceInodo: indiceInodo, inodo: inodo, tamanoBloque: sb.S_block_size, sucios: make(map[int32]bool)}
+	d.escribirContenido(PosicionMarcaIndice, contenidoMarcaIndice(indiceInodo))
+	d.indice.reconstruir(d.Entradas())
+	fmt.Printf("Directorio %d indexado en el inodo %d\n", d.IndiceInodo, indiceInodo)
+
+	if desplazada != nil {
+		return d.Agregar(archivo, sb, desplazada.Nombre, desplazada.Inodo)
+	}
+	return nil
+}
+
+// indexar registra en el indice la entrada recien agregada, creando el indice cuando el
+// directorio alcanza el umbral de bloques
+func (d *Directorio) indexar(archivo *os.File, sb *SuperBlock, nombre string, posicion int) error {
+	if d.indice == nil {
+		if len(d.bloques) < UmbralBloquesIndice {
+			return nil
+		}
+		return d.crearIndice(archivo, sb)
+	}
+	d.indice.insertar(d, HashNombre(nombre), int32(posicion))
+	return nil
+}
+
+// LiberarIndice libera el inodo y los bloques del indice (al eliminar el directorio)
+func (d *Directorio) LiberarIndice(archivo *os.File, sb *SuperBlock) error {
+	if d.indice == nil {
+		return nil
+	}
+	if err := d.indice.inodo.LiberarTodosLosBloques(archivo, sb); err != nil {
+		return fmt.Errorf("error liberando bloques del indice: %w", err)
+	}
+	if err := sb.LiberarInodo(archivo, d.indice.IndiceInodo); err != nil {
+		return fmt.Errorf("error liberando el inodo del indice: %w", err)
+	}
+	d.indice = nil
+	return nil
+}
+
+// BuscarEntrada localiza una entrada del directorio indicado. Si el directorio tiene
+// indice solo se leen el primer bloque, las ranuras sondeadas y el bloque de la
+// entrada; si no lo tiene (o el indice no es legible) se recorre de forma lineal
+func (sb *SuperBlock) BuscarEntrada(archivo *os.File, indiceInodo int32, nombre string) (EntradaDirectorio, bool, error) {
+	nombre = strings.Trim(nombre, "\x00 ")
+
+	inodo := &INodo{}
+	if err := inodo.Decodificar(archivo, sb.CalcularDesplazamientoInodo(indiceInodo)); err != nil {
+		return EntradaDirectorio{}, false, fmt.Errorf("error al deserializar inodo %d: %w", indiceInodo, err)
+	}
+	if inodo.I_type[0] != '0' {
+		return EntradaDirectorio{}, false, fmt.Errorf("el inodo %d no es una carpeta", indiceInodo)
+	}
+
+	if inodo.I_block[0] != -1 {
+		primero := NuevoFolderBlock(sb.S_block_size)
+		if err := primero.Decodificar(archivo, int64(sb.S_block_start+inodo.I_block[0]*sb.S_block_size)); err != nil {
+			return EntradaDirectorio{}, false, fmt.Errorf("error deserializando bloque %d: %w", inodo.I_block[0], err)
+		}
+		if inodoIndice, ok := InodoDeMarca(primero.B_cont[PosicionMarcaIndice]); ok {
+			entrada, existe, err := sb.buscarConIndice(archivo, inodo, inodoIndice, nombre)
+			if err == nil {
+				return entrada, existe, nil
+			}
+			fmt.Printf("Advertencia: indice del directorio %d no disponible (%v), se busca de forma lineal\n", indiceInodo, err)
+		}
+	}
+
+	directorio, err := sb.LeerDirectorio(archivo, indiceInodo)
+	if err != nil {
+		return EntradaDirectorio{}, false, err
+	}
+	entrada, existe := directorio.Buscar(nombre)
+	return entrada, existe, nil
+}
+
+// Sondea la tabla del indice leyendo solo los bloques de las ranuras visitadas
+func (sb *SuperBlock) buscarConIndice(archivo *os.File, inodoDirectorio *INodo, inodoIndice int32, nombre string) (EntradaDirectorio, bool, error) {
+	inodo := &INodo{}
+	if err := inodo.Decodificar(archivo, sb.CalcularDesplazamientoInodo(inodoIndice)); err != nil {
+		return EntradaDirectorio{}, false, fmt.Errorf("error al deserializar el inodo del indice %d: %w", inodoIndice, err)
+	}
+
+	bloques := make(map[int32][]byte)
+	leer := func(desplazamiento int32, cantidad int32) ([]byte, error) {
+		logico := desplazamiento / sb.S_block_size
+		datos, ok := bloques[logico]
+		if !ok {
+			fisico, err := inodo.BloqueDatos(archivo, sb, logico)
+			if err != nil {
+				return nil, err
+			}
+			bloque := NuevoFileBlockVacio(sb.S_block_size)
+			if err := bloque.Decodificar(archivo, int64(sb.S_block_start+fisico*sb.S_block_size)); err != nil {
+				return nil, err
+			}
+			datos = bloque.B_cont
+			bloques[logico] = datos
+		}
+		inicio := desplazamiento % sb.S_block_size
+		return datos[inicio : inicio+cantidad], nil
+	}
+
+	cabecera, err := leer(0, dimensionCabecera)
+	if err != nil {
+		return EntradaDirectorio{}, false, err
+	}
+	if binary.LittleEndian.Uint32(cabecera[0:]) != firmaIndice {
+		return EntradaDirectorio{}, false, errors.New("firma del indice invalida")
+	}
+	capacidad := binary.LittleEndian.Uint32(cabecera[4:])
+	if capacidad < capacidadMinimaIndice || capacidad&(capacidad-1) != 0 {
+		return EntradaDirectorio{}, false, fmt.Errorf("capacidad del indice invalida: %d", capacidad)
+	}
+
+	hash := HashNombre(nombre)
+	mascara := capacidad - 1
+	for i, sondeos := hash&mascara, uint32(0); sondeos < capacidad; i, sondeos = (i+1)&mascara, sondeos+1 {
+		ranura, err := leer(int32(dimensionCabecera+i*dimensionRanura), dimensionRanura)
+		if err != nil {
+			return EntradaDirectorio{}, false, err
+		}
+		posicion := int32(binary.LittleEndian.Uint32(ranura[4:]))
+		if posicion == ranuraVacia {
+			return EntradaDirectorio{}, false, nil
+		}
+		if posicion == ranuraBorrada || binary.LittleEndian.Uint32(ranura[0:]) != hash {
+			continue
+		}
+		entrada, err := sb.leerEntradaEnPosicion(archivo, inodoDirectorio, posicion)
+		if err != nil {
+			return EntradaDirectorio{}, false, err
+		}
+		if strings.EqualFold(entrada.Nombre, nombre) {
+			return entrada, true, nil
+		}
+	}
+	return EntradaDirectorio{}, false, nil
+}
+
+// Lee la entrada cuyo primer contenido esta en la posicion indicada del directorio
+func (sb *SuperBlock) leerEntradaEnPosicion(archivo *os.File, inodoDirectorio *INodo, posicion int32) (EntradaDirectorio, error) {
+	porBloque := sb.S_block_size / DimensionContenidoCarpeta
+	var bloque *FolderBlock
+	var fisico int32 = -1
+	var fragmentos []byte
+
+	for p := posicion; ; p++ {
+		if bloque == nil || p%porBloque == 0 {
+			var err error
+			if fisico, err = inodoDirectorio.BloqueDatos(archivo, sb, p/porBloque); err != nil {
+				return EntradaDirectorio{}, err
+			}
+			bloque = NuevoFolderBlock(sb.S_block_size)
+			if err := bloque.Decodificar(archivo, int64(sb.S_block_start+fisico*sb.S_block_size)); err != nil {
+				return EntradaDirectorio{}, fmt.Errorf("error deserializando bloque %d: %w", fisico, err)
+			}
+		}
+
+		contenido := bloque.B_cont[p%porBloque]
+		switch {
+		case contenido.B_inodo == InodoContinuacion:
+			fragmentos = append(fragmentos, contenido.B_name[:]...)
+		case contenido.B_inodo < 0:
+			return EntradaDirectorio{}, fmt.Errorf("el indice apunta a la posicion %d que no contiene una entrada", posicion)
+		default:
+			return EntradaDirectorio{
+				Nombre:   nombreEntrada(fragmentos, contenido),
+				Inodo:    contenido.B_inodo,
+				Bloque:   fisico,
+				inicio:   int(posicion),
+				cantidad: int(p-posicion) + 1,
+			}, nil
+		}
+	}
+}
@@ -17,21 +17,61 @@ type MBR struct {
     MbrDiskSignature int32        // Identificador único del disco (generado aleatoriamente)
     MbrDiskFit       [1]byte      // Algoritmo de asignación: BF, FF, WF
     MbrPartitions    [4]Particion // Tabla de particiones (máximo 4 entradas)
+    MbrCompatible    [1]byte      // '1' si el disco tiene ademas un MBR estandar (mkdisk -compat)
+    MbrChecksum      uint32       // CRC32 de los campos anteriores
 }
 
+// Bytes del MBR antes de MbrCompatible y MbrChecksum
+var tamanoMBRAnterior = binary.Size(MBR{}) - 5
+
 // Serializa la estructura MBR hacia el archivo
 func (mbr *MBR) Codificar(archivo *os.File) error {
-    return Utils.EscribirAArchivo(archivo, 0, mbr) // Persistir MBR al inicio del archivo
+    if mbr.formatoAnterior() {
+        // Los campos nuevos pisarian el inicio de la primera particion
+        mbr.MbrCompatible, mbr.MbrChecksum = [1]byte{}, 0
+        return escribirFormatoAnterior(archivo, 0, mbr, tamanoMBRAnterior)
+    }
+    mbr.MbrChecksum, _ = calcularChecksum(mbr)
+    if err := Utils.EscribirAArchivo(archivo, 0, mbr); err != nil { // Persistir MBR al inicio del archivo
+        return err
+    }
+    return SincronizarTablaCompatible(archivo, mbr)
 }
 
-// Reconstruye la estructura MBR desde el archivo
+// Reconstruye la estructura MBR desde el archivo y valida su checksum
 func (mbr *MBR) Decodificar(archivo *os.File) error {
-    return Utils.LeerDeArchivo(archivo, 0, mbr) // Leer MBR desde el inicio del archivo
+    if err := Utils.LeerDeArchivo(archivo, 0, mbr); err != nil { // Leer MBR desde el inicio del archivo
+        return err
+    }
+    anterior := mbr.formatoAnterior()
+    err := verificarChecksumAnterior("MBR", 0, mbr, mbr.MbrChecksum, anterior)
+    if errors.Is(err, ErrSinChecksum) {
+        // Lo leido en los campos nuevos pertenece a la primera particion
+        mbr.MbrCompatible, mbr.MbrChecksum = [1]byte{}, 0
+        return nil
+    }
+    return err
+}
+
+// formatoAnterior indica que el disco se creo antes de MbrCompatible y MbrChecksum: alguna
+// particion empieza donde ahora estarian esos campos
+func (mbr *MBR) formatoAnterior() bool {
+    for _, particion := range mbr.MbrPartitions {
+        if particion.Part_start >= int32(tamanoMBRAnterior) && particion.Part_start < int32(binary.Size(MBR{})) {
+            return true
+        }
+    }
+    return false
+}
+
+// SinChecksum indica que el MBR esta en el formato anterior y no se pudo verificar
+func (mbr *MBR) SinChecksum() bool {
+    return mbr.MbrChecksum == 0
 }
 
 // Método para obtener la primera partición disponible
 func (mbr *MBR) GetFirstAvailablePartition() (*Particion, int, int) {
-    offset := binary.Size(mbr)
+    offset := int(mbr.InicioUsable())
     for i := 0; i < len(mbr.MbrPartitions); i++ {
         if mbr.MbrPartitions[i].Part_start == -1 {
             return &mbr.MbrPartitions[i], offset, i
@@ -44,7 +84,7 @@ func (mbr *MBR) GetFirstAvailablePartition() (*Particion, int, int) {
 
 func (mbr *MBR) ObtenerPrimeraParticionDisponible() (*Particion, int, int) {
     // Calcular desplazamiento inicial considerando el MBR
-    desplazamiento := binary.Size(mbr) // Dimensión del MBR en bytes
+    desplazamiento := int(mbr.InicioUsable()) // Dimensión del MBR (o sector reservado) en bytes
 
     // Explorar tabla de particiones
     for i := 0; i < len(mbr.MbrPartitions); i++ {
@@ -133,7 +173,7 @@ func (mbr *MBR) VerificarParticionExtendida() bool {
 // CalculateAvailableSpace calcula el espacio disponible en el disco.
 func (mbr *MBR) CalculateAvailableSpace() (int32, error) {
     totalSize := mbr.MbrSize
-    usedSpace := int32(binary.Size(MBR{}))
+    usedSpace := mbr.InicioUsable()
 
     partitions := mbr.MbrPartitions[:]
     for _, part := range partitions {
@@ -151,7 +191,7 @@ func (mbr *MBR) CalculateAvailableSpace() (int32, error) {
 
 func (mbr *MBR) CalcularEspacioDisponible() (int32, error) {
     capacidadTotal := mbr.MbrSize
-    espacioUsado := int32(binary.Size(*mbr))
+    espacioUsado := mbr.InicioUsable()
 
     particiones := mbr.MbrPartitions[:]
     for _, part := range particiones {
@@ -325,7 +365,7 @@ func (mbr *MBR) CalcularEspacioDisponibleParaParticion(particion *Particion) (in
     var siguienteInicioParticion int32 = -1
 
     for _, p := range mbr.MbrPartitions {
-        if p.Part_start > finParticion && (siguienteInicioParticion == -1 || p.Part_start < siguienteInicioParticion) {
+        if p.Part_start >= finParticion && (siguienteInicioParticion == -1 || p.Part_start < siguienteInicioParticion) {
             siguienteInicioParticion = p.Part_start
         }
     }
@@ -345,7 +385,7 @@ func (mbr *MBR) CalcularEspacioDisponibleParaParticion(particion *Particion) (in
 // AplicarPrimerAjuste: Encuentra el primer espacio disponible que sea mayor o igual al tamaño de la partición
 func (mbr *MBR) AplicarPrimerAjuste(tamanoParticion int32) (*Particion, error) {
     fmt.Println("Iniciando First Fit...")
-    desplazamiento := binary.Size(*mbr)
+    desplazamiento := int(mbr.InicioUsable())
     for i := 0; i < len(mbr.MbrPartitions); i++ {
         particion := &mbr.MbrPartitions[i]
         fmt.Printf("Evaluando partición %d: Inicio %d, Tamaño %d, Estado %c\n", i, particion.Part_start, particion.Part_size, particion.Part_status[0])
@@ -367,7 +407,7 @@ func (mbr *MBR) AplicarPrimerAjuste(tamanoParticion int32) (*Particion, error) {
 func (mbr *MBR) AplicarMejorAjuste(tamanoParticion int32) (*Particion, error) {
     fmt.Println("Iniciando Best Fit...")
     mejorAjuste := -1
-    desplazamiento := binary.Size(*mbr)
+    desplazamiento := int(mbr.InicioUsable())
     
     for i := 0; i < len(mbr.MbrPartitions); i++ {
         particion := &mbr.MbrPartitions[i]
@@ -396,7 +436,7 @@ func (mbr *MBR) AplicarMejorAjuste(tamanoParticion int32) (*Particion, error) {
 func (mbr *MBR) AplicarPeorAjuste(tamanoParticion int32) (*Particion, error) {
     fmt.Println("Iniciando Worst Fit...")
     peorAjuste := -1
-    desplazamiento := binary.Size(*mbr)
+    desplazamiento := int(mbr.InicioUsable())
     
     for i := 0; i < len(mbr.MbrPartitions); i++ {
         particion := &mbr.MbrPartitions[i]
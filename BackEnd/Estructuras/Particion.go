@@ -83,16 +83,21 @@ func (p *Particion) Eliminar(tipoEliminacion string, archivo *os.File, esExtendi
     return nil
 }
 
-// Metodo que sobrescribe el espacio de la particion con \0 (para eliminacion Full)
+// Metodo que sobrescribe el espacio de la particion con \0 (para eliminacion Full).
+// Los ceros se escriben siempre de forma explicita, aunque el disco sea disperso, en
+// trozos de 64 KB para no reservar en memoria toda la particion
 func (p *Particion) Sobrescribir(archivo *os.File) error {
-    _, err := archivo.Seek(int64(p.Part_start), 0)
-    if err != nil {
-        return err
-    }
-    ceros := make([]byte, p.Part_size)
-    _, err = archivo.Write(ceros)
-    if err != nil {
-        return fmt.Errorf("error al sobrescribir el espacio de la partición: %v", err)
+    ceros := make([]byte, 64*1024)
+    posicion, fin := int64(p.Part_start), int64(p.Part_start)+int64(p.Part_size)
+    for posicion < fin {
+        trozo := ceros
+        if restante := fin - posicion; restante < int64(len(trozo)) {
+            trozo = trozo[:restante]
+        }
+        if _, err := archivo.WriteAt(trozo, posicion); err != nil {
+            return fmt.Errorf("error al sobrescribir el espacio de la partición: %v", err)
+        }
+        posicion += int64(len(trozo))
     }
 
     fmt.Printf("Espacio de la partición sobrescrito con ceros.\n")
@@ -6,10 +6,22 @@ import (
 	"os"
 )
 
+// Dimension de cada apuntador dentro de un bloque de apuntadores
+const DimensionApuntador = 8
+
 // Estructura para almacenar bloques de punteros
 type PointerBlock struct {
-	B_apuntadores [16]int64
-	// Punteros a bloques de carpetas o datos
+	B_apuntadores []int64
+	// Punteros a bloques de carpetas o datos, S_block_size / 8 por bloque
+}
+
+// NuevoPointerBlock crea un bloque de apuntadores con todos los punteros libres (-1)
+func NuevoPointerBlock(tamanoBloque int32) *PointerBlock {
+	ba := &PointerBlock{B_apuntadores: make([]int64, tamanoBloque/DimensionApuntador)}
+	for i := range ba.B_apuntadores {
+		ba.B_apuntadores[i] = -1
+	}
+	return ba
 }
 
 // LeerIndireccioSimple lee los bloques a través de indirección simple
@@ -29,7 +41,7 @@ func (ba *PointerBlock) LeerIndireccioDoble(archivo *os.File, sb *SuperBlock) ([
     for _, apuntador := range ba.B_apuntadores {
         if apuntador != -1 {
             // Leer el bloque de apuntadores secundario
-            bloqueSecundario := &PointerBlock{}
+            bloqueSecundario := NuevoPointerBlock(sb.S_block_size)
             err := bloqueSecundario.Decodificar(archivo, int64(sb.S_block_start+int32(apuntador)*sb.S_block_size))
             if err != nil {
                 return nil, err
@@ -121,13 +133,20 @@ func (ba *PointerBlock) ContarApuntadoresLibres() int {
 
 // Codificar serializa el PointerBlock en el archivo en la posicion especificada
 func (ba *PointerBlock) Codificar(archivo *os.File, desplazamiento int64) error {
+	if cache := cacheDe(archivo, desplazamiento); cache != nil {
+		return cache.escribirApuntadores(desplazamiento, ba.B_apuntadores)
+	}
+	return ba.codificarDirecto(archivo, desplazamiento)
+}
+
+func (ba *PointerBlock) codificarDirecto(archivo *os.File, desplazamiento int64) error {
 	_, err := archivo.Seek(desplazamiento, 0)
 	if err != nil {
 		return fmt.Errorf("error posicionando en el archivo: %w", err)
 	}
 
 	// Escribir estructura PointerBlock en el archivo
-	err = binary.Write(archivo, binary.BigEndian, ba)
+	err = binary.Write(archivo, binary.BigEndian, ba.B_apuntadores)
 	if err != nil {
 		return fmt.Errorf("error escribiendo el PointerBlock: %w", err)
 	}
@@ -136,13 +155,23 @@ func (ba *PointerBlock) Codificar(archivo *os.File, desplazamiento int64) error
 
 // Decodificar deserializa el PointerBlock desde el archivo en la posicion especificada
 func (ba *PointerBlock) Decodificar(archivo *os.File, desplazamiento int64) error {
+	if len(ba.B_apuntadores) == 0 {
+		return fmt.Errorf("el PointerBlock no tiene dimension, use NuevoPointerBlock")
+	}
+	if cache := cacheDe(archivo, desplazamiento); cache != nil {
+		return cache.leerApuntadores(desplazamiento, ba.B_apuntadores)
+	}
+	return ba.decodificarDirecto(archivo, desplazamiento)
+}
+
+func (ba *PointerBlock) decodificarDirecto(archivo *os.File, desplazamiento int64) error {
 	_, err := archivo.Seek(desplazamiento, 0)
 	if err != nil {
 		return fmt.Errorf("error posicionando en el archivo: %w", err)
 	}
 
 	// Leer estructura PointerBlock desde el archivo
-	err = binary.Read(archivo, binary.BigEndian, ba)
+	err = binary.Read(archivo, binary.BigEndian, ba.B_apuntadores)
 	if err != nil {
 		return fmt.Errorf("error leyendo el PointerBlock: %w", err)
 	}
@@ -155,7 +184,7 @@ func (ba *PointerBlock) LeerIndireccioTriple(archivo *os.File, sb *SuperBlock) (
     for _, apuntadorPrimario := range ba.B_apuntadores {
         if apuntadorPrimario != -1 {
             // Leer el bloque de apuntadores secundario
-            bloqueSecundario := &PointerBlock{}
+            bloqueSecundario := NuevoPointerBlock(sb.S_block_size)
             offsetSecundario := int64(sb.S_block_start + int32(apuntadorPrimario)*sb.S_block_size)
             if err := bloqueSecundario.Decodificar(archivo, offsetSecundario); err != nil {
                 return nil, fmt.Errorf("error leyendo bloque secundario: %w", err)
@@ -164,7 +193,7 @@ func (ba *PointerBlock) LeerIndireccioTriple(archivo *os.File, sb *SuperBlock) (
             for _, apuntadorSecundario := range bloqueSecundario.B_apuntadores {
                 if apuntadorSecundario != -1 {
                     // Leer el bloque de apuntadores terciario
-                    bloqueTerciario := &PointerBlock{}
+                    bloqueTerciario := NuevoPointerBlock(sb.S_block_size)
                     offsetTerciario := int64(sb.S_block_start + int32(apuntadorSecundario)*sb.S_block_size)
                     if err := bloqueTerciario.Decodificar(archivo, offsetTerciario); err != nil {
                         return nil, fmt.Errorf("error leyendo bloque terciario: %w", err)
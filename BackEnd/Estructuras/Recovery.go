@@ -1,9 +1,7 @@
 package Estructuras
 
 import (
-    "backend/Utils"
     "bytes"
-    "encoding/binary"
     "fmt"
     "os"
     "strings"
@@ -36,11 +34,12 @@ func garantizarDirectorioRaiz(archivo *os.File, sb *SuperBlock) error {
 
     fmt.Println("[RECUPERACION]  Inicializando inodo 0 como directorio raíz")
 
-    /* actualizar bitmaps */
+    /* actualizar bitmaps (con grupos el primer bloque libre no es el 0) */
     if err := sb.ActualizarBitmapInodo(archivo, 0, true); err != nil {
         return err
     }
-    if err := sb.ActualizarBitmapBloque(archivo, 0, true); err != nil {
+    bloqueRaiz, err := sb.BuscarSiguienteBloqueLibre(archivo)
+    if err != nil {
         return err
     }
 
@@ -48,19 +47,19 @@ func garantizarDirectorioRaiz(archivo *os.File, sb *SuperBlock) error {
     raiz := NuevoInodoVacio()
     raiz.I_type[0] = '0'
     raiz.I_perm = [3]byte{'7', '7', '7'}
-    raiz.I_block[0] = 0
+    raiz.I_block[0] = bloqueRaiz
     if err := raiz.Codificar(archivo, int64(sb.S_inode_start)); err != nil {
         return err
     }
 
     /* configurar bloque raíz */
-    bloque0 := NuevoBloqueDirectorio(0, 0, map[string]int32{})
-    if err := bloque0.Codificar(archivo, int64(sb.S_block_start)); err != nil {
+    bloque0 := NuevoBloqueDirectorio(sb.S_block_size, 0, 0, map[string]int32{})
+    if err := bloque0.Codificar(archivo, sb.desplazamientoBloque(bloqueRaiz)); err != nil {
         return err
     }
 
-    sb.S_inodes_count = 1
-    sb.S_blocks_count = 1
+    sb.S_inodes_count++
+    sb.S_blocks_count++
     sb.S_free_inodes_count--
     sb.S_free_blocks_count--
     sb.S_first_ino += sb.S_inode_size
@@ -68,15 +67,34 @@ func garantizarDirectorioRaiz(archivo *os.File, sb *SuperBlock) error {
     return nil
 }
 
-// borrarEstructurasSistema limpia bitmaps, inodos y bloques del sistema
+// borrarEstructurasSistema limpia bitmaps, inodos y bloques del sistema y deja todos los
+// inodos y bloques libres; con grupos vuelve a reservar los metadatos de cada grupo
 func borrarEstructurasSistema(archivo *os.File, sb *SuperBlock) error {
     fmt.Println("[RECUPERACION]  Limpieza de bitmaps, inodos y bloques…")
-    return EjecutarLimpiezaLoss(archivo, sb) // ya imprime información de rangos
+    if err := EjecutarLimpiezaLoss(archivo, sb); err != nil { // ya imprime información de rangos
+        return err
+    }
+
+    sb.S_free_inodes_count += sb.S_inodes_count
+    sb.S_inodes_count = 0
+    sb.S_free_blocks_count += sb.S_blocks_count
+    sb.S_blocks_count = 0
+    sb.S_first_ino = sb.S_inode_start
+    sb.S_first_blo = sb.S_block_start
+    if sb.S_groups_count > 0 {
+        if err := sb.ReservarMetadatosGrupos(archivo); err != nil {
+            return err
+        }
+        if err := sb.EscribirRespaldosGrupos(archivo); err != nil {
+            return err
+        }
+    }
+    return nil
 }
 
 // reproducirJournal aplica las operaciones registradas en el journal
 func reproducirJournal(archivo *os.File, sb *SuperBlock, inicioParticion int32) error {
-    inicioJournal := int64(inicioParticion) + int64(binary.Size(SuperBlock{}))
+    inicioJournal := int64(sb.InicioJournal())
     fmt.Printf("[RECUPERACION]  Leyendo journal en posición=%d\n", inicioJournal)
 
     entradas, err := EncontrarEntradasJournalValidas(archivo, inicioJournal, ENTRADAS_JOURNAL)
@@ -110,9 +128,8 @@ func reproducirJournal(archivo *os.File, sb *SuperBlock, inicioParticion int32)
             fmt.Printf("[RECUPERACION:%02d]   ✓ carpeta creada\n", indice+1)
 
         case "mkfile":
-            fragmentos := Utils.DividirCadenaEnChunks(datos)
             if err := sb.CrearArchivo(archivo, directoriosPadre, nombreElemento,
-                len(datos), fragmentos, false); err != nil {
+                len(datos), []byte(datos), false); err != nil {
                 return fmt.Errorf("reproducir mkfile %s: %w", ruta, err)
             }
             fmt.Printf("[RECUPERACION:%02d]   ✓ archivo (%d bytes) creado\n",
@@ -145,7 +162,12 @@ func RecuperarSistemaArchivos(archivo *os.File, sb *SuperBlock, inicioParticion
         return err
     }
 
-    /* persistir cambios del superbloque */
+    /* persistir cambios del superbloque y de los descriptores de grupo */
+    if sb.S_groups_count > 0 {
+        if err := sb.EscribirDescriptoresGrupo(archivo); err != nil {
+            return err
+        }
+    }
     sb.S_mtime = float64(time.Now().Unix())
     if err := sb.Codificar(archivo, int64(inicioParticion)); err != nil {
         return err
@@ -0,0 +1,251 @@
+package Estructuras
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Errores de resolucion de rutas; se comparan con errors.Is
+var (
+	ErrRutaNoEncontrada = errors.New("no existe")
+	ErrNoEsDirectorio   = errors.New("no es un directorio")
+	ErrPermisoDenegado  = errors.New("permiso denegado")
+	ErrBucleEnlaces     = errors.New("demasiados niveles de enlaces simbolicos")
+	ErrArchivoSistema   = errors.New("archivo del sistema, solo root lo puede crear, eliminar, renombrar o mover")
+)
+
+// EsArchivoSistema indica si la entrada 'nombre' de la carpeta 'indiceCarpeta' es users.txt
+// o el archivo de cuotas. Sin ellos dejarian de aplicarse las cuotas
+func EsArchivoSistema(indiceCarpeta int32, nombre string) bool {
+	return indiceCarpeta == 0 && (nombre == "users.txt" || nombre == nombreArchivoCuotas)
+}
+
+// RevisarArchivoSistema rechaza que quien no es root cree, elimine, renombre o mueva un
+// archivo del sistema
+func (c Credenciales) RevisarArchivoSistema(ruta string, indiceCarpeta int32, nombre string) error {
+	if !c.Root && EsArchivoSistema(indiceCarpeta, nombre) {
+		return &ErrorRuta{Ruta: ruta, Componente: nombre, Err: ErrArchivoSistema}
+	}
+	return nil
+}
+
+// Bits de permiso de cada digito de I_perm
+const (
+	PermisoLectura   byte = 4
+	PermisoEscritura byte = 2
+	PermisoEjecucion byte = 1
+)
+
+// ErrorRuta indica en que componente de la ruta fallo la resolucion
+type ErrorRuta struct {
+	Ruta       string
+	Componente string
+	Err        error
+}
+
+func (e *ErrorRuta) Error() string {
+	return fmt.Sprintf("%v: '%s' (ruta '%s')", e.Err, e.Componente, e.Ruta)
+}
+
+func (e *ErrorRuta) Unwrap() error {
+	return e.Err
+}
+
+// Credenciales identifican a quien recorre la ruta para evaluar los permisos
+type Credenciales struct {
+	Uid  int32
+	Gid  int32
+	Root bool
+}
+
+// CredencialesRoot omiten la revision de permisos (reportes y operaciones internas)
+var CredencialesRoot = Credenciales{Uid: 1, Gid: 1, Root: true}
+
+// Permite evalua el digito de I_perm que corresponde (propietario, grupo u otros)
+func (c Credenciales) Permite(inodo *INodo, permiso byte) bool {
+	if c.Root {
+		return true
+	}
+	digito := inodo.I_perm[2]
+	switch {
+	case inodo.I_uid == c.Uid:
+		digito = inodo.I_perm[0]
+	case inodo.I_gid == c.Gid:
+		digito = inodo.I_perm[1]
+	}
+	return (digito-'0')&permiso != 0
+}
+
+// RutaResuelta es el resultado de resolver una ruta absoluta
+type RutaResuelta struct {
+	Inodo        int32  // inodo del elemento
+	Padre        int32  // inodo del directorio que lo contiene (la raiz es su propio padre)
+	Nombre       string // nombre del elemento tal como esta guardado en su directorio
+	EsDirectorio bool
+}
+
+// componentesRuta separa la ruta omitiendo '/' repetidas y '.'; directorio indica que la
+// ruta solo puede nombrar una carpeta (termina en '/', '.' o '..')
+func componentesRuta(ruta string) (componentes []string, directorio bool) {
+	for _, componente := range strings.Split(ruta, "/") {
+		componente = strings.Trim(componente, "\x00 ")
+		if componente == "" || componente == "." {
+			continue
+		}
+		componentes = append(componentes, componente)
+	}
+	ultimo := strings.TrimRight(ruta, "\x00 ")
+	directorio = strings.HasSuffix(ultimo, "/") || strings.HasSuffix(ultimo, "/.") ||
+		strings.HasSuffix(ultimo, "/..") || ultimo == "." || ultimo == ".."
+	return componentes, directorio
+}
+
+// leerInodoRuta deserializa el inodo indicado
+func (sb *SuperBlock) leerInodoRuta(archivo *os.File, indiceInodo int32) (*INodo, error) {
+	inodo := &INodo{}
+	if err := inodo.Decodificar(archivo, sb.CalcularDesplazamientoInodo(indiceInodo)); err != nil {
+		return nil, fmt.Errorf("error al deserializar inodo %d: %w", indiceInodo, err)
+	}
+	return inodo, nil
+}
+
+// recorrerRuta desciende desde la raiz por cada componente. Antes de buscar un nombre en
+// una carpeta se exige permiso de ejecucion sobre ella; '..' se resuelve con la entrada
+// guardada en el directorio. El archivo de cuotas solo existe para root. Los enlaces simbolicos intermedios siempre se siguen y el
+// ultimo solo si seguirUltimo; su destino se recorre desde la raiz si es absoluto o desde
+// la carpeta que contiene al enlace. Seguir mas de MaximoEnlacesSeguidos indica un ciclo
+func (sb *SuperBlock) recorrerRuta(archivo *os.File, ruta string, componentes []string, seguirUltimo bool, cred Credenciales) (RutaResuelta, *INodo, error) {
+	resuelta := RutaResuelta{Inodo: 0, Padre: 0, Nombre: "/"}
+	inodo, err := sb.leerInodoRuta(archivo, 0)
+	if err != nil {
+		return resuelta, nil, err
+	}
+
+	pendientes := append([]string(nil), componentes...)
+	enlacesSeguidos := 0
+	for len(pendientes) > 0 {
+		componente := pendientes[0]
+		pendientes = pendientes[1:]
+
+		if inodo.I_type[0] != '0' {
+			return resuelta, nil, &ErrorRuta{Ruta: ruta, Componente: resuelta.Nombre, Err: ErrNoEsDirectorio}
+		}
+		if !cred.Permite(inodo, PermisoEjecucion) {
+			return resuelta, nil, &ErrorRuta{Ruta: ruta, Componente: resuelta.Nombre, Err: ErrPermisoDenegado}
+		}
+
+		entrada, existe, err := sb.BuscarEntrada(archivo, resuelta.Inodo, componente)
+		if err != nil {
+			return resuelta, nil, err
+		}
+		if !existe || (!cred.Root && esArchivoCuotas(resuelta.Inodo, entrada.Nombre)) {
+			return resuelta, nil, &ErrorRuta{Ruta: ruta, Componente: componente, Err: ErrRutaNoEncontrada}
+		}
+
+		siguiente, err := sb.leerInodoRuta(archivo, entrada.Inodo)
+		if err != nil {
+			return resuelta, nil, err
+		}
+
+		if siguiente.EsEnlaceSimbolico() && (len(pendientes) > 0 || seguirUltimo) {
+			enlacesSeguidos++
+			if enlacesSeguidos > MaximoEnlacesSeguidos {
+				return resuelta, nil, &ErrorRuta{Ruta: ruta, Componente: entrada.Nombre, Err: ErrBucleEnlaces}
+			}
+			destino, err := sb.LeerDestinoEnlace(archivo, siguiente)
+			if err != nil {
+				return resuelta, nil, err
+			}
+			if strings.HasPrefix(destino, "/") {
+				resuelta = RutaResuelta{Inodo: 0, Padre: 0, Nombre: "/"}
+				if inodo, err = sb.leerInodoRuta(archivo, 0); err != nil {
+					return resuelta, nil, err
+				}
+			}
+			componentesDestino, _ := componentesRuta(destino)
+			pendientes = append(componentesDestino, pendientes...)
+			continue
+		}
+
+		resuelta = RutaResuelta{Inodo: entrada.Inodo, Padre: resuelta.Inodo, Nombre: entrada.Nombre}
+		inodo = siguiente
+	}
+
+	resuelta.EsDirectorio = inodo.I_type[0] == '0'
+	return resuelta, inodo, nil
+}
+
+// ResolverRuta obtiene el inodo de un archivo o carpeta a partir de su ruta absoluta.
+// Admite '.', '..' y '/' repetidas o finales (una '/' final exige que sea carpeta) y
+// sigue los enlaces simbolicos, incluido el ultimo componente
+func (sb *SuperBlock) ResolverRuta(archivo *os.File, ruta string, cred Credenciales) (RutaResuelta, error) {
+	return sb.resolverRuta(archivo, ruta, true, cred)
+}
+
+// ResolverRutaSinSeguir es como ResolverRuta pero, si el ultimo componente es un enlace
+// simbolico, retorna el enlace en lugar de su destino (salvo que la ruta termine en '/')
+func (sb *SuperBlock) ResolverRutaSinSeguir(archivo *os.File, ruta string, cred Credenciales) (RutaResuelta, error) {
+	return sb.resolverRuta(archivo, ruta, false, cred)
+}
+
+func (sb *SuperBlock) resolverRuta(archivo *os.File, ruta string, seguirUltimo bool, cred Credenciales) (RutaResuelta, error) {
+	componentes, directorio := componentesRuta(ruta)
+	resuelta, _, err := sb.recorrerRuta(archivo, ruta, componentes, seguirUltimo || directorio, cred)
+	if err != nil {
+		return RutaResuelta{}, err
+	}
+	if directorio && !resuelta.EsDirectorio {
+		return RutaResuelta{}, &ErrorRuta{Ruta: ruta, Componente: resuelta.Nombre, Err: ErrNoEsDirectorio}
+	}
+	return resuelta, nil
+}
+
+// ResolverDirectorio obtiene el inodo de la carpeta nombrada por la ruta
+func (sb *SuperBlock) ResolverDirectorio(archivo *os.File, ruta string, cred Credenciales) (int32, error) {
+	resuelta, err := sb.ResolverRuta(archivo, ruta, cred)
+	if err != nil {
+		return -1, err
+	}
+	if !resuelta.EsDirectorio {
+		return -1, &ErrorRuta{Ruta: ruta, Componente: resuelta.Nombre, Err: ErrNoEsDirectorio}
+	}
+	return resuelta.Inodo, nil
+}
+
+// ResolverPadre obtiene la carpeta que contiene (o contendra) el ultimo componente de la
+// ruta y el nombre de ese componente, que no necesita existir. Se exige permiso de
+// ejecucion sobre la carpeta porque el llamador buscara el nombre en ella, y el nombre no
+// puede ser un archivo del sistema salvo para root
+func (sb *SuperBlock) ResolverPadre(archivo *os.File, ruta string, cred Credenciales) (int32, string, error) {
+	componentes, _ := componentesRuta(ruta)
+	if len(componentes) == 0 {
+		return -1, "", fmt.Errorf("la ruta '%s' no nombra un elemento dentro de una carpeta", ruta)
+	}
+	nombre := componentes[len(componentes)-1]
+	if nombre == ".." {
+		return -1, "", fmt.Errorf("la ruta '%s' no puede terminar en '..'", ruta)
+	}
+
+	padre, inodo, err := sb.recorrerRuta(archivo, ruta, componentes[:len(componentes)-1], true, cred)
+	if err != nil {
+		return -1, "", err
+	}
+	if !padre.EsDirectorio {
+		return -1, "", &ErrorRuta{Ruta: ruta, Componente: padre.Nombre, Err: ErrNoEsDirectorio}
+	}
+	if !cred.Permite(inodo, PermisoEjecucion) {
+		return -1, "", &ErrorRuta{Ruta: ruta, Componente: padre.Nombre, Err: ErrPermisoDenegado}
+	}
+	if err := cred.RevisarArchivoSistema(ruta, padre.Inodo, nombre); err != nil {
+		return -1, "", err
+	}
+	return padre.Inodo, nombre, nil
+}
+
+// directorioDeComponentes resuelve una lista de carpetas desde la raiz sin revisar
+// permisos; lo usan las operaciones internas que ya recibieron la ruta validada
+func (sb *SuperBlock) directorioDeComponentes(archivo *os.File, directorios []string) (int32, error) {
+	return sb.ResolverDirectorio(archivo, "/"+strings.Join(directorios, "/"), CredencialesRoot)
+}
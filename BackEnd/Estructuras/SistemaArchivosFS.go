@@ -0,0 +1,355 @@
+package Estructuras
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"time"
+)
+
+// SistemaArchivos expone una particion formateada como fs.FS de solo lectura, de modo que
+// fs.WalkDir, http.FS, template.ParseFS o fstest.TestFS funcionen sobre sus inodos. Los
+// permisos no se revisan y las lecturas no cambian la fecha de acceso de los inodos. Open
+// y Stat siguen los enlaces simbolicos; ReadDir, Lstat y ReadLink no
+type SistemaArchivos struct {
+	archivo *os.File
+	sb      *SuperBlock
+}
+
+var (
+	_ fs.FS         = (*SistemaArchivos)(nil)
+	_ fs.ReadDirFS  = (*SistemaArchivos)(nil)
+	_ fs.StatFS     = (*SistemaArchivos)(nil)
+	_ fs.ReadFileFS = (*SistemaArchivos)(nil)
+)
+
+// NuevoSistemaArchivos envuelve el archivo de disco ya abierto y el superbloque de la
+// particion. Close cierra el archivo
+func NuevoSistemaArchivos(archivo *os.File, sb *SuperBlock) *SistemaArchivos {
+	return &SistemaArchivos{archivo: archivo, sb: sb}
+}
+
+// AbrirSistemaArchivos abre en solo lectura el disco y lee el superbloque de la particion
+// que empieza en inicioParticion
+func AbrirSistemaArchivos(rutaDisco string, inicioParticion int64) (*SistemaArchivos, error) {
+	archivo, err := os.Open(rutaDisco)
+	if err != nil {
+		return nil, fmt.Errorf("error al abrir el disco: %w", err)
+	}
+	sb := &SuperBlock{}
+	if err := sb.Decodificar(archivo, inicioParticion); err != nil {
+		archivo.Close()
+		return nil, fmt.Errorf("error al leer el superbloque: %w", err)
+	}
+	if sb.S_magic != 0xEF53 {
+		archivo.Close()
+		return nil, fmt.Errorf("la particion en %d no tiene un sistema de archivos", inicioParticion)
+	}
+	if err := sb.ValidarFormatoInodos(); err != nil {
+		archivo.Close()
+		return nil, err
+	}
+	return NuevoSistemaArchivos(archivo, sb), nil
+}
+
+// Close cierra el archivo de disco
+func (s *SistemaArchivos) Close() error {
+	return s.archivo.Close()
+}
+
+// Open abre un archivo o carpeta. Los nombres siguen fs.ValidPath y los enlaces simbolicos
+// se siguen hasta su destino
+func (s *SistemaArchivos) Open(nombre string) (fs.File, error) {
+	indice, inodo, err := s.resolver("open", nombre, true)
+	if err != nil {
+		return nil, err
+	}
+	info := s.info(path.Base(nombre), indice, inodo)
+	if inodo.I_type[0] == TipoCarpeta {
+		return &carpetaFS{fs: s, nombre: nombre, indice: indice, info: info}, nil
+	}
+	return &archivoFS{fs: s, nombre: nombre, inodo: inodo, info: info}, nil
+}
+
+// Stat retorna la informacion del inodo al que lleva el nombre
+func (s *SistemaArchivos) Stat(nombre string) (fs.FileInfo, error) {
+	indice, inodo, err := s.resolver("stat", nombre, true)
+	if err != nil {
+		return nil, err
+	}
+	return s.info(path.Base(nombre), indice, inodo), nil
+}
+
+// ReadFile retorna exactamente los I_size bytes del archivo
+func (s *SistemaArchivos) ReadFile(nombre string) ([]byte, error) {
+	_, inodo, err := s.resolver("read", nombre, true)
+	if err != nil {
+		return nil, err
+	}
+	if inodo.I_type[0] == TipoCarpeta {
+		return nil, &fs.PathError{Op: "read", Path: nombre, Err: errors.New("es una carpeta")}
+	}
+	datos, err := inodo.LeerDatos(s.archivo, s.sb)
+	if err != nil {
+		return nil, &fs.PathError{Op: "read", Path: nombre, Err: err}
+	}
+	return datos, nil
+}
+
+// ReadDir retorna las entradas de la carpeta ordenadas por nombre, sin '.' ni '..'
+func (s *SistemaArchivos) ReadDir(nombre string) ([]fs.DirEntry, error) {
+	indice, inodo, err := s.resolver("readdir", nombre, true)
+	if err != nil {
+		return nil, err
+	}
+	if inodo.I_type[0] != TipoCarpeta {
+		return nil, &fs.PathError{Op: "readdir", Path: nombre, Err: ErrNoEsDirectorio}
+	}
+	return s.entradas(nombre, indice)
+}
+
+// Lstat es Stat sin seguir el ultimo componente: si es un enlace simbolico describe al
+// enlace. Junto con ReadLink implementa fs.ReadLinkFS
+func (s *SistemaArchivos) Lstat(nombre string) (fs.FileInfo, error) {
+	indice, inodo, err := s.resolver("lstat", nombre, false)
+	if err != nil {
+		return nil, err
+	}
+	return s.info(path.Base(nombre), indice, inodo), nil
+}
+
+// ReadLink retorna la ruta guardada en el enlace simbolico, sin resolverla
+func (s *SistemaArchivos) ReadLink(nombre string) (string, error) {
+	_, inodo, err := s.resolver("readlink", nombre, false)
+	if err != nil {
+		return "", err
+	}
+	if !inodo.EsEnlaceSimbolico() {
+		return "", &fs.PathError{Op: "readlink", Path: nombre, Err: fs.ErrInvalid}
+	}
+	destino, err := s.sb.LeerDestinoEnlace(s.archivo, inodo)
+	if err != nil {
+		return "", &fs.PathError{Op: "readlink", Path: nombre, Err: err}
+	}
+	return destino, nil
+}
+
+// resolver valida el nombre y devuelve el inodo al que lleva; el ultimo componente se
+// sigue solo si es un enlace y seguirUltimo
+func (s *SistemaArchivos) resolver(operacion, nombre string, seguirUltimo bool) (int32, *INodo, error) {
+	if !fs.ValidPath(nombre) {
+		return -1, nil, &fs.PathError{Op: operacion, Path: nombre, Err: fs.ErrInvalid}
+	}
+	resolverRuta := s.sb.ResolverRuta
+	if !seguirUltimo {
+		resolverRuta = s.sb.ResolverRutaSinSeguir
+	}
+	resuelta, err := resolverRuta(s.archivo, "/"+strings.TrimPrefix(nombre, "."), CredencialesRoot)
+	if err != nil {
+		return -1, nil, &fs.PathError{Op: operacion, Path: nombre, Err: errorFS(err)}
+	}
+	inodo := &INodo{}
+	if err := inodo.Decodificar(s.archivo, s.sb.CalcularDesplazamientoInodo(resuelta.Inodo)); err != nil {
+		return -1, nil, &fs.PathError{Op: operacion, Path: nombre, Err: err}
+	}
+	return resuelta.Inodo, inodo, nil
+}
+
+// errorFS traduce los errores de resolucion a los que esperan los usuarios de io/fs
+func errorFS(err error) error {
+	switch {
+	case errors.Is(err, ErrRutaNoEncontrada), errors.Is(err, ErrNoEsDirectorio):
+		return fs.ErrNotExist
+	case errors.Is(err, ErrPermisoDenegado):
+		return fs.ErrPermission
+	}
+	return err
+}
+
+// entradas lee la carpeta y describe cada entrada con su propio inodo. Como en os.DirFS,
+// un enlace simbolico se reporta como enlace (ModeSymlink) aunque no lleve a ningun lado,
+// y fs.WalkDir no entra en el aunque apunte a una carpeta
+func (s *SistemaArchivos) entradas(nombre string, indice int32) ([]fs.DirEntry, error) {
+	directorio, err := s.sb.LeerDirectorio(s.archivo, indice)
+	if err != nil {
+		return nil, &fs.PathError{Op: "readdir", Path: nombre, Err: err}
+	}
+
+	var resultado []fs.DirEntry
+	for _, entrada := range directorio.Entradas() {
+		if entrada.EsEspecial() || directorio.EsOculta(entrada) {
+			continue
+		}
+		inodo := &INodo{}
+		if err := inodo.Decodificar(s.archivo, s.sb.CalcularDesplazamientoInodo(entrada.Inodo)); err != nil {
+			return nil, &fs.PathError{Op: "readdir", Path: nombre, Err: err}
+		}
+		resultado = append(resultado, fs.FileInfoToDirEntry(s.info(entrada.Nombre, entrada.Inodo, inodo)))
+	}
+	slices.SortFunc(resultado, func(a, b fs.DirEntry) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+	return resultado, nil
+}
+
+// info arma el fs.FileInfo del inodo
+func (s *SistemaArchivos) info(nombre string, indice int32, inodo *INodo) *infoInodo {
+	if nombre == "." {
+		nombre = "/"
+	}
+	return &infoInodo{nombre: nombre, indice: indice, inodo: *inodo}
+}
+
+// ModoArchivo convierte I_type e I_perm al fs.FileMode equivalente
+func (inodo *INodo) ModoArchivo() fs.FileMode {
+	var modo fs.FileMode
+	for _, digito := range inodo.I_perm {
+		modo = modo<<3 | fs.FileMode((digito-'0')&7)
+	}
+	switch inodo.I_type[0] {
+	case TipoCarpeta:
+		modo |= fs.ModeDir
+	case TipoEnlaceSimbolico:
+		modo |= fs.ModeSymlink
+	}
+	return modo
+}
+
+// infoInodo implementa fs.FileInfo; Sys retorna el *INodo
+type infoInodo struct {
+	nombre string
+	indice int32
+	inodo  INodo
+}
+
+func (i *infoInodo) Name() string       { return i.nombre }
+func (i *infoInodo) Size() int64        { return int64(i.inodo.I_size) }
+func (i *infoInodo) Mode() fs.FileMode  { return i.inodo.ModoArchivo() }
+func (i *infoInodo) ModTime() time.Time { return FechaDeMarca(i.inodo.I_mtime) }
+func (i *infoInodo) IsDir() bool        { return i.inodo.I_type[0] == TipoCarpeta }
+func (i *infoInodo) Sys() any           { return &i.inodo }
+
+// archivoFS es un archivo abierto; implementa io.ReaderAt e io.Seeker ademas de fs.File
+type archivoFS struct {
+	fs       *SistemaArchivos
+	nombre   string
+	inodo    *INodo
+	info     *infoInodo
+	posicion int64
+	cerrado  bool
+}
+
+func (a *archivoFS) Stat() (fs.FileInfo, error) {
+	if a.cerrado {
+		return nil, &fs.PathError{Op: "stat", Path: a.nombre, Err: fs.ErrClosed}
+	}
+	return a.info, nil
+}
+
+func (a *archivoFS) Read(p []byte) (int, error) {
+	if a.cerrado {
+		return 0, &fs.PathError{Op: "read", Path: a.nombre, Err: fs.ErrClosed}
+	}
+	n, err := a.inodo.ReadAt(a.fs.archivo, a.fs.sb, p, a.posicion)
+	a.posicion += int64(n)
+	if err == io.EOF && n > 0 {
+		err = nil
+	}
+	return n, err
+}
+
+func (a *archivoFS) ReadAt(p []byte, desplazamiento int64) (int, error) {
+	if a.cerrado {
+		return 0, &fs.PathError{Op: "read", Path: a.nombre, Err: fs.ErrClosed}
+	}
+	return a.inodo.ReadAt(a.fs.archivo, a.fs.sb, p, desplazamiento)
+}
+
+func (a *archivoFS) Seek(desplazamiento int64, desde int) (int64, error) {
+	if a.cerrado {
+		return 0, &fs.PathError{Op: "seek", Path: a.nombre, Err: fs.ErrClosed}
+	}
+	switch desde {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		desplazamiento += a.posicion
+	case io.SeekEnd:
+		desplazamiento += int64(a.inodo.I_size)
+	default:
+		return 0, &fs.PathError{Op: "seek", Path: a.nombre, Err: fs.ErrInvalid}
+	}
+	if desplazamiento < 0 {
+		return 0, &fs.PathError{Op: "seek", Path: a.nombre, Err: fs.ErrInvalid}
+	}
+	a.posicion = desplazamiento
+	return desplazamiento, nil
+}
+
+func (a *archivoFS) Close() error {
+	if a.cerrado {
+		return &fs.PathError{Op: "close", Path: a.nombre, Err: fs.ErrClosed}
+	}
+	a.cerrado = true
+	return nil
+}
+
+// carpetaFS es una carpeta abierta; implementa fs.ReadDirFile
+type carpetaFS struct {
+	fs       *SistemaArchivos
+	nombre   string
+	indice   int32
+	info     *infoInodo
+	entradas []fs.DirEntry // se leen en la primera llamada a ReadDir
+	leidas   bool
+	cerrado  bool
+}
+
+func (c *carpetaFS) Stat() (fs.FileInfo, error) {
+	if c.cerrado {
+		return nil, &fs.PathError{Op: "stat", Path: c.nombre, Err: fs.ErrClosed}
+	}
+	return c.info, nil
+}
+
+func (c *carpetaFS) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: c.nombre, Err: errors.New("es una carpeta")}
+}
+
+// ReadDir retorna hasta n entradas en orden; con n <= 0 retorna todas las restantes
+func (c *carpetaFS) ReadDir(n int) ([]fs.DirEntry, error) {
+	if c.cerrado {
+		return nil, &fs.PathError{Op: "readdir", Path: c.nombre, Err: fs.ErrClosed}
+	}
+	if !c.leidas {
+		entradas, err := c.fs.entradas(c.nombre, c.indice)
+		if err != nil {
+			return nil, err
+		}
+		c.entradas, c.leidas = entradas, true
+	}
+	if n <= 0 {
+		restantes := c.entradas
+		c.entradas = nil
+		return restantes, nil
+	}
+	if len(c.entradas) == 0 {
+		return nil, io.EOF
+	}
+	n = min(n, len(c.entradas))
+	lote := c.entradas[:n]
+	c.entradas = c.entradas[n:]
+	return lote, nil
+}
+
+func (c *carpetaFS) Close() error {
+	if c.cerrado {
+		return &fs.PathError{Op: "close", Path: c.nombre, Err: fs.ErrClosed}
+	}
+	c.cerrado = true
+	return nil
+}
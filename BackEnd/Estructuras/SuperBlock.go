@@ -2,6 +2,7 @@ package Estructuras
 
 import (
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"os"
 	"time"
@@ -27,16 +28,62 @@ type SuperBlock struct {
 	S_bm_block_start    int32   /*  Inicio del bitmap de bloques  */
 	S_inode_start       int32   /*  Inicio de la tabla de inodos  */
 	S_block_start       int32   /*  Inicio de la tabla de bloques  */
+	S_groups_count      int32   /*  Numero de grupos de bloques (0 = sin grupos)  */
+	S_blocks_per_group  int32   /*  Bloques por grupo  */
+	S_inodes_per_group  int32   /*  Inodos por grupo  */
+	S_gdt_start         int32   /*  Inicio de la tabla de descriptores de grupo  */
+	S_checksum          uint32  /*  CRC32 de los campos anteriores  */
 }
 
+// Bytes del SuperBlock antes de los campos de grupos y el checksum
+var tamanoSuperBlockAnterior = binary.Size(SuperBlock{}) - 5*4
+
 /*  Serializa la estructura SuperBlock en un archivo  */
 func (sb *SuperBlock) Codificar(archivo *os.File, desplazamiento int64) error {
+	if sb.formatoAnterior(desplazamiento) {
+		// Los campos nuevos pisarian el journal o el bitmap de inodos
+		sb.limpiarCamposNuevos()
+		return escribirFormatoAnterior(archivo, desplazamiento, sb, tamanoSuperBlockAnterior)
+	}
+	sb.S_checksum, _ = calcularChecksum(sb)
 	return Utils.EscribirAArchivo(archivo, desplazamiento, sb)
 }
 
-/*  Deserializa la estructura SuperBlock desde un archivo  */
+/*  Deserializa la estructura SuperBlock desde un archivo y valida su checksum  */
 func (sb *SuperBlock) Decodificar(archivo *os.File, desplazamiento int64) error {
-	return Utils.LeerDeArchivo(archivo, desplazamiento, sb)
+	if err := Utils.LeerDeArchivo(archivo, desplazamiento, sb); err != nil {
+		return err
+	}
+	anterior := sb.formatoAnterior(desplazamiento)
+	err := verificarChecksumAnterior("SuperBlock", desplazamiento, sb, sb.S_checksum, anterior)
+	if errors.Is(err, ErrSinChecksum) {
+		// Lo leido en los campos nuevos pertenece al journal o al bitmap de inodos
+		sb.limpiarCamposNuevos()
+		err = nil
+	}
+	return err
+}
+
+// formatoAnterior indica que el sistema de archivos se formateo antes de los grupos y el
+// checksum: lo que sigue al superbloque empieza donde ahora estarian esos campos
+func (sb *SuperBlock) formatoAnterior(desplazamiento int64) bool {
+	siguiente := int64(sb.S_bm_inode_start)
+	if sb.S_filesystem_type == 3 {
+		siguiente -= int64(ENTRADAS_JOURNAL * binary.Size(Journal{}))
+	}
+	return siguiente >= desplazamiento+int64(tamanoSuperBlockAnterior) &&
+		siguiente < desplazamiento+int64(binary.Size(SuperBlock{}))
+}
+
+// limpiarCamposNuevos deja en cero los campos que el formato anterior no tenia
+func (sb *SuperBlock) limpiarCamposNuevos() {
+	sb.S_groups_count, sb.S_blocks_per_group, sb.S_inodes_per_group, sb.S_gdt_start = 0, 0, 0, 0
+	sb.S_checksum = 0
+}
+
+// SinChecksum indica que el superbloque esta en el formato anterior y no se pudo verificar
+func (sb *SuperBlock) SinChecksum() bool {
+	return sb.S_checksum == 0
 }
 
 // InicioJournal retorna el byte donde inicia el journal
@@ -58,19 +105,27 @@ func (sb *SuperBlock) FinJournal() int32 {
 }
 
 func (sb *SuperBlock) CrearArchivoUsuarios(archivo *os.File) error {
+	// Con grupos los primeros bloques son metadatos: la raiz usa el primer bloque libre
+	indiceBloqueRaiz, err := sb.BuscarSiguienteBloqueLibre(archivo)
+	if err != nil {
+		return fmt.Errorf("error al encontrar el primer bloque libre para la raiz: %w", err)
+	}
+
 	inodoRaiz := &INodo{
 		I_uid:   1,
 		I_gid:   1,
 		I_size:  0,
-		I_atime: float32(time.Now().Unix()),
-		I_ctime: float32(time.Now().Unix()),
-		I_mtime: float32(time.Now().Unix()),
-		I_block: [15]int32{sb.S_blocks_count, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1},
+		I_links: 1,
+		I_atime: MarcaTiempoActual(),
+		I_ctime: MarcaTiempoActual(),
+		I_mtime: MarcaTiempoActual(),
+		I_block: [15]int32{indiceBloqueRaiz, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1},
+		I_xattr: -1,
 		I_type:  [1]byte{'0'},
 		I_perm:  [3]byte{'7', '7', '7'},
 	}
 
-	err := Utils.EscribirAArchivo(archivo, int64(sb.S_inode_start), inodoRaiz)
+	err = Utils.EscribirAArchivo(archivo, int64(sb.S_inode_start), inodoRaiz)
 	if err != nil {
 		return fmt.Errorf("error al escribir el inodo 0: %w", err)
 	}
@@ -83,23 +138,19 @@ func (sb *SuperBlock) CrearArchivoUsuarios(archivo *os.File) error {
 	// Actualizar el contador de inodos y el puntero al primer inodo libre
 	sb.ActualizarSuperblockDespuesAsignacionInodo()
 
-	bloqueRaiz := &FolderBlock{
-		B_cont: [4]FolderContent{
-			{B_name: [12]byte{'.'}, B_inodo: 0},                                                         /*  Apunta a si mismo  */
-			{B_name: [12]byte{'.', '.'}, B_inodo: 0},                                                    /*  Apunta al padre  */
-			{B_name: [12]byte{'u', 's', 'e', 'r', 's', '.', 't', 'x', 't'}, B_inodo: sb.S_inodes_count}, /*  Apunta a users.txt  */
-			{B_name: [12]byte{'-'}, B_inodo: -1},                                                        /*  Vacio  */
-		},
-	}
+	bloqueRaiz := NuevoFolderBlock(sb.S_block_size)
+	bloqueRaiz.B_cont[0] = FolderContent{B_name: [12]byte{'.'}, B_inodo: 0} /*  Apunta a si mismo  */
+	bloqueRaiz.B_cont[1] = FolderContent{B_name: [12]byte{'.', '.'}, B_inodo: 0} /*  Apunta al padre  */
+	bloqueRaiz.B_cont[2] = FolderContent{B_name: [12]byte{'u', 's', 'e', 'r', 's', '.', 't', 'x', 't'}, B_inodo: sb.S_inodes_count} /*  Apunta a users.txt  */
 
 	// Escribir el bloque raiz
-	err = Utils.EscribirAArchivo(archivo, int64(sb.S_block_start), bloqueRaiz)
+	err = bloqueRaiz.Codificar(archivo, sb.desplazamientoBloque(indiceBloqueRaiz))
 	if err != nil {
 		return fmt.Errorf("error al escribir el bloque raiz: %w", err)
 	}
 
-	// Actualizar bitmap de bloques (indice 0)
-	err = sb.ActualizarBitmapBloque(archivo, 0, true)
+	// Actualizar bitmap de bloques
+	err = sb.ActualizarBitmapBloque(archivo, indiceBloqueRaiz, true)
 	if err != nil {
 		return fmt.Errorf("error al actualizar el bitmap de bloques: %w", err)
 	}
@@ -112,16 +163,23 @@ func (sb *SuperBlock) CrearArchivoUsuarios(archivo *os.File) error {
 	usuarioRaiz := NuevoUsuario("1", "root", "root", "123")
 	textoUsuarios := fmt.Sprintf("%s\n%s\n", grupoRaiz.ToString(), usuarioRaiz.ToString())
 
+	indiceBloqueUsuarios, err := sb.BuscarSiguienteBloqueLibre(archivo)
+	if err != nil {
+		return fmt.Errorf("error al encontrar un bloque libre para users.txt: %w", err)
+	}
+
 	inodoUsuarios := &INodo{
 		I_uid:   1,
 		I_gid:   1,
 		I_size:  int32(len(textoUsuarios)),
-		I_atime: float32(time.Now().Unix()),
-		I_ctime: float32(time.Now().Unix()),
-		I_mtime: float32(time.Now().Unix()),
-		I_block: [15]int32{1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1}, // Apunta al bloque 1 (users.txt)
+		I_links: 1,
+		I_atime: MarcaTiempoActual(),
+		I_ctime: MarcaTiempoActual(),
+		I_mtime: MarcaTiempoActual(),
+		I_block: [15]int32{indiceBloqueUsuarios, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1}, // Bloque de users.txt
 		I_type:  [1]byte{'1'},                                                         // Tipo archivo
 		I_perm:  [3]byte{'7', '7', '7'},
+		I_xattr: -1,
 	}
 
 	// Escribir el inodo de users.txt (inodo 1)
@@ -139,18 +197,18 @@ func (sb *SuperBlock) CrearArchivoUsuarios(archivo *os.File) error {
 	// Actualizar el contador de inodos y el puntero al primer inodo libre
 	sb.ActualizarSuperblockDespuesAsignacionInodo()
 
-	// ----------- Crear Bloque para users.txt (bloque 1) -----------
-	bloqueUsuarios := &FileBlock{}
-	copy(bloqueUsuarios.B_cont[:], textoUsuarios)
+	// ----------- Crear Bloque para users.txt -----------
+	bloqueUsuarios := NuevoFileBlockVacio(sb.S_block_size)
+	copy(bloqueUsuarios.B_cont, textoUsuarios)
 
 	// Escribir el bloque de users.txt
-	err = Utils.EscribirAArchivo(archivo, int64(sb.S_block_start+int32(binary.Size(bloqueUsuarios))), bloqueUsuarios)
+	err = bloqueUsuarios.Codificar(archivo, sb.desplazamientoBloque(indiceBloqueUsuarios))
 	if err != nil {
 		return fmt.Errorf("error al escribir el bloque de users.txt: %w", err)
 	}
 
-	// Actualizar el bitmap de bloques (indice 1)
-	err = sb.ActualizarBitmapBloque(archivo, 1, true)
+	// Actualizar el bitmap de bloques
+	err = sb.ActualizarBitmapBloque(archivo, indiceBloqueUsuarios, true)
 	if err != nil {
 		return fmt.Errorf("error al actualizar el bitmap de bloques para users.txt: %w", err)
 	}
@@ -189,6 +247,12 @@ func (sb *SuperBlock) Imprimir() {
 	fmt.Printf("%-25s %-10d\n", "Inicio bitmap bloques:", sb.S_bm_block_start)
 	fmt.Printf("%-25s %-10d\n", "Inicio tabla inodos:", sb.S_inode_start)
 	fmt.Printf("%-25s %-10d\n", "Inicio tabla bloques:", sb.S_block_start)
+	if sb.S_groups_count > 0 {
+		fmt.Printf("%-25s %-10d\n", "Grupos de bloques:", sb.S_groups_count)
+		fmt.Printf("%-25s %-10d\n", "Bloques por grupo:", sb.S_blocks_per_group)
+		fmt.Printf("%-25s %-10d\n", "Inodos por grupo:", sb.S_inodes_per_group)
+		fmt.Printf("%-25s %-10d\n", "Inicio descriptores:", sb.S_gdt_start)
+	}
 }
 
 // Muestra los inodos desde el archivo
@@ -205,7 +269,7 @@ func (sb *SuperBlock) ImprimirInodos(ruta string) error {
 	// Deserializar todos los inodos en memoria
 	for i := int32(0); i < sb.S_inodes_count; i++ {
 		inodo := &inodos[i]
-		err := Utils.LeerDeArchivo(archivo, int64(sb.S_inode_start+(i*int32(binary.Size(INodo{})))), inodo)
+		err := inodo.Decodificar(archivo, sb.CalcularDesplazamientoInodo(i))
 		if err != nil {
 			return fmt.Errorf("fallo al decodificar inodo %d: %w", i, err)
 		}
@@ -234,29 +298,37 @@ func (sb *SuperBlock) ImprimirBloques(ruta string) error {
 	// Deserializar todos los inodos en memoria
 	for i := int32(0); i < sb.S_inodes_count; i++ {
 		inodo := &inodos[i]
-		err := Utils.LeerDeArchivo(archivo, int64(sb.S_inode_start+(i*int32(binary.Size(INodo{})))), inodo)
+		err := inodo.Decodificar(archivo, sb.CalcularDesplazamientoInodo(i))
 		if err != nil {
 			return fmt.Errorf("fallo al decodificar inodo %d: %w", i, err)
 		}
 	}
 
 	// Mostrar los bloques
-	for _, inodo := range inodos {
-		for _, indiceBloques := range inodo.I_block {
-			if indiceBloques == -1 {
-				break
-			}
+	for i := range inodos {
+		inodo := &inodos[i]
+		if inodo.I_type[0] != '0' && inodo.I_type[0] != '1' {
+			continue
+		}
+
+		// Solo bloques de datos; los de apuntadores se recorren para llegar a ellos
+		indicesBloques, err := inodo.ObtenerIndicesBloquesDatos(archivo, sb)
+		if err != nil {
+			return fmt.Errorf("fallo al obtener bloques del inodo %d: %w", i, err)
+		}
+
+		for _, indiceBloques := range indicesBloques {
 			if inodo.I_type[0] == '0' {
-				bloque := &FolderBlock{}
-				err := Utils.LeerDeArchivo(archivo, int64(sb.S_block_start+(indiceBloques*sb.S_block_size)), bloque)
+				bloque := NuevoFolderBlock(sb.S_block_size)
+				err := bloque.Decodificar(archivo, int64(sb.S_block_start+(indiceBloques*sb.S_block_size)))
 				if err != nil {
 					return fmt.Errorf("fallo al decodificar bloque carpeta %d: %w", indiceBloques, err)
 				}
 				fmt.Printf("\nBloque %d:\n", indiceBloques)
 				bloque.Imprimir()
 			} else if inodo.I_type[0] == '1' {
-				bloque := &FileBlock{}
-				err := Utils.LeerDeArchivo(archivo, int64(sb.S_block_start+(indiceBloques*sb.S_block_size)), bloque)
+				bloque := NuevoFileBlockVacio(sb.S_block_size)
+				err := bloque.Decodificar(archivo, int64(sb.S_block_start+(indiceBloques*sb.S_block_size)))
 				if err != nil {
 					return fmt.Errorf("fallo al decodificar bloque archivo %d: %w", indiceBloques, err)
 				}
@@ -269,60 +341,54 @@ func (sb *SuperBlock) ImprimirBloques(ruta string) error {
 	return nil
 }
 
-// Localiza el siguiente bloque libre y lo marca como ocupado
+// Localiza el siguiente bloque libre y lo marca como ocupado. La busqueda sigue desde la
+// ultima asignacion (o desde PreferirBloquesCercaDe) y revisa el bitmap por palabras.
+// Toda asignacion de bloques pasa por aqui, asi que aqui se cobra la cuota de quien la hace
 func (sb *SuperBlock) BuscarSiguienteBloqueLibre(archivo *os.File) (int32, error) {
-	totalBloques := sb.S_blocks_count + sb.S_free_blocks_count // Numero total de bloques
-
-	for posicion := int32(0); posicion < totalBloques; posicion++ {
-		estaLibre, err := sb.verificarBloqueLibre(archivo, sb.S_bm_block_start, posicion)
-		if err != nil {
-			return -1, fmt.Errorf("error buscando bloque libre: %w", err)
-		}
-
-		if estaLibre {
-			// Marcar el bloque como ocupado
-			err = sb.ActualizarBitmapBloque(archivo, posicion, true)
-			if err != nil {
-				return -1, fmt.Errorf("error actualizando el bitmap del bloque: %w", err)
-			}
-
-			// Devolver el indice del bloque libre encontrado
-			fmt.Println("Indice encontrado:", posicion)
-			return posicion, nil
-		}
+	if err := sb.reservarCuota(archivo, 1, 0); err != nil {
+		return -1, err
+	}
+	posicion, err := sb.asignarEnBitmap(archivo, sb.S_bm_block_start, -1)
+	if err != nil {
+		sb.reservarCuota(archivo, -1, 0)
+		return -1, fmt.Errorf("error buscando bloque libre: %w", err)
 	}
 
 	// Si no hay bloques disponibles
-	return -1, fmt.Errorf("no hay bloques disponibles")
+	if posicion == -1 {
+		sb.reservarCuota(archivo, -1, 0)
+		return -1, fmt.Errorf("no hay bloques disponibles")
+	}
+
+	fmt.Println("Indice encontrado:", posicion)
+	return posicion, nil
 }
 
-// Localiza el siguiente inodo libre en el bitmap y lo marca como ocupado
+// Localiza el primer inodo libre en el bitmap y lo marca como ocupado
 func (sb *SuperBlock) BuscarSiguienteInodoLibre(archivo *os.File) (int32, error) {
-	totalInodos := sb.S_inodes_count + sb.S_free_inodes_count // Numero total de inodos
-
-	// Recorre todos los inodos en el bitmap
-	for posicion := int32(0); posicion < totalInodos; posicion++ {
-		// Verifica si el inodo esta libre
-		estaLibre, err := sb.verificarInodoLibre(archivo, sb.S_bm_inode_start, posicion)
-		if err != nil {
-			return -1, fmt.Errorf("error buscando inodo libre en la posicion %d: %w", posicion, err)
-		}
+	return sb.buscarInodoLibreDesde(archivo, 0)
+}
 
-		// Si encontramos un inodo libre
-		if estaLibre {
-			// Marcar el inodo como ocupado
-			err = sb.ActualizarBitmapInodo(archivo, posicion, true)
-			if err != nil {
-				return -1, fmt.Errorf("error actualizando el bitmap del inodo en la posicion %d: %w", posicion, err)
-			}
-			// Devolver la posicion del inodo libre encontrado
-			fmt.Printf("Inodo libre encontrado y asignado: %d\n", posicion)
-			return posicion, nil
-		}
+// Localiza el primer inodo libre desde 'desde' (dando la vuelta) y lo marca como ocupado.
+// Como con los bloques, aqui se cobra la cuota de inodos de quien asigna
+func (sb *SuperBlock) buscarInodoLibreDesde(archivo *os.File, desde int32) (int32, error) {
+	if err := sb.reservarCuota(archivo, 0, 1); err != nil {
+		return -1, err
+	}
+	posicion, err := sb.asignarEnBitmap(archivo, sb.S_bm_inode_start, desde)
+	if err != nil {
+		sb.reservarCuota(archivo, 0, -1)
+		return -1, fmt.Errorf("error buscando inodo libre: %w", err)
 	}
 
 	// Si no hay inodos disponibles
-	return -1, fmt.Errorf("no hay inodos disponibles")
+	if posicion == -1 {
+		sb.reservarCuota(archivo, 0, -1)
+		return -1, fmt.Errorf("no hay inodos disponibles")
+	}
+
+	fmt.Printf("Inodo libre encontrado y asignado: %d\n", posicion)
+	return posicion, nil
 }
 
 // Acá | AssignNewBlock
@@ -385,14 +451,8 @@ func (sb *SuperBlock) AsignarNuevoInodo(archivo *os.File) (int32, error) {
 
 // Escribe un inodo en la posicion especificada del archivo
 func EscribirInodoAArchivo(archivo *os.File, desplazamiento int64, inodo *INodo) error {
-	// Mover el puntero al desplazamiento calculado
-	_, err := archivo.Seek(desplazamiento, 0)
-	if err != nil {
-		return fmt.Errorf("error buscando la posicion para escribir el inodo: %w", err)
-	}
-
-	// Escribir el inodo en el archivo
-	err = binary.Write(archivo, binary.LittleEndian, inodo)
+	// Pasa por la cache de la particion para no dejar una copia vieja en memoria
+	err := inodo.Codificar(archivo, desplazamiento)
 	if err != nil {
 		return fmt.Errorf("error escribiendo el inodo en el archivo: %w", err)
 	}
@@ -401,10 +461,30 @@ func EscribirInodoAArchivo(archivo *os.File, desplazamiento int64, inodo *INodo)
 }
 
 func (sb *SuperBlock) CalcularDesplazamientoInodo(indiceInodo int32) int64 {
+	// Con grupos cada grupo tiene su propia tabla de inodos
+	if sb.S_groups_count > 0 {
+		grupo := indiceInodo / sb.S_inodes_per_group
+		return sb.desplazamientoBloque(sb.DisposicionGrupo(grupo).TablaInodos) +
+			int64(indiceInodo%sb.S_inodes_per_group)*int64(sb.S_inode_size)
+	}
 	// Calcula el desplazamiento en el archivo basado en el indice del inodo
 	return int64(sb.S_inode_start) + int64(indiceInodo)*int64(sb.S_inode_size)
 }
 
+// Desplaza todas las posiciones absolutas del SuperBlock cuando la particion
+// se mueve dentro del disco (el journal se deriva del bitmap de inodos)
+func (sb *SuperBlock) Desplazar(delta int32) {
+	sb.S_bm_inode_start += delta
+	sb.S_bm_block_start += delta
+	sb.S_inode_start += delta
+	sb.S_block_start += delta
+	if sb.S_groups_count > 0 {
+		sb.S_gdt_start += delta
+	}
+	sb.S_first_ino += delta
+	sb.S_first_blo += delta
+}
+
 // Actualiza el SuperBlock despues de asignar un bloque
 func (sb *SuperBlock) ActualizarSuperblockDespuesAsignacionBloque() {
 	// Incrementa el contador de bloques asignados
@@ -480,14 +560,10 @@ func (sb *SuperBlock) CrearArchivoUsuariosExt3(archivo *os.File, inicioJournalin
         return fmt.Errorf("error al crear el inodo raíz: %w", err)
     }
 
-	bloqueRaiz := &FolderBlock{
-		B_cont: [4]FolderContent{
-			{B_name: [12]byte{'.'}, B_inodo: 0},
-			{B_name: [12]byte{'.', '.'}, B_inodo: 0},
-			{B_name: [12]byte{'u', 's', 'e', 'r', 's', '.', 't', 'x', 't'}, B_inodo: sb.S_inodes_count},
-			{B_name: [12]byte{'-'}, B_inodo: -1},
-		},
-	}
+	bloqueRaiz := NuevoFolderBlock(sb.S_block_size)
+	bloqueRaiz.B_cont[0] = FolderContent{B_name: [12]byte{'.'}, B_inodo: 0}
+	bloqueRaiz.B_cont[1] = FolderContent{B_name: [12]byte{'.', '.'}, B_inodo: 0}
+	bloqueRaiz.B_cont[2] = FolderContent{B_name: [12]byte{'u', 's', 'e', 'r', 's', '.', 't', 'x', 't'}, B_inodo: sb.S_inodes_count}
 
     err = sb.ActualizarBitmapBloque(archivo, indiceBloqueRaiz, true)
     if err != nil {
@@ -540,9 +616,7 @@ func (sb *SuperBlock) CrearArchivoUsuariosExt3(archivo *os.File, inicioJournalin
         return fmt.Errorf("error al crear el inodo de /users.txt: %w", err)
     }
 
-	bloqueUsuarios := &FileBlock{
-		B_cont: [DimensionBloque]byte{},
-	}
+	bloqueUsuarios := NuevoFileBlockVacio(sb.S_block_size)
 	bloqueUsuarios.AgregarContenido(textoUsuarios)
 	err = bloqueUsuarios.Codificar(archivo, int64(sb.S_first_blo))
     if err != nil {
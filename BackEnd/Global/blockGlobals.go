@@ -3,36 +3,23 @@ package Global
 import (
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 
 	Estructuras "backend/Estructuras"
 )
 
-// Leer todos los bloques asignados a un archivo y devuelve su contenido completo
+// Leer los bloques asignados a un archivo y devuelve sus I_size bytes de contenido
 func LeerBloquesArchivo(archivo *os.File, sb *Estructuras.SuperBlock, inodo *Estructuras.INodo) (string, error) {
-	var contenido string
-
-	for _, indiceBloques := range inodo.I_block {
-		if indiceBloques == -1 {
-			break
-		}
-
-		desplazamientoBloque := int64(sb.S_block_start + indiceBloques*int32(sb.S_block_size))
-		var bloqueArchivo Estructuras.FileBlock
-
-		// Leer el bloque desde el archivo
-		err := bloqueArchivo.Decodificar(archivo, desplazamientoBloque)
-		if err != nil {
-			return "", fmt.Errorf("error leyendo bloque %d: %w", indiceBloques, err)
-		}
-
-		// Concatenar el contenido del bloque al resultado total
-		contenido += string(bloqueArchivo.B_cont[:])
+	// Incluye los bloques alcanzados por los apuntadores indirectos
+	contenido, err := inodo.LeerDatos(archivo, sb)
+	if err != nil {
+		return "", err
 	}
 
 	inodo.ActualizarTiempoAcceso()
 
-	return strings.TrimRight(contenido, "\x00"), nil
+	return string(contenido), nil
 }
 
 func EscribirBloquesUsuarios(archivo *os.File, sb *Estructuras.SuperBlock, inodo *Estructuras.INodo, nuevoContenido string) error {
@@ -44,39 +31,11 @@ func EscribirBloquesUsuarios(archivo *os.File, sb *Estructuras.SuperBlock, inodo
 	// Combinar el contenido existente con el nuevo contenido
 	contenidoTotal := contenidoExistente + nuevoContenido
 
-	// Dividir el contenido total en bloques de dimension BlockSize
-	bloques, err := Estructuras.DividirContenido(contenidoTotal)
+	// Escribir el contenido en los bloques del inodo; los que falten se asignan
+	// como directos o a traves de los bloques de apuntadores indirectos
+	err = inodo.SobrescribirBloquesDatos(archivo, sb, []byte(contenidoTotal))
 	if err != nil {
-		return fmt.Errorf("error al dividir el contenido en bloques: %w", err)
-	}
-
-	indice := 0
-
-	for _, bloque := range bloques {
-		// Verifica si el indice excede la capacidad del array I_block
-		if indice >= len(inodo.I_block) {
-			return fmt.Errorf("se alcanzo el limite maximo de bloques del inodo")
-		}
-
-		// Si el bloque actual en el inodo esta vacio, asignar uno nuevo
-		if inodo.I_block[indice] == -1 {
-			nuevoIndiceBloques, err := sb.AsignarNuevoBloque(archivo, inodo, indice)
-			if err != nil {
-				return fmt.Errorf("error asignando nuevo bloque: %w", err)
-			}
-			inodo.I_block[indice] = nuevoIndiceBloques
-		}
-
-		desplazamientoBloque := int64(sb.S_block_start + inodo.I_block[indice]*int32(sb.S_block_size))
-
-		// Escribir el contenido del bloque en la particion
-		err = bloque.Codificar(archivo, desplazamientoBloque)
-		if err != nil {
-			return fmt.Errorf("error escribiendo el bloque %d: %w", inodo.I_block[indice], err)
-		}
-
-		// Mover al siguiente bloque
-		indice++
+		return fmt.Errorf("error escribiendo bloques de users.txt: %w", err)
 	}
 
 	// Actualizar la dimension del archivo en el inodo (i_size)
@@ -90,6 +49,16 @@ func EscribirBloquesUsuarios(archivo *os.File, sb *Estructuras.SuperBlock, inodo
 	return nil
 }
 
+// Limpia el contenido de todos los bloques de datos del archivo, directos e indirectos, y
+// deja el archivo vacio
+func LimpiarBloquesArchivo(archivo *os.File, sb *Estructuras.SuperBlock, inodo *Estructuras.INodo) error {
+	if err := inodo.SobrescribirBloquesDatos(archivo, sb, nil); err != nil {
+		return fmt.Errorf("error limpiando bloques del archivo: %w", err)
+	}
+	inodo.I_size = 0
+	return nil
+}
+
 // Inserta una nueva entrada en el archivo users.txt
 func InsertarEnArchivoUsuarios(archivo *os.File, sb *Estructuras.SuperBlock, inodo *Estructuras.INodo, entrada string) error {
 	contenidoActual, err := LeerBloquesArchivo(archivo, sb, inodo)
@@ -121,9 +90,10 @@ func InsertarEnArchivoUsuarios(archivo *os.File, sb *Estructuras.SuperBlock, ino
 		if len(partes) > 2 && partes[1] == "G" && partes[2] == grupoUsuario {
 			idGrupo = partes[0] // Obtener el ID del grupo
 
-			// Insertar el usuario justo despues del grupo si no se ha insertado ya
+			// Insertar el usuario justo despues del grupo si no se ha insertado ya; conserva
+			// su propio UID, que no es el ID del grupo
 			if idGrupo != "" && !usuarioInsertado {
-				usuarioConGrupo := fmt.Sprintf("%s,U,%s,%s,%s", idGrupo, partesEntrada[2], partesEntrada[3], partesEntrada[4])
+				usuarioConGrupo := fmt.Sprintf("%s,U,%s,%s,%s", partesEntrada[0], partesEntrada[2], partesEntrada[3], partesEntrada[4])
 				nuevoContenido = append(nuevoContenido, usuarioConGrupo)
 				usuarioInsertado = true
 			}
@@ -138,20 +108,9 @@ func InsertarEnArchivoUsuarios(archivo *os.File, sb *Estructuras.SuperBlock, ino
 	contenidoNuevo := strings.Join(nuevoContenido, "\n") + "\n"
 
 	// Limpiar los bloques asignados al archivo
-	for _, indiceBloques := range inodo.I_block {
-		if indiceBloques == -1 {
-			break // No hay mas bloques asignados
-		}
-
-		desplazamientoBloque := int64(sb.S_block_start + indiceBloques*sb.S_block_size)
-		var bloqueArchivo Estructuras.FileBlock
-
-		bloqueArchivo.LimpiarContenido()
-
-		err = bloqueArchivo.Codificar(archivo, desplazamientoBloque)
-		if err != nil {
-			return fmt.Errorf("error escribiendo bloque limpio %d: %w", indiceBloques, err)
-		}
+	err = LimpiarBloquesArchivo(archivo, sb, inodo)
+	if err != nil {
+		return err
 	}
 
 	// Reescribir todo el contenido linea por linea
@@ -204,6 +163,88 @@ func CrearUsuario(archivo *os.File, sb *Estructuras.SuperBlock, inodo *Estructur
 	return AgregarEntradaArchivoUsuarios(archivo, sb, inodo, entradaUsuario, nombreUsuario, "U")
 }
 
+// Las asignaciones de bloques e inodos se cobran a la cuota del usuario con sesion
+func init() {
+	Estructuras.CredencialesAsignacion = credencialesAsignacion
+}
+
+// credencialesAsignacion son las credenciales a las que se cobran las asignaciones. Un
+// usuario con sesion que ya no aparece en users.txt no tiene UID al que cobrarle la cuota
+func credencialesAsignacion(archivo *os.File, sb *Estructuras.SuperBlock) (Estructuras.Credenciales, error) {
+	credenciales := CredencialesActuales(archivo, sb)
+	if UsuarioActual != nil && UsuarioActual.Estado && !credenciales.Root && credenciales.Uid < 0 {
+		return credenciales, fmt.Errorf("el usuario '%s' no aparece en users.txt", UsuarioActual.Nombre)
+	}
+	return credenciales, nil
+}
+
+// Credenciales del usuario con sesion activa para resolver rutas. UID y GID se toman de
+// users.txt (UsuarioActual.Id guarda la particion). Sin sesion solo aplican los permisos de otros
+func CredencialesActuales(archivo *os.File, sb *Estructuras.SuperBlock) Estructuras.Credenciales {
+	credenciales := Estructuras.Credenciales{Uid: -1, Gid: -1}
+	if UsuarioActual == nil || !UsuarioActual.Estado {
+		return credenciales
+	}
+	if UsuarioActual.Nombre == "root" {
+		return Estructuras.CredencialesRoot
+	}
+
+	// users.txt ocupa el inodo 1
+	inodoUsuarios := &Estructuras.INodo{}
+	if err := inodoUsuarios.Decodificar(archivo, int64(sb.S_inode_start+sb.S_inode_size)); err != nil {
+		return credenciales
+	}
+	contenido, err := LeerBloquesArchivo(archivo, sb, inodoUsuarios)
+	if err != nil {
+		return credenciales
+	}
+
+	// El identificador es el primer campo de la linea del usuario y de la de su grupo
+	identificador := func(nombre, tipoEntidad string) int32 {
+		linea, _, err := buscarLineaEnArchivoUsuarios(contenido, nombre, tipoEntidad)
+		if err != nil {
+			return -1
+		}
+		id, err := strconv.Atoi(strings.Split(linea, ",")[0])
+		if err != nil {
+			return -1
+		}
+		return int32(id)
+	}
+	credenciales.Uid = identificador(UsuarioActual.Nombre, "U")
+	credenciales.Gid = identificador(UsuarioActual.Grupo, "G")
+	return credenciales
+}
+
+// Nombres del usuario y del grupo duenos de un inodo segun users.txt. Si un identificador
+// ya no existe (o fue eliminado) se muestra el numero
+func NombresPropietario(archivo *os.File, sb *Estructuras.SuperBlock, uid, gid int32) (string, string) {
+	usuario, grupo := strconv.Itoa(int(uid)), strconv.Itoa(int(gid))
+
+	inodoUsuarios := &Estructuras.INodo{}
+	if err := inodoUsuarios.Decodificar(archivo, int64(sb.S_inode_start+sb.S_inode_size)); err != nil {
+		return usuario, grupo
+	}
+	contenido, err := LeerBloquesArchivo(archivo, sb, inodoUsuarios)
+	if err != nil {
+		return usuario, grupo
+	}
+
+	for _, linea := range strings.Split(contenido, "\n") {
+		campos := strings.Split(strings.TrimSpace(linea), ",")
+		id, err := strconv.Atoi(campos[0])
+		if err != nil || id == 0 {
+			continue
+		}
+		if len(campos) == 5 && campos[1] == "U" && int32(id) == uid {
+			usuario = campos[3]
+		} else if len(campos) == 3 && campos[1] == "G" && int32(id) == gid {
+			grupo = campos[2]
+		}
+	}
+	return usuario, grupo
+}
+
 // Busca una entrada en el archivo users.txt segun nombre y tipo
 func BuscarEnArchivoUsuarios(archivo *os.File, sb *Estructuras.SuperBlock, inodo *Estructuras.INodo, nombre, tipoEntidad string) (string, error) {
 	contenido, err := LeerBloquesArchivo(archivo, sb, inodo)
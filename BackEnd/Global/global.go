@@ -4,34 +4,112 @@ import (
 	Estructuras "backend/Estructuras"
 	"errors"
 	"os"
-	"strings"
 )
 
 const Carnet string = "89" // 202200389
 var (
 	UsuarioActual       *Estructuras.Usuario = nil
 	ParticionesMontadas map[string]string    = make(map[string]string)
+	// Cache de inodos y bloques con el disco abierto de cada particion montada
+	CachesMontadas map[string]*Estructuras.CacheParticion = make(map[string]*Estructuras.CacheParticion)
 )
 
+// cacheMontada devuelve la cache de la particion, o nil si no existe o estan suspendidas
+func cacheMontada(id string) *Estructuras.CacheParticion {
+	if Estructuras.CachesSuspendidas() {
+		return nil
+	}
+	return CachesMontadas[id]
+}
+
+// RegistrarMontaje abre la cache de una particion recien montada y, si ya tiene sistema
+// de archivos, carga sus bitmaps en memoria
+func RegistrarMontaje(id string, path string) error {
+	cache, err := Estructuras.AbrirCacheParticion(id, path)
+	if err != nil {
+		return err
+	}
+	if sb, err := cache.LeerSuperBloque(); err == nil && sb.S_magic == 0xEF53 {
+		if err := cache.CargarBitmaps(sb); err != nil {
+			cache.Cerrar()
+			return err
+		}
+	}
+	CachesMontadas[id] = cache
+	return nil
+}
+
+// CerrarMontaje escribe los cambios pendientes de la particion y cierra su disco
+func CerrarMontaje(id string) error {
+	cache := CachesMontadas[id]
+	if cache == nil {
+		return nil
+	}
+	delete(CachesMontadas, id)
+	return cache.Cerrar()
+}
+
+// SincronizarParticion escribe los inodos y bloques pendientes de una particion montada
+func SincronizarParticion(id string) (int, error) {
+	cache := CachesMontadas[id]
+	if cache == nil {
+		return 0, errors.New("la partición no está montada")
+	}
+	return cache.Sincronizar()
+}
+
+// CerrarCaches sincroniza y cierra todas las caches (al salir o al detener el servidor)
+func CerrarCaches() error {
+	var primerError error
+	for id := range CachesMontadas {
+		if err := CerrarMontaje(id); err != nil && primerError == nil {
+			primerError = err
+		}
+	}
+	return primerError
+}
+
+// SuspenderCaches vacia las caches antes de un comando que accede al disco directamente
+func SuspenderCaches() error {
+	return Estructuras.SuspenderCaches()
+}
+
+// ReanudarCaches reactiva las caches y relee la ubicacion de cada particion, que el
+// comando pudo haber cambiado; si la particion ya no existe se cierra su cache
+func ReanudarCaches() {
+	Estructuras.ReanudarCaches()
+	for id, cache := range CachesMontadas {
+		if err := cache.RecargarParticion(); err != nil {
+			CerrarMontaje(id)
+		}
+	}
+}
+
 // GetMountedPartitionSuperblock obtiene el SuperBlock de la partición montada con el id especificado
 func GetMountedPartitionSuperblock(id string) (*Estructuras.SuperBlock, *Estructuras.Particion, string, error) {
 	path := ParticionesMontadas[id]
 	if path == "" {
 		return nil, nil, "", errors.New("la partición no está montada")
 	}
+	if cache := cacheMontada(id); cache != nil {
+		sb, err := cache.LeerSuperBloque()
+		if err != nil {
+			return nil, nil, "", err
+		}
+		if err := sb.ValidarFormatoInodos(); err != nil {
+			return nil, nil, "", err
+		}
+		partition := cache.Particion
+		return sb, &partition, path, nil
+	}
 	file, err := os.Open(path)
 	if err != nil {
 		return nil, nil, "", err
 	}
 	defer file.Close()
 
-	var mbr Estructuras.MBR
-	err = mbr.Decodificar(file)
-	if err != nil {
-		return nil, nil, "", err
-	}
-	
-	partition, err := mbr.ObtenerParticionPorID(id)
+	// La búsqueda considera la tabla del disco (MBR o GPT)
+	partition, err := Estructuras.BuscarParticionPorID(file, id)
 	if partition == nil {
 		return nil, nil, "", err
 	}
@@ -41,6 +119,9 @@ func GetMountedPartitionSuperblock(id string) (*Estructuras.SuperBlock, *Estruct
 	if err != nil {
 		return nil, nil, "", err
 	}
+	if err := sb.ValidarFormatoInodos(); err != nil {
+		return nil, nil, "", err
+	}
 
 	return &sb, partition, path, nil
 }
@@ -51,19 +132,18 @@ func GetMountedPartition(id string) (*Estructuras.Particion, string, error) {
 	if path == "" {
 		return nil, "", errors.New("la partición no está montada")
 	}
+	if cache := cacheMontada(id); cache != nil {
+		partition := cache.Particion
+		return &partition, path, nil
+	}
 	file, err := os.Open(path)
 	if err != nil {
 		return nil, "", err
 	}
 	defer file.Close()
 
-	var mbr Estructuras.MBR
-	err = mbr.Decodificar(file)
-	if err != nil {
-		return nil, "", err
-	}
-	
-	partition, err := mbr.ObtenerParticionPorID(id)
+	// La búsqueda considera la tabla del disco (MBR o GPT)
+	partition, err := Estructuras.BuscarParticionPorID(file, id)
 	if partition == nil {
 		return nil, "", err
 	}
@@ -71,6 +151,16 @@ func GetMountedPartition(id string) (*Estructuras.Particion, string, error) {
 	return partition, path, nil
 }
 
+// SistemaArchivosMontado abre la particion montada como fs.FS de solo lectura. Quien lo
+// usa debe llamar a Close
+func SistemaArchivosMontado(id string) (*Estructuras.SistemaArchivos, error) {
+	partition, path, err := GetMountedPartition(id)
+	if err != nil {
+		return nil, err
+	}
+	return Estructuras.AbrirSistemaArchivos(path, int64(partition.Part_start))
+}
+
 func GetMountedPartitionByName(name string) (*Estructuras.Particion, string, error) {
 	for _, path := range ParticionesMontadas {
 		file, err := os.Open(path)
@@ -78,19 +168,11 @@ func GetMountedPartitionByName(name string) (*Estructuras.Particion, string, err
 			continue
 		}
 
-		var mbr Estructuras.MBR
-		err = mbr.Decodificar(file)
+		// En GPT el nombre completo solo esta en la entrada; Part_name puede venir truncado
+		partition, _ := Estructuras.BuscarParticionPorNombre(file, name)
 		file.Close()
-		if err != nil {
-			continue
-		}
-
-		partition, _ := mbr.ObtenerParticionPorNombre(name)
 		if partition != nil {
-			partitionName := strings.Trim(string(partition.Part_name[:]), "\x00 ")
-			if strings.EqualFold(partitionName, name) {
-				return partition, path, nil
-			}
+			return partition, path, nil
 		}
 	}
 	return nil, "", errors.New("la partición con nombre '" + name + "' no está montada")
@@ -115,7 +197,7 @@ func GetMountedPartitionRep(id string) (*Estructuras.MBR, *Estructuras.SuperBloc
 		return nil, nil, "", err
 	}
 	
-	partition, err := mbr.ObtenerParticionPorID(id)
+	partition, err := Estructuras.BuscarParticionPorID(file, id)
 	if err != nil {
 		return nil, nil, "", err
 	}
@@ -139,6 +221,7 @@ func Logout() {
 		UsuarioActual.Estado = false
 		UsuarioActual = nil
 	}
+	Estructuras.ReiniciarCuotas()
 }
 
 func ValidateAccess(partitionId string) error {
@@ -60,16 +60,21 @@ func graficarBloques(dot string, sb *Estructuras.SuperBlock, archivo *os.File) (
 	var conexiones string
 	for i := int32(0); i < sb.S_inodes_count; i++ {
 		inodo := &Estructuras.INodo{}
-		err := inodo.Decodificar(archivo, int64(sb.S_inode_start+(i*sb.S_inode_size)))
+		err := inodo.Decodificar(archivo, sb.CalcularDesplazamientoInodo(i))
 		if err != nil {
 			return "", "", fmt.Errorf("error al leer inodo %d: %v", i, err)
 		}
 		if inodo.I_uid == -1 || inodo.I_uid == 0 {
 			continue
 		}
-		for _, bloque := range inodo.I_block {
-			if bloque != -1 && !visitados[bloque] {
-				dot, conexiones, err = etiquetaBloque(dot, conexiones, bloque, inodo, sb, archivo, visitados)
+		// Bloques de datos en orden logico, incluidos los alcanzados por indireccion
+		bloques, err := inodo.ObtenerIndicesBloquesDatos(archivo, sb)
+		if err != nil {
+			return "", "", fmt.Errorf("error al obtener bloques del inodo %d: %v", i, err)
+		}
+		for _, bloque := range bloques {
+			if !visitados[bloque] {
+				dot, conexiones, err = etiquetaBloque(dot, conexiones, bloque, inodo, bloques, sb, archivo, visitados)
 				if err != nil {
 					return "", "", err
 				}
@@ -80,10 +85,10 @@ func graficarBloques(dot string, sb *Estructuras.SuperBlock, archivo *os.File) (
 	return dot, conexiones, nil
 }
 
-func etiquetaBloque(dot, conexiones string, idx int32, inodo *Estructuras.INodo, sb *Estructuras.SuperBlock, archivo *os.File, visitados map[int32]bool) (string, string, error) {
+func etiquetaBloque(dot, conexiones string, idx int32, inodo *Estructuras.INodo, bloques []int32, sb *Estructuras.SuperBlock, archivo *os.File, visitados map[int32]bool) (string, string, error) {
 	offset := int64(sb.S_block_start + (idx * sb.S_block_size))
 	if inodo.I_type[0] == '0' {
-		bloqueCarpeta := &Estructuras.FolderBlock{}
+		bloqueCarpeta := Estructuras.NuevoFolderBlock(sb.S_block_size)
 		err := bloqueCarpeta.Decodificar(archivo, offset)
 		if err != nil {
 			return "", "", fmt.Errorf("error al decodificar bloque de carpeta %d: %w", idx, err)
@@ -93,6 +98,15 @@ func etiquetaBloque(dot, conexiones string, idx int32, inodo *Estructuras.INodo,
 		for i, contenido := range bloqueCarpeta.B_cont {
 			nombre := limpiarNombreBloque(contenido.B_name)
 			nombre = html.EscapeString(nombre)
+			if contenido.B_inodo == Estructuras.InodoContinuacion {
+				// Fragmento de un nombre largo, el inodo esta en el contenido principal
+				etiqueta += fmt.Sprintf("\\nContenido %d: %s (continuacion del nombre)", i+1, nombre)
+				continue
+			}
+			if inodoIndice, ok := Estructuras.InodoDeMarca(contenido); ok {
+				etiqueta += fmt.Sprintf("\\nContenido %d: (indice del directorio en inodo %d)", i+1, inodoIndice)
+				continue
+			}
 			if contenido.B_inodo != -1 && !(i == 0 || i == 1) {
 				etiqueta += fmt.Sprintf("\\nContenido %d: %s (Inodo %d)", i+1, nombre, contenido.B_inodo)
 				if contenido.B_inodo != idx {
@@ -106,46 +120,46 @@ func etiquetaBloque(dot, conexiones string, idx int32, inodo *Estructuras.INodo,
 		if tieneConexiones {
 			dot += fmt.Sprintf("bloque%d [label=\"%s\", shape=box, style=filled, fillcolor=\"#FFFDE7\", color=\"#EEEEEE\"]\n", idx, etiqueta)
 		}
-	} else if inodo.I_type[0] == '1' {
-		bloqueArchivo := &Estructuras.FileBlock{}
+	} else if inodo.I_type[0] == '1' || inodo.EsEnlaceSimbolico() {
+		bloqueArchivo := Estructuras.NuevoFileBlockVacio(sb.S_block_size)
 		err := bloqueArchivo.Decodificar(archivo, offset)
 		if err != nil {
 			return "", "", fmt.Errorf("error al decodificar bloque de archivo %d: %w", idx, err)
 		}
 		contenido := limpiarContenidoBloque(bloqueArchivo.ObtenerContenido())
 		if len(strings.TrimSpace(contenido)) > 0 {
-			etiqueta := fmt.Sprintf("BLOQUE ARCHIVO %d\\n%s", idx, contenido)
+			tipo := "ARCHIVO"
+			if inodo.EsEnlaceSimbolico() {
+				tipo = "ENLACE"
+			}
+			etiqueta := fmt.Sprintf("BLOQUE %s %d\\n%s", tipo, idx, contenido)
 			dot += fmt.Sprintf("bloque%d [label=\"%s\", shape=box, style=filled, fillcolor=\"#FFFDE7\", color=\"#EEEEEE\"]\n", idx, etiqueta)
-			siguiente := buscarSiguienteBloque(inodo, idx)
+			siguiente := buscarSiguienteBloque(bloques, idx)
 			if siguiente != -1 {
 				conexiones += fmt.Sprintf("bloque%d -> bloque%d [color=\"#FF7043\"]\n", idx, siguiente)
 			}
 		}
 	}
-	padre := buscarBloquePadre(inodo, idx)
+	padre := buscarBloquePadre(bloques, idx)
 	if padre != -1 {
 		conexiones += fmt.Sprintf("bloque%d -> bloque%d [color=\"#FF7043\"]\n", padre, idx)
 	}
 	return dot, conexiones, nil
 }
 
-func buscarBloquePadre(inodo *Estructuras.INodo, actual int32) int32 {
-	for i := 0; i < len(inodo.I_block); i++ {
-		if inodo.I_block[i] == actual && i > 0 {
-			return inodo.I_block[i-1]
+func buscarBloquePadre(bloques []int32, actual int32) int32 {
+	for i := 1; i < len(bloques); i++ {
+		if bloques[i] == actual {
+			return bloques[i-1]
 		}
 	}
 	return -1
 }
 
-func buscarSiguienteBloque(inodo *Estructuras.INodo, actual int32) int32 {
-	for i := 0; i < len(inodo.I_block); i++ {
-		if inodo.I_block[i] == actual {
-			for j := i + 1; j < len(inodo.I_block); j++ {
-				if inodo.I_block[j] != -1 {
-					return inodo.I_block[j]
-				}
-			}
+func buscarSiguienteBloque(bloques []int32, actual int32) int32 {
+	for i := 0; i < len(bloques)-1; i++ {
+		if bloques[i] == actual {
+			return bloques[i+1]
 		}
 	}
 	return -1
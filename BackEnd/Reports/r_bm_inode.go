@@ -1,7 +1,6 @@
 package Reports
 
 import (
-	"encoding/binary"
 	"fmt"
 	"os"
 	"strings"
@@ -26,19 +25,16 @@ func ReporteBMInodo(sb *Estructuras.SuperBlock, rutaDisco string, ruta string) e
 	totalInodos := sb.S_inodes_count + sb.S_free_inodes_count
 	byteCount := (totalInodos + 7) / 8
 
+	// El bitmap puede tener cambios que solo estan en memoria
+	bitmap, err := sb.LeerBitmapInodos(archivo)
+	if err != nil {
+		return fmt.Errorf("error al leer el bitmap: %v", err)
+	}
+
 	var contenido strings.Builder
 
 	for byteIndex := int32(0); byteIndex < byteCount; byteIndex++ {
-		_, err := archivo.Seek(int64(sb.S_bm_inode_start+byteIndex), 0)
-		if err != nil {
-			return fmt.Errorf("error al posicionar el archivo: %v", err)
-		}
-
-		var byteVal byte
-		err = binary.Read(archivo, binary.LittleEndian, &byteVal)
-		if err != nil {
-			return fmt.Errorf("error al leer el byte del bitmap: %v", err)
-		}
+		byteVal := bitmap[byteIndex]
 
 		for bitOffset := 0; bitOffset < 8; bitOffset++ {
 			if byteIndex*8+int32(bitOffset) >= totalInodos {
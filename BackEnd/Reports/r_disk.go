@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"sort"
 	"strings"
 
 	Utils "backend/Utils"
@@ -50,10 +51,39 @@ func ReporteDisco(mbr *Estructuras.MBR, ruta string, rutaDisco string) error {
 	tamTotal := mbr.MbrSize
 	tamUsado := int32(0)
 
-	dot += "{MBR}"
+	if mbr.EsProtectorGPT() {
+		// Disco GPT: MBR protector, tabla GPT y particiones ordenadas con sus huecos
+		var gpt Estructuras.GPT
+		if err := gpt.Decodificar(archivo); err != nil {
+			return fmt.Errorf("error al leer la tabla GPT: %v", err)
+		}
+		dot += "{MBR protector}|{GPT}"
+		tamUsado = gpt.Encabezado.GptPrimerByteUsable
+
+		var segmentos []Estructuras.EntradaGPT
+		for _, entrada := range gpt.Entradas {
+			if entrada.EnUso() {
+				segmentos = append(segmentos, entrada)
+			}
+		}
+		sort.Slice(segmentos, func(i, j int) bool { return segmentos[i].Part_start < segmentos[j].Part_start })
+
+		cursor := gpt.Encabezado.GptPrimerByteUsable
+		for _, entrada := range segmentos {
+			if entrada.Part_start > cursor {
+				dot += fmt.Sprintf("|Libre %.2f%%", float64(entrada.Part_start-cursor)/float64(tamTotal)*100)
+			}
+			dot += fmt.Sprintf("|{Primaria %s\\n%.2f%%}", entrada.Nombre(), float64(entrada.Part_size)/float64(tamTotal)*100)
+			cursor = entrada.Part_start + entrada.Part_size
+		}
+		// El espacio libre al final se agrega con el calculo comun
+		tamUsado = cursor
+	} else {
+		dot += "{MBR}"
+	}
 
 	for _, part := range mbr.MbrPartitions {
-		if part.Part_size > 0 {
+		if part.Part_size > 0 && part.Part_type[0] != Estructuras.TipoProtectorGPT {
 			porcentaje := (float64(part.Part_size) / float64(tamTotal)) * 100
 			tamUsado += part.Part_size
 
@@ -2,6 +2,7 @@ package Reports
 
 import (
 	"fmt"
+	"html"
 	"os"
 	"os/exec"
 	"time"
@@ -66,14 +67,14 @@ func iniciarDotInodos() string {
 func graficarInodos(dot string, sb *Estructuras.SuperBlock, archivo *os.File) (string, error) {
 	for i := int32(0); i < sb.S_inodes_count; i++ {
 		inodo := &Estructuras.INodo{}
-		err := inodo.Decodificar(archivo, int64(sb.S_inode_start+(i*sb.S_inode_size)))
+		err := inodo.Decodificar(archivo, sb.CalcularDesplazamientoInodo(i))
 		if err != nil {
 			return "", fmt.Errorf("error al leer inodo %d: %v", i, err)
 		}
 		if inodo.I_uid == -1 || inodo.I_uid == 0 {
 			continue
 		}
-		dot += tablaInodo(i, inodo)
+		dot += tablaInodo(i, inodo, atributosInodo(sb, archivo, inodo))
 		if i < sb.S_inodes_count-1 {
 			dot += fmt.Sprintf("inodo%d -> inodo%d [color=\"#FF7043\"]\n", i, i+1)
 		}
@@ -81,29 +82,31 @@ func graficarInodos(dot string, sb *Estructuras.SuperBlock, archivo *os.File) (s
 	return dot, nil
 }
 
-func tablaInodo(idx int32, inodo *Estructuras.INodo) string {
-	atime := time.Unix(int64(inodo.I_atime), 0).Format(time.RFC3339)
-	ctime := time.Unix(int64(inodo.I_ctime), 0).Format(time.RFC3339)
-	mtime := time.Unix(int64(inodo.I_mtime), 0).Format(time.RFC3339)
+func tablaInodo(idx int32, inodo *Estructuras.INodo, atributos string) string {
+	atime := Estructuras.FechaDeMarca(inodo.I_atime).Format(time.RFC3339Nano)
+	ctime := Estructuras.FechaDeMarca(inodo.I_ctime).Format(time.RFC3339Nano)
+	mtime := Estructuras.FechaDeMarca(inodo.I_mtime).Format(time.RFC3339Nano)
 	tabla := fmt.Sprintf(`inodo%d [label=<
 		<table border="0" cellborder="1" cellspacing="0" cellpadding="4" bgcolor="#FFFDE7" style="rounded">
 			<tr><td colspan="2" bgcolor="#4CAF50" align="center"><b>INODO %d</b></td></tr>
 			<tr><td><b>uid</b></td><td>%d</td></tr>
 			<tr><td><b>gid</b></td><td>%d</td></tr>
 			<tr><td><b>size</b></td><td>%d</td></tr>
+			<tr><td><b>links</b></td><td>%d</td></tr>
 			<tr><td><b>atime</b></td><td>%s</td></tr>
 			<tr><td><b>ctime</b></td><td>%s</td></tr>
 			<tr><td><b>mtime</b></td><td>%s</td></tr>
 			<tr><td><b>type</b></td><td>%c</td></tr>
 			<tr><td><b>perm</b></td><td>%s</td></tr>
 			<tr><td colspan="2" bgcolor="#FF9800"><b>BLOQUES DIRECTOS</b></td></tr>
-	`, idx, idx, inodo.I_uid, inodo.I_gid, inodo.I_size, atime, ctime, mtime, rune(inodo.I_type[0]), string(inodo.I_perm[:]))
+	`, idx, idx, inodo.I_uid, inodo.I_gid, inodo.I_size, inodo.I_links, atime, ctime, mtime, rune(inodo.I_type[0]), string(inodo.I_perm[:]))
 	for j, bloque := range inodo.I_block[:12] {
 		if bloque != -1 {
 			tabla += fmt.Sprintf("<tr><td><b>%d</b></td><td>%d</td></tr>", j+1, bloque)
 		}
 	}
 	tabla += bloquesIndirectos(inodo)
+	tabla += atributos
 	tabla += "</table>>];"
 	return tabla
 }
@@ -131,6 +134,25 @@ func bloquesIndirectos(inodo *Estructuras.INodo) string {
 	return res
 }
 
+// atributosInodo arma las filas con el bloque y los atributos extendidos del inodo
+func atributosInodo(sb *Estructuras.SuperBlock, archivo *os.File, inodo *Estructuras.INodo) string {
+	if inodo.I_xattr == -1 {
+		return ""
+	}
+	res := fmt.Sprintf(`
+			<tr><td colspan="2" bgcolor="#9C27B0"><b>ATRIBUTOS (bloque %d)</b></td></tr>
+		`, inodo.I_xattr)
+	atributos, err := sb.LeerAtributos(archivo, inodo)
+	if err != nil {
+		return res + fmt.Sprintf("<tr><td colspan=\"2\">%s</td></tr>", html.EscapeString(err.Error()))
+	}
+	for _, atributo := range atributos {
+		res += fmt.Sprintf("<tr><td><b>%s</b></td><td>%s</td></tr>",
+			html.EscapeString(atributo.Nombre), html.EscapeString(fmt.Sprintf("%q", atributo.Valor)))
+	}
+	return res
+}
+
 func escribirArchivoDot(nombreDot string, dot string) error {
 	archivo, err := os.Create(nombreDot)
 	if err != nil {
@@ -1,12 +1,11 @@
 package Reports
 
 import (
-	"encoding/binary"
 	"fmt"
+	"html"
 	"os"
 	"strconv"
 	"strings"
-	"time"
 
 	Estructuras "backend/Estructuras"
 	Global "backend/Global"
@@ -16,7 +15,7 @@ import (
 // Obtiene el nombre de usuario a partir del UID buscando en users.txt
 func obtenerNombreUsuarioPorUID(sb *Estructuras.SuperBlock, archivo *os.File, uid int32) string {
 	var inodoUsuarios Estructuras.INodo
-	desplazamientoInodo := int64(sb.S_inode_start + int32(binary.Size(inodoUsuarios)))
+	desplazamientoInodo := sb.CalcularDesplazamientoInodo(1)
 	err := inodoUsuarios.Decodificar(archivo, desplazamientoInodo)
 	if err != nil {
 		return "root"
@@ -41,7 +40,7 @@ func obtenerNombreUsuarioPorUID(sb *Estructuras.SuperBlock, archivo *os.File, ui
 // Obtiene el nombre del grupo a partir del GID buscando en users.txt
 func obtenerNombreGrupoPorGID(sb *Estructuras.SuperBlock, archivo *os.File, gid int32) string {
 	var inodoUsuarios Estructuras.INodo
-	desplazamientoInodo := int64(sb.S_inode_start + int32(binary.Size(inodoUsuarios)))
+	desplazamientoInodo := sb.CalcularDesplazamientoInodo(1)
 	err := inodoUsuarios.Decodificar(archivo, desplazamientoInodo)
 	if err != nil {
 		return "root"
@@ -112,6 +111,7 @@ func iniciarDotLs() string {
             <table border="0" cellborder="1" cellspacing="0" cellpadding="6" bgcolor="#FFF9C4" style="rounded">
                 <tr>
                     <td bgcolor="#4CAF50"><b>Permisos</b></td>
+                    <td bgcolor="#4CAF50"><b>Enlaces</b></td>
                     <td bgcolor="#4CAF50"><b>Owner</b></td>
                     <td bgcolor="#4CAF50"><b>Grupo</b></td>
                     <td bgcolor="#4CAF50"><b>Size (bytes)</b></td>
@@ -126,57 +126,49 @@ func iniciarDotLs() string {
 // Debes implementar esta función para recorrer la ruta y obtener los datos de cada archivo/carpeta
 func obtenerFilasLs(sb *Estructuras.SuperBlock, archivo *os.File, rutaLs string) (string, error) {
 	// Buscar el inodo raíz o el correspondiente a rutaLs
-	indiceInodo := int32(0)
-	if rutaLs != "/" && rutaLs != "" {
-		var err error
-		indiceInodo, err = buscarInodoArchivo(sb, archivo, rutaLs)
-		if err != nil {
-			return "", fmt.Errorf("no se encontró el directorio: %v", err)
-		}
+	indiceInodo, err := sb.ResolverDirectorio(archivo, rutaLs, Estructuras.CredencialesRoot)
+	if err != nil {
+		return "", fmt.Errorf("no se encontró el directorio: %w", err)
 	}
 
-	inodo, err := leerInodo(sb, archivo, indiceInodo)
+	directorio, err := sb.LeerDirectorio(archivo, indiceInodo)
 	if err != nil {
-		return "", fmt.Errorf("error al leer inodo: %v", err)
+		return "", fmt.Errorf("error al leer el directorio: %v", err)
 	}
 
 	var filas string
-	for _, idxBloque := range inodo.I_block {
-		if idxBloque == -1 {
+	for _, contenido := range directorio.Entradas() {
+		nombre := contenido.Nombre
+		if nombre == "" || contenido.EsEspecial() || directorio.EsOculta(contenido) {
 			continue
 		}
-		bloque := &Estructuras.FolderBlock{}
-		offset := int64(sb.S_block_start + idxBloque*sb.S_block_size)
-		err := bloque.Decodificar(archivo, offset)
+		inodoHijo, err := leerInodo(sb, archivo, contenido.Inodo)
 		if err != nil {
 			continue
 		}
-		for _, contenido := range bloque.B_cont {
-			nombre := strings.Trim(string(contenido.B_name[:]), "\x00 ")
-			if nombre == "" || nombre == "." || nombre == ".." {
-				continue
-			}
-			inodoHijo, err := leerInodo(sb, archivo, contenido.B_inodo)
-			if err != nil {
-				continue
-			}
-			permisos := string(inodoHijo.I_perm[:])
-
-			owner := obtenerNombreUsuarioPorUID(sb, archivo, inodoHijo.I_uid)
-			grupo := obtenerNombreGrupoPorGID(sb, archivo, inodoHijo.I_gid)
-			
-			size := inodoHijo.I_size
-			fecha := time.Unix(int64(inodoHijo.I_ctime), 0).Format("2006-01-02")
-			hora := time.Unix(int64(inodoHijo.I_ctime), 0).Format("15:04:05")
-			tipo := "Archivo"
-			if inodoHijo.I_type[0] == '0' {
-				tipo = "Carpeta"
+		permisos := string(inodoHijo.I_perm[:])
+
+		owner := obtenerNombreUsuarioPorUID(sb, archivo, inodoHijo.I_uid)
+		grupo := obtenerNombreGrupoPorGID(sb, archivo, inodoHijo.I_gid)
+		
+		size := inodoHijo.I_size
+		// Como ls -l se muestra la ultima modificacion del contenido
+		fecha := Estructuras.FechaDeMarca(inodoHijo.I_mtime).Format("2006-01-02")
+		hora := Estructuras.FechaDeMarca(inodoHijo.I_mtime).Format("15:04:05")
+		tipo := "Archivo"
+		if inodoHijo.I_type[0] == '0' {
+			tipo = "Carpeta"
+		} else if inodoHijo.EsEnlaceSimbolico() {
+			// Como ls -l, el enlace se muestra con su destino sin seguirlo
+			tipo = "Enlace"
+			if destino, err := sb.LeerDestinoEnlace(archivo, inodoHijo); err == nil {
+				nombre += " -> " + destino
 			}
-			filas += fmt.Sprintf(
-				"<tr><td>%s</td><td>%s</td><td>%s</td><td>%d</td><td>%s</td><td>%s</td><td>%s</td><td>%s</td></tr>\n",
-				permisos, owner, grupo, size, fecha, hora, tipo, nombre,
-			)
 		}
+		filas += fmt.Sprintf(
+			"<tr><td>%s</td><td>%d</td><td>%s</td><td>%s</td><td>%d</td><td>%s</td><td>%s</td><td>%s</td><td>%s</td></tr>\n",
+			permisos, inodoHijo.I_links, owner, grupo, size, fecha, hora, tipo, html.EscapeString(nombre),
+		)
 	}
 	return filas, nil
 }
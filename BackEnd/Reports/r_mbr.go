@@ -9,6 +9,8 @@ import (
 
 	Estructuras "backend/Estructuras"
 	Utils "backend/Utils"
+
+	"github.com/google/uuid"
 )
 
 // Genera el reporte visual del MBR
@@ -22,6 +24,11 @@ func ReporteMBR(mbr *Estructuras.MBR, ruta string, archivo *os.File) error {
 	// Nombres de archivos dot y png
 	dotFileName, outputImage := Utils.ObtenerNombresArchivos(ruta)
 
+	// Los discos GPT muestran el encabezado y el arreglo de entradas
+	if mbr.EsProtectorGPT() {
+		return reporteGPT(mbr, archivo, dotFileName, outputImage)
+	}
+
 	colorPrimaria := "#B0B8C1"   // Primarias
 	colorExtendida := "#A7A9AC"  // Extendidas
 	colorLogica := "#5B7FA3"     // Lógicas
@@ -126,7 +133,7 @@ func ReporteMBR(mbr *Estructuras.MBR, ruta string, archivo *os.File) error {
 					if ebr.Ebr_size > 0 {
 						dotContent += fmt.Sprintf(`
                             <tr><td colspan="2" bgcolor="%s"><b>PART. LÓGICA (Inicio: %d)</b></td></tr>
-                        `, colorLogica, ebr.Ebr_start)
+                        `, colorLogica, ebr.InicioDatos(mbr.EsCompatible()))
 					}
 					tamanoAsignado += ebr.Ebr_size
 					inicioEBR = int32(ebr.Ebr_next)
@@ -167,3 +174,85 @@ func ReporteMBR(mbr *Estructuras.MBR, ruta string, archivo *os.File) error {
 	fmt.Println("Imagen de la tabla generada:", outputImage)
 	return nil
 }
+
+// Genera el reporte visual de un disco con tabla GPT
+func reporteGPT(mbr *Estructuras.MBR, archivo *os.File, dotFileName, outputImage string) error {
+	var gpt Estructuras.GPT
+	if err := gpt.Decodificar(archivo); err != nil {
+		return fmt.Errorf("error al leer la tabla GPT: %v", err)
+	}
+
+	colorEncabezado := "#D6EAF8" // Encabezado GPT
+	colorEntrada := "#B0B8C1"    // Entradas en uso
+
+	guidDisco, _ := uuid.FromBytes(gpt.Encabezado.GptDiskGuid[:])
+	dotContent := fmt.Sprintf(`digraph G {
+        node [shape=plaintext]
+        tabla [label=<
+            <table border="0" cellborder="1" cellspacing="0">
+                <tr><td colspan="2" bgcolor="#F8D7DA"><b>REPORTE GPT</b></td></tr>
+                <tr><td bgcolor="#F5B7B1">mbr_tamano</td><td bgcolor="#F5B7B1">%d</td></tr>
+                <tr><td bgcolor="#F5B7B1">mbr_disk_signature</td><td bgcolor="#F5B7B1">%d</td></tr>
+                <tr><td bgcolor="%s">gpt_fecha_creacion</td><td bgcolor="%s">%s</td></tr>
+                <tr><td bgcolor="%s">gpt_disk_guid</td><td bgcolor="%s">%s</td></tr>
+                <tr><td bgcolor="%s">gpt_inicio_entradas</td><td bgcolor="%s">%d</td></tr>
+                <tr><td bgcolor="%s">gpt_numero_entradas</td><td bgcolor="%s">%d</td></tr>
+                <tr><td bgcolor="%s">gpt_primer_byte_usable</td><td bgcolor="%s">%d</td></tr>
+                <tr><td bgcolor="%s">gpt_ultimo_byte_usable</td><td bgcolor="%s">%d</td></tr>
+            `, mbr.MbrSize, mbr.MbrDiskSignature,
+		colorEncabezado, colorEncabezado, time.Unix(int64(gpt.Encabezado.GptCreacionDate), 0),
+		colorEncabezado, colorEncabezado, guidDisco,
+		colorEncabezado, colorEncabezado, gpt.Encabezado.GptInicioEntradas,
+		colorEncabezado, colorEncabezado, gpt.Encabezado.GptNumeroEntradas,
+		colorEncabezado, colorEncabezado, gpt.Encabezado.GptPrimerByteUsable,
+		colorEncabezado, colorEncabezado, gpt.Encabezado.GptUltimoByteUsable)
+
+	for i, entrada := range gpt.Entradas {
+		if !entrada.EnUso() {
+			continue
+		}
+		guid, _ := uuid.FromBytes(entrada.Part_guid[:])
+		tipo, _ := uuid.FromBytes(entrada.Part_type_guid[:])
+		dotContent += fmt.Sprintf(`
+                <tr><td colspan="2" bgcolor="%s"><b>ENTRADA %d</b></td></tr>
+                <tr><td bgcolor="%s">part_type_guid</td><td bgcolor="%s">%s</td></tr>
+                <tr><td bgcolor="%s">part_guid</td><td bgcolor="%s">%s</td></tr>
+                <tr><td bgcolor="%s">part_status</td><td bgcolor="%s">%c</td></tr>
+                <tr><td bgcolor="%s">part_fit</td><td bgcolor="%s">%c</td></tr>
+                <tr><td bgcolor="%s">part_start</td><td bgcolor="%s">%d</td></tr>
+                <tr><td bgcolor="%s">part_size</td><td bgcolor="%s">%d</td></tr>
+                <tr><td bgcolor="%s">part_name</td><td bgcolor="%s">%s</td></tr>
+            `, colorEntrada, i+1,
+			colorEntrada, colorEntrada, tipo,
+			colorEntrada, colorEntrada, guid,
+			colorEntrada, colorEntrada, entrada.Part_status[0],
+			colorEntrada, colorEntrada, entrada.Part_fit[0],
+			colorEntrada, colorEntrada, entrada.Part_start,
+			colorEntrada, colorEntrada, entrada.Part_size,
+			colorEntrada, colorEntrada, entrada.Nombre())
+	}
+
+	dotContent += "</table>>] }"
+
+	// Guarda dot
+	archivoDot, err := os.Create(dotFileName)
+	if err != nil {
+		return fmt.Errorf("error al crear el archivo: %v", err)
+	}
+	defer archivoDot.Close()
+
+	_, err = archivoDot.WriteString(dotContent)
+	if err != nil {
+		return fmt.Errorf("error al escribir en el archivo: %v", err)
+	}
+
+	// Ejecuta Graphviz
+	cmd := exec.Command("dot", "-Tpng", dotFileName, "-o", outputImage)
+	err = cmd.Run()
+	if err != nil {
+		return fmt.Errorf("error al ejecutar Graphviz: %v", err)
+	}
+
+	fmt.Println("Imagen de la tabla GPT generada:", outputImage)
+	return nil
+}
@@ -69,6 +69,10 @@ func dotSuperbloque(sb *Estructuras.SuperBlock) string {
 					<tr><td><b>Inicio Bitmap Bloques</b></td><td>%d</td></tr>
 					<tr><td><b>Inicio Tabla Inodos</b></td><td>%d</td></tr>
 					<tr><td><b>Inicio Tabla Bloques</b></td><td>%d</td></tr>
+					<tr><td><b>Grupos de Bloques</b></td><td>%d</td></tr>
+					<tr><td><b>Bloques por Grupo</b></td><td>%d</td></tr>
+					<tr><td><b>Inodos por Grupo</b></td><td>%d</td></tr>
+					<tr><td><b>Inicio Descriptores</b></td><td>%d</td></tr>
 					<tr><td><b>Última Modificación</b></td><td>%s</td></tr>
 					<tr><td><b>Último Montaje</b></td><td>%s</td></tr>
 					<tr><td><b>Número de Montajes</b></td><td>%d</td></tr>
@@ -90,6 +94,10 @@ func dotSuperbloque(sb *Estructuras.SuperBlock) string {
 		sb.S_bm_block_start,
 		sb.S_inode_start,
 		sb.S_block_start,
+		sb.S_groups_count,
+		sb.S_blocks_per_group,
+		sb.S_inodes_per_group,
+		sb.S_gdt_start,
 		mtime,
 		umtime,
 		sb.S_mnt_count,
@@ -14,6 +14,8 @@ type DirectoryTree struct {
 	Name     string
 	Children []*DirectoryTree
 	IsDir    bool
+	IsLink   bool
+	Target   string // destino de los enlaces simbolicos
 }
 
 // buildDirectoryTree construye recursivamente el árbol de directorios a partir del inodo indicado y el path actual.
@@ -30,37 +32,34 @@ func buildDirectoryTree(sb *Estructuras.SuperBlock, archivo *os.File, inodeIndex
 		currentName = pathSegments[len(pathSegments)-1]
 	}
 	tree := &DirectoryTree{
-		Name:  currentName,
-		IsDir: inodo.I_type[0] == '0',
+		Name:   currentName,
+		IsDir:  inodo.I_type[0] == '0',
+		IsLink: inodo.EsEnlaceSimbolico(),
+	}
+	// Los enlaces simbolicos se muestran con su destino pero no se recorren
+	if tree.IsLink {
+		tree.Target, err = sb.LeerDestinoEnlace(archivo, inodo)
+		if err != nil {
+			return nil, fmt.Errorf("error al leer el destino del enlace '%s': %v", currentPath, err)
+		}
 	}
 	if !tree.IsDir {
 		return tree, nil
 	}
-	for _, blockIndex := range inodo.I_block {
-		if blockIndex == -1 {
-			break
+	directorio, err := sb.LeerDirectorio(archivo, inodeIndex)
+	if err != nil {
+		return nil, fmt.Errorf("error al leer el directorio '%s': %v", currentPath, err)
+	}
+	for _, content := range directorio.Entradas() {
+		if content.EsEspecial() || directorio.EsOculta(content) {
+			continue
 		}
-		bloque := &Estructuras.FolderBlock{}
-		offset := int64(sb.S_block_start + blockIndex*sb.S_block_size)
-		err := bloque.Decodificar(archivo, offset)
+		childPath := currentPath + "/" + content.Nombre
+		childNode, err := buildDirectoryTree(sb, archivo, content.Inodo, childPath)
 		if err != nil {
-			return nil, fmt.Errorf("error al deserializar el bloque %d: %v", blockIndex, err)
-		}
-		for _, content := range bloque.B_cont {
-			if content.B_inodo == -1 {
-				continue
-			}
-			nombre := strings.Trim(string(content.B_name[:]), "\x00 ")
-			if nombre == "." || nombre == ".." {
-				continue
-			}
-			childPath := currentPath + "/" + nombre
-			childNode, err := buildDirectoryTree(sb, archivo, content.B_inodo, childPath)
-			if err != nil {
-				return nil, fmt.Errorf("error al construir el árbol para '%s': %v", childPath, err)
-			}
-			tree.Children = append(tree.Children, childNode)
+			return nil, fmt.Errorf("error al construir el árbol para '%s': %v", childPath, err)
 		}
+		tree.Children = append(tree.Children, childNode)
 	}
 	return tree, nil
 }
@@ -93,6 +92,10 @@ func generateDirectoryTreeDot(sb *Estructuras.SuperBlock, archivo *os.File) (str
 			fill = "#4285F4"
 			font = "#FFFFFF"
 			border = "#2B579A"
+		} else if node.IsLink {
+			fill = "#FBBC05"
+			font = "#000000"
+			border = "#B8860B"
 		} else {
 			fill = "#34A853"
 			font = "#FFFFFF"
@@ -105,6 +108,9 @@ func generateDirectoryTreeDot(sb *Estructuras.SuperBlock, archivo *os.File) (str
 			if label == "/" {
 				label = "ROOT"
 			}
+		} else if node.IsLink {
+			shape = "cds"
+			label = node.Name + " -> " + node.Target
 		}
 		lines = append(lines, fmt.Sprintf(
 			"    %s [label=%q fillcolor=%q fontcolor=%q color=%q shape=%s];",
@@ -122,11 +122,11 @@ func EliminarElemento[T any](slice []T, indice int) []T {
 	return append(slice[:indice], slice[indice+1:]...)
 }
 
-// Divide una cadena en pedazos y las almacena en una lista
-func DividirCadenaEnChunks(s string) []string {
+// Divide una cadena en pedazos de la dimension de bloque y las almacena en una lista
+func DividirCadenaEnChunks(s string, dimension int) []string {
 	var chunks []string
-	for i := 0; i < len(s); i += 64 {
-		fin := i + 64
+	for i := 0; i < len(s); i += dimension {
+		fin := i + dimension
 		if fin > len(s) {
 			fin = len(s)
 		}
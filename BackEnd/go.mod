@@ -4,8 +4,7 @@ go 1.23
 
 require (
 	github.com/gofiber/fiber/v2 v2.52.9 // Web/API
-	github.com/google/uuid v1.6.0 // indirect
-	// Para generar IDs únicos
+	github.com/google/uuid v1.6.0 // Para generar IDs únicos
 )
 
 require (
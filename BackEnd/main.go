@@ -3,10 +3,14 @@ package main
 import (
 	"fmt"
 	"log"
+	"os"
+	"os/signal"
 	"strings"
+	"syscall"
 
 	Analizador "backend/Analizador"
 	usercmds "backend/Comandos/User"
+	Global "backend/Global"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
@@ -106,5 +110,19 @@ func main() {
 	// register partition routes
 	partitions.RegisterRoutes(app)
 
-	log.Fatal(app.Listen(":3000"))
+	// Al recibir Ctrl+C o SIGTERM se detiene el servidor para sincronizar las particiones
+	go func() {
+		senales := make(chan os.Signal, 1)
+		signal.Notify(senales, os.Interrupt, syscall.SIGTERM)
+		<-senales
+		app.Shutdown()
+	}()
+
+	err := app.Listen(":3000")
+	if errCaches := Global.CerrarCaches(); errCaches != nil {
+		log.Println("Error sincronizando particiones:", errCaches)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
 }